@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Adaptive refresh settings; set from --adaptive and friends.
+var (
+	adaptiveRefresh     bool
+	adaptiveMin         = refreshInterval
+	adaptiveMax         = 10 * time.Second
+	adaptiveSensitivity = 1.0
+)
+
+// Change between consecutive snapshots that counts as "something is happening"
+// at sensitivity 1. Higher sensitivity divides these, so smaller moves count.
+const (
+	adaptiveBatteryStep = 1.0  // Battery percent points
+	adaptiveTempStep    = 2.0  // CPU °C
+	adaptiveCPUStep     = 15.0 // CPU usage percent points
+)
+
+// adaptiveInterval picks the next refresh delay: back to min as soon as a snapshot
+// differs noticeably from the last one, doubling towards max while readings hold
+// steady. An idle machine is then polled a fraction as often as a busy one.
+type adaptiveInterval struct {
+	min, max    time.Duration
+	sensitivity float64
+	current     time.Duration
+	last        *MetricsSnapshot
+}
+
+func newAdaptiveInterval(lo, hi time.Duration, sensitivity float64) (*adaptiveInterval, error) {
+	if lo <= 0 || hi < lo {
+		return nil, fmt.Errorf("adaptive interval needs 0 < min <= max, got %v and %v", lo, hi)
+	}
+	if sensitivity <= 0 {
+		return nil, fmt.Errorf("adaptive sensitivity must be positive, got %g", sensitivity)
+	}
+	return &adaptiveInterval{min: lo, max: hi, sensitivity: sensitivity, current: lo}, nil
+}
+
+// Next records m and returns how long to wait before the next collection.
+func (a *adaptiveInterval) Next(m MetricsSnapshot) time.Duration {
+	switch {
+	case a.last == nil:
+	case a.changed(*a.last, m):
+		a.current = a.min
+	default:
+		a.current = min(a.current*2, a.max)
+	}
+	a.last = &m
+	return a.current
+}
+
+// changed reports whether cur moved enough from prev to poll quickly again.
+func (a *adaptiveInterval) changed(prev, cur MetricsSnapshot) bool {
+	exceeds := func(prev, cur, step float64) bool {
+		return math.Abs(cur-prev) >= step/a.sensitivity
+	}
+	pb, prevOK := prev.PrimaryBattery()
+	cb, curOK := cur.PrimaryBattery()
+	if prevOK != curOK {
+		return true
+	}
+	if curOK && (pb.Status != cb.Status || exceeds(pb.Percent, cb.Percent, adaptiveBatteryStep)) {
+		return true
+	}
+	if prev.Thermal.CPUTemp > 0 && cur.Thermal.CPUTemp > 0 && exceeds(prev.Thermal.CPUTemp, cur.Thermal.CPUTemp, adaptiveTempStep) {
+		return true
+	}
+	if prev.Thermal.Level != cur.Thermal.Level {
+		return true
+	}
+	return exceeds(prev.CPU.Usage, cur.CPU.Usage, adaptiveCPUStep)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveIntervalBacksOffWhenSteady(t *testing.T) {
+	a, err := newAdaptiveInterval(time.Second, 8*time.Second, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steady := MetricsSnapshot{
+		Batteries: []BatteryStatus{{Name: "InternalBattery-0", Percent: 80, Status: "discharging"}},
+		Thermal:   ThermalStatus{CPUTemp: 50},
+		CPU:       CPUStatus{Usage: 5},
+	}
+	var got []time.Duration
+	for range 6 {
+		got = append(got, a.Next(steady))
+	}
+	want := []time.Duration{1, 2, 4, 8, 8, 8}
+	for i := range want {
+		if got[i] != want[i]*time.Second {
+			t.Fatalf("steady intervals = %v, want doubling to the 8s cap", got)
+		}
+	}
+
+	// A small wobble below the thresholds keeps the slow pace.
+	wobble := steady
+	wobble.Thermal.CPUTemp = 51
+	wobble.CPU.Usage = 12
+	if d := a.Next(wobble); d != 8*time.Second {
+		t.Fatalf("sub-threshold change reset the interval to %v", d)
+	}
+
+	hot := wobble
+	hot.Thermal.CPUTemp = 55
+	if d := a.Next(hot); d != time.Second {
+		t.Fatalf("rising temperature should poll at min, got %v", d)
+	}
+	if d := a.Next(hot); d != 2*time.Second {
+		t.Fatalf("interval should back off again once steady, got %v", d)
+	}
+}
+
+func TestAdaptiveIntervalReactsToBattery(t *testing.T) {
+	a, _ := newAdaptiveInterval(time.Second, time.Minute, 1)
+	base := MetricsSnapshot{Batteries: []BatteryStatus{{Percent: 62, Status: "discharging"}}}
+	a.Next(base)
+	a.Next(base)
+
+	plugged := base
+	plugged.Batteries = []BatteryStatus{{Percent: 62, Status: "charging"}}
+	if d := a.Next(plugged); d != time.Second {
+		t.Fatalf("status change should poll at min, got %v", d)
+	}
+
+	// Sensitivity 2 halves the step, so half a point of battery counts.
+	s, _ := newAdaptiveInterval(time.Second, time.Minute, 2)
+	s.Next(base)
+	s.Next(base)
+	drop := base
+	drop.Batteries = []BatteryStatus{{Percent: 61.5, Status: "discharging"}}
+	if d := s.Next(drop); d != time.Second {
+		t.Fatalf("0.5 point drop at sensitivity 2 should count, got %v", d)
+	}
+}
+
+func TestNewAdaptiveIntervalValidates(t *testing.T) {
+	for _, tt := range []struct {
+		lo, hi time.Duration
+		sens   float64
+	}{
+		{0, time.Second, 1},
+		{2 * time.Second, time.Second, 1},
+		{time.Second, time.Second, 0},
+	} {
+		if _, err := newAdaptiveInterval(tt.lo, tt.hi, tt.sens); err == nil {
+			t.Errorf("newAdaptiveInterval(%v, %v, %g) should fail", tt.lo, tt.hi, tt.sens)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Alert is a named condition evaluated against each snapshot.
+type Alert struct {
+	Name    string // Stable identifier used for debouncing
+	Firing  bool
+	Message string
+}
+
+// Alert thresholds.
+const (
+	alertBatteryLowPercent = 10.0
+	alertDiskFullPercent   = diskCritThreshold
+	// alertClearMargin is how far a value must move back past the trigger before a
+	// firing alert clears, unless the rule sets its own clear threshold.
+	alertClearMargin = 5.0
+)
+
+// AlertThreshold is a rule's trigger and clear levels. A Falling rule fires on low
+// values (battery percent), any other on high values (temperature); the
+// direction is part of the rule, not inferred from the levels. Between trigger
+// and clear the alert keeps its last state, and a Clear equal to Trigger means
+// no hysteresis at all.
+type AlertThreshold struct {
+	Trigger float64 `json:"trigger"`
+	Clear   float64 `json:"clear"`
+	Falling bool    `json:"falling,omitempty"`
+}
+
+// next returns the alert state for v given whether it was firing. A firing alert
+// holds while v is still past the trigger, so Clear == Trigger can't flap.
+func (t AlertThreshold) next(firing bool, v float64) bool {
+	if t.Falling {
+		if firing {
+			return v < t.Clear || v <= t.Trigger
+		}
+		return v <= t.Trigger
+	}
+	if firing {
+		return v > t.Clear || v >= t.Trigger
+	}
+	return v >= t.Trigger
+}
+
+// alertThresholds holds the numeric rules, keyed by alert name without any
+// ":target" suffix. Overridden with --alert.
+var alertThresholds = map[string]AlertThreshold{
+	"battery-low":      {Trigger: alertBatteryLowPercent, Clear: alertBatteryLowPercent + alertClearMargin, Falling: true},
+	"thermal-critical": {Trigger: thermalHighThreshold, Clear: thermalHighThreshold - alertClearMargin},
+	"disk-full":        {Trigger: alertDiskFullPercent, Clear: alertDiskFullPercent - alertClearMargin},
+}
+
+// setAlertThreshold parses one name=trigger[:clear] override. Without an explicit
+// clear level the default margin applies in the rule's direction.
+func setAlertThreshold(raw string) error {
+	name, levels, ok := strings.Cut(raw, "=")
+	name = strings.TrimSpace(name)
+	cur, known := alertThresholds[name]
+	if !ok || !known {
+		return fmt.Errorf("want rule=trigger[:clear] with rule one of battery-low, thermal-critical, disk-full; got %q", raw)
+	}
+	triggerStr, clearStr, hasClear := strings.Cut(levels, ":")
+	trigger, err := strconv.ParseFloat(strings.TrimSpace(triggerStr), 64)
+	if err != nil {
+		return fmt.Errorf("invalid trigger for %s: %w", name, err)
+	}
+	clearAt := trigger - alertClearMargin
+	if cur.Falling {
+		clearAt = trigger + alertClearMargin
+	}
+	if hasClear {
+		if clearAt, err = strconv.ParseFloat(strings.TrimSpace(clearStr), 64); err != nil {
+			return fmt.Errorf("invalid clear level for %s: %w", name, err)
+		}
+		// Equal levels are allowed and simply turn off hysteresis.
+		if !cur.Falling && clearAt > trigger || cur.Falling && clearAt < trigger {
+			return fmt.Errorf("clear level for %s must be on the safe side of %g, got %g", name, trigger, clearAt)
+		}
+	}
+	alertThresholds[name] = AlertThreshold{Trigger: trigger, Clear: clearAt, Falling: cur.Falling}
+	return nil
+}
+
+// alertEvaluator checks the built-in alert conditions against each snapshot,
+// remembering which alerts are firing so thresholds apply with hysteresis.
+type alertEvaluator struct {
+	firing map[string]bool
+}
+
+func newAlertEvaluator() *alertEvaluator {
+	return &alertEvaluator{firing: make(map[string]bool)}
+}
+
+// evaluateAlerts checks a single snapshot with no history, so only trigger
+// levels matter.
+func evaluateAlerts(m MetricsSnapshot) []Alert {
+	return newAlertEvaluator().Evaluate(m)
+}
+
+// Evaluate returns every alert, firing or clear, so callers can track transitions.
+func (e *alertEvaluator) Evaluate(m MetricsSnapshot) []Alert {
+	var alerts []Alert
+	// crossed applies the named rule to v and records the new state under key.
+	crossed := func(rule, key string, v float64) bool {
+		firing := alertThresholds[rule].next(e.firing[key], v)
+		e.firing[key] = firing
+		return firing
+	}
+
+	battery := Alert{Name: "battery-low"}
+	if b, ok := m.PrimaryBattery(); ok && batteryLowEnabled() && batteryDischarging(b) && crossed("battery-low", battery.Name, b.Percent) {
+		battery.Firing = true
+		battery.Message = fmt.Sprintf("Battery at %.0f%%", b.Percent)
+	} else {
+		e.firing[battery.Name] = false
+	}
+	alerts = append(alerts, battery)
+
+	worn := Alert{Name: "battery-worn"}
+	if b, ok := m.PrimaryBattery(); ok && b.Worn {
+		worn.Firing = true
+		health, _ := b.HealthPercent()
+		worn.Message = fmt.Sprintf("Battery health at %.0f%% of design capacity", health)
+	}
+	alerts = append(alerts, worn)
+
+	drain := Alert{Name: "battery-drain"}
+	if b, ok := m.PrimaryBattery(); ok && b.AbnormalDrain {
+		drain.Firing = true
+		drain.Message = fmt.Sprintf("Battery draining at %.0f%%/h while idle", b.DrainRate)
+	}
+	alerts = append(alerts, drain)
+
+	charger := Alert{Name: "charger-underpowered"}
+	if c := m.Charger; c.UnderpoweredCharger {
+		charger.Firing = true
+		charger.Message = fmt.Sprintf("%.0fW charger is below the %.0fW this Mac expects", c.Watts, c.ExpectedWatts)
+	}
+	alerts = append(alerts, charger)
+
+	thermal := Alert{Name: "thermal-critical"}
+	hot := m.Thermal.CPUTemp > 0 && crossed("thermal-critical", thermal.Name, m.Thermal.CPUTemp)
+	if m.Thermal.Level == ThermalLevelCritical || hot {
+		thermal.Firing = true
+		thermal.Message = "CPU is running hot"
+		if m.Thermal.CPUTemp > 0 {
+			thermal.Message = "CPU at " + displayTempUnit.formatPrec(m.Thermal.CPUTemp, 0)
+		}
+	}
+	alerts = append(alerts, thermal)
+
+	sustained := Alert{Name: "thermal-critical-sustained"}
+	if m.Thermal.CriticalSustained {
+		sustained.Firing = true
+		sustained.Message = fmt.Sprintf("Temperature has stayed at %s; cooling may be failing", displayTempUnit.formatPrec(m.Thermal.CriticalPeak, 0))
+	}
+	alerts = append(alerts, sustained)
+
+	for _, d := range m.Disks {
+		disk := Alert{Name: "disk-full:" + d.Mount}
+		if crossed("disk-full", disk.Name, d.UsedPercent) {
+			disk.Firing = true
+			disk.Message = fmt.Sprintf("%s is %.0f%% full", d.Mount, d.UsedPercent)
+		}
+		alerts = append(alerts, disk)
+	}
+	return alerts
+}
+
+// setBatteryLowAlert parses --battery-low: the percent below which battery-low
+// fires, with the default clear margin. 0 turns the alert off.
+func setBatteryLowAlert(raw string) error {
+	pct, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || pct < 0 || pct >= 100 {
+		return fmt.Errorf("want a percent from 0 (off) to 99, got %q", raw)
+	}
+	alertThresholds["battery-low"] = AlertThreshold{Trigger: pct, Clear: min(pct+alertClearMargin, 100), Falling: true}
+	return nil
+}
+
+// batteryLowEnabled reports whether battery-low can fire; see setBatteryLowAlert.
+func batteryLowEnabled() bool {
+	return alertThresholds["battery-low"].Trigger > 0
+}
+
+// batteryDischarging reports whether b is running the machine. A battery held
+// below full on external power ("not charging", bypass) is not, so it never
+// raises battery-low.
+func batteryDischarging(b BatteryStatus) bool {
+	if b.State != BatteryStateUnknown {
+		return b.State == BatteryStateDischarging
+	}
+	return strings.EqualFold(b.Status, "discharging")
+}
+
+// batteryCharging reports whether a battery status means it is on external power and filling.
+func batteryCharging(status string) bool {
+	switch strings.ToLower(status) {
+	case "charging", "charged", "finishing charge":
+		return true
+	}
+	return false
+}
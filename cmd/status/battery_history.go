@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// batterySample is one battery's wear as persisted between runs.
+type batterySample struct {
+	Name          string    `json:"name"`
+	CycleCount    int       `json:"cycle_count"`
+	HealthPercent float64   `json:"health_percent,omitempty"`
+	RecordedAt    time.Time `json:"recorded_at"`
+	LastFullAt    time.Time `json:"last_full_at,omitzero"`
+	// The oldest sample of this battery, kept across saves so the cycle rate is
+	// measured over the whole history rather than since the previous run.
+	BaselineCycles int       `json:"baseline_cycles,omitempty"`
+	BaselineAt     time.Time `json:"baseline_at,omitzero"`
+}
+
+// batteryHistory is the on-disk state: the last sample of every battery seen.
+type batteryHistory struct {
+	Batteries []batterySample `json:"batteries"`
+}
+
+// BatteryTrend is the change in wear since the previous run's sample.
+type BatteryTrend struct {
+	CycleDelta  int
+	HealthDelta float64 // Percentage points; 0 when either side lacks a health figure
+	Since       time.Time
+	// CyclesPerMonth is measured from the history's baseline; 0 until it spans
+	// cycleRateMinSpan.
+	CyclesPerMonth float64
+}
+
+// batteryHistoryPath returns the state file in the user cache dir, or "" if there is none.
+func batteryHistoryPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mole", "battery_history.json")
+}
+
+// loadBatteryHistory reads path. A missing, unreadable or corrupt file is
+// treated as a first run and yields an empty history.
+func loadBatteryHistory(path string) batteryHistory {
+	var h batteryHistory
+	if path == "" {
+		return h
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return batteryHistory{}
+	}
+	return h
+}
+
+// saveBatteryHistory replaces the state at path with batts as of now, carrying each
+// battery's baseline over from the file; see stateFiles for what happens when the
+// cache dir is unwritable.
+func saveBatteryHistory(path string, batts []BatteryStatus, now time.Time) error {
+	if path == "" || len(batts) == 0 {
+		return nil
+	}
+	prev := loadBatteryHistory(path)
+	h := batteryHistory{Batteries: make([]batterySample, 0, len(batts))}
+	for _, b := range batts {
+		s := batterySample{Name: b.Name, CycleCount: b.CycleCount, RecordedAt: now}
+		s.BaselineCycles, s.BaselineAt = prev.baseline(b, now)
+		if health, ok := b.HealthPercent(); ok {
+			s.HealthPercent = health
+		}
+		if b.LastFullCharge != nil {
+			s.LastFullAt = *b.LastFullCharge
+		}
+		h.Batteries = append(h.Batteries, s)
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return persisted.write(path, data)
+}
+
+// baseline returns the cycle count and time to measure b's cycle rate from: the
+// persisted baseline, the previous sample for files written before baselines
+// existed, or b itself when it is new or its count went down (a replaced battery).
+func (h batteryHistory) baseline(b BatteryStatus, now time.Time) (int, time.Time) {
+	if b.CycleCount <= 0 {
+		return 0, time.Time{}
+	}
+	for _, prev := range h.Batteries {
+		if prev.Name != b.Name {
+			continue
+		}
+		switch {
+		case !prev.BaselineAt.IsZero() && prev.BaselineCycles > 0 && prev.BaselineCycles <= b.CycleCount:
+			return prev.BaselineCycles, prev.BaselineAt
+		case prev.BaselineAt.IsZero() && !prev.RecordedAt.IsZero() && prev.CycleCount > 0 && prev.CycleCount <= b.CycleCount:
+			return prev.CycleCount, prev.RecordedAt
+		}
+		break
+	}
+	return b.CycleCount, now
+}
+
+// diff returns b's trend against the persisted sample with the same name, or nil
+// when the battery wasn't seen before.
+func (h batteryHistory) diff(b BatteryStatus) *BatteryTrend {
+	for _, prev := range h.Batteries {
+		if prev.Name != b.Name || prev.RecordedAt.IsZero() {
+			continue
+		}
+		t := &BatteryTrend{Since: prev.RecordedAt}
+		if b.CycleCount > 0 && prev.CycleCount > 0 {
+			t.CycleDelta = b.CycleCount - prev.CycleCount
+		}
+		if health, ok := b.HealthPercent(); ok && prev.HealthPercent > 0 {
+			t.HealthDelta = health - prev.HealthPercent
+		}
+		return t
+	}
+	return nil
+}
+
+// applyBatteryTrends sets Trend on each battery from the previous run's history,
+// with the cycle rate measured from the history's baseline up to now.
+func applyBatteryTrends(batts []BatteryStatus, h batteryHistory, now time.Time) {
+	for i := range batts {
+		b := &batts[i]
+		b.Trend = h.diff(*b)
+		if b.Trend != nil {
+			from, at := h.baseline(*b, now)
+			b.Trend.CyclesPerMonth = cyclesPerMonth(from, at, b.CycleCount, now)
+		}
+	}
+}
+
+// lastFullTracker remembers when each battery was last seen at full, seeded from
+// the previous run so the time survives restarts.
+type lastFullTracker struct {
+	seen map[string]time.Time
+}
+
+// observe stamps batteries at full with now and sets LastFullCharge on every battery
+// with a known time, from this session or the history. A battery never seen full
+// keeps nil.
+func (t *lastFullTracker) observe(batts []BatteryStatus, h batteryHistory, now time.Time) {
+	if t.seen == nil {
+		t.seen = make(map[string]time.Time)
+		for _, prev := range h.Batteries {
+			if !prev.LastFullAt.IsZero() {
+				t.seen[prev.Name] = prev.LastFullAt
+			}
+		}
+	}
+	for i := range batts {
+		b := &batts[i]
+		if b.atFull() {
+			t.seen[b.Name] = now
+		}
+		if at, ok := t.seen[b.Name]; ok {
+			b.LastFullCharge = &at
+		}
+	}
+}
+
+// atFull reports whether the OS says the battery is charged, or it has reached 100%
+// or its charge limit. A limited battery never gets to 100%, so its limit counts.
+func (b BatteryStatus) atFull() bool {
+	switch strings.ToLower(b.Status) {
+	case "charged", "full":
+		return true
+	}
+	return b.Percent > 0 && b.Percent >= b.ChargeAnimation().Target-fullChargeSlack
+}
+
+// lastFullLabel describes how long ago the battery was last full, e.g. "3h ago".
+func lastFullLabel(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return sinceLabel(d)
+	}
+}
+
+// String renders the trend, e.g. "cycles +12 · health -1.2% since last week";
+// "" when nothing changed.
+func (t BatteryTrend) String(now time.Time) string {
+	var parts []string
+	if t.CycleDelta != 0 {
+		parts = append(parts, fmt.Sprintf("cycles %+d", t.CycleDelta))
+	}
+	if t.HealthDelta <= -0.05 || t.HealthDelta >= 0.05 {
+		parts = append(parts, fmt.Sprintf("health %+.1f%%", t.HealthDelta))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	out := parts[0]
+	if len(parts) > 1 {
+		out += " · " + parts[1]
+	}
+	return out + " since " + sinceLabel(now.Sub(t.Since))
+}
+
+// sinceLabel describes an age in the coarse terms people use for wear.
+func sinceLabel(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 14:
+		return "last week"
+	case days < 60:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	default:
+		return fmt.Sprintf("%d months ago", days/30)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBatteryHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mole", "battery_history.json")
+	week := time.Date(2026, 10, 7, 9, 0, 0, 0, time.UTC)
+	if err := saveBatteryHistory(path, []BatteryStatus{{Name: "BAT0", CycleCount: 300, DesignCapacity: 50, FullChargeCapacity: 45}}, week); err != nil {
+		t.Fatal(err)
+	}
+
+	batts := []BatteryStatus{
+		{Name: "BAT0", CycleCount: 312, DesignCapacity: 50, FullChargeCapacity: 44.4},
+		{Name: "BAT1", CycleCount: 10},
+	}
+	applyBatteryTrends(batts, loadBatteryHistory(path), week.Add(8*24*time.Hour))
+	if batts[1].Trend != nil {
+		t.Fatalf("new battery should have no trend, got %+v", batts[1].Trend)
+	}
+	trend := batts[0].Trend
+	if trend == nil || trend.CycleDelta != 12 {
+		t.Fatalf("unexpected trend %+v", trend)
+	}
+	if got := trend.String(week.Add(8 * 24 * time.Hour)); got != "cycles +12 · health -1.2% since last week" {
+		t.Fatalf("unexpected trend text %q", got)
+	}
+}
+
+func TestLoadBatteryHistoryCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "battery_history.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"", filepath.Join(dir, "missing.json"), corrupt} {
+		if h := loadBatteryHistory(path); len(h.Batteries) != 0 {
+			t.Fatalf("%q: want empty history, got %+v", path, h)
+		}
+	}
+}
+
+func TestLastFullChargePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "battery_history.json")
+	morning := time.Date(2026, 10, 7, 9, 0, 0, 0, time.UTC)
+
+	var first lastFullTracker
+	batts := []BatteryStatus{{Name: "BAT0", Percent: 50, Status: "Discharging"}}
+	first.observe(batts, batteryHistory{}, morning)
+	if batts[0].LastFullCharge != nil {
+		t.Fatalf("first run without a full charge should be unknown, got %v", batts[0].LastFullCharge)
+	}
+	batts = []BatteryStatus{{Name: "BAT0", Percent: 80, ChargeLimited: true, EffectiveFullPercent: 80, Status: "Not charging"}}
+	first.observe(batts, batteryHistory{}, morning)
+	if batts[0].LastFullCharge == nil || !batts[0].LastFullCharge.Equal(morning) {
+		t.Fatalf("battery at its charge limit should count as full, got %v", batts[0].LastFullCharge)
+	}
+	if err := saveBatteryHistory(path, batts, morning); err != nil {
+		t.Fatal(err)
+	}
+
+	var next lastFullTracker
+	batts = []BatteryStatus{{Name: "BAT0", Percent: 40, Status: "Discharging"}}
+	next.observe(batts, loadBatteryHistory(path), morning.Add(5*time.Hour))
+	if batts[0].LastFullCharge == nil || !batts[0].LastFullCharge.Equal(morning) {
+		t.Fatalf("want last full charge carried from history, got %v", batts[0].LastFullCharge)
+	}
+	if got := lastFullLabel(5 * time.Hour); got != "5h ago" {
+		t.Fatalf("unexpected label %q", got)
+	}
+}
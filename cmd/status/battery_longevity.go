@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ratedCycleModel is one entry of the rated-cycles lookup. match is a prefix of
+// HardwareInfo.ModelID, or a substring of HardwareInfo.Model, compared case-insensitively.
+type ratedCycleModel struct {
+	match  string
+	cycles int
+}
+
+// ratedCycleModels are the published cycle ratings for laptops whose model we can
+// name. Apple rates every notebook since 2009 for 1000 cycles; Apple Silicon
+// notebooks have "MacN,M" IDs, so they are matched on the marketing name.
+// --rated-cycles MODEL=N entries are checked first.
+var ratedCycleModels = []ratedCycleModel{
+	{match: "MacBookPro", cycles: 1000},
+	{match: "MacBookAir", cycles: 1000},
+	{match: "MacBook", cycles: 1000},
+}
+
+// ratedCyclesOverride replaces the lookup for this machine; 0 uses the table.
+var ratedCyclesOverride int
+
+// setRatedCycles parses --rated-cycles: N applies to this machine, MODEL=N adds
+// or replaces a lookup entry.
+func setRatedCycles(v string) error {
+	model, count, hasModel := strings.Cut(v, "=")
+	if !hasModel {
+		count = model
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || n <= 0 {
+		return fmt.Errorf("want a positive cycle count, got %q", count)
+	}
+	if !hasModel {
+		ratedCyclesOverride = n
+		return nil
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return fmt.Errorf("missing model before %q", "="+count)
+	}
+	ratedCycleModels = append([]ratedCycleModel{{match: model, cycles: n}}, ratedCycleModels...)
+	return nil
+}
+
+// ratedCyclesFor returns the cycle rating for hw, or false when the model is
+// unknown or not in the lookup. There is no generic default: a guessed rating
+// would make the remaining-cycles figure look more certain than it is.
+func ratedCyclesFor(hw HardwareInfo) (int, bool) {
+	if ratedCyclesOverride > 0 {
+		return ratedCyclesOverride, true
+	}
+	id, name := strings.ToLower(hw.ModelID), strings.ToLower(hw.Model)
+	for _, m := range ratedCycleModels {
+		key := strings.ToLower(m.match)
+		if (id != "" && strings.HasPrefix(id, key)) || (name != "" && strings.Contains(name, key)) {
+			return m.cycles, true
+		}
+	}
+	return 0, false
+}
+
+// BatteryLongevity is how much of its rated cycle life a battery has left.
+type BatteryLongevity struct {
+	RatedCycles     int
+	CyclesRemaining int // Floored at 0 once past the rating
+	// CyclesPerMonth is the usage rate from the battery history; 0 until the
+	// history spans cycleRateMinSpan or no cycles were used in it.
+	CyclesPerMonth float64
+}
+
+// MonthsRemaining estimates the months until the rated limit at the current
+// rate; false while the rate is unknown.
+func (l BatteryLongevity) MonthsRemaining() (float64, bool) {
+	if l.CyclesPerMonth <= 0 {
+		return 0, false
+	}
+	return float64(l.CyclesRemaining) / l.CyclesPerMonth, true
+}
+
+// String renders the estimate, e.g. "412 cycles left · ~34 months"; the months
+// read "unknown" until the history gives a rate.
+func (l BatteryLongevity) String() string {
+	months := longevityUnknown
+	if n, ok := l.MonthsRemaining(); ok {
+		months = fmt.Sprintf("~%.0f", n)
+	}
+	return fmt.Sprintf("%d cycles left · %s months", l.CyclesRemaining, months)
+}
+
+// longevityUnknown is shown in place of an estimate that can't be made.
+const longevityUnknown = "unknown"
+
+// applyBatteryLongevity sets Longevity on the machine's own batteries when both
+// the cycle count and the model's rating are known; otherwise it stays nil.
+// Peripheral and UPS batteries aren't covered by the laptop's rating.
+func applyBatteryLongevity(batts []BatteryStatus, hw HardwareInfo) {
+	rated, ok := ratedCyclesFor(hw)
+	for i := range batts {
+		b := &batts[i]
+		b.Longevity = nil
+		if !ok || b.Kind != BatteryKindSystem || b.CycleCount <= 0 {
+			continue
+		}
+		l := &BatteryLongevity{RatedCycles: rated, CyclesRemaining: max(rated-b.CycleCount, 0)}
+		if b.Trend != nil {
+			l.CyclesPerMonth = b.Trend.CyclesPerMonth
+		}
+		b.Longevity = l
+	}
+}
+
+// ratedCycles is the rating AnalyzeBattery judges cycle counts against: the
+// model's when known, batteryRatedCycles otherwise.
+func (b BatteryStatus) ratedCycles() int {
+	if b.Longevity != nil {
+		return b.Longevity.RatedCycles
+	}
+	return batteryRatedCycles
+}
+
+// cycleRateMinSpan is the shortest history a cycles-per-month rate is taken
+// from; over a few days one long session would dominate it.
+const cycleRateMinSpan = 14 * 24 * time.Hour
+
+// averageMonth is the month cycles-per-month rates are measured in; 30 days, as in
+// sinceLabel.
+const averageMonth = 30 * 24 * time.Hour
+
+// cyclesPerMonth is the rate between a baseline and now; 0 when the span is too
+// short or the count went down (a replaced battery).
+func cyclesPerMonth(fromCycles int, from time.Time, toCycles int, to time.Time) float64 {
+	span := to.Sub(from)
+	if from.IsZero() || span < cycleRateMinSpan || fromCycles <= 0 || toCycles < fromCycles {
+		return 0
+	}
+	return float64(toCycles-fromCycles) / (float64(span) / float64(averageMonth))
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRatedCyclesFor(t *testing.T) {
+	prevModels, prevOverride := slices.Clone(ratedCycleModels), ratedCyclesOverride
+	t.Cleanup(func() { ratedCycleModels, ratedCyclesOverride = prevModels, prevOverride })
+
+	for _, tc := range []struct {
+		hw    HardwareInfo
+		want  int
+		known bool
+	}{
+		{HardwareInfo{ModelID: "MacBookPro18,3", Model: "MacBook Pro 14-inch, 2021"}, 1000, true},
+		{HardwareInfo{ModelID: "Mac15,3", Model: "MacBook Air 13-inch, M3, 2024"}, 1000, true},
+		{HardwareInfo{ModelID: "Mac14,3", Model: "Mac mini (2023)"}, 0, false},
+		{HardwareInfo{Model: "ThinkPad X1 Carbon Gen 11"}, 0, false},
+		{HardwareInfo{}, 0, false},
+	} {
+		if got, ok := ratedCyclesFor(tc.hw); got != tc.want || ok != tc.known {
+			t.Errorf("%+v: got %d, %v; want %d, %v", tc.hw, got, ok, tc.want, tc.known)
+		}
+	}
+
+	if err := setRatedCycles("ThinkPad=1500"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := ratedCyclesFor(HardwareInfo{Model: "ThinkPad X1 Carbon Gen 11"}); got != 1500 || !ok {
+		t.Fatalf("MODEL=N entry: got %d, %v", got, ok)
+	}
+	if err := setRatedCycles("800"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ratedCyclesFor(HardwareInfo{ModelID: "MacBookPro18,3"}); got != 800 {
+		t.Fatalf("override should win over the table, got %d", got)
+	}
+	for _, bad := range []string{"", "0", "many", "=500", "MacBook=-1"} {
+		if err := setRatedCycles(bad); err == nil {
+			t.Errorf("setRatedCycles(%q) should fail", bad)
+		}
+	}
+}
+
+func TestApplyBatteryLongevity(t *testing.T) {
+	mbp := HardwareInfo{ModelID: "MacBookPro18,3"}
+	batts := []BatteryStatus{
+		{Name: "InternalBattery-0", Kind: BatteryKindSystem, CycleCount: 400, Trend: &BatteryTrend{CyclesPerMonth: 20}},
+		{Name: "Old", Kind: BatteryKindSystem, CycleCount: 1200},
+		{Name: "ups", Kind: BatteryKindUPS, CycleCount: 50},
+		{Name: "New", Kind: BatteryKindSystem},
+	}
+	applyBatteryLongevity(batts, mbp)
+
+	l := batts[0].Longevity
+	if l == nil || l.RatedCycles != 1000 || l.CyclesRemaining != 600 {
+		t.Fatalf("unexpected longevity %+v", l)
+	}
+	if months, ok := l.MonthsRemaining(); !ok || months != 30 {
+		t.Fatalf("months = %v, %v; want 30", months, ok)
+	}
+	if got := l.String(); got != "600 cycles left · ~30 months" {
+		t.Fatalf("String() = %q", got)
+	}
+	if l := batts[1].Longevity; l == nil || l.CyclesRemaining != 0 || l.String() != "0 cycles left · unknown months" {
+		t.Fatalf("past the rating should floor at 0 with unknown months, got %+v", l)
+	}
+	if batts[2].Longevity != nil || batts[3].Longevity != nil {
+		t.Fatal("UPS and batteries without a cycle count should stay unknown")
+	}
+
+	applyBatteryLongevity(batts, HardwareInfo{Model: "ThinkPad X1 Carbon Gen 11"})
+	if batts[0].Longevity != nil {
+		t.Fatal("an unknown model should give no estimate rather than a guess")
+	}
+}
+
+func TestCycleRateFromHistoryBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "battery_history.json")
+	start := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	save := func(cycles int, at time.Time) {
+		t.Helper()
+		if err := saveBatteryHistory(path, []BatteryStatus{{Name: "BAT0", CycleCount: cycles}}, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(300, start)
+	save(310, start.Add(10*24*time.Hour))
+
+	// The baseline survives the second save, so the rate covers 60 days, not 50.
+	now := start.Add(60 * 24 * time.Hour)
+	batts := []BatteryStatus{{Name: "BAT0", CycleCount: 340}}
+	applyBatteryTrends(batts, loadBatteryHistory(path), now)
+	if got := batts[0].Trend.CyclesPerMonth; got != 20 {
+		t.Fatalf("CyclesPerMonth = %v, want 20", got)
+	}
+
+	// Too short a history gives no rate.
+	applyBatteryTrends(batts, loadBatteryHistory(path), start.Add(7*24*time.Hour))
+	if got := batts[0].Trend.CyclesPerMonth; got != 0 {
+		t.Fatalf("a week of history should give no rate, got %v", got)
+	}
+
+	// A lower count means a new battery: the baseline restarts.
+	save(5, now)
+	if h := loadBatteryHistory(path); h.Batteries[0].BaselineCycles != 5 || !h.Batteries[0].BaselineAt.Equal(now) {
+		t.Fatalf("baseline should restart after a battery swap, got %+v", h.Batteries[0])
+	}
+}
+
+func TestAnalyzeBatteryUsesModelRating(t *testing.T) {
+	b := BatteryStatus{Name: "BAT0", CycleCount: 900, Longevity: &BatteryLongevity{RatedCycles: 1500, CyclesRemaining: 600}}
+	for _, f := range AnalyzeBattery(b, batteryHistory{}).Findings {
+		if f.Code == FindingCyclesHigh || f.Code == FindingCyclesExceeded {
+			t.Fatalf("900 of 1500 cycles shouldn't be flagged, got %+v", f)
+		}
+	}
+}
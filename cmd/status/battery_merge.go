@@ -0,0 +1,107 @@
+package main
+
+// batteryIdentity is what makes two readings the same physical battery: the pack
+// serial when the gauge reports one, since buggy drivers list one pack under two
+// names (BAT0 and BAT1), and otherwise the kind and the name the OS gives it
+// (InternalBattery-0, BAT1), an index that is stable from one poll to the next.
+func batteryIdentity(b BatteryStatus) string {
+	if b.Controller != nil && b.Controller.Serial != "" {
+		return "serial:" + b.Controller.Serial
+	}
+	return batteryNameKey(b)
+}
+
+// batteryNameKey is the name half of batteryIdentity.
+func batteryNameKey(b BatteryStatus) string {
+	return string(b.Kind) + "/" + b.Name
+}
+
+// dedupeBatteries merges entries with the same batteryIdentity into the first one,
+// which keeps its position and values; the duplicates only fill fields it lacks.
+func dedupeBatteries(batts []BatteryStatus) []BatteryStatus {
+	out := batts[:0:0]
+	index := make(map[string]int, len(batts))
+	for _, b := range batts {
+		id := batteryIdentity(b)
+		if i, ok := index[id]; ok {
+			fillBatteryFields(&out[i], b)
+			continue
+		}
+		index[id] = len(out)
+		out = append(out, b)
+	}
+	return out
+}
+
+// fillBatteryFields copies into dst every reading it lacks from src, another
+// reading of the same battery at the same moment.
+func fillBatteryFields(dst *BatteryStatus, src BatteryStatus) {
+	fillSlowBatteryFields(dst, src)
+	if dst.TimeLeft == "" {
+		dst.TimeLeft = src.TimeLeft
+	}
+	if dst.VoltageV == 0 {
+		dst.VoltageV = src.VoltageV
+	}
+	if dst.CurrentA == 0 {
+		dst.CurrentA = src.CurrentA
+	}
+	if dst.Watts == 0 {
+		dst.Watts = src.Watts
+	}
+	if dst.FullChargeCapacity == 0 && src.FullChargeCapacity > 0 {
+		dst.DesignCapacity, dst.FullChargeCapacity = src.DesignCapacity, src.FullChargeCapacity
+		dst.CurrentCharge, dst.CapacityUnit = src.CurrentCharge, src.CapacityUnit
+	}
+	if dst.Cells == nil {
+		dst.Cells = src.Cells
+	}
+	if !dst.ChargeLimited && src.ChargeLimited {
+		dst.ChargeLimited, dst.EffectiveFullPercent = true, src.EffectiveFullPercent
+	}
+}
+
+// fillSlowBatteryFields copies the fields that hold for much longer than a poll:
+// health, wear and identity. An earlier poll's src can still fill them.
+func fillSlowBatteryFields(dst *BatteryStatus, src BatteryStatus) {
+	if dst.Health == "" {
+		dst.Health = src.Health
+	}
+	if dst.CycleCount == 0 {
+		dst.CycleCount = src.CycleCount
+	}
+	if dst.Capacity == 0 {
+		dst.Capacity = src.Capacity
+	}
+	if dst.Controller == nil {
+		dst.Controller = src.Controller
+	}
+}
+
+// batteryBackfill carries slow fields across polls. A source that sometimes
+// comes back without them, such as pmset when system_profiler misses its
+// timeout, keeps showing the last known health and cycle count instead of
+// flickering to empty.
+type batteryBackfill struct {
+	last map[string]BatteryStatus // By batteryIdentity, and by batteryNameKey
+}
+
+// apply fills batts in place from the previous poll, then remembers them. A
+// battery is also found by name, for polls where the serial went missing with
+// the rest of the slow fields.
+func (f *batteryBackfill) apply(batts []BatteryStatus) {
+	if f.last == nil {
+		f.last = make(map[string]BatteryStatus)
+	}
+	for i := range batts {
+		id, name := batteryIdentity(batts[i]), batteryNameKey(batts[i])
+		prev, ok := f.last[id]
+		if !ok {
+			prev, ok = f.last[name]
+		}
+		if ok {
+			fillSlowBatteryFields(&batts[i], prev)
+		}
+		f.last[id], f.last[name] = batts[i], batts[i]
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestDedupeBatteriesBySerial(t *testing.T) {
+	pack := &BatteryController{Model: "5B10W13930", Serial: "1234"}
+	batts := []BatteryStatus{
+		{Name: "BAT0", Percent: 64, Status: "Discharging", Controller: pack},
+		// The same pack listed again by a buggy driver, with the capacity BAT0 lacked.
+		{Name: "BAT1", Percent: 63, CycleCount: 210, DesignCapacity: 57, FullChargeCapacity: 51, CapacityUnit: CapacityWh, Controller: pack},
+		{Name: "BAT2", Percent: 90, Status: "Full"},
+	}
+	got := dedupeBatteries(batts)
+	if len(got) != 2 || got[0].Name != "BAT0" || got[1].Name != "BAT2" {
+		t.Fatalf("dedupeBatteries = %+v, want BAT0 and BAT2", got)
+	}
+	if b := got[0]; b.Percent != 64 || b.CycleCount != 210 || b.FullChargeCapacity != 51 || b.CapacityUnit != CapacityWh {
+		t.Fatalf("BAT0 should keep its own values and gain BAT1's missing ones, got %+v", b)
+	}
+	if batts[0].CycleCount != 0 {
+		t.Fatal("dedupeBatteries modified its input")
+	}
+}
+
+func TestDedupeBatteriesKeepsDistinctPacks(t *testing.T) {
+	batts := []BatteryStatus{
+		{Name: "BAT0", Percent: 80},
+		{Name: "BAT1", Percent: 40},
+		{Name: "BAT0", Kind: BatteryKindUPS, Percent: 100}, // Same name, different kind
+	}
+	if got := dedupeBatteries(batts); len(got) != 3 {
+		t.Fatalf("expected three distinct batteries, got %+v", got)
+	}
+}
+
+func TestBatteryBackfillCarriesSlowFields(t *testing.T) {
+	var f batteryBackfill
+	first := []BatteryStatus{{
+		Name: "InternalBattery-0", Percent: 80, Health: "Normal", CycleCount: 412, Capacity: 91,
+		Controller: &BatteryController{Serial: "F5D"},
+	}}
+	f.apply(first)
+
+	// system_profiler and ioreg timed out: pmset only had percent and status.
+	next := []BatteryStatus{{Name: "InternalBattery-0", Percent: 79, Status: "discharging"}}
+	f.apply(next)
+	b := next[0]
+	if b.Percent != 79 || b.Health != "Normal" || b.CycleCount != 412 || b.Capacity != 91 || b.Controller == nil {
+		t.Fatalf("slow fields not backfilled: %+v", b)
+	}
+
+	// A reported value always wins over the remembered one.
+	later := []BatteryStatus{{Name: "InternalBattery-0", Percent: 78, CycleCount: 413}}
+	f.apply(later)
+	if later[0].CycleCount != 413 {
+		t.Fatalf("CycleCount = %d, want the fresh 413", later[0].CycleCount)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BatteryVerdict is the one-word answer to "is my battery OK?".
+type BatteryVerdict string
+
+const (
+	VerdictUnknown BatteryVerdict = "Unknown" // Nothing to judge by: no health, cycles or condition
+	VerdictGood    BatteryVerdict = "Good"
+	VerdictFair    BatteryVerdict = "Fair"
+	VerdictPoor    BatteryVerdict = "Poor"
+	VerdictReplace BatteryVerdict = "Replace"
+)
+
+// FindingSeverity orders findings; only warn and critical affect the verdict.
+type FindingSeverity string
+
+const (
+	FindingInfo     FindingSeverity = "info"
+	FindingWarn     FindingSeverity = "warn"
+	FindingCritical FindingSeverity = "critical"
+)
+
+// Finding codes are stable: UIs and alert rules key on them, so never rename one.
+const (
+	FindingHealthUnknown    = "health-unknown"
+	FindingWorn             = "worn"
+	FindingWornSevere       = "worn-severe"
+	FindingCyclesHigh       = "cycles-high"
+	FindingCyclesExceeded   = "cycles-exceeded"
+	FindingServiceCondition = "service-condition"
+	FindingWearAccelerating = "wear-accelerating"
+	FindingCellImbalance    = "cell-imbalance"
+	FindingAbnormalDrain    = "abnormal-drain"
+	FindingChargeLimited    = "charge-limited"
+)
+
+// BatteryFinding is one observation behind a BatteryReport.
+type BatteryFinding struct {
+	Code     string          `json:"code"`
+	Severity FindingSeverity `json:"severity"`
+	Message  string          `json:"message"`
+}
+
+// BatteryReport combines every battery diagnostic into a verdict and its reasons.
+type BatteryReport struct {
+	Name          string           `json:"name"`
+	Verdict       BatteryVerdict   `json:"verdict"`
+	HealthPercent float64          `json:"health_percent,omitempty"` // 0 when unknown
+	CycleCount    int              `json:"cycle_count,omitempty"`
+	Findings      []BatteryFinding `json:"findings"`
+}
+
+const (
+	// batteryRatedCycles is the cycle count Apple and most laptop makers rate packs
+	// for before capacity is expected to fall below 80%; findings fall back to it
+	// when the model isn't in ratedCycleModels.
+	batteryRatedCycles = 1000
+	// cyclesHighFraction of the rating raises an early warning.
+	cyclesHighFraction = 0.8
+	// severeWearPercent is the health below which a worn pack needs replacing.
+	severeWearPercent = 60.0
+	// wearAcceleratingPoints is a health drop since the previous run worth flagging.
+	wearAcceleratingPoints = 2.0
+)
+
+// serviceConditions are OS condition strings that mean "take it in", by severity.
+var serviceConditions = map[string]FindingSeverity{
+	"service recommended": FindingWarn,
+	"replace soon":        FindingWarn,
+	"check battery":       FindingWarn,
+	"replace now":         FindingCritical,
+	"service battery":     FindingCritical,
+	"poor":                FindingCritical,
+}
+
+// AnalyzeBattery judges b, using h for the change since the previous run. Each input
+// that is missing (no design capacity, no cycle count, no history) simply yields no
+// finding; the verdict is Unknown only when none of health, cycles or condition is known.
+func AnalyzeBattery(b BatteryStatus, h batteryHistory) BatteryReport {
+	r := BatteryReport{Name: b.Name, CycleCount: b.CycleCount, Findings: []BatteryFinding{}}
+	add := func(code string, sev FindingSeverity, format string, args ...any) {
+		r.Findings = append(r.Findings, BatteryFinding{Code: code, Severity: sev, Message: fmt.Sprintf(format, args...)})
+	}
+
+	health, hasHealth := b.HealthPercent()
+	switch {
+	case !hasHealth:
+		add(FindingHealthUnknown, FindingInfo, "Capacity figures aren't exposed, so wear can't be measured")
+	case health < severeWearPercent:
+		r.HealthPercent = health
+		add(FindingWornSevere, FindingCritical, "Holds %.0f%% of its design capacity", health)
+	case health < wornThresholdPercent:
+		r.HealthPercent = health
+		add(FindingWorn, FindingWarn, "Holds %.0f%% of its design capacity, below %.0f%%", health, wornThresholdPercent)
+	default:
+		r.HealthPercent = health
+	}
+
+	switch rated := b.ratedCycles(); {
+	case b.CycleCount >= rated:
+		add(FindingCyclesExceeded, FindingCritical, "%d cycles, past the %d it is rated for", b.CycleCount, rated)
+	case float64(b.CycleCount) >= cyclesHighFraction*float64(rated):
+		add(FindingCyclesHigh, FindingWarn, "%d cycles of the %d it is rated for", b.CycleCount, rated)
+	}
+
+	condition := strings.ToLower(strings.TrimSpace(b.Health))
+	if sev, ok := serviceConditions[condition]; ok {
+		add(FindingServiceCondition, sev, "The OS reports the condition as %q", b.Health)
+	}
+
+	if t := h.diff(b); t != nil && t.HealthDelta <= -wearAcceleratingPoints {
+		add(FindingWearAccelerating, FindingWarn, "Health fell %.1f points since %s", -t.HealthDelta, t.Since.Format("2 Jan"))
+	}
+	if b.Cells != nil && b.Cells.Imbalanced() {
+		add(FindingCellImbalance, FindingWarn, "Cell voltages are %.0fmV apart", b.Cells.ImbalanceMV)
+	}
+	if b.AbnormalDrain {
+		add(FindingAbnormalDrain, FindingWarn, "Draining %.1f%%/h while the system is mostly idle", b.DrainRate)
+	}
+	if b.ChargeLimited {
+		add(FindingChargeLimited, FindingInfo, "Charging stops at %.0f%% to reduce wear", b.EffectiveFullPercent)
+	}
+
+	r.Verdict = batteryVerdict(r.Findings, hasHealth || b.CycleCount > 0 || condition != "", health)
+	return r
+}
+
+// batteryVerdict grades findings: any critical means Replace, two warnings or
+// health under 70% mean Poor, one warning Fair.
+func batteryVerdict(findings []BatteryFinding, known bool, health float64) BatteryVerdict {
+	var warns, criticals int
+	for _, f := range findings {
+		switch f.Severity {
+		case FindingWarn:
+			warns++
+		case FindingCritical:
+			criticals++
+		}
+	}
+	switch {
+	case criticals > 0:
+		return VerdictReplace
+	case !known:
+		return VerdictUnknown
+	case warns >= 2, health > 0 && health < 70:
+		return VerdictPoor
+	case warns == 1:
+		return VerdictFair
+	}
+	return VerdictGood
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func findingCodes(r BatteryReport) []string {
+	codes := make([]string, len(r.Findings))
+	for i, f := range r.Findings {
+		codes[i] = f.Code
+	}
+	return codes
+}
+
+func TestAnalyzeBattery(t *testing.T) {
+	healthy := BatteryStatus{Name: "InternalBattery-0", Health: "Normal", CycleCount: 210, DesignCapacity: 5000, FullChargeCapacity: 4600}
+	since := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		b       BatteryStatus
+		h       batteryHistory
+		verdict BatteryVerdict
+		codes   []string
+	}{
+		{"healthy", healthy, batteryHistory{}, VerdictGood, []string{}},
+		{"charge limited is informational", func() BatteryStatus {
+			b := healthy
+			b.ChargeLimited, b.EffectiveFullPercent = true, 80
+			return b
+		}(), batteryHistory{}, VerdictGood, []string{FindingChargeLimited}},
+		{"worn", func() BatteryStatus {
+			b := healthy
+			b.FullChargeCapacity = 3850
+			return b
+		}(), batteryHistory{}, VerdictFair, []string{FindingWorn}},
+		{"worn and high cycles", func() BatteryStatus {
+			b := healthy
+			b.FullChargeCapacity, b.CycleCount = 3700, 850
+			return b
+		}(), batteryHistory{}, VerdictPoor, []string{FindingWorn, FindingCyclesHigh}},
+		{"os says replace", func() BatteryStatus {
+			b := healthy
+			b.Health = "Replace Now"
+			return b
+		}(), batteryHistory{}, VerdictReplace, []string{FindingServiceCondition}},
+		{"fast wear since last run", healthy, batteryHistory{Batteries: []batterySample{{Name: "InternalBattery-0", CycleCount: 200, HealthPercent: 95, RecordedAt: since}}}, VerdictFair, []string{FindingWearAccelerating}},
+		{"nothing known", BatteryStatus{Name: "BAT0", Percent: 50}, batteryHistory{}, VerdictUnknown, []string{FindingHealthUnknown}},
+		{"drain without capacity figures", BatteryStatus{Name: "BAT0", CycleCount: 12, AbnormalDrain: true, DrainRate: 18}, batteryHistory{}, VerdictFair, []string{FindingHealthUnknown, FindingAbnormalDrain}},
+	}
+	for _, tt := range tests {
+		r := AnalyzeBattery(tt.b, tt.h)
+		if r.Verdict != tt.verdict || !slices.Equal(findingCodes(r), tt.codes) {
+			t.Errorf("%s: verdict %s, findings %v; want %s, %v", tt.name, r.Verdict, findingCodes(r), tt.verdict, tt.codes)
+		}
+	}
+
+	r := AnalyzeBattery(healthy, batteryHistory{})
+	if r.HealthPercent != 92 || r.CycleCount != 210 || r.Name != "InternalBattery-0" {
+		t.Fatalf("report = %+v", r)
+	}
+}
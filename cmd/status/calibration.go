@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// calibration is the fan and CPU temperature range learned on this machine with
+// --calibrate. Once trusted it replaces generic defaults: the learned fan maximum
+// calibrates FanNoise, and the learned peak temperature shifts the thermal levels.
+type calibration struct {
+	FanMinRPM int       `json:"fan_min_rpm,omitempty"`
+	FanMaxRPM int       `json:"fan_max_rpm,omitempty"`
+	TempMinC  float64   `json:"temp_min_celsius,omitempty"`
+	TempMaxC  float64   `json:"temp_max_celsius,omitempty"`
+	Samples   int       `json:"samples"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+const (
+	// calibrationMinSamples is how many snapshots a calibration needs before it is used.
+	calibrationMinSamples = 30
+	// calibrationTempMargin puts the High level this far above the learned peak, so
+	// the machine's ordinary full load doesn't read as critical.
+	calibrationTempMargin = 3.0
+	// calibrationMaxShift bounds how far learning moves the thermal levels, in °C.
+	calibrationMaxShift = 15.0
+)
+
+// calibrating records ranges from this session; set from --calibrate.
+var calibrating bool
+
+// thermalCutoffs are the CPU temperatures where the estimated thermal level becomes
+// Fair, Serious and Critical.
+type thermalCutoffs struct {
+	Normal, Serious, High float64
+}
+
+var defaultThermalCutoffs = thermalCutoffs{thermalNormalThreshold, thermalSeriousThreshold, thermalHighThreshold}
+
+// Active calibration; set by applyCalibration. learnedFanMax is 0 until trusted.
+var (
+	activeThermalCutoffs = defaultThermalCutoffs
+	learnedFanMax        int
+)
+
+// calibrationPath returns the state file in the user cache dir, or "" if there is none.
+func calibrationPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mole", "calibration.json")
+}
+
+// loadCalibration reads path. A missing or corrupt file means not calibrated.
+func loadCalibration(path string) calibration {
+	var c calibration
+	if path == "" {
+		return c
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return calibration{}
+	}
+	return c
+}
+
+// saveCalibration writes c to path; see stateFiles for an unwritable cache dir.
+func saveCalibration(path string, c calibration) error {
+	if path == "" || c.Samples == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return persisted.write(path, data)
+}
+
+// resetCalibration deletes the learned ranges; nothing to delete is not an error.
+func resetCalibration(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// observe widens the ranges to cover t. Zero readings mean "not reported" and are skipped.
+func (c *calibration) observe(t ThermalStatus, now time.Time) {
+	if t.FanSpeed > 0 {
+		if c.FanMinRPM == 0 || t.FanSpeed < c.FanMinRPM {
+			c.FanMinRPM = t.FanSpeed
+		}
+		c.FanMaxRPM = max(c.FanMaxRPM, t.FanSpeed)
+	}
+	if t.CPUTemp > 0 {
+		if c.TempMinC == 0 || t.CPUTemp < c.TempMinC {
+			c.TempMinC = t.CPUTemp
+		}
+		c.TempMaxC = max(c.TempMaxC, t.CPUTemp)
+	}
+	c.Samples++
+	c.UpdatedAt = now
+}
+
+// trusted reports whether enough of a session was seen to act on.
+func (c calibration) trusted() bool {
+	return c.Samples >= calibrationMinSamples
+}
+
+// cutoffs shifts the default thermal levels so High starts just above the learned
+// peak: a fanless laptop that routinely runs at 95°C isn't permanently critical,
+// and a desktop that never passes 60°C flags a hot spell sooner.
+func (c calibration) cutoffs() thermalCutoffs {
+	if !c.trusted() || c.TempMaxC <= 0 {
+		return defaultThermalCutoffs
+	}
+	shift := min(max(c.TempMaxC+calibrationTempMargin-thermalHighThreshold, -calibrationMaxShift), calibrationMaxShift)
+	d := defaultThermalCutoffs
+	return thermalCutoffs{d.Normal + shift, d.Serious + shift, d.High + shift}
+}
+
+// applyCalibration makes c the active calibration for thermal levels and FanNoise.
+func applyCalibration(c calibration) {
+	activeThermalCutoffs = c.cutoffs()
+	learnedFanMax = 0
+	if c.trusted() {
+		learnedFanMax = c.FanMaxRPM
+	}
+}
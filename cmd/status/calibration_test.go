@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCalibrationLearnsAndShiftsLevels(t *testing.T) {
+	t.Cleanup(func() { applyCalibration(calibration{}) })
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	var c calibration
+	for i := range calibrationMinSamples {
+		c.observe(ThermalStatus{CPUTemp: 70 + float64(i%25), FanSpeed: 1200 + 100*(i%10)}, now)
+	}
+	c.observe(ThermalStatus{}, now) // Nothing reported: counted, ranges untouched
+	if c.FanMinRPM != 1200 || c.FanMaxRPM != 2100 || c.TempMinC != 70 || c.TempMaxC != 94 {
+		t.Fatalf("learned ranges = %+v", c)
+	}
+	if !c.trusted() {
+		t.Fatalf("%d samples should be trusted", c.Samples)
+	}
+
+	// A machine peaking at 94°C gets High at 97°C instead of the generic 85°C.
+	applyCalibration(c)
+	if activeThermalCutoffs.High != 97 || learnedFanMax != 2100 {
+		t.Fatalf("cutoffs = %+v, fan max = %d", activeThermalCutoffs, learnedFanMax)
+	}
+	if got := thermalLevelFromTemp(90); got != ThermalLevelSerious {
+		t.Fatalf("90°C after calibration = %v, want serious", got)
+	}
+
+	// The shift is bounded, and an untrusted calibration changes nothing.
+	if got := (calibration{TempMaxC: 200, Samples: calibrationMinSamples}).cutoffs().High; got != thermalHighThreshold+calibrationMaxShift {
+		t.Fatalf("clamped High = %v", got)
+	}
+	applyCalibration(calibration{TempMaxC: 94, FanMaxRPM: 2100, Samples: 1})
+	if activeThermalCutoffs != defaultThermalCutoffs || learnedFanMax != 0 {
+		t.Fatalf("untrusted calibration applied: %+v, %d", activeThermalCutoffs, learnedFanMax)
+	}
+}
+
+func TestCalibrationPersistsAndResets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mole", "calibration.json")
+	want := calibration{FanMinRPM: 1200, FanMaxRPM: 5800, TempMinC: 38, TempMaxC: 92, Samples: 40, UpdatedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	if err := saveCalibration(path, want); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadCalibration(path); got != want {
+		t.Fatalf("loaded %+v, want %+v", got, want)
+	}
+	if err := resetCalibration(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("calibration file still present: %v", err)
+	}
+	if err := resetCalibration(path); err != nil {
+		t.Fatalf("resetting twice: %v", err)
+	}
+	if got := loadCalibration(path); got != (calibration{}) {
+		t.Fatalf("after reset loaded %+v", got)
+	}
+}
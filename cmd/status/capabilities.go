@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A command that fails this many times in a row is marked degraded for degradedWindow,
+// so a sandboxed pmset or system_profiler isn't respawned every tick.
+const (
+	degradedAfterFailures = 3
+	degradedWindow        = time.Minute
+)
+
+// ErrCommandDegraded is returned without spawning when a tool is in its degraded window.
+var ErrCommandDegraded = errors.New("command present but failing")
+
+type commandHealth struct {
+	name          string
+	failures      int
+	degradedUntil time.Time
+}
+
+// commandTracker records consecutive failures per invocation, name plus arguments,
+// so one failing query (sysctl for an oid this machine lacks) doesn't degrade
+// every other use of the same tool.
+type commandTracker struct {
+	mu     sync.Mutex
+	health map[string]*commandHealth
+	now    func() time.Time
+}
+
+var commands = &commandTracker{health: make(map[string]*commandHealth), now: time.Now}
+
+// reset forgets every command's failure history.
+func (t *commandTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.health = make(map[string]*commandHealth)
+}
+
+// commandKey identifies one invocation in the tracker.
+func commandKey(name string, args []string) string {
+	return name + "\x00" + strings.Join(args, "\x00")
+}
+
+// allow reports whether name may be spawned with args right now.
+func (t *commandTracker) allow(name string, args []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if h, ok := t.health[commandKey(name, args)]; ok && t.now().Before(h.degradedUntil) {
+		return fmt.Errorf("%s: %w", name, ErrCommandDegraded)
+	}
+	return nil
+}
+
+// record updates the health of name run with args. Cancellations and timeouts are the
+// caller's doing, not the tool's, so they don't count as failures; neither do dry-run stubs.
+func (t *commandTracker) record(ctx context.Context, name string, args []string, err error) {
+	if err != nil && (ctx.Err() != nil || errors.Is(err, errDryRun)) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := commandKey(name, args)
+	h, ok := t.health[key]
+	if !ok {
+		h = &commandHealth{name: name}
+		t.health[key] = h
+	}
+	if err == nil {
+		*h = commandHealth{name: name}
+		return
+	}
+	h.failures++
+	if h.failures >= degradedAfterFailures {
+		h.degradedUntil = t.now().Add(degradedWindow)
+		h.failures = 0
+	}
+}
+
+// degraded reports whether any invocation of name is in its degraded window.
+func (t *commandTracker) degraded(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	for _, h := range t.health {
+		if h.name == name && now.Before(h.degradedUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+// CapabilityState describes whether a data source can be used.
+type CapabilityState string
+
+const (
+	CapabilityAvailable CapabilityState = "available"
+	CapabilityMissing   CapabilityState = "missing"
+	CapabilityDegraded  CapabilityState = "present but failing"
+)
+
+// Capability is the state of one external tool the collectors rely on.
+type Capability struct {
+	Source string
+	State  CapabilityState
+}
+
+// capabilitySources are the external tools worth reporting on.
+var capabilitySources = []string{
+	"pmset", "system_profiler", "ioreg", "sysctl", "diskutil", "scutil",
+	"networksetup", "powermetrics", "nvidia-smi", "bluetoothctl", "upsc", "apcaccess", "upower",
+	"powerprofilesctl", "tlp-stat", "sensors",
+}
+
+// Capabilities reports each external tool as available, missing, or present but failing.
+func Capabilities() []Capability {
+	caps := make([]Capability, 0, len(capabilitySources))
+	for _, name := range capabilitySources {
+		state := CapabilityAvailable
+		switch {
+		case !commandExists(name):
+			state = CapabilityMissing
+		case commands.degraded(name):
+			state = CapabilityDegraded
+		}
+		caps = append(caps, Capability{Source: name, State: state})
+	}
+	return caps
+}
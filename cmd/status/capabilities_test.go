@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCommandTrackerDegradesAfterRepeatedFailures(t *testing.T) {
+	now := time.Now()
+	tracker := &commandTracker{health: make(map[string]*commandHealth), now: func() time.Time { return now }}
+	ctx := context.Background()
+	fail := errors.New("exit status 1")
+	batt := []string{"-g", "batt"}
+
+	for range degradedAfterFailures - 1 {
+		tracker.record(ctx, "pmset", batt, fail)
+	}
+	if err := tracker.allow("pmset", batt); err != nil {
+		t.Fatalf("should still allow before the threshold, got %v", err)
+	}
+	tracker.record(ctx, "pmset", batt, fail)
+	if err := tracker.allow("pmset", batt); !errors.Is(err, ErrCommandDegraded) {
+		t.Fatalf("expected degraded after %d failures, got %v", degradedAfterFailures, err)
+	}
+
+	now = now.Add(degradedWindow)
+	if err := tracker.allow("pmset", batt); err != nil {
+		t.Fatalf("expected retry after the window, got %v", err)
+	}
+	tracker.record(ctx, "pmset", batt, nil)
+	tracker.record(ctx, "pmset", batt, fail)
+	if tracker.degraded("pmset") {
+		t.Fatal("a success must reset the failure count")
+	}
+}
+
+func TestCommandTrackerIgnoresCancellation(t *testing.T) {
+	tracker := &commandTracker{health: make(map[string]*commandHealth), now: time.Now}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range degradedAfterFailures {
+		tracker.record(ctx, "system_profiler", []string{spPowerDataType}, ctx.Err())
+	}
+	if tracker.degraded("system_profiler") {
+		t.Fatal("caller cancellations must not mark a tool degraded")
+	}
+}
+
+func TestCommandTrackerKeysByInvocation(t *testing.T) {
+	tracker := &commandTracker{health: make(map[string]*commandHealth), now: time.Now}
+	missing := []string{"-n", "hw.optional.arm64"}
+	for range degradedAfterFailures {
+		tracker.record(context.Background(), "sysctl", missing, errors.New("exit status 1"))
+	}
+	if err := tracker.allow("sysctl", missing); !errors.Is(err, ErrCommandDegraded) {
+		t.Fatalf("expected the failing query degraded, got %v", err)
+	}
+	if err := tracker.allow("sysctl", []string{"-n", "machdep.xcpm.cpu_thermal_level"}); err != nil {
+		t.Fatalf("one failing query degraded other sysctl calls: %v", err)
+	}
+	if !tracker.degraded("sysctl") {
+		t.Fatal("Capabilities should still see sysctl as failing")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultCommandConcurrency bounds how many external commands run at once, so the
+// first cold tick (every probe spawning together) doesn't fork a burst of processes.
+const defaultCommandConcurrency = 4
+
+// commandSlots is a counting semaphore shared by every runCmd caller.
+type commandSlots struct {
+	mu    sync.Mutex
+	slots chan struct{}
+}
+
+var cmdSlots = newCommandSlots(defaultCommandConcurrency)
+
+func newCommandSlots(n int) *commandSlots {
+	return &commandSlots{slots: make(chan struct{}, n)}
+}
+
+// resize changes the limit for later acquisitions; commands already holding a slot
+// in the old semaphore release into it and don't count against the new one.
+func (s *commandSlots) resize(n int) error {
+	if n < 1 {
+		return fmt.Errorf("command concurrency must be at least 1, got %d", n)
+	}
+	s.mu.Lock()
+	s.slots = make(chan struct{}, n)
+	s.mu.Unlock()
+	return nil
+}
+
+// limit reports the current maximum.
+func (s *commandSlots) limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cap(s.slots)
+}
+
+// acquire blocks until a slot is free or ctx is done. The returned func releases the slot.
+func (s *commandSlots) acquire(ctx context.Context) (release func(), err error) {
+	s.mu.Lock()
+	slots := s.slots
+	s.mu.Unlock()
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
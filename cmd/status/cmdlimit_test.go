@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunCmdRespectsConcurrencyLimit(t *testing.T) {
+	prevSlots, prevRunner := cmdSlots, cmdRunner
+	t.Cleanup(func() { waitCommands(); cmdSlots, cmdRunner = prevSlots, prevRunner })
+	cmdSlots = newCommandSlots(2)
+
+	var running, peak atomic.Int32
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return "", nil
+	}
+
+	// Distinct arguments, so the calls aren't merged into one shared run.
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = runCmd(context.Background(), "true", strconv.Itoa(i))
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got != 2 {
+		t.Fatalf("peak concurrency %d, want 2", got)
+	}
+}
+
+func TestCommandSlotsAcquireHonorsContext(t *testing.T) {
+	s := newCommandSlots(1)
+	release, err := s.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline while all slots are busy, got %v", err)
+	}
+	if err := s.resize(0); err == nil {
+		t.Fatal("expected error for a zero limit")
+	}
+}
+
+func TestRunCmdSharesConcurrentCalls(t *testing.T) {
+	prevRunner := cmdRunner
+	t.Cleanup(func() { waitCommands(); cmdRunner = prevRunner })
+
+	var calls atomic.Int32
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		if calls.Add(1) == 1 {
+			close(entered)
+		}
+		<-unblock
+		return "Now drawing from 'AC Power'", nil
+	}
+
+	var wg sync.WaitGroup
+	outs := make([]string, 2)
+	call := func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outs[i], _ = runCmd(context.Background(), "pmset", "-g", "batt")
+		}()
+	}
+	call(0)
+	<-entered
+	call(1)
+	// Give the second caller time to join the in-flight run before it finishes.
+	time.Sleep(50 * time.Millisecond)
+	close(unblock)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("two simultaneous calls ran the command %d times, want 1", n)
+	}
+	if outs[0] != outs[1] || outs[0] == "" {
+		t.Fatalf("callers got %q and %q, want the same output", outs[0], outs[1])
+	}
+
+	// Once the first run is done, a new call runs the command again.
+	if _, err := runCmd(context.Background(), "pmset", "-g", "batt"); err != nil || calls.Load() != 2 {
+		t.Fatalf("later call: err %v, calls %d", err, calls.Load())
+	}
+}
+
+func TestRunCmdDeadlineCoversQueueing(t *testing.T) {
+	prevSlots, prevRunner := cmdSlots, cmdRunner
+	t.Cleanup(func() { waitCommands(); cmdSlots, cmdRunner = prevSlots, prevRunner })
+	cmdSlots = newCommandSlots(1)
+	var ran atomic.Bool
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		ran.Store(true)
+		return "ok", nil
+	}
+
+	// Another command holds the only slot for longer than this call's timeout.
+	release, err := cmdSlots.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(300*time.Millisecond, release)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := runCmd(ctx, "pmset", "-g", "batt"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("runCmd took %v past a 50ms deadline", elapsed)
+	}
+	waitCommands()
+	if ran.Load() {
+		t.Fatal("command spawned after its caller's deadline passed in the queue")
+	}
+}
+
+func TestRunCmdJoinedCallerOutlivesLeader(t *testing.T) {
+	prevRunner := cmdRunner
+	t.Cleanup(func() { waitCommands(); cmdRunner = prevRunner })
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		close(entered)
+		select {
+		case <-unblock:
+			return "Now drawing from 'AC Power'", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := runCmd(leaderCtx, "pmset", "-g", "batt")
+		leaderErr <- err
+	}()
+	<-entered
+	joined := make(chan string, 1)
+	go func() {
+		out, _ := runCmd(context.Background(), "pmset", "-g", "batt")
+		joined <- out
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancelLeader()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled leader: err = %v", err)
+	}
+	close(unblock)
+	if out := <-joined; out == "" {
+		t.Fatal("the joined caller failed with the leader")
+	}
+}
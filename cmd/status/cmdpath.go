@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// commandPaths overrides where external binaries live, keyed by command name
+// (e.g. "pmset" → "/usr/bin/pmset"). Unlisted commands are looked up on PATH.
+var commandPaths = map[string]string{}
+
+// resolveCommand returns the override for name, or name itself for a PATH lookup.
+func resolveCommand(name string) string {
+	if path, ok := commandPaths[name]; ok {
+		return path
+	}
+	return name
+}
+
+// setCommandPath parses one name=/absolute/path override.
+func setCommandPath(raw string) error {
+	name, path, ok := strings.Cut(raw, "=")
+	name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+	if !ok || name == "" || path == "" {
+		return fmt.Errorf("want name=/absolute/path, got %q", raw)
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("command path for %s must be absolute, got %q", name, path)
+	}
+	commandPaths[name] = path
+	return nil
+}
+
+// commandPathList renders the overrides as sorted name=path pairs.
+func commandPathList() []string {
+	out := []string{}
+	for _, name := range slices.Sorted(maps.Keys(commandPaths)) {
+		out = append(out, name+"="+commandPaths[name])
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandPathOverride(t *testing.T) {
+	prev := commandPaths
+	t.Cleanup(func() { commandPaths = prev })
+	commandPaths = map[string]string{}
+
+	stub := filepath.Join(t.TempDir(), "pmset-stub")
+	script := "#!/bin/sh\necho \"Now drawing from 'AC Power' $*\"\n"
+	if err := os.WriteFile(stub, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	const name = "mole-test-pmset"
+	if commandExists(name) {
+		t.Fatalf("%s should not be on PATH", name)
+	}
+	if err := setCommandPath(name + "=" + stub); err != nil {
+		t.Fatal(err)
+	}
+	if !commandExists(name) {
+		t.Fatal("override should make the command available")
+	}
+	out, err := execCmd(context.Background(), nil, name, "-g", "batt")
+	if err != nil || strings.TrimSpace(out) != "Now drawing from 'AC Power' -g batt" {
+		t.Fatalf("stub output %q, %v", out, err)
+	}
+}
+
+func TestSetCommandPathRejectsBadInput(t *testing.T) {
+	prev := commandPaths
+	t.Cleanup(func() { commandPaths = prev })
+	commandPaths = map[string]string{}
+
+	for _, raw := range []string{"pmset", "pmset=", "=/usr/bin/pmset", "pmset=bin/pmset"} {
+		if err := setCommandPath(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+	if len(commandPaths) != 0 {
+		t.Fatalf("rejected overrides were stored: %v", commandPaths)
+	}
+}
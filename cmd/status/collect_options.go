@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Built-in per-command time limits; CollectOptions overrides them.
+const (
+	quickQueryTimeout         = 500 * time.Millisecond // ioreg and sysctl
+	systemPowerProfileTimeout = 3 * time.Second        // system_profiler SPPowerDataType
+)
+
+// CollectOptions overrides per-command time limits for machines where the usual
+// ones are too tight, e.g. ioreg taking over 500ms under load, which would
+// otherwise silently drop the battery temperature. Zero fields keep each call
+// site's built-in limit.
+type CollectOptions struct {
+	CommandTimeout        time.Duration // ioreg and sysctl queries on macOS
+	SystemProfilerTimeout time.Duration // Every system_profiler run
+}
+
+// collectOptions is the active set; set from --command-timeout and --profiler-timeout.
+var collectOptions CollectOptions
+
+// SetCollectOptions replaces the active options.
+func SetCollectOptions(o CollectOptions) error {
+	if o.CommandTimeout < 0 || o.SystemProfilerTimeout < 0 {
+		return fmt.Errorf("command timeouts must not be negative")
+	}
+	collectOptions = o
+	return nil
+}
+
+// commandTimeout is CommandTimeout, or def when unset.
+func (o CollectOptions) commandTimeout(def time.Duration) time.Duration {
+	if o.CommandTimeout > 0 {
+		return o.CommandTimeout
+	}
+	return def
+}
+
+// profilerTimeout is SystemProfilerTimeout, or def when unset.
+func (o CollectOptions) profilerTimeout(def time.Duration) time.Duration {
+	if o.SystemProfilerTimeout > 0 {
+		return o.SystemProfilerTimeout
+	}
+	return def
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCollectOptionsDefaults(t *testing.T) {
+	var o CollectOptions
+	if got := o.commandTimeout(quickQueryTimeout); got != 500*time.Millisecond {
+		t.Fatalf("zero CommandTimeout = %v, want the built-in 500ms", got)
+	}
+	o = CollectOptions{CommandTimeout: 2 * time.Second}
+	if got := o.commandTimeout(quickQueryTimeout); got != 2*time.Second {
+		t.Fatalf("CommandTimeout override = %v", got)
+	}
+	if got := o.profilerTimeout(systemPowerProfileTimeout); got != 3*time.Second {
+		t.Fatalf("unset SystemProfilerTimeout = %v, want the built-in 3s", got)
+	}
+	if err := SetCollectOptions(CollectOptions{CommandTimeout: -time.Second}); err == nil {
+		t.Fatal("expected a negative timeout to be rejected")
+	}
+}
+
+func TestSystemProfilerCacheUsesProfilerTimeout(t *testing.T) {
+	prev := collectOptions
+	t.Cleanup(func() { collectOptions = prev })
+	if err := SetCollectOptions(CollectOptions{SystemProfilerTimeout: 9 * time.Second}); err != nil {
+		t.Fatal(err)
+	}
+
+	var remaining time.Duration
+	cache := newSystemProfilerCache(map[string]time.Duration{spPowerDataType: time.Minute})
+	cache.fetch = func(ctx context.Context, dataType string) (string, error) {
+		deadline, _ := ctx.Deadline()
+		remaining = time.Until(deadline)
+		return "ok", nil
+	}
+	cache.get(context.Background(), spPowerDataType)
+	if remaining <= 8*time.Second || remaining > 9*time.Second {
+		t.Fatalf("fetch deadline %v away, want about 9s", remaining)
+	}
+}
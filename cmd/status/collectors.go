@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CollectorKind names one probe that Collect can run or skip.
+type CollectorKind string
+
+const (
+	CollectCPU        CollectorKind = "cpu"
+	CollectMemory     CollectorKind = "memory"
+	CollectDisks      CollectorKind = "disks"
+	CollectDiskIO     CollectorKind = "diskio"
+	CollectNetwork    CollectorKind = "network"
+	CollectProxy      CollectorKind = "proxy"
+	CollectBattery    CollectorKind = "battery"
+	CollectUPS        CollectorKind = "ups"
+	CollectThermal    CollectorKind = "thermal"
+	CollectPower      CollectorKind = "power"
+	CollectSensors    CollectorKind = "sensors"
+	CollectGPU        CollectorKind = "gpu"
+	CollectBluetooth  CollectorKind = "bluetooth"
+	CollectDisplay    CollectorKind = "display"
+	CollectAssertions CollectorKind = "assertions"
+	CollectEnergy     CollectorKind = "energy"
+	CollectProcesses  CollectorKind = "processes"
+	CollectHost       CollectorKind = "host" // Host info and the cached hardware profile
+)
+
+// collectorKinds lists every kind, in the order --help shows them.
+var collectorKinds = []CollectorKind{
+	CollectCPU, CollectMemory, CollectDisks, CollectDiskIO, CollectNetwork, CollectProxy,
+	CollectBattery, CollectUPS, CollectThermal, CollectPower, CollectSensors, CollectGPU, CollectBluetooth, CollectDisplay, CollectAssertions, CollectEnergy, CollectProcesses, CollectHost,
+}
+
+// disabledCollectors seeds every NewCollector; set from --disable/--only.
+var disabledCollectors = map[CollectorKind]bool{}
+
+// SetEnabled turns one collector on or off. Disabled collectors are skipped entirely:
+// no subprocesses, no errors, and their snapshot fields stay zero.
+func (c *Collector) SetEnabled(kind CollectorKind, on bool) {
+	if on {
+		delete(c.disabled, kind)
+	} else {
+		c.disabled[kind] = true
+	}
+}
+
+// Enabled reports whether kind runs on the next Collect.
+func (c *Collector) Enabled(kind CollectorKind) bool {
+	return !c.disabled[kind]
+}
+
+// parseCollectorList parses a comma-separated list such as "thermal,sensors".
+func parseCollectorList(raw string) ([]CollectorKind, error) {
+	var kinds []CollectorKind
+	for name := range strings.SplitSeq(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		kind := CollectorKind(name)
+		if !slices.Contains(collectorKinds, kind) {
+			return nil, fmt.Errorf("unknown collector %q (want one of %s)", name, collectorKindNames())
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// onlyCollectors disables every kind not listed.
+func onlyCollectors(keep []CollectorKind) map[CollectorKind]bool {
+	disabled := make(map[CollectorKind]bool)
+	for _, kind := range collectorKinds {
+		if !slices.Contains(keep, kind) {
+			disabled[kind] = true
+		}
+	}
+	return disabled
+}
+
+func collectorKindNames() string {
+	names := make([]string, len(collectorKinds))
+	for i, kind := range collectorKinds {
+		names[i] = string(kind)
+	}
+	return strings.Join(names, ", ")
+}
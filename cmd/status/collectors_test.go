@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCollectSkipsDisabledCollectors(t *testing.T) {
+	rec := newDryRunRecorder()
+	prev := cmdRunner
+	cmdRunner = rec.run
+	t.Cleanup(func() { cmdRunner = prev })
+
+	c := NewCollector()
+	for _, kind := range collectorKinds {
+		c.SetEnabled(kind, false)
+	}
+	m, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("disabled collectors must not report errors: %v", err)
+	}
+	if m.CPU.LogicalCPU != 0 || len(m.Disks) != 0 || len(m.Batteries) != 0 || m.BatteryErr != nil {
+		t.Fatalf("disabled collectors left data behind: %+v", m)
+	}
+	for _, cmd := range rec.Commands() {
+		t.Errorf("unexpected subprocess with every collector disabled: %s", cmd)
+	}
+	if m.Host != "" || m.Hardware != (HardwareInfo{}) {
+		t.Fatalf("host collector disabled but host data collected: %q %+v", m.Host, m.Hardware)
+	}
+
+	c.SetEnabled(CollectMemory, true)
+	if !c.Enabled(CollectMemory) || c.Enabled(CollectCPU) {
+		t.Fatal("SetEnabled did not toggle a single collector")
+	}
+}
+
+func TestParseCollectorList(t *testing.T) {
+	kinds, err := parseCollectorList(" Thermal, sensors,,")
+	if err != nil || len(kinds) != 2 || kinds[0] != CollectThermal || kinds[1] != CollectSensors {
+		t.Fatalf("got %v, %v", kinds, err)
+	}
+	if _, err := parseCollectorList("thermal,wifi"); err == nil {
+		t.Fatal("expected error for unknown collector")
+	}
+
+	disabled := onlyCollectors([]CollectorKind{CollectBattery})
+	if disabled[CollectBattery] || !disabled[CollectThermal] || len(disabled) != len(collectorKinds)-1 {
+		t.Fatalf("unexpected --only result %v", disabled)
+	}
+}
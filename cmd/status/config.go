@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+)
+
+// EffectiveConfig is every tunable in effect after flags are parsed, defaults included.
+// Users paste it into bug reports or save it as a starting point.
+type EffectiveConfig struct {
+	Version string `json:"version"`
+
+	DisabledCollectors []CollectorKind `json:"disabled_collectors"`
+	MaxProcs           int             `json:"max_procs"`
+	CommandPaths       []string        `json:"command_paths"`
+	Debug              bool            `json:"debug"`
+
+	Filters struct {
+		IgnoreNet      []string `json:"ignore_net"`
+		IgnoreDisk     []string `json:"ignore_disk"`
+		SensorsInclude []string `json:"sensors_include"`
+		SensorsExclude []string `json:"sensors_exclude"`
+	} `json:"filters"`
+
+	Battery struct {
+		Primary             string   `json:"primary"`
+		Sources             []string `json:"sources"`
+		DisableTempFallback bool     `json:"disable_temp_fallback"`
+		ChargerInfo         bool     `json:"charger_info"`
+		DisplayInfo         bool     `json:"display_info"`
+		Assertions          bool     `json:"assertions"`
+		EnergyImpact        bool     `json:"energy_impact"`
+		EnergyTop           int      `json:"energy_top"`
+		RatedCycles         int      `json:"rated_cycles,omitempty"` // 0 uses the model lookup
+		Powermetrics        bool     `json:"powermetrics"`
+		SiliconPower        bool     `json:"silicon_power"`
+		InstantCurrent      bool     `json:"instant_current"`
+		ProfilerXML         bool     `json:"profiler_xml"`
+	} `json:"battery"`
+
+	Thresholds struct {
+		BatteryLowPercent    float64                   `json:"battery_low_percent"`
+		BatteryWornPercent   float64                   `json:"battery_worn_percent"`
+		DiskWarnPercent      float64                   `json:"disk_warn_percent"`
+		DiskFullPercent      float64                   `json:"disk_full_percent"`
+		CPUHighPercent       float64                   `json:"cpu_high_percent"`
+		MemHighPercent       float64                   `json:"mem_high_percent"`
+		ThermalSeriousC      float64                   `json:"thermal_serious_celsius"`
+		ThermalHighC         float64                   `json:"thermal_high_celsius"`
+		CriticalTempC        float64                   `json:"critical_temp_celsius"`
+		CriticalTempSamples  int                       `json:"critical_temp_samples"`
+		FanNoiseBandsRPM     [3]int                    `json:"fan_noise_bands_rpm"`
+		CommandFailuresLimit int                       `json:"command_failures_limit"`
+		Alerts               map[string]AlertThreshold `json:"alerts"`
+		Nagios               struct {
+			BatteryWarnPercent float64 `json:"battery_warn_percent"`
+			BatteryCritPercent float64 `json:"battery_crit_percent"`
+			TempWarnC          float64 `json:"temp_warn_celsius"`
+			TempCritC          float64 `json:"temp_crit_celsius"`
+		} `json:"nagios"`
+	} `json:"thresholds"`
+
+	Display struct {
+		SensorDecimals  int    `json:"sensor_decimals"`
+		SensorLocations bool   `json:"sensor_locations"`
+		SensorSummary   bool   `json:"sensor_summary"`
+		CapacityUnit    string `json:"capacity_unit"`
+		TempUnit        string `json:"temp_unit"`
+	} `json:"display"`
+
+	Sampling struct {
+		TempSamples         int                 `json:"temp_samples"`
+		TempWindow          string              `json:"temp_window"`
+		Adaptive            bool                `json:"adaptive"`
+		AdaptiveSensitivity float64             `json:"adaptive_sensitivity"`
+		HistorySamples      int                 `json:"history_samples"`
+		SensorUnits         map[string]TempUnit `json:"sensor_units"`
+		Calibrate           bool                `json:"calibrate"`
+		FanSmoothing        int                 `json:"fan_smoothing"`
+		StreamBuffer        int                 `json:"stream_buffer"`
+		StreamDrop          StreamDrop          `json:"stream_drop"`
+	} `json:"sampling"`
+
+	Export struct {
+		HostLabels    bool `json:"host_labels"`
+		HideMachineID bool `json:"hide_machine_id"`
+	} `json:"export"`
+
+	Remote struct {
+		Hosts   []string `json:"hosts"`
+		Timeout string   `json:"timeout"`
+	} `json:"remote"`
+
+	Timing struct {
+		Refresh          string `json:"refresh"`
+		Prime            string `json:"prime"`
+		PowerCacheTTL    string `json:"power_cache_ttl"`
+		HardwareCacheTTL string `json:"hardware_cache_ttl"`
+		TopologyTTL      string `json:"topology_ttl"`
+		DiskCacheTTL     string `json:"disk_cache_ttl"`
+		SensorCacheTTL   string `json:"sensor_cache_ttl"`
+		DegradedWindow   string `json:"degraded_window"`
+		AdaptiveMin      string `json:"adaptive_min"`
+		AdaptiveMax      string `json:"adaptive_max"`
+		HistoryMaxAge    string `json:"history_max_age"`
+		CommandTimeout   string `json:"command_timeout"`
+		ProfilerTimeout  string `json:"profiler_timeout"`
+	} `json:"timing"`
+}
+
+// effectiveConfig snapshots the package-level settings as they are right now.
+func effectiveConfig() EffectiveConfig {
+	var c EffectiveConfig
+	c.Version = Version
+
+	c.MaxProcs = cmdSlots.limit()
+	c.Debug = debugLogging
+	c.CommandPaths = commandPathList()
+
+	// Non-nil so an empty list prints [] rather than null.
+	c.DisabledCollectors = []CollectorKind{}
+	for _, kind := range collectorKinds {
+		if disabledCollectors[kind] {
+			c.DisabledCollectors = append(c.DisabledCollectors, kind)
+		}
+	}
+	c.Filters.IgnoreNet = append([]string{}, ignoreNetDevices...)
+	c.Filters.IgnoreDisk = append([]string{}, ignoreDiskDevices...)
+	c.Filters.SensorsInclude = append([]string{}, sensorFilter.Include...)
+	c.Filters.SensorsExclude = append([]string{}, sensorFilter.Exclude...)
+
+	c.Battery.Primary = primaryBatteryName
+	c.Battery.Sources = batteryChainNames()
+	c.Battery.DisableTempFallback = disableTempFallback
+	c.Battery.ChargerInfo = collectChargerInfo
+	c.Battery.DisplayInfo = collectDisplayInfo
+	c.Battery.Assertions = collectPowerAssertions
+	c.Battery.EnergyImpact = collectEnergyImpact
+	c.Battery.EnergyTop = energyImpactTopN
+	c.Battery.RatedCycles = ratedCyclesOverride
+	c.Battery.Powermetrics = collectPowermetrics
+	c.Battery.SiliconPower = collectSiliconPowerCounters
+	c.Battery.InstantCurrent = estimateFromInstantCurrent
+	c.Battery.ProfilerXML = useProfilerXML
+
+	c.Thresholds.BatteryLowPercent = alertThresholds["battery-low"].Trigger
+	c.Thresholds.BatteryWornPercent = wornThresholdPercent
+	c.Thresholds.DiskWarnPercent = diskWarnThreshold
+	c.Thresholds.DiskFullPercent = alertDiskFullPercent
+	c.Thresholds.CPUHighPercent = cpuHighThreshold
+	c.Thresholds.MemHighPercent = memHighThreshold
+	c.Thresholds.ThermalSeriousC = activeThermalCutoffs.Serious
+	c.Thresholds.ThermalHighC = activeThermalCutoffs.High
+	c.Thresholds.CriticalTempC = criticalTempCelsius
+	c.Thresholds.CriticalTempSamples = criticalTempSamples
+	c.Thresholds.FanNoiseBandsRPM = fanNoiseBands
+	c.Thresholds.CommandFailuresLimit = degradedAfterFailures
+	c.Thresholds.Alerts = maps.Clone(alertThresholds)
+	c.Thresholds.Nagios.BatteryWarnPercent = nagiosThresholds.BatteryWarn
+	c.Thresholds.Nagios.BatteryCritPercent = nagiosThresholds.BatteryCrit
+	c.Thresholds.Nagios.TempWarnC = nagiosThresholds.TempWarn
+	c.Thresholds.Nagios.TempCritC = nagiosThresholds.TempCrit
+
+	c.Display.SensorDecimals = sensorDisplayDecimals
+	c.Display.SensorLocations = sensorLocations
+	c.Display.SensorSummary = sensorSummaryOnly
+	c.Display.TempUnit = string(displayTempUnit)
+	c.Display.CapacityUnit = string(capacityDisplayUnit)
+	if c.Display.CapacityUnit == "" {
+		c.Display.CapacityUnit = "auto"
+	}
+
+	c.Sampling.TempSamples = tempSamples
+	c.Sampling.TempWindow = tempSampleWindow.String()
+	c.Sampling.Adaptive = adaptiveRefresh
+	c.Sampling.AdaptiveSensitivity = adaptiveSensitivity
+	c.Sampling.HistorySamples = historyRetention.Samples
+	c.Sampling.SensorUnits = maps.Clone(sensorSourceUnits)
+	c.Sampling.Calibrate = calibrating
+	c.Sampling.FanSmoothing = fanSmoothingWindow
+	c.Sampling.StreamBuffer = max(streamBuffer, 1)
+	c.Sampling.StreamDrop = streamDrop
+
+	c.Export.HostLabels = exportHostLabels
+	c.Export.HideMachineID = hideMachineID
+
+	c.Remote.Hosts = append([]string{}, remoteHosts...)
+	c.Remote.Timeout = remoteTimeout.String()
+
+	c.Timing.Refresh = refreshInterval.String()
+	c.Timing.Prime = primeInterval.String()
+	c.Timing.PowerCacheTTL = powerCacheTTL.String()
+	c.Timing.HardwareCacheTTL = hardwareCacheTTL.String()
+	c.Timing.TopologyTTL = topologyTTL.String()
+	c.Timing.DiskCacheTTL = diskCacheTTL.String()
+	c.Timing.SensorCacheTTL = sensorTemps.TTL().String()
+	c.Timing.DegradedWindow = degradedWindow.String()
+	c.Timing.AdaptiveMin = adaptiveMin.String()
+	c.Timing.AdaptiveMax = adaptiveMax.String()
+	c.Timing.HistoryMaxAge = historyRetention.MaxAge.String()
+	c.Timing.CommandTimeout = collectOptions.commandTimeout(quickQueryTimeout).String()
+	c.Timing.ProfilerTimeout = collectOptions.profilerTimeout(systemPowerProfileTimeout).String()
+	return c
+}
+
+// writeEffectiveConfig prints the effective configuration as indented JSON.
+func writeEffectiveConfig(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(effectiveConfig())
+}
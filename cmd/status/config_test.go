@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteEffectiveConfig(t *testing.T) {
+	origPrime, origNet := primeInterval, ignoreNetDevices
+	t.Cleanup(func() { primeInterval, ignoreNetDevices = origPrime, origNet })
+	primeInterval = 0
+	ignoreNetDevices = nil
+
+	var buf bytes.Buffer
+	if err := writeEffectiveConfig(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got EffectiveConfig
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	if got.Timing.Prime != "0s" || got.Filters.IgnoreNet == nil || len(got.Filters.IgnoreNet) != 0 {
+		t.Fatalf("overrides not reflected: %+v", got)
+	}
+	if got.Filters.IgnoreDisk == nil || got.Thresholds.DiskFullPercent != diskCritThreshold {
+		t.Fatalf("defaults missing: %+v", got)
+	}
+	if d, err := time.ParseDuration(got.Timing.PowerCacheTTL); err != nil || d != powerCacheTTL {
+		t.Fatalf("power cache TTL %q does not round-trip", got.Timing.PowerCacheTTL)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// csvFixedColumns lead every --csv row; sensor columns, one per reading, follow.
+var csvFixedColumns = []string{"timestamp", "battery_percent", "cpu_temp_c"}
+
+// csvSensorColumns names the column of each reading in sensors: its label and
+// unit, e.g. "GPU Die (°C)". Readings that share both get their key appended,
+// so two "Core 0" chips each keep a column of their own.
+func csvSensorColumns(sensors []SensorReading) []string {
+	names := make([]string, len(sensors))
+	count := make(map[string]int, len(sensors))
+	for i, r := range sensors {
+		names[i] = r.Label
+		if r.Unit != "" {
+			names[i] += " (" + r.Unit + ")"
+		}
+		count[names[i]]++
+	}
+	seen := make(map[string]int, len(sensors))
+	for i, r := range sensors {
+		if count[names[i]] > 1 && r.Key != "" {
+			names[i] += " [" + r.Key + "]"
+		}
+	}
+	// Keys can be missing or repeat too; number whatever is still ambiguous.
+	for i := range names {
+		if seen[names[i]]++; seen[names[i]] > 1 {
+			names[i] += " " + strconv.Itoa(seen[names[i]])
+		}
+	}
+	return names
+}
+
+// csvColumns is the header for a new file: the fixed columns, then m's sensor
+// columns sorted, so the order doesn't depend on the order sensors were read.
+func csvColumns(m MetricsSnapshot) []string {
+	var names []string
+	for _, name := range csvSensorColumns(m.Sensors) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return append(slices.Clone(csvFixedColumns), names...)
+}
+
+// csvRow renders m under columns. Temperatures are °C, like the other exports;
+// unavailable values and sensors missing this run are empty cells.
+func csvRow(m MetricsSnapshot, columns []string) []string {
+	values := make(map[string]string, len(m.Sensors)+len(csvFixedColumns))
+	values["timestamp"] = m.CollectedAt.Format(time.RFC3339)
+	if b, ok := m.PrimaryBattery(); ok {
+		values["battery_percent"] = csvNumber(b.Percent)
+	}
+	if m.Thermal.CPUTemp > 0 {
+		values["cpu_temp_c"] = csvNumber(m.Thermal.CPUTemp)
+	}
+	for i, name := range csvSensorColumns(m.Sensors) {
+		if _, dup := values[name]; !dup {
+			values[name] = csvNumber(m.Sensors[i].Value)
+		}
+	}
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = values[col]
+	}
+	return row
+}
+
+func csvNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// readCSVHeader returns the first record of the file at path, or nil when the
+// file doesn't exist or is empty.
+func readCSVHeader(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	header, err := csv.NewReader(bufio.NewReader(f)).Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	return header, err
+}
+
+// appendCSV appends m as one row to path. A new or empty file gets a header
+// first; an existing one keeps its header, so repeated runs line up: sensors it
+// doesn't list are left out and ones missing this run are empty.
+func appendCSV(path string, m MetricsSnapshot) error {
+	columns, err := readCSVHeader(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	records := [][]string{}
+	if columns == nil {
+		columns = csvColumns(m)
+		records = append(records, columns)
+	}
+	records = append(records, csvRow(m, columns))
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(records); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runCSV collects once and appends a row to path.
+func runCSV(ctx context.Context, path string) error {
+	// Section failures leave empty cells.
+	m, _ := collectStatusSnapshot(ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return appendCSV(path, m)
+}
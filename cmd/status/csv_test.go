@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAppendCSVKeepsColumnsAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "thermals.csv")
+	at := time.Date(2026, 10, 14, 9, 26, 53, 0, time.UTC)
+	first := MetricsSnapshot{
+		CollectedAt: at,
+		Batteries:   []BatteryStatus{{Name: "BAT0", Percent: 72}},
+		Thermal:     ThermalStatus{CPUTemp: 54.5},
+		Sensors: []SensorReading{
+			{Label: "GPU Die", Value: 48, Unit: "°C"},
+			{Label: "CPU Proximity", Value: 52.25, Unit: "°C"},
+		},
+	}
+	if err := appendCSV(path, first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sensors come back in another order, one is missing and one is new; there is
+	// no battery and no CPU temperature this time.
+	second := MetricsSnapshot{
+		CollectedAt: at.Add(time.Minute),
+		Sensors: []SensorReading{
+			{Label: "Ambient", Value: 30, Unit: "°C"},
+			{Label: "GPU Die", Value: 50, Unit: "°C"},
+		},
+	}
+	if err := appendCSV(path, second); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "timestamp,battery_percent,cpu_temp_c,CPU Proximity (°C),GPU Die (°C)\n" +
+		"2026-10-14T09:26:53Z,72,54.5,52.25,48\n" +
+		"2026-10-14T09:27:53Z,,,,50\n"
+	if string(got) != want {
+		t.Fatalf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCSVSensorColumnsSplitDuplicateLabels(t *testing.T) {
+	m := MetricsSnapshot{
+		CollectedAt: time.Date(2026, 10, 14, 9, 26, 53, 0, time.UTC),
+		Sensors: []SensorReading{
+			{Key: "coretemp_core_0", Label: "Core 0", Value: 61, Unit: "°C"},
+			{Key: "coretemp_core_0_2", Label: "Core 0", Value: 58, Unit: "°C"},
+			{Key: "nct6775_fan1", Label: "Fan 1", Value: 1200, Unit: "RPM"},
+			{Label: "Fan 1", Value: 900, Unit: "RPM"},
+			{Label: "Fan 1", Value: 800, Unit: "RPM"},
+		},
+	}
+	columns := csvColumns(m)
+	want := []string{"timestamp", "battery_percent", "cpu_temp_c",
+		"Core 0 (°C) [coretemp_core_0]", "Core 0 (°C) [coretemp_core_0_2]",
+		"Fan 1 (RPM)", "Fan 1 (RPM) 2", "Fan 1 (RPM) [nct6775_fan1]"}
+	if !slices.Equal(columns, want) {
+		t.Fatalf("columns = %q, want %q", columns, want)
+	}
+	row := csvRow(m, columns)
+	if got := row[3:]; !slices.Equal(got, []string{"61", "58", "900", "800", "1200"}) {
+		t.Fatalf("row = %q, want every duplicate in its own column", got)
+	}
+}
+
+func TestReadCSVHeaderEmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.csv")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if header, err := readCSVHeader(path); err != nil || header != nil {
+		t.Fatalf("readCSVHeader(empty) = %q, %v; want nil, nil", header, err)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// debugLogging prints internal diagnostics to stderr; set from --debug.
+var debugLogging bool
+
+// debugf logs a diagnostic line when --debug is set. The UI owns the terminal
+// while it runs, so TUI-mode callers only log after it exits.
+func debugf(format string, args ...any) {
+	if !debugLogging {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "mole: "+format+"\n", args...)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Glob patterns (path.Match syntax) for devices the collectors drop. Settable
+// via --ignore-net and --ignore-disk; an empty list disables filtering.
+var (
+	ignoreNetDevices  = []string{"veth*", "br-*", "docker*", "virbr*", "vmnet*", "vboxnet*", "cni*", "flannel*"}
+	ignoreDiskDevices = []string{"loop*", "ram*", "zram*"}
+)
+
+// deviceIgnored reports whether name, or its base for /dev paths, matches any pattern.
+func deviceIgnored(name string, patterns []string) bool {
+	base := path.Base(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGlobList splits a comma-separated pattern list, rejecting malformed globs.
+func parseGlobList(raw string) ([]string, error) {
+	var patterns []string
+	for p := range strings.SplitSeq(raw, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDeviceIgnoredDefaults(t *testing.T) {
+	for _, name := range []string{"veth1a2b3c", "br-0f3e9a", "docker0", "virbr0"} {
+		if !deviceIgnored(name, ignoreNetDevices) {
+			t.Errorf("expected %q to be ignored", name)
+		}
+	}
+	for _, name := range []string{"eth0", "en0", "wlan0"} {
+		if deviceIgnored(name, ignoreNetDevices) {
+			t.Errorf("expected %q to be kept", name)
+		}
+	}
+	for _, name := range []string{"/dev/loop3", "loop0", "zram0"} {
+		if !deviceIgnored(name, ignoreDiskDevices) {
+			t.Errorf("expected disk %q to be ignored", name)
+		}
+	}
+	if deviceIgnored("/dev/nvme0n1p2", ignoreDiskDevices) {
+		t.Error("expected nvme partition to be kept")
+	}
+}
+
+func TestParseGlobList(t *testing.T) {
+	got, err := parseGlobList(" tap*, ,wg0 ")
+	if err != nil {
+		t.Fatalf("parseGlobList: %v", err)
+	}
+	if !slices.Equal(got, []string{"tap*", "wg0"}) {
+		t.Fatalf("unexpected patterns %v", got)
+	}
+	if got, _ := parseGlobList(""); len(got) != 0 {
+		t.Fatalf("expected empty list, got %v", got)
+	}
+	if _, err := parseGlobList("eth[0"); err == nil {
+		t.Fatal("expected error for malformed glob")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// abnormalDrainPercentPerHour is the discharge rate that counts as abnormal while idle;
+	// a laptop idling at the desktop typically loses well under 15%/h.
+	abnormalDrainPercentPerHour = 40.0
+	// drainWindow is how far back samples count toward the rate.
+	drainWindow = 10 * time.Minute
+	// drainMinSpan and drainMinSamples keep one noisy reading from raising a flag.
+	drainMinSpan    = 3 * time.Minute
+	drainMinSamples = 3
+	// drainBusyCPU is CPU usage above which a fast drain is expected, not abnormal.
+	drainBusyCPU = 60.0
+)
+
+type drainSample struct {
+	at      time.Time
+	percent float64
+	cpu     float64 // -1 when CPU data wasn't collected
+}
+
+// drainTracker keeps recent discharging samples per battery to derive %/h.
+type drainTracker struct {
+	samples map[string][]drainSample
+}
+
+// observe records this tick's batteries and sets DrainRate and AbnormalDrain in place.
+// History restarts after a wake, when a battery stops discharging, or when its charge rises.
+// cpu is the tick's CPU usage, or negative when unknown.
+func (d *drainTracker) observe(batts []BatteryStatus, now time.Time, cpu float64, afterWake bool) {
+	if d.samples == nil || afterWake {
+		d.samples = make(map[string][]drainSample)
+	}
+	live := make(map[string]bool, len(batts))
+	for i := range batts {
+		b := &batts[i]
+		live[b.Name] = true
+		hist := d.samples[b.Name]
+		if !strings.EqualFold(b.Status, "discharging") || (len(hist) > 0 && b.Percent > hist[len(hist)-1].percent) {
+			delete(d.samples, b.Name)
+			continue
+		}
+		hist = append(hist, drainSample{at: now, percent: b.Percent, cpu: cpu})
+		window := historyRetention.window(drainWindow)
+		drop := max(len(hist)-historyRetention.Samples, 0)
+		for drop < len(hist) && now.Sub(hist[drop].at) > window {
+			drop++
+		}
+		// Shift in place so evicted samples don't pin a growing backing array.
+		hist = append(hist[:0], hist[drop:]...)
+		d.samples[b.Name] = hist
+		b.DrainRate, b.AbnormalDrain = drainRate(hist)
+	}
+	for name := range d.samples {
+		if !live[name] {
+			delete(d.samples, name)
+		}
+	}
+}
+
+// drainRate returns %/h across hist and whether it is abnormally fast for the load.
+func drainRate(hist []drainSample) (rate float64, abnormal bool) {
+	if len(hist) < drainMinSamples {
+		return 0, false
+	}
+	first, last := hist[0], hist[len(hist)-1]
+	span := last.at.Sub(first.at)
+	if span < drainMinSpan {
+		return 0, false
+	}
+	rate = (first.percent - last.percent) / span.Hours()
+	if rate < abnormalDrainPercentPerHour {
+		return rate, false
+	}
+	for _, s := range hist {
+		if s.cpu > drainBusyCPU {
+			return rate, false // A benchmark or build explains the drain.
+		}
+	}
+	return rate, true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDrainTracker(t *testing.T) {
+	start := time.Now()
+	tick := func(d *drainTracker, min int, percent, cpu float64, wake bool) BatteryStatus {
+		batts := []BatteryStatus{{Name: "BAT0", Status: "Discharging", Percent: percent}}
+		d.observe(batts, start.Add(time.Duration(min)*time.Minute), cpu, wake)
+		return batts[0]
+	}
+
+	var d drainTracker
+	tick(&d, 0, 90, 5, false)
+	if b := tick(&d, 2, 88, 5, false); b.DrainRate != 0 || b.AbnormalDrain {
+		t.Fatalf("two samples over 2 minutes should not report yet: %+v", b)
+	}
+	b := tick(&d, 4, 86, 5, false) // 4% in 4 minutes is 60%/h
+	if math.Abs(b.DrainRate-60) > 1e-9 || !b.AbnormalDrain {
+		t.Fatalf("expected an abnormal 60%%/h drain, got %+v", b)
+	}
+
+	// A busy CPU in the window explains the drain.
+	tick(&d, 6, 84, 95, false)
+	if b := tick(&d, 8, 82, 5, false); !(b.DrainRate > 0) || b.AbnormalDrain {
+		t.Fatalf("drain during heavy load must not be abnormal: %+v", b)
+	}
+
+	// Waking from sleep restarts the history.
+	if b := tick(&d, 60, 70, 5, true); b.DrainRate != 0 || b.AbnormalDrain {
+		t.Fatalf("history should restart after wake: %+v", b)
+	}
+
+	// Unknown CPU load doesn't suppress the flag.
+	var idle drainTracker
+	tick(&idle, 0, 50, -1, false)
+	tick(&idle, 2, 48, -1, false)
+	if b := tick(&idle, 4, 46, -1, false); !b.AbnormalDrain {
+		t.Fatalf("expected abnormal drain without CPU data: %+v", b)
+	}
+
+	// A normal drain is reported but not flagged.
+	var normal drainTracker
+	tick(&normal, 0, 50, 5, false)
+	tick(&normal, 3, 49.5, 5, false)
+	if b := tick(&normal, 6, 49, 5, false); math.Abs(b.DrainRate-10) > 1e-9 || b.AbnormalDrain {
+		t.Fatalf("expected a normal 10%%/h drain, got %+v", b)
+	}
+}
+
+func TestDrainTrackerResetsWhenCharging(t *testing.T) {
+	var d drainTracker
+	now := time.Now()
+	d.observe([]BatteryStatus{{Name: "BAT0", Status: "Discharging", Percent: 80}}, now, 5, false)
+	d.observe([]BatteryStatus{{Name: "BAT0", Status: "Charging", Percent: 81}}, now.Add(time.Minute), 5, false)
+	if _, ok := d.samples["BAT0"]; ok {
+		t.Fatal("charging should clear the discharge history")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// errDryRun is what every subprocess "returns" during a dry run, so collectors
+// also walk their fallback paths and those commands get listed too.
+var errDryRun = errors.New("dry run: command not executed")
+
+// plannedCommand is one subprocess invocation captured during a dry run.
+type plannedCommand struct {
+	Env  []string
+	Name string
+	Args []string
+}
+
+func (c plannedCommand) String() string {
+	return strings.Join(slices.Concat(c.Env, []string{c.Name}, c.Args), " ")
+}
+
+// dryRunRecorder stands in for cmdRunner and records commands instead of running them.
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	seen     map[string]bool
+	commands []plannedCommand
+}
+
+func newDryRunRecorder() *dryRunRecorder {
+	return &dryRunRecorder{seen: make(map[string]bool)}
+}
+
+func (r *dryRunRecorder) run(_ context.Context, env []string, name string, args ...string) (string, error) {
+	c := plannedCommand{Env: env, Name: name, Args: args}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key := c.String(); !r.seen[key] {
+		r.seen[key] = true
+		r.commands = append(r.commands, c)
+	}
+	return "", errDryRun
+}
+
+// Commands returns each distinct command, sorted for a stable report.
+func (r *dryRunRecorder) Commands() []plannedCommand {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.SortedFunc(slices.Values(r.commands), func(a, b plannedCommand) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
+
+// runDryRun performs one collection with subprocesses stubbed out and prints every
+// command Mole would have spawned on this platform with the current flags.
+// Tools that aren't installed are skipped by the collectors, as in a real run.
+func runDryRun(ctx context.Context, w io.Writer) error {
+	rec := newDryRunRecorder()
+	prev := cmdRunner
+	cmdRunner = rec.run
+	defer func() { cmdRunner = prev }()
+
+	_, _ = NewCollector().Collect(ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "# Commands mole status would run (none were executed).")
+	fmt.Fprintln(w, "# In-process reads (sysfs, IOKit, gopsutil) are not listed.")
+	for _, c := range rec.Commands() {
+		if path, err := exec.LookPath(resolveCommand(c.Name)); err == nil {
+			fmt.Fprintf(w, "%s\t# %s\n", c, path)
+		} else {
+			fmt.Fprintln(w, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDryRunRecorder(t *testing.T) {
+	rec := newDryRunRecorder()
+	prev := cmdRunner
+	cmdRunner = rec.run
+	t.Cleanup(func() { cmdRunner = prev })
+
+	ctx := context.Background()
+	if _, err := runCmd(ctx, "sysctl", "-n", "hw.ncpu"); !errors.Is(err, errDryRun) {
+		t.Fatalf("expected errDryRun, got %v", err)
+	}
+	_, _ = runCmdEnv(ctx, englishLocaleEnv, "pmset", "-g", "batt")
+	_, _ = runCmd(ctx, "sysctl", "-n", "hw.ncpu")
+
+	var got []string
+	for _, c := range rec.Commands() {
+		got = append(got, c.String())
+	}
+	want := []string{"LANG=C LC_ALL=C pmset -g batt", "sysctl -n hw.ncpu"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
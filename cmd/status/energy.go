@@ -0,0 +1,67 @@
+package main
+
+import "time"
+
+const (
+	// wakeSlack is how far the wall clock may outrun the monotonic clock before we
+	// assume the machine slept; both stop-the-world pauses and NTP slews stay well under it.
+	wakeSlack = 5 * time.Second
+	// energyMaxGap caps the interval integrated in one step, so a stalled process
+	// (SIGSTOP, debugger) isn't credited with a long stretch at a single reading.
+	energyMaxGap = time.Minute
+)
+
+// sleptBetween reports whether the machine was suspended between two time.Now readings.
+// The monotonic clock stops during sleep on macOS and Linux while the wall clock keeps going.
+func sleptBetween(prev, now time.Time) bool {
+	if prev.IsZero() {
+		return false
+	}
+	wall := now.Round(0).Sub(prev.Round(0))
+	return wall-now.Sub(prev) > wakeSlack
+}
+
+// energyMeter integrates power readings into watt-hours over a session.
+type energyMeter struct {
+	wh        float64
+	lastWatts float64
+	lastAt    time.Time
+}
+
+// add folds in one reading. Intervals that span a sleep or exceed energyMaxGap are
+// skipped, as are ticks without a reading; the next reading starts a fresh interval.
+func (e *energyMeter) add(watts float64, now time.Time, afterWake bool) {
+	if watts <= 0 {
+		e.lastAt = time.Time{}
+		return
+	}
+	if !e.lastAt.IsZero() && !afterWake {
+		if gap := now.Sub(e.lastAt); gap > 0 && gap <= energyMaxGap {
+			// Trapezoid rule between consecutive readings.
+			e.wh += (e.lastWatts + watts) / 2 * gap.Hours()
+		}
+	}
+	e.lastWatts, e.lastAt = watts, now
+}
+
+func (e *energyMeter) reset() {
+	*e = energyMeter{}
+}
+
+// sessionPowerWatts picks the reading to integrate: whole-system draw when the
+// platform reports it, otherwise CPU package power.
+func sessionPowerWatts(t ThermalStatus) float64 {
+	switch {
+	case t.SystemPower > 0:
+		return t.SystemPower
+	case t.BatteryPower > 0:
+		return t.BatteryPower
+	default:
+		return t.CPUPower
+	}
+}
+
+// ResetEnergy zeroes the EnergyWh accumulated since the collector started.
+func (c *Collector) ResetEnergy() {
+	c.energy.reset()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSleptBetween(t *testing.T) {
+	prev := time.Now()
+	if sleptBetween(prev, prev.Add(10*time.Second)) {
+		t.Fatal("monotonic and wall clocks agree; no sleep")
+	}
+	if sleptBetween(time.Time{}, prev) {
+		t.Fatal("no previous sample means no sleep")
+	}
+	// Without monotonic readings Sub falls back to wall time, so nothing is flagged.
+	if sleptBetween(prev.Round(0), prev.Round(0).Add(time.Hour)) {
+		t.Fatal("wall-only readings cannot reveal a sleep")
+	}
+}
+
+func TestEnergyMeter(t *testing.T) {
+	var e energyMeter
+	start := time.Now()
+	e.add(10, start, false)
+	e.add(20, start.Add(36*time.Second), false) // 15 W average for 0.01 h
+	if math.Abs(e.wh-0.15) > 1e-9 {
+		t.Fatalf("got %v Wh, want 0.15", e.wh)
+	}
+
+	e.add(20, start.Add(72*time.Second), true)
+	if math.Abs(e.wh-0.15) > 1e-9 {
+		t.Fatalf("interval spanning sleep was accumulated: %v Wh", e.wh)
+	}
+
+	e.add(20, start.Add(10*time.Minute), false)
+	if math.Abs(e.wh-0.15) > 1e-9 {
+		t.Fatalf("interval past energyMaxGap was accumulated: %v Wh", e.wh)
+	}
+
+	e.add(0, start.Add(11*time.Minute), false)
+	e.add(20, start.Add(11*time.Minute+30*time.Second), false)
+	if math.Abs(e.wh-0.15) > 1e-9 {
+		t.Fatalf("interval without a reading was accumulated: %v Wh", e.wh)
+	}
+
+	e.reset()
+	if e.wh != 0 || !e.lastAt.IsZero() {
+		t.Fatalf("reset left state behind: %+v", e)
+	}
+}
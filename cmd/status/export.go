@@ -0,0 +1,317 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+)
+
+// metricSample is one exported gauge value; every exporter renders the same samples.
+type metricSample struct {
+	Name   string
+	Help   string
+	Value  float64
+	Labels []metricLabel
+}
+
+type metricLabel struct {
+	Key   string
+	Value string
+}
+
+// collectSensorReadings enables the sensor probe, which the TUI skips but exporters need.
+var collectSensorReadings bool
+
+// exportHostLabels adds host and machine_id labels to every exported sample, so
+// several machines can feed one aggregator without being merged together.
+var exportHostLabels bool
+
+// hideMachineID keeps the machine identifier out of snapshots and exports.
+var hideMachineID bool
+
+// machineID returns id unless the privacy setting suppresses it. On macOS
+// gopsutil reports the IOPlatformUUID; on Linux, /etc/machine-id or the DMI product UUID.
+func machineID(id string) string {
+	if hideMachineID {
+		return ""
+	}
+	return id
+}
+
+// identityLabels are the per-host labels for m; empty values are left out.
+func identityLabels(m MetricsSnapshot) []metricLabel {
+	var labels []metricLabel
+	if m.Host != "" {
+		labels = append(labels, metricLabel{Key: "host", Value: m.Host})
+	}
+	if m.MachineID != "" {
+		labels = append(labels, metricLabel{Key: "machine_id", Value: m.MachineID})
+	}
+	return labels
+}
+
+// snapshotMetrics flattens a snapshot into exportable gauges.
+func snapshotMetrics(m MetricsSnapshot) []metricSample {
+	var samples []metricSample
+	for i, b := range m.Batteries {
+		labels := []metricLabel{{Key: "battery", Value: strconv.Itoa(i)}}
+		samples = append(samples, metricSample{
+			Name:   "battery_percent",
+			Help:   "Battery charge level in percent.",
+			Value:  b.Percent,
+			Labels: labels,
+		})
+		if c, ok := b.DisplayCapacity(); ok {
+			unitLabels := append(slices.Clone(labels), metricLabel{Key: "unit", Value: string(c.Unit)})
+			samples = append(samples, metricSample{
+				Name:   "battery_full_charge_capacity",
+				Help:   "Battery full-charge capacity, in the unit given by the unit label.",
+				Value:  c.Full,
+				Labels: unitLabels,
+			})
+			if c.Design > 0 {
+				samples = append(samples, metricSample{
+					Name:   "battery_design_capacity",
+					Help:   "Battery design capacity, in the unit given by the unit label.",
+					Value:  c.Design,
+					Labels: unitLabels,
+				})
+			}
+		}
+		if b.Cells != nil {
+			for cell, v := range b.Cells.Voltages {
+				samples = append(samples, metricSample{
+					Name:   "battery_cell_voltage_volts",
+					Help:   "Battery cell voltage in volts, where the hardware reports per-cell readings.",
+					Value:  v,
+					Labels: append(slices.Clone(labels), metricLabel{Key: "cell", Value: strconv.Itoa(cell)}),
+				})
+			}
+			samples = append(samples, metricSample{
+				Name:   "battery_cell_imbalance_millivolts",
+				Help:   "Spread between the highest and lowest battery cell voltage in millivolts.",
+				Value:  b.Cells.ImbalanceMV,
+				Labels: labels,
+			})
+		}
+		for _, r := range []struct {
+			reading string
+			amps    float64
+		}{{"average", b.AverageCurrentA}, {"instant", b.InstantCurrentA}} {
+			if r.amps == 0 {
+				continue
+			}
+			samples = append(samples, metricSample{
+				Name:   "battery_current_amperes",
+				Help:   "Battery pack current in amperes, negative while discharging; reading is the averaged or momentary value.",
+				Value:  r.amps,
+				Labels: append(slices.Clone(labels), metricLabel{Key: "reading", Value: r.reading}),
+			})
+		}
+		if b.MaxChargeCurrentA > 0 {
+			samples = append(samples, metricSample{
+				Name:   "battery_max_charge_current_amperes",
+				Help:   "Charge current cap enforced by the battery or charger, in amperes.",
+				Value:  b.MaxChargeCurrentA,
+				Labels: labels,
+			})
+		}
+		if b.CycleCount > 0 {
+			samples = append(samples, metricSample{
+				Name:   "battery_cycle_count",
+				Help:   "Battery charge cycle count.",
+				Value:  float64(b.CycleCount),
+				Labels: labels,
+			})
+		}
+		if l := b.Longevity; l != nil {
+			samples = append(samples, metricSample{
+				Name:   "battery_cycles_remaining",
+				Help:   "Charge cycles left before the model's rated cycle limit.",
+				Value:  float64(l.CyclesRemaining),
+				Labels: labels,
+			})
+			if months, ok := l.MonthsRemaining(); ok {
+				samples = append(samples, metricSample{
+					Name:   "battery_months_to_rated_cycles",
+					Help:   "Estimated months until the rated cycle limit at the recent cycles-per-month rate.",
+					Value:  months,
+					Labels: labels,
+				})
+			}
+		}
+		if in := b.Input; in != nil {
+			samples = append(samples, metricSample{
+				Name:   "ups_input_voltage_volts",
+				Help:   "Mains voltage feeding the UPS; 0 during an outage.",
+				Value:  in.VoltageV,
+				Labels: labels,
+			})
+			if in.FrequencyHz > 0 {
+				samples = append(samples, metricSample{
+					Name:   "ups_input_frequency_hertz",
+					Help:   "Mains frequency feeding the UPS.",
+					Value:  in.FrequencyHz,
+					Labels: labels,
+				})
+			}
+		}
+	}
+	if m.Thermal.CPUTemp > 0 {
+		samples = append(samples, metricSample{
+			Name:  "cpu_temperature_celsius",
+			Help:  "CPU temperature in degrees Celsius; the hottest CPU sensor.",
+			Value: m.Thermal.CPUTemp,
+		})
+	}
+	if m.Thermal.CPUTempAvg > 0 {
+		samples = append(samples, metricSample{
+			Name:  "cpu_temperature_average_celsius",
+			Help:  "Mean over every CPU temperature sensor in degrees Celsius.",
+			Value: m.Thermal.CPUTempAvg,
+		})
+	}
+	if sev := m.Thermal.Level.Severity(); sev >= 0 {
+		samples = append(samples, metricSample{
+			Name:   "thermal_level",
+			Help:   "Thermal pressure severity: 0 nominal, 1 fair, 2 serious, 3 critical.",
+			Value:  float64(sev),
+			Labels: []metricLabel{{Key: "level", Value: m.Thermal.Level.String()}},
+		})
+	}
+	if m.Thermal.EnclosureTemp > 0 {
+		samples = append(samples, metricSample{
+			Name:  "enclosure_temperature_celsius",
+			Help:  "Hottest chassis/skin temperature in degrees Celsius.",
+			Value: m.Thermal.EnclosureTemp,
+		})
+	}
+	if s := m.SystemWatts; s.Watts > 0 {
+		samples = append(samples, metricSample{
+			Name:   "system_power_watts",
+			Help:   "Whole-machine power draw in watts; estimated=\"true\" marks proxies.",
+			Value:  s.Watts,
+			Labels: []metricLabel{{Key: "source", Value: s.Source}, {Key: "estimated", Value: strconv.FormatBool(s.Estimated)}},
+		})
+	}
+	for _, sub := range []struct {
+		name  string
+		watts float64
+	}{{"gpu", m.SystemWatts.GPUWatts}, {"ane", m.SystemWatts.ANEWatts}} {
+		if sub.watts > 0 {
+			samples = append(samples, metricSample{
+				Name:   "subsystem_power_watts",
+				Help:   "Approximate Apple Silicon GPU and Neural Engine power from ioreg energy counters.",
+				Value:  sub.watts,
+				Labels: []metricLabel{{Key: "subsystem", Value: sub.name}},
+			})
+		}
+	}
+	if m.Thermal.CPUPower > 0 {
+		samples = append(samples, metricSample{
+			Name:  "cpu_package_power_watts",
+			Help:  "CPU package power draw in watts.",
+			Value: m.Thermal.CPUPower,
+		})
+	}
+	for _, g := range m.GPU {
+		if g.TempC > 0 {
+			samples = append(samples, metricSample{
+				Name:   "gpu_temperature_celsius",
+				Help:   "GPU temperature in degrees Celsius.",
+				Value:  g.TempC,
+				Labels: []metricLabel{{Key: "gpu", Value: g.Name}},
+			})
+		}
+	}
+	if p := m.Charger; p.Known {
+		onAC := 0.0
+		if p.Connected {
+			onAC = 1
+		}
+		samples = append(samples, metricSample{
+			Name:  "on_ac_power",
+			Help:  "1 while the machine runs on external power, 0 on battery.",
+			Value: onAC,
+		})
+		if p.Watts > 0 {
+			samples = append(samples, metricSample{
+				Name:  "adapter_power_watts",
+				Help:  "AC adapter power in watts: its rating on macOS, the power delivered over USB on Linux.",
+				Value: p.Watts,
+			})
+		}
+	}
+	if m.EnergyWh > 0 {
+		samples = append(samples, metricSample{
+			Name:  "session_energy_watt_hours",
+			Help:  "Energy consumed since the collector started, excluding sleep.",
+			Value: m.EnergyWh,
+		})
+	}
+	if m.Throttled {
+		samples = append(samples, metricSample{
+			Name:  "cpu_throttled_seconds",
+			Help:  "Time the CPU spent thermally throttled since the collector started, excluding sleep.",
+			Value: m.ThrottledFor.Seconds(),
+		})
+	}
+	if m.Thermal.ThrottlePercent > 0 {
+		samples = append(samples, metricSample{
+			Name:  "cpu_speed_limit_percent",
+			Help:  "Share of full CPU speed macOS allows; below 100 while throttled (Intel Macs).",
+			Value: float64(m.Thermal.ThrottlePercent),
+		})
+	}
+	if m.Thermal.FanSpeed > 0 {
+		samples = append(samples, metricSample{
+			Name:  "fan_speed_rpm",
+			Help:  "Fan speed in revolutions per minute.",
+			Value: float64(m.Thermal.FanSpeed),
+		})
+	}
+	if mode := m.Thermal.FanControlMode; mode != FanControlUnknown {
+		manual := 0.0
+		if mode == FanControlManual {
+			manual = 1
+		}
+		samples = append(samples, metricSample{
+			Name:  "fan_manual_control",
+			Help:  "1 while a tool forces the fans instead of the automatic curve (macOS).",
+			Value: manual,
+		})
+	}
+	if d := m.Display; d != nil && d.HasBrightness {
+		samples = append(samples, metricSample{
+			Name:  "display_brightness_percent",
+			Help:  "Built-in display brightness in percent; 0 while the backlight is off.",
+			Value: d.BrightnessPercent,
+		})
+	}
+	for _, s := range m.Sensors {
+		sample := metricSample{
+			Name:   "sensor_temperature_celsius",
+			Help:   "Temperature sensor reading in degrees Celsius.",
+			Value:  s.Value,
+			Labels: []metricLabel{{Key: "sensor", Value: s.StableKey()}},
+		}
+		// sensor stays the stable key for queries; label is the readable name.
+		if s.Label != "" && s.Label != s.StableKey() {
+			sample.Labels = append(sample.Labels, metricLabel{Key: "label", Value: s.Label})
+		}
+		switch s.Unit {
+		case "RPM":
+			sample.Name, sample.Help = "sensor_fan_rpm", "Fan speed from lm-sensors in RPM."
+		case "V":
+			sample.Name, sample.Help = "sensor_voltage_volts", "Voltage rail from lm-sensors in volts."
+		}
+		samples = append(samples, sample)
+	}
+	if exportHostLabels {
+		if ids := identityLabels(m); len(ids) > 0 {
+			for i := range samples {
+				samples[i].Labels = append(slices.Clone(ids), samples[i].Labels...)
+			}
+		}
+	}
+	return samples
+}
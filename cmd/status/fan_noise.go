@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FanNoise is a qualitative loudness estimate derived from fan RPM.
+type FanNoise string
+
+const (
+	FanSilent  FanNoise = "Silent"
+	FanQuiet   FanNoise = "Quiet"
+	FanAudible FanNoise = "Audible"
+	FanLoud    FanNoise = "Loud"
+)
+
+// fanNoiseBands are the upper RPM bounds for Silent, Quiet and Audible; anything
+// above the last is Loud. Defaults suit a typical laptop fan. Settable via --fan-bands.
+var fanNoiseBands = [3]int{1500, 2500, 4000}
+
+// fanMaxFractions replace the RPM bands when the machine reports its fan's maximum,
+// since a 2500 RPM desktop fan can be flat out where a laptop is barely spinning.
+var fanMaxFractions = [3]float64{0.3, 0.5, 0.75}
+
+// estimateFanNoise maps rpm to a noise level, calibrating against fanMax when known.
+func estimateFanNoise(rpm, fanMax int) FanNoise {
+	if rpm <= 0 {
+		return ""
+	}
+	bands := fanNoiseBands
+	if fanMax > 0 {
+		for i, f := range fanMaxFractions {
+			bands[i] = int(f * float64(fanMax))
+		}
+	}
+	switch {
+	case rpm <= bands[0]:
+		return FanSilent
+	case rpm <= bands[1]:
+		return FanQuiet
+	case rpm <= bands[2]:
+		return FanAudible
+	default:
+		return FanLoud
+	}
+}
+
+// parseFanBands reads three ascending RPM bounds, e.g. "1500,2500,4000".
+func parseFanBands(raw string) ([3]int, error) {
+	var bands [3]int
+	parts := strings.Split(raw, ",")
+	if len(parts) != len(bands) {
+		return bands, fmt.Errorf("want 3 comma-separated RPM bounds, got %q", raw)
+	}
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || v <= 0 {
+			return bands, fmt.Errorf("bad RPM bound %q", p)
+		}
+		if i > 0 && v <= bands[i-1] {
+			return bands, fmt.Errorf("RPM bounds must ascend: %q", raw)
+		}
+		bands[i] = v
+	}
+	return bands, nil
+}
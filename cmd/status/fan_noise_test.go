@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestEstimateFanNoise(t *testing.T) {
+	tests := []struct {
+		rpm, max int
+		want     FanNoise
+	}{
+		{0, 0, ""},
+		{1200, 0, FanSilent},
+		{2000, 0, FanQuiet},
+		{3200, 0, FanAudible},
+		{5600, 0, FanLoud},
+		// A 2400 RPM ceiling means 2000 RPM is nearly flat out.
+		{2000, 2400, FanLoud},
+		{700, 2400, FanSilent},
+	}
+	for _, tt := range tests {
+		if got := estimateFanNoise(tt.rpm, tt.max); got != tt.want {
+			t.Errorf("estimateFanNoise(%d, %d) = %q, want %q", tt.rpm, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestParseFanBands(t *testing.T) {
+	got, err := parseFanBands("1000, 2000,3000")
+	if err != nil || got != [3]int{1000, 2000, 3000} {
+		t.Fatalf("parseFanBands = %v, %v", got, err)
+	}
+	for _, bad := range []string{"1000,2000", "3000,2000,1000", "a,b,c", "0,1,2"} {
+		if _, err := parseFanBands(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
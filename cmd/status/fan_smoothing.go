@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// fanSmoothingWindow is how many samples FanSpeed is averaged over; 1 shows each
+// reading as is. Set from --fan-smoothing or SetFanSmoothing.
+var fanSmoothingWindow = 1
+
+// SetFanSmoothing averages the displayed fan speed over the last n samples;
+// n = 1 disables smoothing.
+func SetFanSmoothing(n int) error {
+	if n < 1 {
+		return fmt.Errorf("fan smoothing window must be at least 1, got %d", n)
+	}
+	fanSmoothingWindow = n
+	return nil
+}
+
+// fanSmoother is a moving average over the last few fan readings, so a fan
+// hunting around its target doesn't make the shown RPM jump every tick.
+type fanSmoother struct {
+	samples []int
+	mode    FanControl
+}
+
+// observe keeps the reading in t.FanSpeedRaw and replaces t.FanSpeed with the
+// average over fanSmoothingWindow samples. The average restarts when the fan
+// control mode changes, since a forced speed has nothing to do with the curve
+// before it, and when no fan was read.
+func (s *fanSmoother) observe(t *ThermalStatus) {
+	t.FanSpeedRaw = t.FanSpeed
+	if t.FanControlMode != s.mode {
+		if s.mode != FanControlUnknown && t.FanControlMode != FanControlUnknown {
+			s.samples = s.samples[:0]
+		}
+		s.mode = t.FanControlMode
+	}
+	if t.FanSpeed <= 0 || fanSmoothingWindow <= 1 {
+		s.samples = s.samples[:0]
+		return
+	}
+	s.samples = append(s.samples, t.FanSpeed)
+	if extra := len(s.samples) - fanSmoothingWindow; extra > 0 {
+		s.samples = append(s.samples[:0], s.samples[extra:]...)
+	}
+	var sum int
+	for _, rpm := range s.samples {
+		sum += rpm
+	}
+	t.FanSpeed = int(math.Round(float64(sum) / float64(len(s.samples))))
+}
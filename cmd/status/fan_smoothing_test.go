@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestFanSmootherAverages(t *testing.T) {
+	prev := fanSmoothingWindow
+	t.Cleanup(func() { fanSmoothingWindow = prev })
+	if err := SetFanSmoothing(3); err != nil {
+		t.Fatal(err)
+	}
+
+	var s fanSmoother
+	var got []int
+	for _, rpm := range []int{1200, 1800, 1500, 2400} {
+		th := ThermalStatus{FanSpeed: rpm}
+		s.observe(&th)
+		if th.FanSpeedRaw != rpm {
+			t.Fatalf("raw = %d, want %d", th.FanSpeedRaw, rpm)
+		}
+		got = append(got, th.FanSpeed)
+	}
+	want := []int{1200, 1500, 1500, 1900}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("smoothed = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFanSmootherRestartsOnModeChange(t *testing.T) {
+	prev := fanSmoothingWindow
+	t.Cleanup(func() { fanSmoothingWindow = prev })
+	fanSmoothingWindow = 5
+
+	var s fanSmoother
+	for _, rpm := range []int{1200, 1300} {
+		th := ThermalStatus{FanSpeed: rpm, FanControlMode: FanControlAuto}
+		s.observe(&th)
+	}
+	th := ThermalStatus{FanSpeed: 5000, FanControlMode: FanControlManual}
+	s.observe(&th)
+	if th.FanSpeed != 5000 {
+		t.Fatalf("smoothed = %d after switching to manual, want the new reading", th.FanSpeed)
+	}
+}
+
+func TestFanSmootherDisabled(t *testing.T) {
+	prev := fanSmoothingWindow
+	t.Cleanup(func() { fanSmoothingWindow = prev })
+	if err := SetFanSmoothing(0); err == nil {
+		t.Fatal("expected a zero window to be rejected")
+	}
+	if err := SetFanSmoothing(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var s fanSmoother
+	for _, rpm := range []int{1200, 2400} {
+		th := ThermalStatus{FanSpeed: rpm}
+		s.observe(&th)
+		if th.FanSpeed != rpm || th.FanSpeedRaw != rpm {
+			t.Fatalf("got %d/%d, want %d unsmoothed", th.FanSpeed, th.FanSpeedRaw, rpm)
+		}
+	}
+}
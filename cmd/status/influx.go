@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxMeasurement is the measurement every point is written to.
+const influxMeasurement = "mole"
+
+// influxMaxPending bounds the lines kept for retry while the endpoint is down, so
+// a long outage drops the oldest points instead of growing without limit.
+const influxMaxPending = 50000
+
+// influxWriteTimeout bounds one HTTP write.
+const influxWriteTimeout = 10 * time.Second
+
+// InfluxConfig configures the InfluxDB line protocol sink. Exactly one of URL and
+// File is set.
+type InfluxConfig struct {
+	URL      string        // Server root, e.g. http://localhost:8086
+	File     string        // Append lines here instead; "-" is stdout
+	Bucket   string        // InfluxDB 2.x bucket; selects the /api/v2/write endpoint
+	Org      string        // InfluxDB 2.x organization
+	Database string        // InfluxDB 1.x database; used when Bucket is empty
+	Token    string        // Sent as "Authorization: Token"; read from INFLUX_TOKEN
+	Batch    int           // Snapshots buffered per write; 1 writes every interval
+	Interval time.Duration // Collection interval
+}
+
+// formatInflux renders samples as line protocol. Samples sharing a label set
+// become fields of one point, tagged with those labels and the host, e.g.
+//
+//	mole,battery=0,host=mbp battery_percent=72,battery_health_percent=91 1760000000000000000
+func formatInflux(samples []metricSample, host string, at time.Time) []string {
+	type point struct {
+		tags   string
+		fields []string
+	}
+	var points []*point
+	byTags := make(map[string]*point)
+	for _, s := range samples {
+		labels := slices.Clone(s.Labels)
+		if host != "" && !slices.ContainsFunc(labels, func(l metricLabel) bool { return l.Key == "host" }) {
+			labels = append(labels, metricLabel{Key: "host", Value: host})
+		}
+		slices.SortFunc(labels, func(a, b metricLabel) int { return strings.Compare(a.Key, b.Key) })
+		var tags strings.Builder
+		for _, l := range labels {
+			if l.Value == "" {
+				continue // Line protocol has no empty tag values
+			}
+			tags.WriteString("," + escapeInfluxTag(l.Key) + "=" + escapeInfluxTag(l.Value))
+		}
+		p := byTags[tags.String()]
+		if p == nil {
+			p = &point{tags: tags.String()}
+			byTags[p.tags] = p
+			points = append(points, p)
+		}
+		p.fields = append(p.fields, escapeInfluxTag(s.Name)+"="+strconv.FormatFloat(s.Value, 'f', -1, 64))
+	}
+	ts := strconv.FormatInt(at.UnixNano(), 10)
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		lines = append(lines, influxMeasurement+p.tags+" "+strings.Join(p.fields, ",")+" "+ts)
+	}
+	return lines
+}
+
+// escapeInfluxTag escapes the characters line protocol treats as separators in
+// tag keys, tag values and field keys.
+func escapeInfluxTag(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`).Replace(s)
+}
+
+// writeURL is the endpoint for cfg: the 2.x API when a bucket is set, else 1.x.
+func (cfg InfluxConfig) writeURL() (string, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return "", fmt.Errorf("invalid InfluxDB URL %q", cfg.URL)
+	}
+	q := url.Values{"precision": {"ns"}}
+	switch {
+	case cfg.Bucket != "":
+		base.Path += "/api/v2/write"
+		q.Set("bucket", cfg.Bucket)
+		if cfg.Org != "" {
+			q.Set("org", cfg.Org)
+		}
+	case cfg.Database != "":
+		base.Path += "/write"
+		q.Set("db", cfg.Database)
+	default:
+		return "", fmt.Errorf("InfluxDB needs a bucket or a database")
+	}
+	base.RawQuery = q.Encode()
+	return base.String(), nil
+}
+
+// influxHTTPWriter posts batches of lines to an InfluxDB write endpoint.
+type influxHTTPWriter struct {
+	client   *http.Client
+	endpoint string
+	token    string
+}
+
+func (w influxHTTPWriter) write(ctx context.Context, lines []string) error {
+	ctx, cancel := context.WithTimeout(ctx, influxWriteTimeout)
+	defer cancel()
+	body := strings.Join(lines, "\n") + "\n"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx write: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// influxFileWriter appends lines to a file or stdout.
+type influxFileWriter struct{ w io.Writer }
+
+func (w influxFileWriter) write(_ context.Context, lines []string) error {
+	_, err := io.WriteString(w.w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// influxBatcher buffers lines until a batch is full and keeps failed batches for
+// the next flush, up to influxMaxPending lines.
+type influxBatcher struct {
+	write     func(context.Context, []string) error
+	batch     int
+	pending   []string
+	snapshots int
+}
+
+// add queues one snapshot's lines and flushes once batch snapshots are queued.
+func (b *influxBatcher) add(ctx context.Context, lines []string) error {
+	b.pending = append(b.pending, lines...)
+	b.snapshots++
+	if b.snapshots < max(b.batch, 1) {
+		return nil
+	}
+	return b.flush(ctx)
+}
+
+func (b *influxBatcher) flush(ctx context.Context) error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+	if err := b.write(ctx, b.pending); err != nil {
+		if extra := len(b.pending) - influxMaxPending; extra > 0 {
+			b.pending = b.pending[extra:]
+		}
+		return err
+	}
+	b.pending = b.pending[:0]
+	b.snapshots = 0
+	return nil
+}
+
+// runInflux collects on every interval and writes points until ctx is cancelled.
+// Write errors are reported on stderr and retried with the next batch rather than
+// stopping the loop; only an unusable configuration is returned.
+func runInflux(ctx context.Context, cfg InfluxConfig) error {
+	var write func(context.Context, []string) error
+	switch {
+	case cfg.File == "-":
+		write = influxFileWriter{w: os.Stdout}.write
+	case cfg.File != "":
+		f, err := os.OpenFile(cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		write = influxFileWriter{w: f}.write
+	default:
+		endpoint, err := cfg.writeURL()
+		if err != nil {
+			return err
+		}
+		write = influxHTTPWriter{client: &http.Client{}, endpoint: endpoint, token: cfg.Token}.write
+	}
+	batcher := &influxBatcher{write: write, batch: cfg.Batch}
+
+	collectSensorReadings = true
+	collector := NewCollector()
+	collector.Prime(ctx, primeInterval)
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Partial failures still yield useful points.
+		data, _ := collector.Collect(ctx)
+		if err := batcher.add(ctx, formatInflux(snapshotMetrics(data), data.Host, data.CollectedAt)); err != nil {
+			fmt.Fprintf(os.Stderr, "influx error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			// Flush what is buffered; ctx is already cancelled, so use a fresh one.
+			flushCtx, cancel := context.WithTimeout(context.Background(), influxWriteTimeout)
+			defer cancel()
+			if err := batcher.flush(flushCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "influx error: %v\n", err)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
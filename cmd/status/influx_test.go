@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatInfluxGroupsByTags(t *testing.T) {
+	at := time.Unix(1760000000, 0)
+	samples := []metricSample{
+		{Name: "battery_percent", Value: 72, Labels: []metricLabel{{Key: "battery", Value: "0"}}},
+		{Name: "cpu_usage_percent", Value: 12.5},
+		{Name: "battery_health_percent", Value: 91, Labels: []metricLabel{{Key: "battery", Value: "0"}}},
+		{Name: "sensor_celsius", Value: 48, Labels: []metricLabel{{Key: "sensor", Value: "CPU Die, core 0"}}},
+	}
+	got := formatInflux(samples, "mbp", at)
+	want := []string{
+		"mole,battery=0,host=mbp battery_percent=72,battery_health_percent=91 1760000000000000000",
+		"mole,host=mbp cpu_usage_percent=12.5 1760000000000000000",
+		`mole,host=mbp,sensor=CPU\ Die\,\ core\ 0 sensor_celsius=48 1760000000000000000`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInfluxWriteURL(t *testing.T) {
+	v2, err := InfluxConfig{URL: "http://localhost:8086/", Bucket: "mole", Org: "home"}.writeURL()
+	if err != nil || v2 != "http://localhost:8086/api/v2/write?bucket=mole&org=home&precision=ns" {
+		t.Fatalf("v2 = %q, %v", v2, err)
+	}
+	v1, err := InfluxConfig{URL: "http://influx:8086", Database: "telegraf"}.writeURL()
+	if err != nil || v1 != "http://influx:8086/write?db=telegraf&precision=ns" {
+		t.Fatalf("v1 = %q, %v", v1, err)
+	}
+	if _, err := (InfluxConfig{URL: "http://localhost:8086"}).writeURL(); err == nil {
+		t.Fatal("expected an error without a bucket or database")
+	}
+	if _, err := (InfluxConfig{URL: "localhost", Bucket: "mole"}).writeURL(); err == nil {
+		t.Fatal("expected an error for a URL without a scheme")
+	}
+}
+
+func TestInfluxHTTPWriter(t *testing.T) {
+	var gotAuth, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		if strings.Contains(gotBody, "bad") {
+			http.Error(w, `{"code":"invalid","message":"unable to parse"}`, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w := influxHTTPWriter{client: srv.Client(), endpoint: srv.URL + "/api/v2/write?bucket=mole", token: "secret"}
+	if err := w.write(context.Background(), []string{"mole a=1 1", "mole a=2 2"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Token secret" || gotBody != "mole a=1 1\nmole a=2 2\n" {
+		t.Fatalf("auth %q body %q", gotAuth, gotBody)
+	}
+	err := w.write(context.Background(), []string{"bad"})
+	if err == nil || !strings.Contains(err.Error(), "unable to parse") {
+		t.Fatalf("err = %v, want the server's message", err)
+	}
+}
+
+func TestInfluxBatcherRetriesFailedBatch(t *testing.T) {
+	var writes [][]string
+	fail := true
+	b := &influxBatcher{batch: 2, write: func(_ context.Context, lines []string) error {
+		if fail {
+			return errors.New("connection refused")
+		}
+		writes = append(writes, slices.Clone(lines))
+		return nil
+	}}
+	ctx := context.Background()
+	if err := b.add(ctx, []string{"a"}); err != nil {
+		t.Fatalf("first snapshot should only be buffered: %v", err)
+	}
+	if err := b.add(ctx, []string{"b"}); err == nil {
+		t.Fatal("expected the failed write to be reported")
+	}
+	fail = false
+	if err := b.add(ctx, []string{"c"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(writes) != 1 || !slices.Equal(writes[0], []string{"a", "b", "c"}) {
+		t.Fatalf("writes = %v, want the kept batch plus the new snapshot", writes)
+	}
+	if err := b.add(ctx, []string{"d"}); err != nil || len(writes) != 1 {
+		t.Fatalf("batch restarted after a good write: err %v, writes %v", err, writes)
+	}
+}
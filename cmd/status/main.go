@@ -2,10 +2,16 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -37,7 +43,12 @@ type model struct {
 	lastUpdated time.Time
 	collecting  bool
 	animFrame   int
-	catHidden   bool // true = hidden, false = visible
+	catHidden   bool              // true = hidden, false = visible
+	alerts      *alertDispatcher  // nil unless --notify is set
+	adaptive    *adaptiveInterval // nil unless --adaptive is set
+	cards       *cardCache        // Redraws only changed cards; nil draws every card
+	calibration *calibration      // Ranges being learned; nil unless --calibrate is set
+	ctx         context.Context   // Cancelled on shutdown to abort in-flight probes
 }
 
 // getConfigPath returns the path to the status preferences file.
@@ -80,10 +91,14 @@ func saveCatHidden(hidden bool) {
 	_ = os.WriteFile(path, []byte(value+"\n"), 0644)
 }
 
-func newModel() model {
+func newModel(ctx context.Context) model {
+	collector := NewCollector()
+	collector.SetBatteryHistory(loadBatteryHistory(batteryHistoryPath()))
 	return model{
-		collector: NewCollector(),
+		ctx:       ctx,
+		collector: collector,
 		catHidden: loadCatHidden(),
+		cards:     &cardCache{},
 	}
 }
 
@@ -121,11 +136,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.metrics = msg.data
 		m.lastUpdated = msg.data.CollectedAt
+		if m.calibration != nil && msg.err == nil {
+			m.calibration.observe(msg.data.Thermal, msg.data.CollectedAt)
+		}
 		m.collecting = false
 		// Mark ready after first successful data collection.
 		if !m.ready {
 			m.ready = true
 		}
+		if m.adaptive != nil {
+			return m, tickAfter(m.adaptive.Next(msg.data))
+		}
 		return m, tickAfter(refreshInterval)
 	case animTickMsg:
 		m.animFrame++
@@ -140,11 +161,19 @@ func (m model) View() string {
 	}
 
 	header := renderHeader(m.metrics, m.errMessage, m.animFrame, m.width, m.catHidden)
+	if warning := renderCriticalWarning(m.metrics.Thermal, m.width); warning != "" {
+		header = warning + "\n" + header
+	}
 	cardWidth := 0
 	if m.width > 80 {
 		cardWidth = max(24, m.width/2-4)
 	}
-	cards := buildCards(m.metrics, cardWidth)
+	var cards []cardData
+	if m.cards != nil {
+		cards = m.cards.build(m.metrics, cardWidth)
+	} else {
+		cards = buildCards(m.metrics, cardWidth)
+	}
 
 	if m.width <= 80 {
 		var rendered []string
@@ -172,7 +201,11 @@ func (m model) View() string {
 
 func (m model) collectCmd() tea.Cmd {
 	return func() tea.Msg {
-		data, err := m.collector.Collect()
+		m.collector.Prime(m.ctx, primeInterval)
+		data, err := m.collector.Collect(m.ctx)
+		if m.alerts != nil {
+			m.alerts.Observe(data)
+		}
 		return metricsMsg{data: data, err: err}
 	}
 }
@@ -192,9 +225,411 @@ func animTickWithSpeed(cpuUsage float64) tea.Cmd {
 }
 
 func main() {
-	p := tea.NewProgram(newModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	promptMode := flag.Bool("prompt", false, "print a one-line status for shell prompts or tmux and exit")
+	promptSegments := flag.String("prompt-segments", "battery,temp", "comma-separated prompt segments: battery, temp, cpu, mem, sensors")
+	tableMode := flag.Bool("table", false, "print batteries, thermal state and sensors as aligned tables and exit")
+	jsonMode := flag.Bool("json", false, "print batteries, thermal state and sensors as JSON and exit")
+	csvPath := flag.String("csv", "", "append one CSV row of battery percent, CPU temperature and sensors (one column per reading, unit in the header) to this file and exit; a new file gets a header")
+	watchMode := flag.Bool("watch", false, "print a line with battery and CPU temperature sparklines every --interval instead of showing the UI")
+	watchInterval := flag.Duration("interval", 2*time.Second, "collection interval for --watch")
+	flag.Func("sensor-unit", "declare the unit a temperature source reports, as source=C|F; source is "+strings.Join(sensorSources, ", ")+" (repeatable)", setSensorSourceUnit)
+	tempUnitSet := false
+	flag.Func("temp-unit", "temperature unit for the UI, --table and --prompt: C or F (default C, or the unit saved in a --replay file); exports and --json stay in Celsius", func(v string) (err error) {
+		displayTempUnit, err = parseTempUnit(v)
+		tempUnitSet = true
+		return err
+	})
+	promptGlyphs := flag.String("prompt-glyphs", string(GlyphEmoji), "prompt glyph style: emoji, nerd, ascii")
+	flag.Func("battery-sources", "comma-separated battery sources to try in order, first with data wins ("+batterySourceNames()+"; default \""+strings.Join(defaultBatteryChain, ",")+"\")", setBatteryChain)
+	flag.StringVar(&primaryBatteryName, "primary-battery", "", "battery name shown in summaries, e.g. InternalBattery-0 or BAT1 (default: internal)")
+	flag.Float64Var(&criticalTempCelsius, "critical-temp", criticalTempCelsius, "temperature in °C that raises the sustained critical warning")
+	flag.IntVar(&criticalTempSamples, "critical-samples", criticalTempSamples, "consecutive samples at or above --critical-temp before the warning shows")
+	flag.Float64Var(&wornThresholdPercent, "worn-threshold", wornThresholdPercent, "battery health percent below which the battery is flagged as worn")
+	flag.Func("capacity-unit", "unit for battery capacities in the UI and exports: auto (as reported), mAh, Wh", setCapacityDisplayUnit)
+	flag.BoolVar(&disableTempFallback, "disable-temp-fallback", false, "never show battery temperature or thermal-level estimates as CPU temperature")
+	flag.BoolVar(&estimateFromInstantCurrent, "instant-current", false, "base time-to-empty and discharge watts on the momentary battery current instead of the averaged one (macOS)")
+	flag.BoolVar(&collectPowermetrics, "powermetrics", false, "sample whole-SoC power with powermetrics on macOS (needs root)")
+	flag.BoolVar(&collectSiliconPowerCounters, "silicon-power", false, "estimate GPU and Neural Engine watts from ioreg energy counters on Apple Silicon (experimental)")
+	flag.BoolVar(&collectPowerAssertions, "assertions", false, "list the power assertions keeping macOS awake, with their owning process")
+	flag.BoolVar(&collectEnergyImpact, "energy-impact", false, "list the processes with the highest energy impact, as Activity Monitor scores it (macOS)")
+	flag.IntVar(&energyImpactTopN, "energy-top", energyImpactTopN, "how many processes --energy-impact lists")
+	flag.Func("rated-cycles", "battery cycle rating for the cycles-left estimate: N for this machine, or MODEL=N to extend the model lookup (repeatable)", setRatedCycles)
+	flag.BoolVar(&collectDisplayInfo, "display-info", false, "report display and keyboard backlight levels as context for battery drain")
+	flag.BoolVar(&collectChargerInfo, "charger-info", false, "query the connected charger's negotiated USB-C PD profile (macOS)")
+	statsdAddr := flag.String("statsd-addr", "", "push gauges to a StatsD agent at host:port instead of showing the UI")
+	statsdPrefix := flag.String("statsd-prefix", "mole", "StatsD metric name prefix")
+	statsdTags := flag.String("statsd-tags", "", "comma-separated constant DogStatsD tags, e.g. env:prod,team:infra")
+	statsdDog := flag.Bool("dogstatsd", false, "emit DogStatsD tags instead of folding labels into metric names")
+	influxURL := flag.String("influx-url", "", "write InfluxDB line protocol to this server, e.g. http://localhost:8086, instead of showing the UI (token from INFLUX_TOKEN)")
+	influxFile := flag.String("influx-file", "", "append InfluxDB line protocol to this file (\"-\" for stdout) instead of showing the UI")
+	influxBucket := flag.String("influx-bucket", "", "InfluxDB 2.x bucket for --influx-url")
+	influxOrg := flag.String("influx-org", "", "InfluxDB 2.x organization for --influx-url")
+	influxDB := flag.String("influx-db", "", "InfluxDB 1.x database for --influx-url, used when no bucket is set")
+	influxBatch := flag.Int("influx-batch", 1, "snapshots to buffer per InfluxDB write")
+	influxInterval := flag.Duration("influx-interval", 10*time.Second, "InfluxDB collection interval")
+	flag.BoolVar(&exportHostLabels, "host-labels", false, "label exported metrics with host and machine_id for multi-host aggregation")
+	flag.BoolVar(&hideMachineID, "no-machine-id", false, "never include the machine identifier in snapshots or exports")
+	statsdInterval := flag.Duration("statsd-interval", 10*time.Second, "StatsD flush interval")
+	flag.IntVar(&sensorDisplayDecimals, "sensor-decimals", 1, "decimal places for displayed sensor values (exports keep full precision)")
+	flag.BoolVar(&sensorSummaryOnly, "sensor-summary", false, "in --table, show one row per sensor class (count, min, max, avg) instead of every sensor")
+	flag.BoolVar(&sensorLocations, "sensor-locations", false, "tag sensors with a location guessed from their labels (CPU package, DIMM, PCH, front/rear ambient) and summarize by location in --table")
+	flag.Func("sensors-include", "comma-separated sensor labels to show, as substrings or globs (case-insensitive); empty shows all", func(v string) (err error) {
+		sensorFilter.Include, err = parseGlobList(v)
+		return err
+	})
+	flag.Func("sensors-exclude", "comma-separated sensor labels to hide, as substrings or globs (case-insensitive)", func(v string) (err error) {
+		sensorFilter.Exclude, err = parseGlobList(v)
+		return err
+	})
+	flag.Func("ignore-net", "comma-separated interface globs to hide (default \""+strings.Join(ignoreNetDevices, ",")+"\"; empty shows all)", func(v string) (err error) {
+		ignoreNetDevices, err = parseGlobList(v)
+		return err
+	})
+	flag.Func("ignore-disk", "comma-separated disk device globs to hide (default \""+strings.Join(ignoreDiskDevices, ",")+"\"; empty shows all)", func(v string) (err error) {
+		ignoreDiskDevices, err = parseGlobList(v)
+		return err
+	})
+	flag.Func("temp-samples", "average each temperature over this many reads spread across --temp-window (default 1, a single read)", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		if n < 1 {
+			return fmt.Errorf("must be at least 1, got %d", n)
+		}
+		tempSamples = n
+		return nil
+	})
+	flag.Func("sensor-cache-ttl", "reuse hardware temperature readings for this long (default 0, read every refresh)", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		sensorTemps.SetTTL(d)
+		return nil
+	})
+	flag.Func("command-timeout", "time limit for ioreg and sysctl queries on macOS; raise it on slow machines that lose the battery temperature (default 500ms)", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		return SetCollectOptions(CollectOptions{CommandTimeout: d, SystemProfilerTimeout: collectOptions.SystemProfilerTimeout})
+	})
+	flag.Func("profiler-timeout", "time limit for each system_profiler run on macOS (default 3s for power data, 4s for GPU and Bluetooth)", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		return SetCollectOptions(CollectOptions{CommandTimeout: collectOptions.CommandTimeout, SystemProfilerTimeout: d})
+	})
+	flag.DurationVar(&tempSampleWindow, "temp-window", tempSampleWindow, "time span that --temp-samples reads are spread over")
+	flag.DurationVar(&primeInterval, "prime-interval", primeInterval, "baseline sample gap so the first screen shows real network/disk rates (0 disables)")
+	flag.Func("history-samples", fmt.Sprintf("most samples each in-memory history keeps; bounds sparkline width and trend windows (default %d)", defaultHistorySamples), func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		return SetHistoryRetention(n, historyRetention.MaxAge)
+	})
+	flag.Func("history-age", "drop history samples older than this, e.g. 10m (default no age limit)", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		return SetHistoryRetention(historyRetention.Samples, d)
+	})
+	flag.BoolVar(&adaptiveRefresh, "adaptive", false, "refresh quickly while readings change and back off towards --adaptive-max while they hold steady")
+	flag.DurationVar(&adaptiveMin, "adaptive-min", adaptiveMin, "fastest refresh interval for --adaptive")
+	flag.DurationVar(&adaptiveMax, "adaptive-max", adaptiveMax, "slowest refresh interval for --adaptive")
+	flag.Float64Var(&adaptiveSensitivity, "adaptive-sensitivity", adaptiveSensitivity, "how small a change counts as activity for --adaptive; 2 reacts to half the default change")
+	notifyKind := flag.String("notify", string(NotifierNone), "desktop notifications when an alert starts firing: none, auto, macos, linux")
+	flag.Func("battery-low", fmt.Sprintf("battery percent below which a discharging battery raises the battery-low alert, notified with --notify; 0 turns it off (default %g)", alertBatteryLowPercent), setBatteryLowAlert)
+	flag.Func("alert", "alert rule levels as rule=trigger[:clear], e.g. thermal-critical=90:85 (repeatable)", setAlertThreshold)
+	flag.Func("fan-bands", "upper RPM bounds for Silent,Quiet,Audible fan noise (default \"1500,2500,4000\")", func(v string) (err error) {
+		fanNoiseBands, err = parseFanBands(v)
+		return err
+	})
+	flag.Func("fan-smoothing", "average the displayed fan speed over this many samples (default 1, no smoothing)", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		return SetFanSmoothing(n)
+	})
+	flag.BoolVar(&debugLogging, "debug", false, "log internal diagnostics, such as state files that couldn't be written, to stderr")
+	flag.BoolVar(&calibrating, "calibrate", false, "learn this machine's fan RPM and CPU temperature range during the session and use it on later runs")
+	resetCalib := flag.Bool("reset-calibration", false, "forget the learned fan and temperature ranges and exit")
+	flag.BoolVar(&useProfilerXML, "profiler-xml", false, "parse system_profiler's XML power report instead of its localized text (macOS)")
+	backgroundRefresh := flag.Bool("background-refresh", false, "re-fetch system_profiler data in the background before it expires (macOS)")
+	flag.Func("disable", "comma-separated collectors to skip entirely ("+collectorKindNames()+")", func(v string) error {
+		kinds, err := parseCollectorList(v)
+		for _, kind := range kinds {
+			disabledCollectors[kind] = true
+		}
+		return err
+	})
+	flag.Func("only", "comma-separated collectors to run; every other collector is skipped", func(v string) error {
+		kinds, err := parseCollectorList(v)
+		if err == nil {
+			disabledCollectors = onlyCollectors(kinds)
+		}
+		return err
+	})
+	flag.Func("command-path", "use a specific binary for an external command, as name=/absolute/path (repeatable)", setCommandPath)
+	flag.Func("max-procs", fmt.Sprintf("maximum external commands running at once (default %d)", defaultCommandConcurrency), func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		return cmdSlots.resize(n)
+	})
+	flag.IntVar(&streamBuffer, "stream-buffer", streamBuffer, "snapshots queued for slow --ws-addr, --unix-socket and --http-addr consumers before --stream-drop applies")
+	flag.Func("stream-drop", "what a full stream queue drops: oldest (consumers catch up to current readings) or newest (skip the tick) (default oldest)", func(v string) (err error) {
+		streamDrop, err = parseStreamDrop(v)
+		return err
+	})
+	wsAddr := flag.String("ws-addr", "", "serve live JSON snapshots over WebSocket at ws://host:port/ws instead of showing the UI; a bare :port listens on 127.0.0.1 only")
+	metricsAddr := flag.String("metrics-addr", "", "serve Prometheus metrics at http://host:port/metrics, collected on each scrape, instead of showing the UI")
+	pageAddr := flag.String("http-addr", "", "serve an auto-refreshing HTML status page at http://host:port/ instead of showing the UI")
+	pageRefresh := flag.Duration("http-refresh", 5*time.Second, "how often the --http-addr page reloads itself")
+	socketPath := flag.String("unix-socket", "", "stream newline-delimited JSON snapshots to clients of a Unix socket at this path instead of showing the UI")
+	nagiosMode := flag.Bool("nagios", false, "run as a Nagios/Icinga plugin: print one result line with perfdata and exit 0/1/2/3")
+	flag.Func("nagios-battery", "battery warn,crit percent for --nagios (default \"20,10\")", func(v string) (err error) {
+		nagiosThresholds.BatteryWarn, nagiosThresholds.BatteryCrit, err = parseNagiosPair(v)
+		return err
+	})
+	flag.Func("nagios-temp", "CPU temperature warn,crit °C for --nagios (default \"75,85\")", func(v string) (err error) {
+		nagiosThresholds.TempWarn, nagiosThresholds.TempCrit, err = parseNagiosPair(v)
+		return err
+	})
+	flag.Func("remote", "comma-separated ssh hosts to probe for temperatures and battery instead of this machine, e.g. mini.local,admin@build-01; prints one line per host and exits", setRemoteHosts)
+	flag.DurationVar(&remoteTimeout, "remote-timeout", remoteTimeout, "per-host time limit for --remote, connection included")
+	dryRun := flag.Bool("dry-run", false, "list every external command a collection would run, without running any, and exit")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, defaults included, as JSON and exit")
+	savePath := flag.String("save-snapshot", "", "collect once, write the snapshot as JSON to this file for a bug report or demo, and exit")
+	replayPath := flag.String("replay", "", "run the UI or any output mode against a snapshot saved with --save-snapshot instead of collecting")
+	selfTest := flag.Bool("selftest", false, "run every probe once, report results, and exit nonzero if a required source is broken")
+	flag.Parse()
+
+	// One context for the whole run: signals or quitting the UI cancel in-flight probes.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *resetCalib {
+		if err := resetCalibration(calibrationPath()); err != nil {
+			fmt.Fprintf(os.Stderr, "calibration error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	learned := loadCalibration(calibrationPath())
+	applyCalibration(learned)
+
+	if *printConfig {
+		if err := writeEffectiveConfig(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *replayPath != "" {
+		snap, unit, err := LoadSnapshot(*replayPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay error: %v\n", err)
+			os.Exit(1)
+		}
+		replaySnapshot = &snap
+		if unit != "" && !tempUnitSet {
+			displayTempUnit = unit
+		}
+	}
+
+	if *savePath != "" {
+		if err := runSaveSnapshot(ctx, *savePath); err != nil {
+			fmt.Fprintf(os.Stderr, "save snapshot error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *dryRun {
+		if err := runDryRun(ctx, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "dry run error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(remoteHosts) > 0 {
+		if !runRemote(ctx, os.Stdout, remoteHosts, max(remoteTimeout, time.Second)) {
+			stop()
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *selfTest {
+		if !runSelfTest(ctx, os.Stdout, selfTestProbes()) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *backgroundRefresh && runtime.GOOS == "darwin" {
+		if useProfilerXML {
+			profilerCache.track(spPowerDataTypeXML, powerCacheTTL)
+		}
+		profilerCache.Start(ctx)
+		defer profilerCache.Stop()
+	}
+
+	if *wsAddr != "" {
+		if err := runWebSocket(ctx, *wsAddr, refreshInterval); err != nil {
+			fmt.Fprintf(os.Stderr, "websocket error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *pageAddr != "" {
+		if err := runStatusPage(ctx, *pageAddr, refreshInterval, *pageRefresh); err != nil {
+			fmt.Fprintf(os.Stderr, "status page error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *metricsAddr != "" {
+		if err := runPrometheus(ctx, *metricsAddr); err != nil {
+			fmt.Fprintf(os.Stderr, "metrics error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *socketPath != "" {
+		if err := runUnixSocket(ctx, *socketPath, refreshInterval); err != nil {
+			fmt.Fprintf(os.Stderr, "unix socket error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *statsdAddr != "" {
+		err := runStatsD(ctx, StatsDConfig{
+			Addr:      *statsdAddr,
+			Prefix:    *statsdPrefix,
+			Tags:      parseStatsDTags(*statsdTags),
+			DogStatsD: *statsdDog,
+			Interval:  max(*statsdInterval, time.Second),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "statsd error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *influxURL != "" || *influxFile != "" {
+		if *influxURL != "" && *influxFile != "" {
+			fmt.Fprintln(os.Stderr, "influx error: --influx-url and --influx-file are mutually exclusive")
+			os.Exit(2)
+		}
+		err := runInflux(ctx, InfluxConfig{
+			URL:      *influxURL,
+			File:     *influxFile,
+			Bucket:   *influxBucket,
+			Org:      *influxOrg,
+			Database: *influxDB,
+			Token:    os.Getenv("INFLUX_TOKEN"),
+			Batch:    max(*influxBatch, 1),
+			Interval: max(*influxInterval, time.Second),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "influx error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *nagiosMode {
+		code := runNagios(ctx, os.Stdout)
+		stop()
+		os.Exit(code)
+	}
+
+	if *tableMode {
+		runTable(ctx, os.Stdout)
+		return
+	}
+
+	if *jsonMode {
+		if err := runJSON(ctx, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "json error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *csvPath != "" {
+		if err := runCSV(ctx, *csvPath); err != nil {
+			fmt.Fprintf(os.Stderr, "csv error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *watchMode {
+		if err := runWatch(ctx, os.Stdout, max(*watchInterval, time.Second)); err != nil {
+			fmt.Fprintf(os.Stderr, "watch error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *promptMode {
+		if err := runPrompt(ctx, *promptSegments, *promptGlyphs); err != nil {
+			fmt.Fprintf(os.Stderr, "prompt error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	m := newModel(ctx)
+	if NotifierKind(*notifyKind) != NotifierNone {
+		notifier, err := newNotifier(NotifierKind(*notifyKind))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "notify error: %v\n", err)
+			os.Exit(1)
+		}
+		m.alerts = newAlertDispatcher(notifier)
+	}
+	if adaptiveRefresh {
+		adaptive, err := newAdaptiveInterval(adaptiveMin, adaptiveMax, adaptiveSensitivity)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "adaptive error: %v\n", err)
+			os.Exit(1)
+		}
+		m.adaptive = adaptive
+	}
+
+	if calibrating {
+		m.calibration = &learned
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "system status error: %v\n", err)
 		os.Exit(1)
 	}
+	// Persist this run's battery wear so the next run can show the change.
+	// A replayed snapshot isn't this machine's, so it must not touch the state files.
+	if fm, ok := final.(model); ok && fm.ready && replaySnapshot == nil {
+		_ = saveBatteryHistory(batteryHistoryPath(), fm.metrics.Batteries, fm.metrics.CollectedAt)
+		if fm.calibration != nil {
+			_ = saveCalibration(calibrationPath(), *fm.calibration)
+		}
+	}
 }
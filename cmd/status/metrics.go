@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
 	"os/exec"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/v4/disk"
 	"github.com/shirou/gopsutil/v4/host"
 	"github.com/shirou/gopsutil/v4/net"
+	"golang.org/x/sync/singleflight"
 )
 
 // RingBuffer is a fixed-size circular buffer for float64 values.
@@ -57,8 +63,15 @@ func (rb *RingBuffer) Slice() []float64 {
 }
 
 type MetricsSnapshot struct {
-	CollectedAt    time.Time
+	CollectedAt time.Time // Stamped by Collect; carries a monotonic reading
+	AfterWake   bool      // The machine slept since the previous Collect; rate-based values restart
+	EnergyWh    float64   // Energy consumed since the collector started or ResetEnergy
+	// Throttled is set once the CPU has throttled since the collector started or
+	// ResetThrottle; ThrottledFor is the cumulative time, excluding sleep.
+	Throttled      bool
+	ThrottledFor   time.Duration
 	Host           string
+	MachineID      string // Stable hardware/OS identifier; empty when --no-machine-id is set
 	Platform       string
 	Uptime         string
 	Procs          uint64
@@ -75,14 +88,26 @@ type MetricsSnapshot struct {
 	NetworkHistory NetworkHistory
 	Proxy          ProxyStatus
 	Batteries      []BatteryStatus
+	BatteryErr     error // ErrNoBattery, ErrBatteryUnreadable, ErrBatteryPermission, or a probe failure
+	Charger        ChargerInfo
+	PowerProfile   PowerProfile
+	SystemWatts    SystemWatts // Whole-machine draw, measured or estimated
 	Thermal        ThermalStatus
 	Sensors        []SensorReading
+	SensorRollup   SensorRollup
 	Bluetooth      []BluetoothDevice
+	Display        *DisplayStatus   // nil unless --display-info is set and brightness is exposed
+	Assertions     []PowerAssertion // What's holding macOS awake; nil unless --assertions is set
+	ProcessEnergy  []ProcessEnergy  // Highest energy impact first; nil unless --energy-impact is set
 	TopProcesses   []ProcessInfo
+	// Sections says, per collector, whether it ran and produced data, came up
+	// empty, failed, or was skipped, so "no data" can be told apart.
+	Sections map[CollectorKind]SectionStatus
 }
 
 type HardwareInfo struct {
 	Model       string // MacBook Pro 14-inch, 2021
+	ModelID     string // MacBookPro18,3 / Mac15,3
 	CPUModel    string // Apple M1 Pro / Intel Core i7
 	TotalRAM    string // 16GB
 	DiskSize    string // 512GB
@@ -120,6 +145,7 @@ type GPUStatus struct {
 	MemoryUsed  float64
 	MemoryTotal float64
 	CoreCount   int
+	TempC       float64 // Die or edge temperature; 0 when the GPU doesn't report one
 	Note        string
 }
 
@@ -144,20 +170,20 @@ type DiskStatus struct {
 }
 
 type NetworkStatus struct {
-	Name      string
+	Name      string // Raw interface name (en0, eth0)
+	Label     string // Friendly name (Wi-Fi, Ethernet); falls back to Name
 	RxRateMBs float64
 	TxRateMBs float64
 	IP        string
 }
 
-// NetworkHistory holds the global network usage history.
+// NetworkHistory holds the global network usage history, up to
+// historyRetention.capacity() samples per direction.
 type NetworkHistory struct {
 	RxHistory []float64
 	TxHistory []float64
 }
 
-const NetworkHistorySize = 120 // Increased history size for wider graph
-
 type ProxyStatus struct {
 	Enabled bool
 	Type    string // HTTP, HTTPS, SOCKS, PAC, WPAD, TUN
@@ -165,29 +191,197 @@ type ProxyStatus struct {
 }
 
 type BatteryStatus struct {
-	Percent    float64
-	Status     string
-	TimeLeft   string
-	Health     string
-	CycleCount int
-	Capacity   int // Maximum capacity percentage (e.g., 85 means 85% of original)
+	Name    string // InternalBattery-0, BAT0, ...
+	Kind    BatteryKind
+	Percent float64
+	// PercentPrecise is CurrentCharge ÷ FullChargeCapacity, which moves in fractions
+	// of a point where Percent (the OS's whole-number figure) jumps by 1; 0 when
+	// the raw charge isn't exposed or disagrees with Percent.
+	PercentPrecise float64
+	Status         string
+	State          BatteryState // Status normalised, plus Bypass; see batteryState
+	TimeLeft       string       // OS estimate, e.g. "2:30"
+	// TimeLeftUnreliable flags a TimeLeft that is stale or bogus, e.g. right after
+	// a wake or "0:00" on a charged battery; see timeLeftGuard.
+	TimeLeftUnreliable bool
+	Health             string
+	CycleCount         int
+	Capacity           int     // Maximum capacity percentage (e.g., 85 means 85% of original)
+	VoltageV           float64 // Pack voltage in volts; 0 when unavailable
+	CurrentA           float64 // Pack current in amps, negative while discharging; 0 when unavailable
+	Watts              float64 // Pack power in watts as the driver reports it (sysfs power_now); 0 when unavailable
+	// InstantCurrentA and AverageCurrentA split CurrentA into the momentary and the
+	// time-averaged reading. macOS only; 0 elsewhere. See estimateCurrentA.
+	InstantCurrentA float64
+	AverageCurrentA float64
+	// MaxChargeCurrentA is the charge current cap the battery or charger enforces;
+	// 0 when not exposed. See ChargeCurrentUtilization.
+	MaxChargeCurrentA float64
+	// ChargeLimited is set when the OS caps charging below 100% (e.g. an 80% limit);
+	// EffectiveFullPercent is then that cap, the practical "full for now".
+	// ChargeAnimation combines these with Status and Percent for animated UIs.
+	ChargeLimited        bool
+	EffectiveFullPercent float64
+	// Raw capacity figures, all in CapacityUnit; zero when the OS doesn't expose them.
+	DesignCapacity     float64
+	FullChargeCapacity float64
+	CurrentCharge      float64
+	CapacityUnit       CapacityUnit
+	// Cells holds per-cell voltages where the hardware exposes them; nil otherwise.
+	Cells *BatteryCells
+	// Controller is the gauge firmware, chemistry and identity; nil when not exposed.
+	Controller *BatteryController
+	// ComputedTimeLeft is remaining charge ÷ (V × I) while discharging; 0 when unknown.
+	ComputedTimeLeft time.Duration
+	// DrainRate is the discharge rate in %/h over the last few minutes; 0 until enough
+	// samples exist. AbnormalDrain flags a fast drain while the CPU is mostly idle.
+	DrainRate     float64
+	AbnormalDrain bool
+	// Worn is set when HealthPercent is known and below wornThresholdPercent.
+	Worn bool
+	// Trend is the wear change since the previous run; nil on a first run.
+	Trend *BatteryTrend
+	// Longevity is the rated cycle life left; nil when the model's rating or the
+	// cycle count is unknown.
+	Longevity *BatteryLongevity
+	// LastFullCharge is when the battery was last seen at 100% or its charge limit,
+	// kept across runs; nil until it has been observed full.
+	LastFullCharge *time.Time
+	// UPS only: output load, and how long the UPS has been on battery (apcupsd).
+	LoadPercent  float64
+	OnBatteryFor time.Duration
+	Input        *UPSInput // UPS only: mains line quality; nil unless the backend reports it
+	Source       string    // Probe that produced the reading: iokit, pmset, sysfs, acpi, nut, apcupsd
+}
+
+// CapacityUnit is the unit of BatteryStatus raw capacity figures.
+type CapacityUnit string
+
+const (
+	CapacityMAh CapacityUnit = "mAh" // Linux charge_*, macOS ioreg
+	CapacityWh  CapacityUnit = "Wh"  // Linux energy_*
+)
+
+// ThermalLevel is the coarse thermal pressure state reported by the OS.
+type ThermalLevel int
+
+const (
+	ThermalLevelUnknown ThermalLevel = iota
+	ThermalLevelNominal
+	ThermalLevelFair
+	ThermalLevelSerious
+	ThermalLevelCritical
+)
+
+// Severity is the numeric form for dashboards and exports: nominal 0, fair 1,
+// serious 2, critical 3. Unknown is -1 so it never passes a "≥ N" threshold.
+func (l ThermalLevel) Severity() int {
+	if l < ThermalLevelNominal || l > ThermalLevelCritical {
+		return -1
+	}
+	return int(l - ThermalLevelNominal)
 }
 
+// MarshalJSON emits both forms, e.g. {"name":"serious","severity":2}.
+func (l ThermalLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name     string `json:"name"`
+		Severity int    `json:"severity"`
+	}{l.String(), l.Severity()})
+}
+
+// UnmarshalJSON reads the MarshalJSON form back by name.
+func (l *ThermalLevel) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*l = ThermalLevelUnknown
+	for level := ThermalLevelNominal; level <= ThermalLevelCritical; level++ {
+		if level.String() == v.Name {
+			*l = level
+		}
+	}
+	return nil
+}
+
+func (l ThermalLevel) String() string {
+	switch l {
+	case ThermalLevelNominal:
+		return "nominal"
+	case ThermalLevelFair:
+		return "fair"
+	case ThermalLevelSerious:
+		return "serious"
+	case ThermalLevelCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// ThermalStatus temperatures are always Celsius, whatever unit the source reports.
 type ThermalStatus struct {
-	CPUTemp      float64
-	GPUTemp      float64
-	FanSpeed     int
-	FanCount     int
-	SystemPower  float64 // System power consumption in Watts
-	AdapterPower float64 // AC adapter max power in Watts
-	BatteryPower float64 // Battery charge/discharge power in Watts (positive = discharging)
+	Level          ThermalLevel // Thermal pressure (OS-reported when available)
+	CPUTemp        float64
+	CPUTempTrend   Trend         // Direction of CPUTemp over the last few samples
+	CPUTempSource  CPUTempSource // Where CPUTemp came from; estimates are flagged by Estimate
+	Estimated      bool          // CPUTemp is an estimate rather than a die reading; see CPUTempSource.Estimate
+	CPUTempMax     float64       // Hottest CPU sensor; CPUTemp is kept equal to it
+	CPUTempAvg     float64       // Mean over CPU sensors; equals CPUTemp with only one reading
+	GPUTemp        float64
+	FanSpeed       int // RPM, averaged over --fan-smoothing samples
+	FanSpeedRaw    int // This tick's RPM before smoothing
+	FanCount       int
+	FanSpeeds      []int           // RPM of each spinning fan this tick, unsmoothed; nil when per-fan speeds aren't known
+	FanMax         int             // Maximum fan RPM, reported or learned by --calibrate; calibrates FanNoise
+	FanNoise       FanNoise        // Qualitative loudness estimate from FanSpeed
+	FanControlMode FanControl      // Auto or Manual from the SMC on macOS; empty elsewhere
+	SystemPower    float64         // System power consumption in Watts
+	AdapterPower   float64         // AC adapter max power in Watts
+	BatteryPower   float64         // Battery charge/discharge power in Watts (positive = discharging)
+	BatteryTemp    float64         // Battery temperature in °C from ioreg on macOS; 0 elsewhere
+	CPUPower       float64         // CPU package power in Watts from Linux RAPL; 0 until two samples exist
+	EnclosureTemp  float64         // Hottest chassis/skin sensor; 0 unless sensors were collected
+	Zones          []SensorReading // Linux thermal zones; merged into MetricsSnapshot.Sensors
+	// CriticalSustained is set once the hottest temperature has stayed at or above
+	// criticalTempCelsius for criticalTempSamples samples; CriticalPeak is that reading.
+	CriticalSustained bool
+	CriticalPeak      float64
+	// Throttling is set while the OS reports a serious thermal state (macOS) or the
+	// CPU throttle counters rose since the last tick (Linux). ThrottleCount is the
+	// raw Linux counter, summed over CPUs; 0 elsewhere. ThrottlePercent is the
+	// share of full CPU speed the OS allows (pmset CPU_Speed_Limit, Intel Macs):
+	// 100 unthrottled, 0 when unknown.
+	Throttling      bool
+	ThrottleCount   int64
+	ThrottlePercent int
+	// PermissionDenied is set when sensor files exist but the OS refused to read them.
+	PermissionDenied bool
 }
 
 type SensorReading struct {
-	Label string
+	Key   string // Raw source key, e.g. "TC0P" or "coretemp_core0"; stable across releases
+	Label string // Display name derived from Key; may change as prettification improves
 	Value float64
 	Unit  string
 	Note  string
+	Class SensorClass
+	Trend Trend // Direction over the last few samples, keyed by StableKey
+	// Location is where in the machine the sensor sits, guessed from its label;
+	// only set with --sensor-locations, and empty when the label gives no hint.
+	Location SensorLocation `json:",omitempty"`
+}
+
+// StableKey is the identifier exports and trend tracking key on: Key, or Label for
+// readings built without one.
+func (r SensorReading) StableKey() string {
+	if r.Key != "" {
+		return r.Key
+	}
+	return r.Label
 }
 
 type BluetoothDevice struct {
@@ -203,8 +397,9 @@ type Collector struct {
 	hasStatic bool
 
 	// Slow cache (30s-1m).
-	lastBTAt time.Time
-	lastBT   []BluetoothDevice
+	lastBTAt   time.Time
+	lastBT     []BluetoothDevice
+	procEnergy *energySampler // top's energy impact, sampled in the background
 
 	// Fast metrics (1s).
 	prevNet      map[string]net.IOCountersStat
@@ -215,38 +410,140 @@ type Collector struct {
 	cachedGPU    []GPUStatus
 	prevDiskIO   disk.IOCountersStat
 	lastDiskAt   time.Time
+	prevRAPL     map[string]raplCounter
+	lastRAPLAt   time.Time
+
+	prevSiliconEnergy map[string]int64 // Apple Silicon GPU/ANE counters, millijoules
+	lastSiliconAt     time.Time
+
+	disabled map[CollectorKind]bool // See SetEnabled
+
+	lastCollectAt time.Time
+	energy        energyMeter
+	drain         drainTracker
+	history       batteryHistory // Previous run's battery wear; see SetBatteryHistory
+	trends        trendTracker
+	lastFull      lastFullTracker
+	critical      criticalTempTracker
+	throttle      throttleMeter
+	fans          fanSmoother
+	timeLeft      timeLeftGuard
+	backfill      batteryBackfill
+
+	replay *MetricsSnapshot // Returned by Collect instead of collecting; see replaySnapshot
 }
 
 func NewCollector() *Collector {
 	return &Collector{
 		prevNet:      make(map[string]net.IOCountersStat),
-		rxHistoryBuf: NewRingBuffer(NetworkHistorySize),
-		txHistoryBuf: NewRingBuffer(NetworkHistorySize),
+		rxHistoryBuf: NewRingBuffer(historyRetention.capacity()),
+		txHistoryBuf: NewRingBuffer(historyRetention.capacity()),
+		disabled:     maps.Clone(disabledCollectors),
+		procEnergy:   newEnergySampler(),
+		replay:       replaySnapshot,
+	}
+}
+
+// Reset drops everything the collector has accumulated (rate baselines, trends,
+// drain and energy tracking, the loaded battery history) as if it were new.
+// Enabled collectors are kept.
+func (c *Collector) Reset() {
+	disabled := c.disabled
+	*c = *NewCollector()
+	c.disabled = disabled
+}
+
+// SetBatteryHistory sets the previous run's battery samples that Trend is measured against.
+func (c *Collector) SetBatteryHistory(h batteryHistory) {
+	c.history = h
+}
+
+// Fresh reports whether the snapshot was collected less than maxAge ago. time.Since
+// uses the monotonic clock reading from Collect, so wall-clock jumps don't matter.
+func (m MetricsSnapshot) Fresh(maxAge time.Duration) bool {
+	if m.CollectedAt.IsZero() || maxAge <= 0 {
+		return false
 	}
+	return time.Since(m.CollectedAt) < maxAge
 }
 
-func (c *Collector) Collect() (MetricsSnapshot, error) {
+// primeInterval is the gap between the baseline and first real throughput sample.
+// Zero skips priming.
+var primeInterval = 250 * time.Millisecond
+
+// Prime takes a baseline network, disk and RAPL sample and waits interval, so the
+// first Collect reports real rates instead of zeros. It is a no-op once a
+// baseline exists, when replaying, or when interval is not positive; single-shot callers that
+// don't show rates should skip it.
+func (c *Collector) Prime(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || c.replay != nil || !c.lastNetAt.IsZero() || !c.lastDiskAt.IsZero() {
+		return
+	}
 	now := time.Now()
+	primed := false
+	if c.Enabled(CollectNetwork) {
+		_, _ = c.collectNetwork(ctx, now)
+		primed = true
+	}
+	if c.Enabled(CollectDiskIO) {
+		c.collectDiskIO(ctx, now)
+		primed = true
+	}
+	if c.Enabled(CollectPower) {
+		c.collectCPUPower(now)
+		primed = true
+	}
+	if !primed {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(interval):
+	}
+}
 
-	// Host info is cached by gopsutil; fetch once.
-	hostInfo, _ := host.Info()
+// Collect gathers a snapshot. ctx is threaded into every probe and subprocess,
+// so cancelling it aborts in-flight work instead of waiting out per-probe timeouts.
+// A collector created under --replay returns the loaded snapshot every time.
+func (c *Collector) Collect(ctx context.Context) (MetricsSnapshot, error) {
+	if c.replay != nil {
+		return *c.replay, nil
+	}
+	// An abandoned refresh, e.g. the user quitting mid-collection, returns at once.
+	if err := ctx.Err(); err != nil {
+		return MetricsSnapshot{}, err
+	}
+	now := time.Now()
 
 	var (
 		wg       sync.WaitGroup
 		errMu    sync.Mutex
 		mergeErr error
 
+		hostInfo     host.InfoStat
 		cpuStats     CPUStatus
 		memStats     MemoryStatus
 		diskStats    []DiskStatus
 		diskIO       DiskIOStatus
+		cpuPower     float64
+		pmWatts      float64
+		silicon      siliconPower
 		netStats     []NetworkStatus
 		proxyStats   ProxyStatus
 		batteryStats []BatteryStatus
+		batteryErr   error
+		sensorErr    error
+		upsStats     []BatteryStatus
+		chargerStats ChargerInfo
+		powerSource  ChargerInfo
+		powerProfile PowerProfile
 		thermalStats ThermalStatus
 		sensorStats  []SensorReading
 		gpuStats     []GPUStatus
 		btStats      []BluetoothDevice
+		displayStats *DisplayStatus
+		assertions   []PowerAssertion
+		procEnergy   []ProcessEnergy
 		topProcs     []ProcessInfo
 	)
 
@@ -267,22 +564,66 @@ func (c *Collector) Collect() (MetricsSnapshot, error) {
 		}()
 	}
 
-	// Launch independent collection tasks.
-	collect(func() (err error) { cpuStats, err = collectCPU(); return })
-	collect(func() (err error) { memStats, err = collectMemory(); return })
-	collect(func() (err error) { diskStats, err = collectDisks(); return })
-	collect(func() (err error) { diskIO = c.collectDiskIO(now); return nil })
-	collect(func() (err error) { netStats, err = c.collectNetwork(now); return })
-	collect(func() (err error) { proxyStats = collectProxy(); return nil })
-	collect(func() (err error) { batteryStats, _ = collectBatteries(); return nil })
-	collect(func() (err error) { thermalStats = collectThermal(); return nil })
-	// Sensors disabled - CPU temp already shown in CPU card
-	// collect(func() (err error) { sensorStats, _ = collectSensors(); return nil })
-	collect(func() (err error) { gpuStats, err = c.collectGPU(now); return })
-	collect(func() (err error) {
+	// Launch independent collection tasks; disabled collectors never start.
+	started := make(map[CollectorKind]bool)
+	sectionErrs := make(map[CollectorKind]error)
+	run := func(kind CollectorKind, fn func() error) {
+		if !c.Enabled(kind) {
+			return
+		}
+		started[kind] = true
+		collect(func() (err error) {
+			defer func() {
+				// A panicking probe costs only its own section.
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%s collection failed: %v", kind, r)
+				}
+				if err != nil {
+					errMu.Lock()
+					sectionErrs[kind] = err
+					errMu.Unlock()
+				}
+			}()
+			return fn()
+		})
+	}
+	run(CollectHost, func() (err error) {
+		// gopsutil caches host info; a partial result still has the hostname.
+		if info, _ := host.InfoWithContext(ctx); info != nil {
+			hostInfo = *info
+		}
+		return nil
+	})
+	run(CollectCPU, func() (err error) { cpuStats, err = collectCPU(ctx); return })
+	run(CollectMemory, func() (err error) { memStats, err = collectMemory(ctx); return })
+	run(CollectDisks, func() (err error) { diskStats, err = collectDisks(ctx); return })
+	run(CollectDiskIO, func() (err error) { diskIO = c.collectDiskIO(ctx, now); return nil })
+	run(CollectPower, func() (err error) { cpuPower = c.collectCPUPower(now); return nil })
+	if collectPowermetrics {
+		run(CollectPower, func() (err error) { pmWatts = readPowermetricsWatts(ctx); return nil })
+	}
+	if collectSiliconPowerCounters {
+		run(CollectPower, func() (err error) { silicon = c.collectSiliconPower(ctx, now); return nil })
+	}
+	run(CollectNetwork, func() (err error) { netStats, err = c.collectNetwork(ctx, now); return })
+	run(CollectProxy, func() (err error) { proxyStats = collectProxy(ctx); return nil })
+	run(CollectBattery, func() (err error) { batteryStats, batteryErr = collectBatteries(ctx); return nil })
+	run(CollectUPS, func() (err error) { upsStats = collectUPS(ctx); return nil })
+	run(CollectThermal, func() (err error) { thermalStats = averageThermal(ctx, collectThermal); return nil })
+	run(CollectBattery, func() (err error) { powerProfile = collectPowerProfile(ctx); return nil })
+	run(CollectBattery, func() (err error) { powerSource = collectPowerSource(ctx); return nil })
+	if collectChargerInfo {
+		run(CollectBattery, func() (err error) { chargerStats = collectCharger(ctx); return nil })
+	}
+	// Sensors are skipped in the TUI (CPU temp already shown in CPU card) but exporters need them.
+	if collectSensorReadings {
+		run(CollectSensors, func() (err error) { sensorStats, sensorErr = averageSensors(ctx, sensorProbe); return nil })
+	}
+	run(CollectGPU, func() (err error) { gpuStats, err = c.collectGPU(ctx, now); return })
+	run(CollectBluetooth, func() (err error) {
 		// Bluetooth is slow; cache for 30s.
 		if now.Sub(c.lastBTAt) > 30*time.Second || len(c.lastBT) == 0 {
-			btStats = c.collectBluetooth(now)
+			btStats = c.collectBluetooth(ctx, now)
 			c.lastBT = btStats
 			c.lastBTAt = now
 		} else {
@@ -290,25 +631,90 @@ func (c *Collector) Collect() (MetricsSnapshot, error) {
 		}
 		return nil
 	})
-	collect(func() (err error) { topProcs = collectTopProcesses(); return nil })
+	if collectDisplayInfo {
+		run(CollectDisplay, func() (err error) { displayStats = collectDisplay(ctx); return nil })
+	}
+	if collectPowerAssertions {
+		run(CollectAssertions, func() (err error) { assertions = collectAssertions(ctx); return nil })
+	}
+	if collectEnergyImpact {
+		run(CollectEnergy, func() (err error) { procEnergy = c.procEnergy.get(now); return nil })
+	}
+	run(CollectProcesses, func() (err error) { topProcs = collectTopProcesses(ctx); return nil })
 
 	// Wait for all to complete.
 	wg.Wait()
 
 	// Dependent tasks (post-collect).
 	// Cache hardware info as it's expensive and rarely changes.
-	if !c.hasStatic || now.Sub(c.lastHWAt) > 10*time.Minute {
-		c.cachedHW = collectHardware(memStats.Total, diskStats)
+	if c.Enabled(CollectHost) && (!c.hasStatic || now.Sub(c.lastHWAt) > 10*time.Minute) {
+		c.cachedHW = collectHardware(ctx, memStats.Total, diskStats)
 		c.lastHWAt = now
 		c.hasStatic = true
 	}
 	hwInfo := c.cachedHW
+	// Battery and sensor errors don't fail Collect, but their sections record them.
+	if batteryErr != nil {
+		sectionErrs[CollectBattery] = batteryErr
+	}
+	if sensorErr != nil {
+		sectionErrs[CollectSensors] = sensorErr
+	}
+	batteryStats, batteryErr = mergeUPS(batteryStats, batteryErr, upsStats)
+	c.backfill.apply(batteryStats)
+	markWornBatteries(batteryStats)
+	setCapacityPercents(batteryStats)
+	setPrecisePercents(batteryStats)
+	setBatteryStates(batteryStats)
+	estimateTimeToEmpty(batteryStats)
+	sensorStats = mergeSensorReadings(sensorStats, thermalStats.Zones)
+	if sensorLocations {
+		applySensorLocations(sensorStats)
+	}
+	thermalStats.EnclosureTemp = enclosureTemp(sensorStats)
+	applyCPUTempSpread(&thermalStats, sensorStats)
+	sensorRollup := rollupSensors(sensorStats)
+	c.trends.apply(sensorStats, &thermalStats)
+	if thermalStats.FanMax == 0 {
+		thermalStats.FanMax = learnedFanMax
+	}
+	c.fans.observe(&thermalStats)
+	thermalStats.FanNoise = estimateFanNoise(thermalStats.FanSpeed, thermalStats.FanMax)
+	thermalStats.CPUPower = cpuPower
+	chargerStats = powerSource.withDetails(chargerStats)
+	if thermalStats.AdapterPower == 0 {
+		thermalStats.AdapterPower = chargerStats.Watts
+	}
+	if thermalStats.GPUTemp == 0 {
+		thermalStats.GPUTemp = hottestGPUTemp(gpuStats)
+	}
 
 	score, scoreMsg := calculateHealthScore(cpuStats, memStats, diskStats, diskIO, thermalStats)
 
-	return MetricsSnapshot{
+	afterWake := sleptBetween(c.lastCollectAt, now)
+	c.lastCollectAt = now
+	c.critical.observe(&thermalStats, sensorRollup.Hottest, afterWake)
+	c.throttle.observe(&thermalStats, now, afterWake)
+	c.energy.add(sessionPowerWatts(thermalStats), now, afterWake)
+	cpuLoad := -1.0
+	if c.Enabled(CollectCPU) {
+		cpuLoad = cpuStats.Usage
+	}
+	c.drain.observe(batteryStats, now, cpuLoad, afterWake)
+	c.timeLeft.observe(batteryStats, now, afterWake)
+	applyBatteryTrends(batteryStats, c.history, now)
+	applyBatteryLongevity(batteryStats, hwInfo)
+	c.lastFull.observe(batteryStats, c.history, now)
+	chargerStats.checkRating(hwInfo.ModelID)
+
+	m := MetricsSnapshot{
 		CollectedAt:    now,
+		AfterWake:      afterWake,
+		EnergyWh:       c.energy.wh,
+		Throttled:      c.throttle.occurred,
+		ThrottledFor:   c.throttle.total,
 		Host:           hostInfo.Hostname,
+		MachineID:      machineID(hostInfo.HostID),
 		Platform:       fmt.Sprintf("%s %s", hostInfo.Platform, hostInfo.PlatformVersion),
 		Uptime:         formatUptime(hostInfo.Uptime),
 		Procs:          hostInfo.Procs,
@@ -325,17 +731,142 @@ func (c *Collector) Collect() (MetricsSnapshot, error) {
 			RxHistory: c.rxHistoryBuf.Slice(),
 			TxHistory: c.txHistoryBuf.Slice(),
 		},
-		Proxy:        proxyStats,
-		Batteries:    batteryStats,
-		Thermal:      thermalStats,
-		Sensors:      sensorStats,
-		Bluetooth:    btStats,
-		TopProcesses: topProcs,
-	}, mergeErr
+		Proxy:         proxyStats,
+		Batteries:     batteryStats,
+		BatteryErr:    batteryErr,
+		Charger:       chargerStats,
+		PowerProfile:  powerProfile,
+		SystemWatts:   systemWatts(thermalStats, batteryStats, pmWatts).withSubsystems(silicon),
+		Thermal:       thermalStats,
+		Sensors:       sensorFilter.apply(sensorStats), // Display only: the thermal summary and alerts see every sensor
+		SensorRollup:  sensorRollup,
+		Bluetooth:     btStats,
+		Display:       displayStats,
+		Assertions:    assertions,
+		ProcessEnergy: procEnergy,
+		TopProcesses:  topProcs,
+	}
+	m.Sections = snapshotSections(started, sectionErrs, m)
+	return m, mergeErr
 }
 
+// englishLocaleEnv forces English output from tools whose text output we parse.
+var englishLocaleEnv = []string{"LANG=C", "LC_ALL=C"}
+
 func runCmd(ctx context.Context, name string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+	return runCmdEnv(ctx, nil, name, args...)
+}
+
+// defaultCommandTimeout bounds commands whose caller passed a context without a
+// deadline, so a wedged tool can't block a collector forever. Call sites set their
+// own, tighter timeouts; this is only the backstop.
+var defaultCommandTimeout = 10 * time.Second
+
+// commandWaitDelay is how long to wait for output pipes after a command is killed;
+// a grandchild holding stdout open would otherwise keep Output blocked.
+const commandWaitDelay = 500 * time.Millisecond
+
+// cmdFlight shares one run of an identical command between concurrent callers,
+// e.g. the UI and an exporter collecting at the same moment.
+var cmdFlight singleflight.Group
+
+// runCmdEnv runs a command with extra environment variables layered over the current ones.
+// Tools that keep failing are skipped for a while; see commandTracker. At most
+// cmdSlots commands run at once. ctx's deadline bounds the whole call, queueing
+// for a slot included, so a caller with a hard limit never overshoots it; calls
+// without one get defaultCommandTimeout to wait for a slot and again to run.
+// Callers asking for the same command while it is running join that run and
+// share its result instead of spawning another. The run is detached from the
+// caller that started it, so one caller giving up doesn't fail the rest; each
+// returns as soon as its own ctx is done. Nothing is spawned once ctx is done.
+func runCmdEnv(ctx context.Context, env []string, name string, args ...string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := commands.allow(name, args); err != nil {
+		return "", err
+	}
+	// The run can outlive this call, so it works from copies: tests and remote
+	// collection swap these globals back once their callers return.
+	runner, host, backstop, slots := cmdRunner, cmdHost, defaultCommandTimeout, cmdSlots
+	deadline, bounded := ctx.Deadline()
+	detached := context.WithoutCancel(ctx)
+	limit := func() (context.Context, context.CancelFunc) {
+		if bounded {
+			return context.WithDeadline(detached, deadline)
+		}
+		return context.WithTimeout(detached, backstop)
+	}
+	key := host + "\x02" + name + "\x00" + strings.Join(args, "\x00") + "\x01" + strings.Join(env, "\x00")
+	cmdRuns.Add(1)
+	results := cmdFlight.DoChan(key, func() (any, error) {
+		waitCtx, cancelWait := limit()
+		release, err := slots.acquire(waitCtx)
+		cancelWait()
+		if err != nil {
+			return "", err
+		}
+		defer release()
+		runCtx, cancel := limit()
+		defer cancel()
+		out, err := runner(runCtx, env, name, args...)
+		// Running out a caller's own budget isn't held against the tool, while
+		// hitting the backstop on an unbounded call means it hung.
+		judge := detached
+		if bounded {
+			judge = runCtx
+		}
+		commands.record(judge, name, args, err)
+		return out, err
+	})
+	select {
+	case res := <-results:
+		cmdRuns.Done()
+		return res.Val.(string), res.Err
+	case <-ctx.Done():
+		// Callers that joined this run still get its result.
+		go func() { <-results; cmdRuns.Done() }()
+		return "", ctx.Err()
+	}
+}
+
+// cmdRuns counts runCmd calls whose shared run hasn't delivered its result yet,
+// including calls that already returned because their ctx was done.
+var cmdRuns sync.WaitGroup
+
+// waitCommands blocks until every command started through runCmd has finished.
+// Call it before swapping cmdRunner back, once no new commands are starting.
+func waitCommands() {
+	cmdRuns.Wait()
+}
+
+// commandRunner runs one command and returns its stdout.
+type commandRunner func(ctx context.Context, env []string, name string, args ...string) (string, error)
+
+// cmdRunner executes subprocesses; tests swap it for a fake.
+var cmdRunner commandRunner = execCmd
+
+// cmdHost names the machine cmdRunner reaches, "" for this one. It is part of
+// runCmd's sharing key, so a run for one host is never joined by a call for another.
+var cmdHost string
+
+// probeOS is the OS the probes are written for, and localProbes whether they may
+// read this machine directly (sysfs, IOKit, gopsutil). Remote collection swaps
+// both, along with cmdRunner, so the same probes run over ssh; see useRemoteProbes.
+var (
+	probeOS     = runtime.GOOS
+	localProbes = true
+)
+
+// sensorProbe reads the sensor list for Collect; tests swap it for a fake.
+var sensorProbe = collectSensors
+
+func execCmd(ctx context.Context, env []string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, resolveCommand(name), args...)
+	cmd.WaitDelay = commandWaitDelay
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -347,10 +878,14 @@ func commandExists(name string) bool {
 	if name == "" {
 		return false
 	}
+	if !localProbes {
+		// A remote PATH can't be searched from here; a missing tool just fails.
+		return true
+	}
 	defer func() {
 		// Treat LookPath panics as "missing".
 		_ = recover()
 	}()
-	_, err := exec.LookPath(name)
+	_, err := exec.LookPath(resolveCommand(name))
 	return err == nil
 }
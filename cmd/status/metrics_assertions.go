@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const assertionsQueryTimeout = 500 * time.Millisecond
+
+// PowerAssertion is one active macOS power assertion: a process asking the system
+// (or display) not to idle-sleep. Listed from `pmset -g assertions`.
+type PowerAssertion struct {
+	Type    string        // e.g. PreventUserIdleSystemSleep
+	Process string        // Owning process name, e.g. coreaudiod
+	PID     int           // Owning process ID
+	Name    string        // Reason the owner gave; may be empty
+	Age     time.Duration // How long the assertion has been held
+}
+
+// sleepPreventingAssertions are the assertion types that keep the machine awake;
+// the rest (UserIsActive, BackgroundTask, ...) only describe activity.
+var sleepPreventingAssertions = map[string]bool{
+	"PreventSystemSleep":          true,
+	"PreventUserIdleSystemSleep":  true,
+	"PreventUserIdleDisplaySleep": true,
+	"NoIdleSleepAssertion":        true,
+	"NoDisplaySleepAssertion":     true,
+}
+
+// PreventsSleep reports whether a is one that keeps the system or display awake.
+func (a PowerAssertion) PreventsSleep() bool {
+	return sleepPreventingAssertions[a.Type]
+}
+
+// collectPowerAssertions enables the assertion list on macOS. Opt-in: it only
+// matters when chasing drain, and pmset is another subprocess per refresh.
+var collectPowerAssertions bool
+
+// collectAssertions lists active assertions by owning process. nil off macOS or when
+// pmset fails; best-effort either way.
+func collectAssertions(ctx context.Context) []PowerAssertion {
+	if probeOS != "darwin" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, assertionsQueryTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "pmset", "-g", "assertions")
+	if err != nil {
+		return nil
+	}
+	return parsePMSetAssertions(out)
+}
+
+// parsePMSetAssertions reads the "Listed by owning process:" section, whose lines look like
+//
+//	pid 143(coreaudiod): [0x00001f3a00018a2b] 00:05:12 PreventUserIdleSystemSleep named: "com.apple.audio.context"
+//
+// Indented continuation lines (timeouts, details) and the kernel section are skipped.
+func parsePMSetAssertions(out string) []PowerAssertion {
+	var list []PowerAssertion
+	inProcesses := false
+	for line := range strings.Lines(out) {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "Listed by owning process:"):
+			inProcesses = true
+			continue
+		case !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t"):
+			// Any other unindented heading ends the section.
+			inProcesses = false
+			continue
+		}
+		if !inProcesses {
+			continue
+		}
+		if a, ok := parseAssertionLine(trimmed); ok {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+func parseAssertionLine(line string) (PowerAssertion, bool) {
+	rest, ok := strings.CutPrefix(line, "pid ")
+	if !ok {
+		return PowerAssertion{}, false
+	}
+	pidText, rest, ok := strings.Cut(rest, "(")
+	if !ok {
+		return PowerAssertion{}, false
+	}
+	process, rest, ok := strings.Cut(rest, "):")
+	if !ok {
+		return PowerAssertion{}, false
+	}
+	pid, err := strconv.Atoi(pidText)
+	if err != nil {
+		return PowerAssertion{}, false
+	}
+	a := PowerAssertion{Process: process, PID: pid}
+	// Drop the "[0x...]" assertion ID.
+	if _, after, found := strings.Cut(rest, "]"); found {
+		rest = after
+	}
+	if head, name, found := strings.Cut(rest, " named: "); found {
+		rest = head
+		a.Name = strings.Trim(strings.TrimSpace(name), `"`)
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return PowerAssertion{}, false
+	}
+	if len(fields) > 1 {
+		a.Age = parseClockDuration(fields[0])
+	}
+	a.Type = fields[len(fields)-1]
+	return a, true
+}
+
+// parseClockDuration reads "HH:MM:SS"; anything else is 0.
+func parseClockDuration(s string) time.Duration {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 {
+			return 0
+		}
+		d += time.Duration(n) * unit
+	}
+	return d
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+const pmsetAssertionsOutput = `2026-03-01 10:00:00 +0100
+Assertion status system-wide:
+   BackgroundTask                 0
+   UserIsActive                   1
+   PreventUserIdleDisplaySleep    0
+   PreventSystemSleep             0
+   PreventUserIdleSystemSleep     1
+Listed by owning process:
+   pid 143(coreaudiod): [0x00001f3a00018a2b] 00:05:12 PreventUserIdleSystemSleep named: "com.apple.audio.context"
+	Timeout will fire in 3588 secs Action=TimeoutActionRelease
+   pid 402(WindowServer): [0x00001f3a00098a10] 00:00:03 UserIsActive named: "com.apple.iohideventsystem.queue.tickle"
+   pid 877(caffeinate): [0x00001f3a0009a011] 01:02:03 PreventUserIdleSystemSleep named: "caffeinate command-line tool"
+Kernel Assertions: 0x4=USB
+   id=500  level=255 0x4=USB mod=01/03/2026, 09:00 description=com.apple.usb.externaldevice.14100000 owner=AppleUSBXHCI
+Idle sleep preventers: IODisplayWrangler
+`
+
+func TestParsePMSetAssertions(t *testing.T) {
+	got := parsePMSetAssertions(pmsetAssertionsOutput)
+	want := []PowerAssertion{
+		{Type: "PreventUserIdleSystemSleep", Process: "coreaudiod", PID: 143, Name: "com.apple.audio.context", Age: 5*time.Minute + 12*time.Second},
+		{Type: "UserIsActive", Process: "WindowServer", PID: 402, Name: "com.apple.iohideventsystem.queue.tickle", Age: 3 * time.Second},
+		{Type: "PreventUserIdleSystemSleep", Process: "caffeinate", PID: 877, Name: "caffeinate command-line tool", Age: time.Hour + 2*time.Minute + 3*time.Second},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d assertions, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("assertion %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got[1].PreventsSleep() || !got[0].PreventsSleep() {
+		t.Fatal("only the PreventUserIdleSystemSleep assertions keep the Mac awake")
+	}
+	if text := awakeText(got); text != "Kept awake by coreaudiod (PreventUserIdleSystemSleep), caffeinate (PreventUserIdleSystemSleep)" {
+		t.Fatalf("awakeText = %q", text)
+	}
+	if parsePMSetAssertions("Assertion status system-wide:\n   UserIsActive 0\n") != nil {
+		t.Fatal("no process section should give no assertions")
+	}
+}
@@ -4,100 +4,1024 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"iter"
+	"math"
 	"os"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	spPowerDataType    = "SPPowerDataType"
+	spHardwareDataType = "SPHardwareDataType"
 )
 
 var (
-	// Cache for heavy system_profiler output.
-	lastPowerAt   time.Time
-	cachedPower   string
-	powerCacheTTL = 30 * time.Second
+	// Cache for heavy system_profiler output, keyed by data type.
+	powerCacheTTL    = 30 * time.Second
+	hardwareCacheTTL = 10 * time.Minute
+	profilerCache    = newSystemProfilerCache(map[string]time.Duration{
+		spPowerDataType:    powerCacheTTL,
+		spHardwareDataType: hardwareCacheTTL,
+	})
 )
 
-func collectBatteries() (batts []BatteryStatus, err error) {
+type systemProfilerEntry struct {
+	output    string
+	fetchedAt time.Time
+}
+
+const (
+	// profilerRefreshLead is how long before expiry the background refresher re-fetches.
+	profilerRefreshLead = 5 * time.Second
+	// profilerRetryDelay spaces out background retries after a failed fetch.
+	profilerRetryDelay = 5 * time.Second
+)
+
+// systemProfilerCache holds system_profiler output per data type with per-key TTLs.
+type systemProfilerCache struct {
+	mu      sync.Mutex
+	ttls    map[string]time.Duration
+	entries map[string]systemProfilerEntry
+	fetch   func(ctx context.Context, dataType string) (string, error)
+	// flight runs one fetch per data type at a time, outside mu. gen counts
+	// resets, so a fetch that straddles one doesn't repopulate the cache.
+	flight singleflight.Group
+	gen    int
+
+	// Background refresher state; nil when not running.
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func newSystemProfilerCache(ttls map[string]time.Duration) *systemProfilerCache {
+	return &systemProfilerCache{
+		ttls:    ttls,
+		entries: make(map[string]systemProfilerEntry),
+		fetch: func(ctx context.Context, dataType string) (string, error) {
+			return runCmdEnv(ctx, englishLocaleEnv, "system_profiler", profilerArgs(dataType)...)
+		},
+	}
+}
+
+func (c *systemProfilerCache) ttl(dataType string) time.Duration {
+	if ttl, ok := c.ttls[dataType]; ok {
+		return ttl
+	}
+	return powerCacheTTL
+}
+
+// track adds dataType to the set the background refresher keeps warm.
+func (c *systemProfilerCache) track(dataType string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttls[dataType] = ttl
+}
+
+// get returns cached output for dataType, refreshing it once the TTL has expired.
+// On refresh failure the last good output is returned. Callers wanting the same
+// data type share one fetch; other data types and the cache's lock aren't held
+// up by it.
+func (c *systemProfilerCache) get(ctx context.Context, dataType string) string {
+	c.mu.Lock()
+	out, fresh := c.lookup(dataType, time.Now())
+	gen := c.gen
+	c.mu.Unlock()
+	if fresh {
+		return out
+	}
+
+	v, _, _ := c.flight.Do(dataType+"\x00"+strconv.Itoa(gen), func() (any, error) {
+		// A fetch that finished just before this flight began already has it.
+		c.mu.Lock()
+		out, fresh := c.lookup(dataType, time.Now())
+		c.mu.Unlock()
+		if fresh {
+			return out, nil
+		}
+
+		now := time.Now()
+		ctx, cancel := context.WithTimeout(ctx, collectOptions.profilerTimeout(systemPowerProfileTimeout))
+		defer cancel()
+		fetched, err := c.fetch(ctx, dataType)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err == nil && c.gen == gen {
+			c.entries[dataType] = systemProfilerEntry{output: fetched, fetchedAt: now}
+			return fetched, nil
+		}
+		return c.entries[dataType].output, nil
+	})
+	return v.(string)
+}
+
+// lookup returns the cached output for dataType and whether it is inside its
+// TTL. Caller holds mu.
+func (c *systemProfilerCache) lookup(dataType string, now time.Time) (string, bool) {
+	entry, ok := c.entries[dataType]
+	return entry.output, ok && entry.output != "" && now.Sub(entry.fetchedAt) < c.ttl(dataType)
+}
+
+// Invalidate marks the given data types stale, so the next get fetches them
+// afresh instead of waiting out the TTL. The old output is kept as the fallback
+// should that fetch fail.
+func (c *systemProfilerCache) Invalidate(dataTypes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, dataType := range dataTypes {
+		if entry, ok := c.entries[dataType]; ok {
+			entry.fetchedAt = time.Time{}
+			c.entries[dataType] = entry
+		}
+	}
+}
+
+// Start launches an opt-in background refresher that re-fetches each data type
+// shortly before its TTL expires, so get keeps hitting a warm cache instead of
+// stalling the unlucky caller. It runs until Stop is called or ctx is cancelled.
+func (c *systemProfilerCache) Start(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+	ctx, c.stop = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+	go c.refreshLoop(ctx, c.done)
+}
+
+// Stop halts the background refresher and waits for it to exit.
+func (c *systemProfilerCache) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+	if stop != nil {
+		stop()
+		<-done
+	}
+}
+
+// reset stops the refresher and drops every cached entry; TTLs are kept.
+func (c *systemProfilerCache) reset() {
+	c.Stop()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]systemProfilerEntry)
+	c.gen++
+}
+
+func (c *systemProfilerCache) refreshLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		c.refreshDue(ctx, time.Now())
+		timer.Reset(c.nextRefresh(time.Now()))
+	}
+}
+
+// refreshDue re-fetches every data type whose entry is about to expire. The
+// fetch runs without the lock so readers keep getting the still-valid entry.
+func (c *systemProfilerCache) refreshDue(ctx context.Context, now time.Time) {
+	c.mu.Lock()
+	var due []string
+	for dataType := range c.ttls {
+		if !now.Before(c.refreshAt(dataType)) {
+			due = append(due, dataType)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, dataType := range due {
+		fetchCtx, cancel := context.WithTimeout(ctx, collectOptions.profilerTimeout(systemPowerProfileTimeout))
+		out, err := c.fetch(fetchCtx, dataType)
+		cancel()
+		if err != nil {
+			continue
+		}
+		c.mu.Lock()
+		c.entries[dataType] = systemProfilerEntry{output: out, fetchedAt: time.Now()}
+		c.mu.Unlock()
+	}
+}
+
+// nextRefresh returns how long the refresher should sleep before the next entry is due.
+func (c *systemProfilerCache) nextRefresh(now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wait := time.Duration(-1)
+	for dataType := range c.ttls {
+		d := c.refreshAt(dataType).Sub(now)
+		if wait < 0 || d < wait {
+			wait = d
+		}
+	}
+	return max(wait, profilerRetryDelay)
+}
+
+// refreshAt is when the background refresher should re-fetch dataType. Caller holds mu.
+func (c *systemProfilerCache) refreshAt(dataType string) time.Time {
+	entry, ok := c.entries[dataType]
+	if !ok || entry.output == "" {
+		return time.Time{}
+	}
+	ttl := c.ttl(dataType)
+	lead := profilerRefreshLead
+	if lead >= ttl {
+		lead = ttl / 2
+	}
+	return entry.fetchedAt.Add(ttl - lead)
+}
+
+var (
+	// ErrNoBattery means the machine has no battery hardware (e.g. Mac mini, desktop PC).
+	ErrNoBattery = errors.New("no battery present")
+	// ErrBatteryUnreadable means a battery exists but the OS returned no usable data.
+	ErrBatteryUnreadable = errors.New("battery present but unreadable")
+	// ErrBatteryPermission is an unreadable battery whose sysfs files returned EACCES.
+	ErrBatteryPermission = fmt.Errorf("%w: permission denied", ErrBatteryUnreadable)
+)
+
+func collectBatteries(ctx context.Context) (batts []BatteryStatus, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			// Swallow panics to keep UI alive.
+			// Swallow panics to keep UI alive; batts keeps whatever was
+			// gathered before the panic.
 			err = fmt.Errorf("battery collection failed: %v", r)
 		}
 	}()
 
-	// macOS: pmset for real-time percentage/status.
-	if runtime.GOOS == "darwin" && commandExists("pmset") {
-		if out, err := runCmd(context.Background(), "pmset", "-g", "batt"); err == nil {
-			// Health/cycles/capacity from cached system_profiler.
-			health, cycles, capacity := getCachedPowerData()
-			if batts := parsePMSet(out, health, cycles, capacity); len(batts) > 0 {
-				return batts, nil
+	// Try each source in order until one yields batteries. Append as we go so a
+	// panic part-way through a source still returns the earlier batteries.
+	present := false
+	chain := activeBatteryChain(probeOS)
+	for _, src := range chain {
+		if ctx.Err() != nil {
+			// Cancelled between sources: don't misreport the battery as missing.
+			return batts, ctx.Err()
+		}
+		if src.read(ctx, func(b BatteryStatus) { batts = append(batts, b) }) {
+			present = true
+		}
+		if len(batts) > 0 {
+			return dedupeBatteries(batts), nil
+		}
+	}
+
+	if slices.ContainsFunc(chain, func(src batterySource) bool { return src.name == "sysfs" }) &&
+		powerSupplyPermissionDenied(powerSupplyRoot) {
+		return nil, ErrBatteryPermission
+	}
+	if present {
+		return nil, ErrBatteryUnreadable
+	}
+	return nil, ErrNoBattery
+}
+
+// pmsetTimeout bounds `pmset -g batt`, which can hang under some kernel states.
+const pmsetTimeout = time.Second
+
+// pmsetBatteries reads batteries from pmset, with health from power (normally the
+// cached system_profiler data; only fetched once pmset succeeds) and voltage and raw
+// capacity from ioreg. present reports battery hardware even when nothing parsed.
+func pmsetBatteries(ctx context.Context, power func(context.Context) []powerData) (batts []BatteryStatus, present bool) {
+	pmsetCtx, cancel := context.WithTimeout(ctx, pmsetTimeout)
+	out, err := runCmd(pmsetCtx, "pmset", "-g", "batt")
+	cancel()
+	if err == nil {
+		if batts = parsePMSet(out, power(ctx)); len(batts) > 0 {
+			if pmsetPowerState.flipped(batts) {
+				// Plugged in or unplugged: cycle count, condition and fan data from
+				// before the switch would otherwise linger for up to powerCacheTTL.
+				profilerCache.Invalidate(spPowerDataType, spPowerDataTypeXML)
+				batts = parsePMSet(out, power(ctx))
 			}
+			applySmartBatteryDetails(ctx, batts)
+			return batts, true
 		}
 	}
+	// A failing pmset or an unparsable battery line both mean the hardware is there.
+	return nil, err != nil || pmsetHasBattery(out)
+}
 
-	// Linux: /sys/class/power_supply.
-	matches, _ := filepath.Glob("/sys/class/power_supply/BAT*/capacity")
-	for _, capFile := range matches {
-		statusFile := filepath.Join(filepath.Dir(capFile), "status")
-		capData, err := os.ReadFile(capFile)
-		if err != nil {
+// powerStateWatch remembers whether the primary battery was discharging on the
+// last pmset read, so a plug or unplug can be told from steady state.
+type powerStateWatch struct {
+	mu          sync.Mutex
+	seen        bool
+	discharging bool
+}
+
+// pmsetPowerState is the watch pmsetBatteries uses to invalidate profilerCache.
+var pmsetPowerState powerStateWatch
+
+// flipped records batts' state and reports whether it changed since the last
+// call. The first call only records.
+func (w *powerStateWatch) flipped(batts []BatteryStatus) bool {
+	discharging := strings.EqualFold(batts[0].Status, "discharging")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	changed := w.seen && w.discharging != discharging
+	w.seen, w.discharging = true, discharging
+	return changed
+}
+
+func (w *powerStateWatch) reset() {
+	w.mu.Lock()
+	w.seen, w.discharging = false, false
+	w.mu.Unlock()
+}
+
+// smartBatteryProps are the AppleSmartBattery registry values read through IOKit.
+type smartBatteryProps struct {
+	CurrentCapacity    int64 // Percent on Apple Silicon, mAh on Intel
+	MaxCapacity        int64 // 100 on Apple Silicon, mAh on Intel
+	DesignCapacity     int64 // mAh
+	RawMaxCapacity     int64 // mAh
+	RawCurrentCapacity int64 // mAh
+	CycleCount         int64
+	VoltageMV          int64
+	AmperageMA         int64 // Time-averaged, negative while discharging
+	InstantAmperageMA  int64 // Momentary, negative while discharging
+	MaxChargeCurrentMA int64 // Charge current cap; 0 when not reported
+	TimeRemainingMin   int64 // 65535 while the estimate is still settling
+	IsCharging         bool
+	ExternalConnected  bool
+	FullyCharged       bool
+	Controller         BatteryController
+}
+
+// smartBatteryTimeUnknown is TimeRemaining while macOS is still calculating.
+const smartBatteryTimeUnknown = 65535
+
+// status converts registry values into the same shape parsePMSet produces. Health is
+// derived from capacity (Apple flags service below 80%) so system_profiler isn't needed.
+func (p smartBatteryProps) status() (BatteryStatus, bool) {
+	if p.MaxCapacity <= 0 {
+		return BatteryStatus{}, false
+	}
+	percent, ok := normalizeBatteryPercent(float64(p.CurrentCapacity) * 100 / float64(p.MaxCapacity))
+	if !ok {
+		return BatteryStatus{}, false
+	}
+	b := BatteryStatus{
+		Name:       "InternalBattery-0",
+		Percent:    percent,
+		CycleCount: int(p.CycleCount),
+		VoltageV:   FromMilli(p.VoltageMV),
+		CurrentA:   FromMilli(p.AmperageMA),
+		Source:     "iokit",
+	}
+	b.AverageCurrentA, b.InstantCurrentA = b.CurrentA, FromMilli(p.InstantAmperageMA)
+	if p.MaxChargeCurrentMA > 0 {
+		b.MaxChargeCurrentA = FromMilli(p.MaxChargeCurrentMA)
+	}
+	switch {
+	case p.FullyCharged:
+		b.Status = "charged"
+	case p.IsCharging:
+		b.Status = "charging"
+	case p.ExternalConnected:
+		b.Status = "not charging"
+	default:
+		b.Status = "discharging"
+	}
+	b.settleNotCharging()
+	if p.TimeRemainingMin > 0 && p.TimeRemainingMin != smartBatteryTimeUnknown && b.Status != "charged" {
+		b.TimeLeft = fmt.Sprintf("%d:%02d", p.TimeRemainingMin/60, p.TimeRemainingMin%60)
+	}
+	full := p.RawMaxCapacity
+	if full <= 0 && p.MaxCapacity > 100 {
+		full = p.MaxCapacity // Intel reports mAh directly
+	}
+	if p.DesignCapacity > 0 && full > 0 {
+		b.Capacity = int(min(full*100/p.DesignCapacity, 100))
+		b.Health = "Normal"
+		if b.Capacity < 80 {
+			b.Health = "Service Recommended"
+		}
+		b.DesignCapacity = float64(p.DesignCapacity)
+		b.FullChargeCapacity = float64(full)
+		b.CurrentCharge = float64(p.RawCurrentCapacity)
+		b.CapacityUnit = CapacityMAh
+	}
+	b.setController(p.Controller)
+	return b, true
+}
+
+// applySmartBatteryDetails fills voltage, current and raw capacities on the internal
+// battery from ioreg; pmset reports none of them.
+func applySmartBatteryDetails(ctx context.Context, batts []BatteryStatus) {
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.commandTimeout(quickQueryTimeout))
+	defer cancel()
+	out, err := runCmd(ctx, "ioreg", "-rn", "AppleSmartBattery")
+	if err != nil {
+		return
+	}
+	for i := range batts {
+		if isInternalBattery(batts[i].Name) || len(batts) == 1 {
+			batts[i].VoltageV = parseSmartBatteryVoltage(out)
+			parseSmartBatteryCapacity(out, &batts[i])
+			parseSmartBatteryCurrent(out, &batts[i])
+			batts[i].setCellVoltages(parseSmartBatteryCells(out))
+			batts[i].setController(parseSmartBatteryController(out))
+			return
+		}
+	}
+}
+
+// parseSmartBatteryVoltage reads the top-level "Voltage" (mV) from ioreg AppleSmartBattery output.
+func parseSmartBatteryVoltage(out string) float64 {
+	if mv, ok := smartBatteryInt(out, "Voltage"); ok && mv > 0 {
+		return FromMilli(mv)
+	}
+	return 0
+}
+
+// parseSmartBatteryCapacity reads mAh figures. On Apple Silicon MaxCapacity and
+// CurrentCapacity are percentages, so the AppleRaw* keys are the real charge.
+func parseSmartBatteryCapacity(out string, b *BatteryStatus) {
+	design, _ := smartBatteryInt(out, "DesignCapacity")
+	full, _ := smartBatteryInt(out, "AppleRawMaxCapacity")
+	now, _ := smartBatteryInt(out, "AppleRawCurrentCapacity")
+	if design <= 0 && full <= 0 {
+		return
+	}
+	b.DesignCapacity = float64(design)
+	b.FullChargeCapacity = float64(full)
+	b.CurrentCharge = float64(now)
+	b.CapacityUnit = CapacityMAh
+}
+
+// parseSmartBatteryCurrent reads the time-averaged "Amperage", the momentary
+// "InstantAmperage" and the "MaxChargeCurrent" cap (mA). CurrentA takes the
+// averaged one, as the iokit source does.
+func parseSmartBatteryCurrent(out string, b *BatteryStatus) {
+	if ma, ok := smartBatteryInt(out, "Amperage"); ok {
+		b.AverageCurrentA = FromMilli(ma)
+		b.CurrentA = b.AverageCurrentA
+	}
+	if ma, ok := smartBatteryInt(out, "InstantAmperage"); ok {
+		b.InstantCurrentA = FromMilli(ma)
+	}
+	if ma, ok := smartBatteryInt(out, "MaxChargeCurrent"); ok && ma > 0 {
+		b.MaxChargeCurrentA = FromMilli(ma)
+	}
+}
+
+// smartBatteryInt reads a top-level `"Key" = 123` line; nested dictionary entries are ignored.
+// ioreg prints negative values as their uint64 two's complement, so those are cast back.
+func smartBatteryInt(out, key string) (int64, bool) {
+	prefix := "\"" + key + "\" = "
+	for line := range strings.Lines(out) {
+		after, found := strings.CutPrefix(strings.TrimSpace(line), prefix)
+		if !found {
 			continue
 		}
-		statusData, _ := os.ReadFile(statusFile)
-		percentStr := strings.TrimSpace(string(capData))
-		percent, _ := strconv.ParseFloat(percentStr, 64)
-		status := strings.TrimSpace(string(statusData))
-		if status == "" {
-			status = "Unknown"
+		after = strings.TrimSpace(after)
+		if v, err := strconv.ParseInt(after, 10, 64); err == nil {
+			return v, true
 		}
-		batts = append(batts, BatteryStatus{
-			Percent: percent,
-			Status:  status,
-		})
+		u, err := strconv.ParseUint(after, 10, 64)
+		return int64(u), err == nil
 	}
-	if len(batts) > 0 {
-		return batts, nil
+	return 0, false
+}
+
+// readPowerSupplyCapacity reads design/full/now figures, preferring charge_* (µAh)
+// and falling back to energy_* (µWh), since drivers expose one pair or the other.
+func readPowerSupplyCapacity(dir string, b *BatteryStatus) {
+	for _, src := range []struct {
+		prefix string
+		unit   CapacityUnit
+		scale  func(int64) float64
+	}{
+		{"charge", CapacityMAh, func(v int64) float64 { return float64(v) / 1000 }}, // µAh → mAh
+		{"energy", CapacityWh, FromMicro},                                           // µWh → Wh
+	} {
+		full, ok := readSysfsInt(filepath.Join(dir, src.prefix+"_full"))
+		if !ok {
+			continue
+		}
+		design, _ := readSysfsInt(filepath.Join(dir, src.prefix+"_full_design"))
+		now, _ := readSysfsInt(filepath.Join(dir, src.prefix+"_now"))
+		b.DesignCapacity = src.scale(design)
+		b.FullChargeCapacity = src.scale(full)
+		b.CurrentCharge = src.scale(now)
+		b.CapacityUnit = src.unit
+		return
 	}
+}
 
-	return nil, errors.New("no battery data found")
+// pmsetHasBattery reports whether pmset listed any battery, parsable or not.
+func pmsetHasBattery(raw string) bool {
+	return strings.Contains(raw, "Battery-")
 }
 
-func parsePMSet(raw string, health string, cycles int, capacity int) []BatteryStatus {
-	var out []BatteryStatus
-	var timeLeft string
+// powerSupplyHasBattery reports whether sysfs exposes any battery power supply;
+// see isPowerSupplyBattery.
+func powerSupplyHasBattery(root string) bool {
+	return len(powerSupplyBatteryDirs(root)) > 0
+}
 
-	for line := range strings.Lines(raw) {
-		// Time remaining.
-		if strings.Contains(line, "remaining") {
-			parts := strings.Fields(line)
-			for i, p := range parts {
-				if p == "remaining" && i > 0 {
-					timeLeft = parts[i-1]
-				}
+// powerSupplyPermissionDenied reports whether any battery capacity file is unreadable due to permissions.
+func powerSupplyPermissionDenied(root string) bool {
+	for _, dir := range powerSupplyBatteryDirs(root) {
+		if _, err := os.ReadFile(filepath.Join(dir, "capacity")); errors.Is(err, fs.ErrPermission) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	powerSupplyRoot = "/sys/class/power_supply"
+
+	// Readings slightly above 100% happen right after calibration; anything beyond is garbage.
+	batteryPercentSlack = 5.0
+)
+
+// readPowerSupplyBatteries reads the battery entries under a sysfs power_supply root.
+func readPowerSupplyBatteries(root string) []BatteryStatus {
+	return slices.Collect(powerSupplyBatteries(root))
+}
+
+// powerSupplyBatteries yields battery entries one at a time so a caller can keep
+// the batteries read before a later entry fails. Entries are classified by their
+// type file (see isPowerSupplyBattery) on every call and never cached, so
+// hot-plugged packs and docks appear on the next collection.
+func powerSupplyBatteries(root string) iter.Seq[BatteryStatus] {
+	return func(yield func(BatteryStatus) bool) {
+		for _, dir := range powerSupplyBatteryDirs(root) {
+			capFile := filepath.Join(dir, "capacity")
+			statusFile := filepath.Join(dir, "status")
+			capData, err := os.ReadFile(capFile)
+			if err != nil {
+				continue
+			}
+			statusData, _ := os.ReadFile(statusFile)
+			percentStr := strings.TrimSpace(string(capData))
+			percent, err := strconv.ParseFloat(percentStr, 64)
+			if err != nil {
+				continue
+			}
+			percent, ok := normalizeBatteryPercent(percent)
+			if !ok {
+				continue
+			}
+			status := strings.TrimSpace(string(statusData))
+			if status == "" {
+				status = "Unknown"
 			}
+			b := BatteryStatus{
+				Name:    filepath.Base(dir),
+				Percent: percent,
+				Status:  status,
+				Source:  "sysfs",
+			}
+			if uv, ok := readSysfsInt(filepath.Join(dir, "voltage_now")); ok && uv > 0 {
+				b.VoltageV = FromMicro(uv)
+			}
+			readPowerSupplyCurrent(dir, &b)
+			readPowerSupplyCapacity(dir, &b)
+			b.setCellVoltages(readPowerSupplyCells(dir))
+			b.setController(readPowerSupplyController(dir))
+			if limit, ok := readSysfsInt(filepath.Join(dir, "charge_control_end_threshold")); ok {
+				b.setChargeLimit(float64(limit))
+			}
+			b.settleNotCharging()
+			readPowerSupplyTimeLeft(dir, &b)
+			if !yield(b) {
+				return
+			}
+		}
+	}
+}
+
+// setChargeLimit records a charge cap below 100%. Thresholds of 100 or out of
+// range mean no limit, so EffectiveFullPercent stays unset.
+func (b *BatteryStatus) setChargeLimit(limit float64) {
+	if limit <= 0 || limit >= 100 {
+		return
+	}
+	b.ChargeLimited = true
+	b.EffectiveFullPercent = limit
+}
+
+// settleNotCharging turns "not charging" into a full status once the battery is at its
+// effective full: 100%, or the charge limit when one is known. pmset and some
+// Linux drivers report "not charging" right after topping off, which reads as a
+// fault on a healthy battery. Below full, "not charging" is kept: the battery is
+// held there by a limit the OS didn't expose, by thermal hold, or by a fault.
+func (b *BatteryStatus) settleNotCharging() {
+	if !strings.EqualFold(b.Status, "not charging") {
+		return
+	}
+	full := 100.0
+	if b.ChargeLimited && b.EffectiveFullPercent > 0 {
+		full = b.EffectiveFullPercent
+	}
+	if b.Percent >= full-fullChargeSlack {
+		// Keep each source's own vocabulary: sysfs and wmi say "Full", pmset "charged".
+		b.Status = "charged"
+		if b.Source == "sysfs" || b.Source == "wmi" {
+			b.Status = "Full"
 		}
+	}
+}
+
+// fullChargeSlack lets a battery that stopped at 99% of its target count as full.
+const fullChargeSlack = 1.0
 
-		if !strings.Contains(line, "%") {
+// BatteryState is Status normalised across sources, with one state no OS reports
+// directly: Bypass, where the machine runs straight off AC and the battery is held
+// below full, neither charging nor discharging.
+type BatteryState string
+
+const (
+	BatteryStateUnknown     BatteryState = ""
+	BatteryStateCharging    BatteryState = "Charging"
+	BatteryStateDischarging BatteryState = "Discharging"
+	BatteryStateFull        BatteryState = "Full"
+	BatteryStateBypass      BatteryState = "Bypass"
+)
+
+// bypassCurrentA is the charge current below which a "charging" battery short of
+// full is really being held; firmware at a charge limit often trickles a few mA.
+const bypassCurrentA = 0.05
+
+// batteryState classifies b. A zero CurrentA means the current wasn't reported, so
+// a "charging" battery is only treated as held when a trickle was measured.
+func batteryState(b BatteryStatus) BatteryState {
+	switch strings.ToLower(b.Status) {
+	case "charging", "finishing charge":
+		if b.CurrentA > 0 && b.CurrentA < bypassCurrentA && !b.atFull() {
+			return BatteryStateBypass
+		}
+		return BatteryStateCharging
+	case "discharging":
+		return BatteryStateDischarging
+	case "charged", "full":
+		return BatteryStateFull
+	case "not charging":
+		// settleNotCharging has already turned "not charging" at full into a full
+		// status, so what's left is held below full: by a limit the OS didn't
+		// expose, a dock, thermal hold, or a fault.
+		return BatteryStateBypass
+	}
+	return BatteryStateUnknown
+}
+
+// setBatteryStates fills State in place.
+func setBatteryStates(batts []BatteryStatus) {
+	for i := range batts {
+		batts[i].State = batteryState(batts[i])
+	}
+}
+
+// ChargeAnimation is the state a UI needs to animate a filling battery: fill from
+// Percent toward Target while Filling, and hold still otherwise. Target is the charge
+// limit when one is active, so the animation stops at 80% rather than implying 100%.
+type ChargeAnimation struct {
+	Filling bool
+	Percent float64
+	Target  float64
+}
+
+// ChargeAnimation derives animation state from Status, Percent and the charge limit.
+// "charged" and "not charging" are on external power but not filling, and a battery
+// already at its limit is never shown as filling even if the OS still says charging.
+func (b BatteryStatus) ChargeAnimation() ChargeAnimation {
+	a := ChargeAnimation{Percent: b.Percent, Target: 100}
+	if b.ChargeLimited && b.EffectiveFullPercent > 0 {
+		a.Target = b.EffectiveFullPercent
+	}
+	switch strings.ToLower(b.Status) {
+	case "charging", "finishing charge":
+		a.Filling = b.Percent < a.Target
+	}
+	return a
+}
+
+// readPowerSupplyCurrent sets CurrentA from current_now, or from power_now ÷ voltage on
+// energy-based batteries. Drivers disagree on the sign, so it is taken from Status instead.
+// constant_charge_current_max, where the driver has it, is the charge current cap.
+func readPowerSupplyCurrent(dir string, b *BatteryStatus) {
+	var amps float64
+	if ua, ok := readSysfsInt(filepath.Join(dir, "current_now")); ok && ua != 0 {
+		amps = math.Abs(FromMicro(ua))
+	} else if uw, ok := readSysfsInt(filepath.Join(dir, "power_now")); ok && uw != 0 && b.VoltageV > 0 {
+		amps = math.Abs(FromMicro(uw)) / b.VoltageV
+	}
+	if strings.EqualFold(b.Status, "discharging") {
+		amps = -amps
+	}
+	b.CurrentA = amps
+	if ua, ok := readSysfsInt(filepath.Join(dir, "constant_charge_current_max")); ok && ua > 0 {
+		b.MaxChargeCurrentA = FromMicro(ua)
+	}
+}
+
+// readPowerSupplyTimeLeft sets TimeLeft to time-to-empty while discharging, or
+// time-to-full while charging, as "H:MM" like pmset. It divides energy_* (µWh) by
+// power_now (µW), falling back to charge_* (µAh) over current_now (µA), since
+// drivers expose one pair or the other. An idle battery (zero rate) gets no
+// estimate. Watts is set from power_now regardless.
+func readPowerSupplyTimeLeft(dir string, b *BatteryStatus) {
+	if uw, ok := readSysfsInt(filepath.Join(dir, "power_now")); ok && uw != 0 {
+		b.Watts = math.Abs(FromMicro(uw))
+	}
+	charging := strings.EqualFold(b.Status, "charging")
+	if !charging && !strings.EqualFold(b.Status, "discharging") {
+		return
+	}
+	for _, pair := range [][2]string{{"energy", "power_now"}, {"charge", "current_now"}} {
+		now, ok := readSysfsInt(filepath.Join(dir, pair[0]+"_now"))
+		if !ok {
+			continue
+		}
+		rate, ok := readSysfsInt(filepath.Join(dir, pair[1]))
+		if !ok || rate == 0 {
 			continue
 		}
+		remaining := now
+		if charging {
+			full, ok := readSysfsInt(filepath.Join(dir, pair[0]+"_full"))
+			if !ok || full <= now {
+				continue
+			}
+			remaining = full - now
+		}
+		minutes := int(math.Round(float64(remaining) / math.Abs(float64(rate)) * 60))
+		if minutes > 0 {
+			b.TimeLeft = fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+		}
+		return
+	}
+}
+
+// readSysfsInt reads a single integer attribute such as voltage_now.
+func readSysfsInt(path string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return v, err == nil
+}
+
+// wornThresholdPercent is the health below which a battery counts as worn; 80 matches Apple's service threshold.
+var wornThresholdPercent = 80.0
+
+// HealthPercent is full-charge capacity as a share of design capacity. Raw figures
+// win over the rounded Capacity; ok is false when design capacity is unknown.
+func (b BatteryStatus) HealthPercent() (float64, bool) {
+	if b.DesignCapacity > 0 && b.FullChargeCapacity > 0 {
+		return min(b.FullChargeCapacity/b.DesignCapacity*100, 100), true
+	}
+	if b.Capacity > 0 {
+		return float64(b.Capacity), true
+	}
+	return 0, false
+}
+
+// markWornBatteries sets Worn in place from HealthPercent, so a battery without a
+// design capacity still warns on the Capacity percent the OS reported; only one
+// with neither never warns.
+func markWornBatteries(batts []BatteryStatus) {
+	for i := range batts {
+		health, ok := batts[i].HealthPercent()
+		batts[i].Worn = ok && health < wornThresholdPercent
+	}
+}
+
+// setCapacityPercents fills the whole-percent Capacity in place from raw design and
+// full-charge figures for sources that report only those (sysfs, ACPI, UPower,
+// WMI), so the health bar shows on every platform. A Capacity the OS already
+// reported, such as macOS's "Maximum Capacity", is kept.
+func setCapacityPercents(batts []BatteryStatus) {
+	for i := range batts {
+		b := &batts[i]
+		if b.Capacity == 0 && b.DesignCapacity > 0 && b.FullChargeCapacity > 0 {
+			b.Capacity = int(math.Round(min(b.FullChargeCapacity/b.DesignCapacity*100, 100)))
+		}
+	}
+}
+
+// precisePercentTolerance is how far the charge ratio may sit from the OS percent
+// before it is distrusted; some drivers calibrate capacity differently from charge_now.
+const precisePercentTolerance = 2.0
+
+// setPrecisePercents fills PercentPrecise in place from the raw charge figures.
+func setPrecisePercents(batts []BatteryStatus) {
+	for i := range batts {
+		b := &batts[i]
+		b.PercentPrecise = 0
+		if b.Kind == BatteryKindUPS || b.CurrentCharge <= 0 || b.FullChargeCapacity <= 0 {
+			continue
+		}
+		pct := min(b.CurrentCharge/b.FullChargeCapacity*100, 100)
+		if math.Abs(pct-b.Percent) <= precisePercentTolerance {
+			b.PercentPrecise = pct
+		}
+	}
+}
+
+// DisplayPercent is PercentPrecise when known, else Percent.
+func (b BatteryStatus) DisplayPercent() float64 {
+	if b.PercentPrecise > 0 {
+		return b.PercentPrecise
+	}
+	return b.Percent
+}
+
+// capacityDisplayUnit is the unit battery capacities are shown and exported in;
+// empty keeps each battery's reported unit. Set from --capacity-unit.
+var capacityDisplayUnit CapacityUnit
+
+// setCapacityDisplayUnit parses "auto", "mah" or "wh" (any case).
+func setCapacityDisplayUnit(v string) error {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "auto":
+		capacityDisplayUnit = ""
+	case "mah":
+		capacityDisplayUnit = CapacityMAh
+	case "wh":
+		capacityDisplayUnit = CapacityWh
+	default:
+		return fmt.Errorf("unknown capacity unit %q (want auto, mAh or Wh)", v)
+	}
+	return nil
+}
+
+// BatteryCapacity is a battery's raw capacity figures in one unit.
+type BatteryCapacity struct {
+	Design  float64
+	Full    float64
+	Current float64
+	Unit    CapacityUnit
+}
+
+// CapacityIn returns the raw capacities converted to unit, or in the reported
+// unit when unit is empty. ok is false when there are no raw figures or the
+// conversion needs a voltage the battery didn't report.
+func (b BatteryStatus) CapacityIn(unit CapacityUnit) (BatteryCapacity, bool) {
+	if b.CapacityUnit == "" || b.FullChargeCapacity <= 0 {
+		return BatteryCapacity{}, false
+	}
+	if unit == "" {
+		unit = b.CapacityUnit
+	}
+	c := BatteryCapacity{Unit: unit}
+	var ok bool
+	if c.Full, ok = ConvertCapacity(b.FullChargeCapacity, b.CapacityUnit, unit, b.VoltageV); !ok {
+		return BatteryCapacity{}, false
+	}
+	c.Design, _ = ConvertCapacity(b.DesignCapacity, b.CapacityUnit, unit, b.VoltageV)
+	c.Current, _ = ConvertCapacity(b.CurrentCharge, b.CapacityUnit, unit, b.VoltageV)
+	return c, true
+}
+
+// DisplayCapacity is CapacityIn(capacityDisplayUnit), falling back to the
+// reported unit when the preferred one can't be reached.
+func (b BatteryStatus) DisplayCapacity() (BatteryCapacity, bool) {
+	if c, ok := b.CapacityIn(capacityDisplayUnit); ok {
+		return c, true
+	}
+	return b.CapacityIn("")
+}
+
+// estimateFromInstantCurrent bases estimates on the momentary current rather than
+// the averaged one; set from --instant-current.
+var estimateFromInstantCurrent bool
+
+// estimateCurrentA is the current that time and power estimates use: the averaged
+// reading by default, since a momentary spike would swing the estimate, or the instant
+// one with --instant-current. Sources without the split fall back to CurrentA.
+func (b BatteryStatus) estimateCurrentA() float64 {
+	if estimateFromInstantCurrent && b.InstantCurrentA != 0 {
+		return b.InstantCurrentA
+	}
+	if b.AverageCurrentA != 0 {
+		return b.AverageCurrentA
+	}
+	return b.CurrentA
+}
+
+// ChargeCurrentUtilization is the charge current as a share of MaxChargeCurrentA,
+// e.g. 0.5 for "charging at 1.5A of 3A max". ok is false unless the battery is
+// charging and both currents are known.
+func (b BatteryStatus) ChargeCurrentUtilization() (float64, bool) {
+	if !strings.EqualFold(b.Status, "charging") || b.MaxChargeCurrentA <= 0 || b.CurrentA <= 0 {
+		return 0, false
+	}
+	return b.CurrentA / b.MaxChargeCurrentA, true
+}
+
+// ComputeTimeToEmpty derives time to empty from remaining charge and V × I, independent of
+// the OS's smoothed TimeLeft. ok is false unless the battery is discharging and voltage,
+// current and remaining charge are all known.
+func (b BatteryStatus) ComputeTimeToEmpty() (time.Duration, bool) {
+	current := b.estimateCurrentA()
+	if !strings.EqualFold(b.Status, "discharging") || b.VoltageV <= 0 || current == 0 || b.CurrentCharge <= 0 {
+		return 0, false
+	}
+	var remainingWh float64
+	switch b.CapacityUnit {
+	case CapacityWh:
+		remainingWh = b.CurrentCharge
+	case CapacityMAh:
+		remainingWh = MAhToWh(b.CurrentCharge, b.VoltageV)
+	default:
+		return 0, false
+	}
+	watts := b.VoltageV * math.Abs(current)
+	return time.Duration(remainingWh / watts * float64(time.Hour)).Round(time.Minute), true
+}
+
+// estimateTimeToEmpty sets ComputedTimeLeft in place wherever it can be derived.
+func estimateTimeToEmpty(batts []BatteryStatus) {
+	for i := range batts {
+		batts[i].ComputedTimeLeft, _ = batts[i].ComputeTimeToEmpty()
+	}
+}
+
+// primaryBatteryName pins the battery used for summary fields; empty means auto.
+var primaryBatteryName string
+
+// PrimaryBattery returns the battery that drives top-line displays.
+func (m MetricsSnapshot) PrimaryBattery() (BatteryStatus, bool) {
+	return primaryBattery(m.Batteries)
+}
+
+// primaryBattery prefers the user-pinned battery, then the first internal one, then the first listed.
+func primaryBattery(batts []BatteryStatus) (BatteryStatus, bool) {
+	if len(batts) == 0 {
+		return BatteryStatus{}, false
+	}
+	if primaryBatteryName != "" {
+		for _, b := range batts {
+			if strings.EqualFold(b.Name, primaryBatteryName) {
+				return b, true
+			}
+		}
+	}
+	for _, b := range batts {
+		if isInternalBattery(b.Name) {
+			return b, true
+		}
+	}
+	return batts[0], true
+}
+
+func isInternalBattery(name string) bool {
+	return strings.HasPrefix(name, "InternalBattery") || strings.HasPrefix(name, "BAT")
+}
+
+// normalizeBatteryPercent clamps a raw reading to [0,100] and rejects bogus values.
+func normalizeBatteryPercent(percent float64) (float64, bool) {
+	if percent < 0 || percent > 100+batteryPercentSlack {
+		return 0, false
+	}
+	return min(percent, 100), true
+}
+
+func parsePMSet(raw string, power []powerData) []BatteryStatus {
+	var out []BatteryStatus
+
+	// ordinal counts every battery line, parsed or not, since system_profiler
+	// lists the same batteries in the same order: one unreadable line must not
+	// shift the health data of the batteries after it.
+	ordinal := -1
+	for line := range strings.Lines(raw) {
+		if !strings.Contains(line, "%") && !strings.HasPrefix(strings.TrimSpace(line), "-") {
+			continue
+		}
+		ordinal++
 		fields := strings.Fields(line)
 		var (
 			percent float64
 			found   bool
-			status  = "Unknown"
 		)
-		for i, f := range fields {
+		for _, f := range fields {
 			if strings.Contains(f, "%") {
 				value := strings.TrimSuffix(strings.TrimSuffix(f, ";"), "%")
 				if p, err := strconv.ParseFloat(value, 64); err == nil {
-					percent = p
-					found = true
-					if i+1 < len(fields) {
-						status = strings.TrimSuffix(fields[i+1], ";")
-					}
+					percent, found = normalizeBatteryPercent(p)
 				}
 				break
 			}
@@ -105,79 +1029,188 @@ func parsePMSet(raw string, health string, cycles int, capacity int) []BatterySt
 		if !found {
 			continue
 		}
+		status := pmsetStatus(line)
+		var name string
+		if strings.HasPrefix(fields[0], "-") {
+			name = strings.TrimPrefix(fields[0], "-")
+		}
 
+		var pd powerData
+		if ordinal < len(power) {
+			pd = power[ordinal]
+		}
 		out = append(out, BatteryStatus{
+			Name:       name,
 			Percent:    percent,
 			Status:     status,
-			TimeLeft:   timeLeft,
-			Health:     health,
-			CycleCount: cycles,
-			Capacity:   capacity,
+			TimeLeft:   pmsetTimeLeft(fields),
+			Health:     pd.Health,
+			CycleCount: pd.Cycles,
+			Capacity:   pd.Capacity,
+			Source:     "pmset",
 		})
+		out[len(out)-1].settleNotCharging()
 	}
 	return out
 }
 
-// getCachedPowerData returns condition, cycles, and capacity from cached system_profiler.
-func getCachedPowerData() (health string, cycles int, capacity int) {
-	out := getSystemPowerOutput()
-	if out == "" {
-		return "", 0, 0
+// pmsetTimeLeft returns the "3:21" before "remaining" on one battery's line, or
+// "" when that battery has no estimate. Each battery carries its own, so the
+// lookup never crosses lines.
+func pmsetTimeLeft(fields []string) string {
+	for i, f := range fields {
+		if f == "remaining" && i > 0 {
+			return fields[i-1]
+		}
 	}
+	return ""
+}
 
-	for line := range strings.Lines(out) {
-		lower := strings.ToLower(line)
-		if strings.Contains(lower, "cycle count") {
-			if _, after, found := strings.Cut(line, ":"); found {
-				cycles, _ = strconv.Atoi(strings.TrimSpace(after))
-			}
-		}
-		if strings.Contains(lower, "condition") {
-			if _, after, found := strings.Cut(line, ":"); found {
-				health = strings.TrimSpace(after)
+// pmsetStatusKeywords are matched in order, so longer phrases must precede their substrings.
+var pmsetStatusKeywords = []string{
+	"finishing charge",
+	"not charging",
+	"discharging",
+	"charging",
+	"charged",
+	"ac attached",
+}
+
+// pmsetStatus finds the battery state keyword anywhere on a pmset battery line.
+func pmsetStatus(line string) string {
+	lower := strings.ToLower(line)
+	for _, keyword := range pmsetStatusKeywords {
+		if strings.Contains(lower, keyword) {
+			if keyword == "ac attached" {
+				return "AC attached"
 			}
+			return keyword
 		}
-		if strings.Contains(lower, "maximum capacity") {
-			if _, after, found := strings.Cut(line, ":"); found {
-				capacityStr := strings.TrimSpace(after)
-				capacityStr = strings.TrimSuffix(capacityStr, "%")
-				capacity, _ = strconv.Atoi(strings.TrimSpace(capacityStr))
+	}
+	return "Unknown"
+}
+
+// powerData is one battery's health section from SPPowerDataType.
+type powerData struct {
+	Health   string
+	Cycles   int
+	Capacity int
+}
+
+// getCachedPowerData returns per-battery health sections from cached system_profiler,
+// preferring the XML report when --profiler-xml is set.
+func getCachedPowerData(ctx context.Context) []powerData {
+	if useProfilerXML {
+		if out := getSystemProfilerOutput(ctx, spPowerDataTypeXML); out != "" {
+			if batts, _, err := parsePowerDataXML(out); err == nil {
+				return batts
 			}
 		}
 	}
-	return health, cycles, capacity
+	out := getSystemPowerOutput(ctx)
+	if out == "" {
+		return nil
+	}
+	return parsePowerData(out)
 }
 
-func getSystemPowerOutput() string {
-	if runtime.GOOS != "darwin" {
-		return ""
-	}
+// Localized SPPowerDataType headings, in case the C locale is not honored.
+var (
+	cycleCountKeys      = []string{"cycle count", "anzahl der ladezyklen", "nombre de cycles", "número de ciclos", "numero di cicli", "充放電回数", "循环计数"}
+	conditionKeys       = []string{"condition", "zustand", "état", "estado", "condizione", "状態", "状况"}
+	maximumCapacityKeys = []string{"maximum capacity", "maximale kapazität", "capacité maximale", "capacidad máxima", "capacità massima", "最大容量"}
+)
 
-	now := time.Now()
-	if cachedPower != "" && now.Sub(lastPowerAt) < powerCacheTTL {
-		return cachedPower
+// parsePowerData extracts condition, cycles, and capacity per battery from SPPowerDataType
+// text. Section headings are localized, so a new battery starts whenever a field that the
+// current one already has shows up again.
+func parsePowerData(out string) []powerData {
+	var (
+		blocks []powerData
+		cur    powerData
+		seen   = map[string]bool{}
+	)
+	field := func(name string) {
+		if seen[name] {
+			blocks = append(blocks, cur)
+			cur, seen = powerData{}, map[string]bool{}
+		}
+		seen[name] = true
 	}
+	for line := range strings.Lines(out) {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch {
+		case slices.Contains(cycleCountKeys, key):
+			field("cycles")
+			cur.Cycles, _ = strconv.Atoi(value)
+		case slices.Contains(conditionKeys, key):
+			field("health")
+			cur.Health = value
+		case slices.Contains(maximumCapacityKeys, key):
+			field("capacity")
+			cur.Capacity, _ = strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(value, "%")))
+		}
+	}
+	if len(seen) > 0 {
+		blocks = append(blocks, cur)
+	}
+	return blocks
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+func getSystemPowerOutput(ctx context.Context) string {
+	return getSystemProfilerOutput(ctx, spPowerDataType)
+}
 
-	out, err := runCmd(ctx, "system_profiler", "SPPowerDataType")
-	if err == nil {
-		cachedPower = out
-		lastPowerAt = now
+// getSystemProfilerOutput returns cached system_profiler output for a data type.
+func getSystemProfilerOutput(ctx context.Context, dataType string) string {
+	if probeOS != "darwin" {
+		return ""
 	}
-	return cachedPower
+	return profilerCache.get(ctx, dataType)
 }
 
-func collectThermal() ThermalStatus {
-	if runtime.GOOS != "darwin" {
+func collectThermal(ctx context.Context) ThermalStatus {
+	if probeOS == "linux" {
+		if !localProbes {
+			// Only lm-sensors runs remotely; thermal zones, hwmon and the
+			// throttle counters are files on this machine.
+			var thermal ThermalStatus
+			if readings, ok := lmSensorsShared.get(ctx); ok {
+				applyFanReadings(&thermal, readings)
+			}
+			return thermal
+		}
+		thermal := collectLinuxThermal(thermalZoneRoot)
+		if thermal.CPUTemp > 0 {
+			thermal.CPUTempSource = CPUTempSourceThermalZone
+		} else if temp, ok := cpuPackageTemp(hwmonShared.value(ctx)); ok {
+			// No CPU thermal zone (common on AMD and in VMs); the coretemp or
+			// k10temp package sensor is the same die reading.
+			thermal.CPUTemp, thermal.CPUTempSource = temp, CPUTempSourceHwmon
+			thermal.Level = thermalLevelFromTemp(temp)
+		}
+		thermal.ThrottleCount, _ = readThrottleCount(cpuSysfsRoot)
+		// lm-sensors knows each chip's fan inputs and scaling; hwmon's raw
+		// fan*_input covers machines without it.
+		if readings, ok := lmSensorsShared.get(ctx); ok {
+			applyFanReadings(&thermal, readings)
+		}
+		applyHwmonFans(&thermal, hwmonRoot)
+		return thermal
+	}
+	if probeOS != "darwin" {
 		return ThermalStatus{}
 	}
 
 	var thermal ThermalStatus
 
 	// Fan info from cached system_profiler.
-	out := getSystemPowerOutput()
+	out := getSystemPowerOutput(ctx)
 	if out != "" {
 		for line := range strings.Lines(out) {
 			lower := strings.ToLower(line)
@@ -191,93 +1224,143 @@ func collectThermal() ThermalStatus {
 		}
 	}
 
+	if localProbes {
+		thermal.FanControlMode = readFanControl()
+	}
+
 	// Power metrics from ioreg (fast, real-time).
-	ctxPower, cancelPower := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctxPower, cancelPower := context.WithTimeout(ctx, collectOptions.commandTimeout(quickQueryTimeout))
 	defer cancelPower()
+	var batteryTemp float64
 	if out, err := runCmd(ctxPower, "ioreg", "-rn", "AppleSmartBattery"); err == nil {
-		for line := range strings.Lines(out) {
-			line = strings.TrimSpace(line)
+		batteryTemp = parseSmartBatteryPower(out, &thermal)
+	}
+	thermal.BatteryTemp = batteryTemp
 
-			// Battery temperature ("Temperature" = 3055).
-			if _, after, found := strings.Cut(line, "\"Temperature\" = "); found {
-				valStr := strings.TrimSpace(after)
-				if tempRaw, err := strconv.Atoi(valStr); err == nil && tempRaw > 0 {
-					thermal.CPUTemp = float64(tempRaw) / 100.0
-				}
+	if thermal.AdapterPower == 0 && useProfilerXML {
+		if out := getSystemProfilerOutput(ctx, spPowerDataTypeXML); out != "" {
+			if _, adapter, err := parsePowerDataXML(out); err == nil && adapter.Connected {
+				thermal.AdapterPower = adapter.Watts
 			}
+		}
+	}
 
-			// Adapter power (Watts) from current adapter.
-			if strings.Contains(line, "\"AdapterDetails\" = {") && !strings.Contains(line, "AppleRaw") {
-				if _, after, found := strings.Cut(line, "\"Watts\"="); found {
-					valStr := strings.TrimSpace(after)
-					valStr, _, _ = strings.Cut(valStr, ",")
-					valStr, _, _ = strings.Cut(valStr, "}")
-					valStr = strings.TrimSpace(valStr)
-					if watts, err := strconv.ParseFloat(valStr, 64); err == nil && watts > 0 {
-						thermal.AdapterPower = watts
-					}
-				}
-			}
+	// Intel and Apple Silicon keep the CPU temperature in different places; see macCPUTempSources.
+	arch := detectMacArch(ctx)
+	thermal.CPUTemp, thermal.CPUTempSource = readMacCPUTemp(ctx, arch, batteryTemp)
+	thermal.Estimated = thermal.CPUTempSource.Estimate()
+	if arch == macArchIntel {
+		thermal.ThrottlePercent, _ = readCPUSpeedLimit(ctx)
+	}
 
-			// System power consumption (mW -> W).
-			if _, after, found := strings.Cut(line, "\"SystemPowerIn\"="); found {
-				valStr := strings.TrimSpace(after)
-				valStr, _, _ = strings.Cut(valStr, ",")
-				valStr, _, _ = strings.Cut(valStr, "}")
-				valStr = strings.TrimSpace(valStr)
-				if powerMW, err := strconv.ParseFloat(valStr, 64); err == nil {
-					// SystemPower should always be positive, reject invalid values
-					if powerMW >= 0 && powerMW < 1000000 { // 0 to 1000W
-						thermal.SystemPower = powerMW / 1000.0
-					}
-				}
+	// Thermal pressure: prefer the OS thermal state, fall back to the temperature
+	// estimate. The state is this Mac's, so a remote host only gets the estimate.
+	if level, ok := readThermalState(); ok && localProbes {
+		thermal.Level = level
+		thermal.Throttling = level >= ThermalLevelSerious
+	} else {
+		thermal.Level = thermalLevelFromTemp(thermal.CPUTemp)
+	}
+	// A speed limit is throttling whatever the thermal state says.
+	if thermal.ThrottlePercent > 0 && thermal.ThrottlePercent < 100 {
+		thermal.Throttling = true
+	}
+
+	return thermal
+}
+
+// parseSmartBatteryPower fills power fields from `ioreg -rn AppleSmartBattery` output
+// and returns the battery temperature in Celsius (0 when absent).
+func parseSmartBatteryPower(out string, thermal *ThermalStatus) (batteryTemp float64) {
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+
+		// Battery temperature ("Temperature" = 3055).
+		if _, after, found := strings.Cut(line, "\"Temperature\" = "); found {
+			valStr := strings.TrimSpace(after)
+			if tempRaw, err := strconv.Atoi(valStr); err == nil && tempRaw > 0 {
+				batteryTemp = FromCentiCelsius(tempRaw)
 			}
+		}
 
-			// Battery power (mW -> W, positive = discharging, negative = charging).
-			if _, after, found := strings.Cut(line, "\"BatteryPower\"="); found {
+		// Adapter power (Watts) from current adapter.
+		if strings.Contains(line, "\"AdapterDetails\" = {") && !strings.Contains(line, "AppleRaw") {
+			if _, after, found := strings.Cut(line, "\"Watts\"="); found {
 				valStr := strings.TrimSpace(after)
 				valStr, _, _ = strings.Cut(valStr, ",")
 				valStr, _, _ = strings.Cut(valStr, "}")
 				valStr = strings.TrimSpace(valStr)
-
-				var powerMW float64
-				var parsed bool
-
-				// Strategy 1: Try parsing as a signed integer first.
-				// This handles standard positive values and explicit negative strings like "-12345".
-				if valInt, err := strconv.ParseInt(valStr, 10, 64); err == nil {
-					powerMW = float64(valInt)
-					parsed = true
-				} else if valUint, err := strconv.ParseUint(valStr, 10, 64); err == nil {
-					// Strategy 2: Try parsing as an unsigned integer (Two's Complement).
-					// ioreg often returns negative values as huge uint64 numbers (e.g. 2^64 - 100).
-					// Casting such a uint64 to int64 correctly restores the negative value.
-					powerMW = float64(int64(valUint))
-					parsed = true
+				if watts, err := strconv.ParseFloat(valStr, 64); err == nil && watts > 0 {
+					thermal.AdapterPower = watts
 				}
+			}
+		}
 
-				if parsed {
-					// Validate reasonable battery power range: -200W to 200W
-					if powerMW > -200000 && powerMW < 200000 {
-						thermal.BatteryPower = powerMW / 1000.0
-					}
+		// System power consumption (mW -> W).
+		if _, after, found := strings.Cut(line, "\"SystemPowerIn\"="); found {
+			valStr := strings.TrimSpace(after)
+			valStr, _, _ = strings.Cut(valStr, ",")
+			valStr, _, _ = strings.Cut(valStr, "}")
+			valStr = strings.TrimSpace(valStr)
+			if powerMW, err := strconv.ParseFloat(valStr, 64); err == nil {
+				// SystemPower should always be positive, reject invalid values
+				if powerMW >= 0 && powerMW < 1000000 { // 0 to 1000W
+					thermal.SystemPower = powerMW / 1000.0
 				}
 			}
 		}
-	}
 
-	// Fallback: thermal level proxy.
-	if thermal.CPUTemp == 0 {
-		ctx2, cancel2 := context.WithTimeout(context.Background(), 500*time.Millisecond)
-		defer cancel2()
-		out2, err := runCmd(ctx2, "sysctl", "-n", "machdep.xcpm.cpu_thermal_level")
-		if err == nil {
-			level, _ := strconv.Atoi(strings.TrimSpace(out2))
-			if level >= 0 {
-				thermal.CPUTemp = 45 + float64(level)*0.5
+		// Battery power (mW -> W, positive = discharging, negative = charging).
+		if _, after, found := strings.Cut(line, "\"BatteryPower\"="); found {
+			valStr := strings.TrimSpace(after)
+			valStr, _, _ = strings.Cut(valStr, ",")
+			valStr, _, _ = strings.Cut(valStr, "}")
+			valStr = strings.TrimSpace(valStr)
+
+			var powerMW float64
+			var parsed bool
+
+			// Strategy 1: Try parsing as a signed integer first.
+			// This handles standard positive values and explicit negative strings like "-12345".
+			if valInt, err := strconv.ParseInt(valStr, 10, 64); err == nil {
+				powerMW = float64(valInt)
+				parsed = true
+			} else if valUint, err := strconv.ParseUint(valStr, 10, 64); err == nil {
+				// Strategy 2: Try parsing as an unsigned integer (Two's Complement).
+				// ioreg often returns negative values as huge uint64 numbers (e.g. 2^64 - 100).
+				// Casting such a uint64 to int64 correctly restores the negative value.
+				powerMW = float64(int64(valUint))
+				parsed = true
+			}
+
+			if parsed {
+				// Validate reasonable battery power range: -200W to 200W
+				if powerMW > -200000 && powerMW < 200000 {
+					thermal.BatteryPower = powerMW / 1000.0
+				}
 			}
 		}
 	}
+	return batteryTemp
+}
 
-	return thermal
+// disableTempFallback stops battery temperature and thermal-level estimates from
+// standing in for CPU temperature; CPUTemp then stays zero without a real CPU source.
+var disableTempFallback bool
+
+// thermalLevelFromTemp estimates thermal pressure from a CPU temperature, against
+// the learned cutoffs when the machine has been calibrated.
+func thermalLevelFromTemp(temp float64) ThermalLevel {
+	switch {
+	case temp <= 0:
+		return ThermalLevelUnknown
+	case temp < activeThermalCutoffs.Normal:
+		return ThermalLevelNominal
+	case temp < activeThermalCutoffs.Serious:
+		return ThermalLevelFair
+	case temp < activeThermalCutoffs.High:
+		return ThermalLevelSerious
+	default:
+		return ThermalLevelCritical
+	}
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procACPIBatteryRoot is the legacy (pre-2.6.24 style) ACPI battery interface,
+// still present on some older and embedded kernels.
+const procACPIBatteryRoot = "/proc/acpi/battery"
+
+// procACPIStatus maps "charging state" values onto sysfs spellings.
+var procACPIStatus = map[string]string{
+	"charging":    "Charging",
+	"discharging": "Discharging",
+	"charged":     "Full",
+}
+
+// readProcACPIBatteries reads <root>/BAT*/{info,state}. Percent is remaining
+// capacity over last full capacity; entries reporting "present: no" are skipped.
+func readProcACPIBatteries(root string) []BatteryStatus {
+	dirs, _ := filepath.Glob(filepath.Join(root, "BAT*"))
+	var out []BatteryStatus
+	for _, dir := range dirs {
+		info := readProcACPIFile(filepath.Join(dir, "info"))
+		state := readProcACPIFile(filepath.Join(dir, "state"))
+		if info["present"] != "yes" || state["present"] == "no" {
+			continue
+		}
+		full, unit, ok := procACPICapacity(info["last full capacity"])
+		remaining, remUnit, remOK := procACPICapacity(state["remaining capacity"])
+		if !ok || !remOK || unit != remUnit || full <= 0 {
+			continue
+		}
+		percent, ok := normalizeBatteryPercent(remaining / full * 100)
+		if !ok {
+			continue
+		}
+		status, known := procACPIStatus[state["charging state"]]
+		if !known {
+			status = "Unknown"
+		}
+		b := BatteryStatus{
+			Name:               filepath.Base(dir),
+			Percent:            percent,
+			Status:             status,
+			FullChargeCapacity: full,
+			CurrentCharge:      remaining,
+			CapacityUnit:       unit,
+			Source:             "acpi",
+		}
+		if design, designUnit, ok := procACPICapacity(info["design capacity"]); ok && designUnit == unit {
+			b.DesignCapacity = design
+		}
+		if mv, ok := procACPIMilli(state["present voltage"], "mV"); ok {
+			b.VoltageV = FromMilli(mv)
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// readProcACPIFile parses "key:   value" lines; a missing file yields an empty map.
+func readProcACPIFile(path string) map[string]string {
+	fields := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fields
+	}
+	for line := range strings.Lines(string(data)) {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+// procACPICapacity parses "4400 mAh" or "48000 mWh" into the CapacityUnit figures
+// sysfs batteries use (mAh, or Wh).
+func procACPICapacity(value string) (float64, CapacityUnit, bool) {
+	if mah, ok := procACPIMilli(value, "mAh"); ok {
+		return float64(mah), CapacityMAh, true
+	}
+	if mwh, ok := procACPIMilli(value, "mWh"); ok {
+		return FromMilli(mwh), CapacityWh, true
+	}
+	return 0, "", false
+}
+
+// procACPIMilli parses "<n> <unit>"; "unknown" and other units are rejected.
+func procACPIMilli(value, unit string) (int64, bool) {
+	num, u, ok := strings.Cut(value, " ")
+	if !ok || strings.TrimSpace(u) != unit {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	return n, err == nil && n >= 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestReadProcACPIBatteries(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/info", `present:                 yes
+design capacity:         4400 mAh
+last full capacity:      4000 mAh
+battery technology:      rechargeable
+design voltage:          10800 mV
+model number:            42T4631
+`)
+	writeSysfs(t, root, "BAT0/state", `present:                 yes
+capacity state:          ok
+charging state:          discharging
+present rate:            1200 mA
+remaining capacity:      3000 mAh
+present voltage:         11500 mV
+`)
+	writeSysfs(t, root, "BAT1/info", `present:                 yes
+design capacity:         57000 mWh
+last full capacity:      51300 mWh
+`)
+	writeSysfs(t, root, "BAT1/state", `present:                 yes
+charging state:          charged
+remaining capacity:      51300 mWh
+present voltage:         unknown
+`)
+	writeSysfs(t, root, "BAT2/info", "present:                 no\n")
+
+	batts := readProcACPIBatteries(root)
+	if len(batts) != 2 {
+		t.Fatalf("expected two present batteries, got %+v", batts)
+	}
+	want0 := BatteryStatus{Name: "BAT0", Percent: 75, Status: "Discharging", VoltageV: 11.5,
+		DesignCapacity: 4400, FullChargeCapacity: 4000, CurrentCharge: 3000, CapacityUnit: CapacityMAh, Source: "acpi"}
+	if batts[0] != want0 {
+		t.Fatalf("BAT0 = %+v, want %+v", batts[0], want0)
+	}
+	want1 := BatteryStatus{Name: "BAT1", Percent: 100, Status: "Full",
+		DesignCapacity: 57, FullChargeCapacity: 51.3, CurrentCharge: 51.3, CapacityUnit: CapacityWh, Source: "acpi"}
+	if batts[1] != want1 {
+		t.Fatalf("BAT1 = %+v, want %+v", batts[1], want1)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Per-cell voltages are best-effort and only filled where the hardware reports them:
+//
+//   - macOS: Apple Silicon and most Intel MacBooks list "CellVoltage" (mV) inside the
+//     AppleSmartBattery "BatteryData" dictionary.
+//   - Linux: the power_supply ABI has no per-cell attribute. Some vendor and
+//     out-of-tree drivers add cell<N>_voltage or voltage_cell<N> files (µV) next to
+//     voltage_now; those are read when present.
+//
+// Everywhere else BatteryStatus.Cells stays nil and nothing is shown.
+
+// BatteryCells is a pack's per-cell voltages and the spread between them.
+type BatteryCells struct {
+	Voltages    []float64 // Volts, in cell order
+	ImbalanceMV float64   // Highest minus lowest cell, in millivolts
+}
+
+// cellImbalanceWarnMV is the spread between the highest and lowest cell at which
+// the pack is flagged; healthy cells sit within a few tens of millivolts.
+const cellImbalanceWarnMV = 100.0
+
+// setCellVoltages records cell readings in volts and derives the imbalance.
+// Fewer than two cells carry no balance information and are ignored.
+func (b *BatteryStatus) setCellVoltages(volts []float64) {
+	if len(volts) < 2 {
+		return
+	}
+	b.Cells = &BatteryCells{
+		Voltages:    volts,
+		ImbalanceMV: math.Round((slices.Max(volts) - slices.Min(volts)) * 1000),
+	}
+}
+
+// Imbalanced reports whether the cell spread is large enough to suggest a failing cell.
+func (c *BatteryCells) Imbalanced() bool {
+	return c != nil && c.ImbalanceMV >= cellImbalanceWarnMV
+}
+
+// parseSmartBatteryCells reads "CellVoltage"=(mV,mV,...) from ioreg AppleSmartBattery
+// output. Zero entries are slots without a cell and are skipped.
+func parseSmartBatteryCells(out string) []float64 {
+	_, after, found := strings.Cut(out, `"CellVoltage"=(`)
+	if !found {
+		return nil
+	}
+	list, _, _ := strings.Cut(after, ")")
+	var volts []float64
+	for field := range strings.SplitSeq(list, ",") {
+		mv, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil || mv <= 0 {
+			continue
+		}
+		volts = append(volts, FromMilli(mv))
+	}
+	return volts
+}
+
+// readPowerSupplyCells reads per-cell voltage files from a power_supply directory,
+// ordered by cell number.
+func readPowerSupplyCells(dir string) []float64 {
+	type cell struct {
+		n int
+		v float64
+	}
+	var cells []cell
+	for _, pattern := range []string{"cell*_voltage", "voltage_cell*"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, path := range matches {
+			digits := strings.Trim(filepath.Base(path), "abcdefghijklmnopqrstuvwxyz_")
+			n, err := strconv.Atoi(digits)
+			if err != nil {
+				continue
+			}
+			if uv, ok := readSysfsInt(path); ok && uv > 0 {
+				cells = append(cells, cell{n: n, v: FromMicro(uv)})
+			}
+		}
+		if len(cells) > 0 {
+			break
+		}
+	}
+	slices.SortFunc(cells, func(a, b cell) int { return a.n - b.n })
+	volts := make([]float64, len(cells))
+	for i, c := range cells {
+		volts[i] = c.v
+	}
+	return volts
+}
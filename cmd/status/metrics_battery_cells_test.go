@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSmartBatteryCells(t *testing.T) {
+	out := `      "Voltage" = 12480
+      "BatteryData" = {"CycleCount"=212,"CellVoltage"=(4160,4158,4162,0),"DesignCapacity"=6075}`
+	var b BatteryStatus
+	b.setCellVoltages(parseSmartBatteryCells(out))
+	if b.Cells == nil || !slices.Equal(b.Cells.Voltages, []float64{4.16, 4.158, 4.162}) {
+		t.Fatalf("cells = %+v", b.Cells)
+	}
+	if b.Cells.ImbalanceMV != 4 || b.Cells.Imbalanced() {
+		t.Fatalf("imbalance = %v mV, imbalanced %v", b.Cells.ImbalanceMV, b.Cells.Imbalanced())
+	}
+
+	if cells := parseSmartBatteryCells(`"Voltage" = 12480`); cells != nil {
+		t.Fatalf("expected no cells without CellVoltage, got %v", cells)
+	}
+}
+
+func TestReadPowerSupplyCells(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "64\n")
+	writeSysfs(t, root, "BAT0/status", "Discharging\n")
+	writeSysfs(t, root, "BAT0/cell10_voltage", "3712000\n")
+	writeSysfs(t, root, "BAT0/cell2_voltage", "3840000\n")
+	writeSysfs(t, root, "BAT0/cell1_voltage", "3851000\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 1 {
+		t.Fatalf("expected one battery, got %d", len(batts))
+	}
+	b := batts[0]
+	if b.Cells == nil || !slices.Equal(b.Cells.Voltages, []float64{3.851, 3.84, 3.712}) {
+		t.Fatalf("cells = %+v, want ordered by cell number", b.Cells)
+	}
+	if b.Cells.ImbalanceMV != 139 || !b.Cells.Imbalanced() {
+		t.Fatalf("imbalance = %v mV, imbalanced %v", b.Cells.ImbalanceMV, b.Cells.Imbalanced())
+	}
+}
+
+func TestSetCellVoltagesSingleCell(t *testing.T) {
+	var b BatteryStatus
+	b.setCellVoltages([]float64{3.9})
+	if b.Cells != nil || b.Cells.Imbalanced() {
+		t.Fatalf("a single cell should be ignored: %+v", b)
+	}
+}
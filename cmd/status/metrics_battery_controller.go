@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Battery controller identity is best-effort, for matching packs against recalls
+// and known-bad gauge firmware:
+//
+//   - macOS: ioreg AppleSmartBattery has the gauge chip ("DeviceName"), its
+//     firmware ("GasGaugeFirmwareVersion"), the pack "Serial" and, inside
+//     "BatteryData", the vendor chemistry ID ("ChemID").
+//   - Linux: power_supply exposes manufacturer, model_name, technology and
+//     serial_number; firmware versions aren't part of the ABI.
+//   - UPower reports vendor, model, technology and serial.
+//
+// BatteryStatus.Controller stays nil when none of these are exposed.
+
+// BatteryController is the pack's controller and cell identity. Empty fields
+// weren't reported.
+type BatteryController struct {
+	Manufacturer string
+	Model        string // Gauge chip or pack model, e.g. bq40z651 or 5B10W13930
+	Firmware     string
+	Chemistry    string // Li-ion, Li-poly, or a vendor chemistry ID
+	Serial       string // Left empty with --no-machine-id; it identifies the machine
+}
+
+// setController records c unless it is empty. The serial is dropped under the
+// same privacy setting as the machine identifier.
+func (b *BatteryStatus) setController(c BatteryController) {
+	if hideMachineID {
+		c.Serial = ""
+	}
+	if c == (BatteryController{}) {
+		return
+	}
+	b.Controller = &c
+}
+
+// parseSmartBatteryController reads controller identity from ioreg AppleSmartBattery output.
+func parseSmartBatteryController(out string) BatteryController {
+	model, _ := smartBatteryString(out, "DeviceName")
+	serial, _ := smartBatteryString(out, "Serial")
+	firmware, _ := smartBatteryInt(out, "GasGaugeFirmwareVersion")
+	var chemID int64
+	if _, data, found := strings.Cut(out, `"BatteryData" = {`); found {
+		data, _, _ = strings.Cut(data, "\n")
+		chemID, _ = ioregDictInt(data, "ChemID")
+	}
+	return smartBatteryController(model, serial, firmware, chemID)
+}
+
+// smartBatteryController builds the identity shared by the ioreg and IOKit paths.
+// Zero firmware or chemistry IDs mean the key was absent.
+func smartBatteryController(model, serial string, firmware, chemID int64) BatteryController {
+	c := BatteryController{Model: model, Serial: serial}
+	if firmware > 0 {
+		c.Firmware = strconv.FormatInt(firmware, 10)
+	}
+	if chemID > 0 {
+		c.Chemistry = "ChemID " + strconv.FormatInt(chemID, 10)
+	}
+	return c
+}
+
+// smartBatteryString reads a top-level quoted string value, e.g. "DeviceName" = "bq40z651".
+func smartBatteryString(out, key string) (string, bool) {
+	prefix := "\"" + key + "\" = \""
+	for line := range strings.Lines(out) {
+		after, found := strings.CutPrefix(strings.TrimSpace(line), prefix)
+		if !found {
+			continue
+		}
+		val, _, found := strings.Cut(after, `"`)
+		return strings.TrimSpace(val), found
+	}
+	return "", false
+}
+
+// readPowerSupplyController reads identity attributes from a power_supply directory.
+// "Unknown" is the kernel's placeholder for technology and is treated as absent.
+func readPowerSupplyController(dir string) BatteryController {
+	c := BatteryController{
+		Manufacturer: readSysfsString(filepath.Join(dir, "manufacturer")),
+		Model:        readSysfsString(filepath.Join(dir, "model_name")),
+		Chemistry:    readSysfsString(filepath.Join(dir, "technology")),
+		Serial:       readSysfsString(filepath.Join(dir, "serial_number")),
+	}
+	if c.Chemistry == "Unknown" {
+		c.Chemistry = ""
+	}
+	return c
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSmartBatteryController(t *testing.T) {
+	out := `+-o AppleSmartBattery  <class AppleSmartBattery, id 0x100000251, registered, matched, active, busy 0 (0 ms), retain 8>
+    {
+      "DeviceName" = "bq40z651"
+      "Serial" = "F8Y0000000000000"
+      "GasGaugeFirmwareVersion" = 1793
+      "BatteryData" = {"DesignCapacity"=4382,"ChemID"=12538,"CycleCount"=187}
+      "PackReserve" = 200
+    }
+`
+	got := parseSmartBatteryController(out)
+	want := BatteryController{Model: "bq40z651", Firmware: "1793", Chemistry: "ChemID 12538", Serial: "F8Y0000000000000"}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSetControllerEmptyAndPrivate(t *testing.T) {
+	prev := hideMachineID
+	t.Cleanup(func() { hideMachineID = prev })
+
+	var b BatteryStatus
+	b.setController(BatteryController{})
+	if b.Controller != nil {
+		t.Fatalf("empty identity should leave Controller nil, got %+v", b.Controller)
+	}
+
+	hideMachineID = true
+	b.setController(BatteryController{Serial: "F8Y0000000000000"})
+	if b.Controller != nil {
+		t.Fatalf("a serial alone should be dropped under --no-machine-id, got %+v", b.Controller)
+	}
+	b.setController(BatteryController{Firmware: "1793", Serial: "F8Y0000000000000"})
+	if b.Controller == nil || b.Controller.Serial != "" || b.Controller.Firmware != "1793" {
+		t.Fatalf("unexpected controller %+v", b.Controller)
+	}
+}
+
+func TestPowerSupplyBatteryController(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "80\n")
+	writeSysfs(t, root, "BAT0/status", "Discharging\n")
+	writeSysfs(t, root, "BAT0/manufacturer", "SMP\n")
+	writeSysfs(t, root, "BAT0/model_name", "5B10W13930\n")
+	writeSysfs(t, root, "BAT0/technology", "Li-poly\n")
+	writeSysfs(t, root, "BAT1/capacity", "50\n")
+	writeSysfs(t, root, "BAT1/technology", "Unknown\n")
+
+	batts := slices.Collect(powerSupplyBatteries(root))
+	if len(batts) != 2 {
+		t.Fatalf("expected 2 batteries, got %d", len(batts))
+	}
+	want := BatteryController{Manufacturer: "SMP", Model: "5B10W13930", Chemistry: "Li-poly"}
+	if c := batts[0].Controller; c == nil || *c != want {
+		t.Fatalf("BAT0 controller = %+v, want %+v", c, want)
+	}
+	if batts[1].Controller != nil {
+		t.Fatalf("BAT1 reports nothing but the kernel placeholder, got %+v", batts[1].Controller)
+	}
+}
@@ -0,0 +1,125 @@
+//go:build darwin && cgo
+
+package main
+
+/*
+#cgo LDFLAGS: -framework IOKit -framework CoreFoundation
+#include <IOKit/IOKitLib.h>
+#include <CoreFoundation/CoreFoundation.h>
+
+typedef struct {
+	int ok;
+	long long currentCapacity;
+	long long maxCapacity;
+	long long designCapacity;
+	long long rawMaxCapacity;
+	long long rawCurrentCapacity;
+	long long cycleCount;
+	long long voltage;
+	long long amperage;
+	long long instantAmperage;
+	long long maxChargeCurrent;
+	long long timeRemaining;
+	int isCharging;
+	int externalConnected;
+	int fullyCharged;
+	long long gasGaugeFirmware;
+	long long chemID;
+	char deviceName[64];
+	char serial[64];
+} moleBattery;
+
+static long long moleDictInt(CFDictionaryRef dict, const char *key) {
+	long long out = 0;
+	CFStringRef k = CFStringCreateWithCString(kCFAllocatorDefault, key, kCFStringEncodingUTF8);
+	CFTypeRef v = CFDictionaryGetValue(dict, k);
+	CFRelease(k);
+	if (v != NULL && CFGetTypeID(v) == CFNumberGetTypeID()) {
+		CFNumberGetValue((CFNumberRef)v, kCFNumberLongLongType, &out);
+	}
+	return out;
+}
+
+static int moleDictBool(CFDictionaryRef dict, const char *key) {
+	CFStringRef k = CFStringCreateWithCString(kCFAllocatorDefault, key, kCFStringEncodingUTF8);
+	CFTypeRef v = CFDictionaryGetValue(dict, k);
+	CFRelease(k);
+	return v != NULL && CFGetTypeID(v) == CFBooleanGetTypeID() && CFBooleanGetValue((CFBooleanRef)v);
+}
+
+static void moleDictString(CFDictionaryRef dict, const char *key, char *buf, CFIndex size) {
+	CFStringRef k = CFStringCreateWithCString(kCFAllocatorDefault, key, kCFStringEncodingUTF8);
+	CFTypeRef v = CFDictionaryGetValue(dict, k);
+	CFRelease(k);
+	if (v == NULL || CFGetTypeID(v) != CFStringGetTypeID() || !CFStringGetCString((CFStringRef)v, buf, size, kCFStringEncodingUTF8)) {
+		buf[0] = 0;
+	}
+}
+
+static moleBattery moleReadBattery(void) {
+	moleBattery b = {0};
+	// MACH_PORT_NULL selects the default main port on every macOS version.
+	io_service_t svc = IOServiceGetMatchingService(MACH_PORT_NULL, IOServiceMatching("AppleSmartBattery"));
+	if (svc == IO_OBJECT_NULL) {
+		return b;
+	}
+	CFMutableDictionaryRef props = NULL;
+	kern_return_t kr = IORegistryEntryCreateCFProperties(svc, &props, kCFAllocatorDefault, 0);
+	IOObjectRelease(svc);
+	if (kr != KERN_SUCCESS || props == NULL) {
+		return b;
+	}
+	b.currentCapacity = moleDictInt(props, "CurrentCapacity");
+	b.maxCapacity = moleDictInt(props, "MaxCapacity");
+	b.designCapacity = moleDictInt(props, "DesignCapacity");
+	b.rawMaxCapacity = moleDictInt(props, "AppleRawMaxCapacity");
+	b.rawCurrentCapacity = moleDictInt(props, "AppleRawCurrentCapacity");
+	b.cycleCount = moleDictInt(props, "CycleCount");
+	b.voltage = moleDictInt(props, "Voltage");
+	b.amperage = moleDictInt(props, "Amperage");
+	b.instantAmperage = moleDictInt(props, "InstantAmperage");
+	b.maxChargeCurrent = moleDictInt(props, "MaxChargeCurrent");
+	b.timeRemaining = moleDictInt(props, "TimeRemaining");
+	b.isCharging = moleDictBool(props, "IsCharging");
+	b.externalConnected = moleDictBool(props, "ExternalConnected");
+	b.fullyCharged = moleDictBool(props, "FullyCharged");
+	b.gasGaugeFirmware = moleDictInt(props, "GasGaugeFirmwareVersion");
+	moleDictString(props, "DeviceName", b.deviceName, sizeof(b.deviceName));
+	moleDictString(props, "Serial", b.serial, sizeof(b.serial));
+	CFTypeRef data = CFDictionaryGetValue(props, CFSTR("BatteryData"));
+	if (data != NULL && CFGetTypeID(data) == CFDictionaryGetTypeID()) {
+		b.chemID = moleDictInt((CFDictionaryRef)data, "ChemID");
+	}
+	CFRelease(props);
+	b.ok = 1;
+	return b;
+}
+*/
+import "C"
+
+// readIOKitBattery reads AppleSmartBattery properties in-process, with no subprocess.
+func readIOKitBattery() (BatteryStatus, bool) {
+	b := C.moleReadBattery()
+	if b.ok == 0 {
+		return BatteryStatus{}, false
+	}
+	return smartBatteryProps{
+		CurrentCapacity:    int64(b.currentCapacity),
+		MaxCapacity:        int64(b.maxCapacity),
+		DesignCapacity:     int64(b.designCapacity),
+		RawMaxCapacity:     int64(b.rawMaxCapacity),
+		RawCurrentCapacity: int64(b.rawCurrentCapacity),
+		CycleCount:         int64(b.cycleCount),
+		VoltageMV:          int64(b.voltage),
+		AmperageMA:         int64(b.amperage),
+		InstantAmperageMA:  int64(b.instantAmperage),
+		MaxChargeCurrentMA: int64(b.maxChargeCurrent),
+		TimeRemainingMin:   int64(b.timeRemaining),
+		IsCharging:         b.isCharging != 0,
+		ExternalConnected:  b.externalConnected != 0,
+		FullyCharged:       b.fullyCharged != 0,
+		Controller: smartBatteryController(
+			C.GoString(&b.deviceName[0]), C.GoString(&b.serial[0]),
+			int64(b.gasGaugeFirmware), int64(b.chemID)),
+	}.status()
+}
@@ -0,0 +1,8 @@
+//go:build !darwin || !cgo
+
+package main
+
+// readIOKitBattery is unavailable without cgo on macOS; callers fall back to pmset.
+func readIOKitBattery() (BatteryStatus, bool) {
+	return BatteryStatus{}, false
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// batterySource is one way of reading batteries. read passes each battery to add
+// as it is parsed, so a panic part-way through keeps the earlier ones, and reports
+// whether battery hardware exists even when nothing usable was read.
+type batterySource struct {
+	name string
+	goos string // Only tried on this GOOS; empty means any
+	read func(ctx context.Context, add func(BatteryStatus)) (present bool)
+}
+
+// batterySources lists every known source. The default chain is the subset for the
+// running OS that defaultBatteryChain names, in this order.
+var batterySources = []batterySource{
+	{name: "iokit", goos: "darwin", read: func(ctx context.Context, add func(BatteryStatus)) bool {
+		// In-process, no subprocess; unavailable without cgo.
+		if b, ok := readIOKitBattery(); ok {
+			add(b)
+		}
+		return false
+	}},
+	{name: "pmset", goos: "darwin", read: func(ctx context.Context, add func(BatteryStatus)) bool {
+		if !commandExists("pmset") {
+			return false
+		}
+		batts, present := pmsetBatteries(ctx, getCachedPowerData)
+		for _, b := range batts {
+			add(b)
+		}
+		return present
+	}},
+	{name: "upower", goos: "linux", read: func(ctx context.Context, add func(BatteryStatus)) bool {
+		batts, present := upowerBatteries(ctx)
+		for _, b := range batts {
+			add(b)
+		}
+		return present
+	}},
+	{name: "sysfs", read: func(ctx context.Context, add func(BatteryStatus)) bool {
+		for b := range powerSupplyBatteries(powerSupplyRoot) {
+			add(b)
+		}
+		return powerSupplyHasBattery(powerSupplyRoot)
+	}},
+	{name: "wmi", goos: "windows", read: func(ctx context.Context, add func(BatteryStatus)) bool {
+		batts, present := wmiBatteries(func() ([]wmiBattery, error) { return readWMIBatteries(ctx) })
+		for _, b := range batts {
+			add(b)
+		}
+		return present
+	}},
+	{name: "acpi", goos: "linux", read: func(ctx context.Context, add func(BatteryStatus)) bool {
+		for _, b := range readProcACPIBatteries(procACPIBatteryRoot) {
+			add(b)
+		}
+		return false
+	}},
+}
+
+// defaultBatteryChain leaves upower out so the default path needs no DBus;
+// --battery-sources can put it first.
+var defaultBatteryChain = []string{"iokit", "pmset", "sysfs", "acpi", "wmi"}
+
+// batteryChain overrides the default order; set from --battery-sources.
+var batteryChain []string
+
+// batterySourceNames lists every source for flag help and errors.
+func batterySourceNames() string {
+	names := make([]string, len(batterySources))
+	for i, src := range batterySources {
+		names[i] = src.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// setBatteryChain parses a comma-separated source order. Sources for another OS
+// are accepted so one config works everywhere; they are skipped at run time.
+func setBatteryChain(raw string) error {
+	var chain []string
+	for name := range strings.SplitSeq(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !slices.ContainsFunc(batterySources, func(src batterySource) bool { return src.name == name }) {
+			return fmt.Errorf("unknown battery source %q (want one of %s)", name, batterySourceNames())
+		}
+		if !slices.Contains(chain, name) {
+			chain = append(chain, name)
+		}
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("battery source list is empty")
+	}
+	batteryChain = chain
+	return nil
+}
+
+// activeBatteryChain resolves the configured order to sources for goos.
+func activeBatteryChain(goos string) []batterySource {
+	order := batteryChain
+	if order == nil {
+		order = defaultBatteryChain
+	}
+	var chain []batterySource
+	for _, name := range order {
+		for _, src := range batterySources {
+			if src.name == name && (src.goos == "" || src.goos == goos) {
+				chain = append(chain, src)
+			}
+		}
+	}
+	return chain
+}
+
+// batteryChainNames is the effective chain for this OS, for --print-config.
+func batteryChainNames() []string {
+	names := []string{}
+	for _, src := range activeBatteryChain(probeOS) {
+		names = append(names, src.name)
+	}
+	return names
+}
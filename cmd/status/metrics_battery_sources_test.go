@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSetBatteryChain(t *testing.T) {
+	t.Cleanup(func() { batteryChain = nil })
+
+	if err := setBatteryChain(" SysFS, acpi,sysfs "); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(batteryChain, []string{"sysfs", "acpi"}) {
+		t.Fatalf("chain = %v", batteryChain)
+	}
+	for _, bad := range []string{"dbus", "", " , "} {
+		if err := setBatteryChain(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestActiveBatteryChain(t *testing.T) {
+	t.Cleanup(func() { batteryChain = nil })
+
+	names := func(goos string) []string {
+		var out []string
+		for _, src := range activeBatteryChain(goos) {
+			out = append(out, src.name)
+		}
+		return out
+	}
+	if got := names("linux"); !slices.Equal(got, []string{"sysfs", "acpi"}) {
+		t.Fatalf("default linux chain = %v", got)
+	}
+	if got := names("darwin"); !slices.Equal(got, []string{"iokit", "pmset", "sysfs"}) {
+		t.Fatalf("default darwin chain = %v", got)
+	}
+	batteryChain = []string{"upower", "pmset", "sysfs"}
+	if got := names("linux"); !slices.Equal(got, []string{"upower", "sysfs"}) {
+		t.Fatalf("custom linux chain = %v, want darwin-only sources skipped", got)
+	}
+}
+
+// fakeBatterySources makes sources the whole table and chain, recording which ones run.
+func fakeBatterySources(t *testing.T, sources ...batterySource) *[]string {
+	t.Helper()
+	saved := batterySources
+	t.Cleanup(func() { batterySources, batteryChain = saved, nil })
+	var tried []string
+	batterySources = nil
+	for _, src := range sources {
+		read := src.read
+		src.read = func(ctx context.Context, add func(BatteryStatus)) bool {
+			tried = append(tried, src.name)
+			return read(ctx, add)
+		}
+		batterySources = append(batterySources, src)
+	}
+	batteryChain = nil
+	for _, src := range sources {
+		batteryChain = append(batteryChain, src.name)
+	}
+	return &tried
+}
+
+func TestCollectBatteriesFollowsChain(t *testing.T) {
+	none := func(context.Context, func(BatteryStatus)) bool { return false }
+	tried := fakeBatterySources(t,
+		batterySource{name: "first", read: none},
+		batterySource{name: "second", read: func(_ context.Context, add func(BatteryStatus)) bool {
+			add(BatteryStatus{Name: "BAT0", Percent: 50, Source: "second"})
+			return true
+		}},
+		batterySource{name: "third", read: none},
+	)
+	batts, err := collectBatteries(context.Background())
+	if err != nil || len(batts) != 1 || batts[0].Source != "second" {
+		t.Fatalf("collectBatteries = %+v, %v", batts, err)
+	}
+	if !slices.Equal(*tried, []string{"first", "second"}) {
+		t.Fatalf("tried %v, want the chain to stop at the first source with data", *tried)
+	}
+}
+
+func TestCollectBatteriesChainErrors(t *testing.T) {
+	fakeBatterySources(t, batterySource{name: "hw", read: func(context.Context, func(BatteryStatus)) bool { return true }})
+	if _, err := collectBatteries(context.Background()); !errors.Is(err, ErrBatteryUnreadable) {
+		t.Fatalf("present but unread battery: err = %v", err)
+	}
+
+	fakeBatterySources(t, batterySource{name: "none", read: func(context.Context, func(BatteryStatus)) bool { return false }})
+	if _, err := collectBatteries(context.Background()); !errors.Is(err, ErrNoBattery) {
+		t.Fatalf("no battery: err = %v", err)
+	}
+}
+
+func TestCollectBatteriesPanicKeepsEarlier(t *testing.T) {
+	fakeBatterySources(t, batterySource{name: "flaky", read: func(_ context.Context, add func(BatteryStatus)) bool {
+		add(BatteryStatus{Name: "BAT0", Percent: 40})
+		panic("driver returned garbage")
+	}})
+	batts, err := collectBatteries(context.Background())
+	if err == nil || len(batts) != 1 || batts[0].Name != "BAT0" {
+		t.Fatalf("collectBatteries = %+v, %v", batts, err)
+	}
+}
+
+func TestCollectBatteriesStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tried := fakeBatterySources(t,
+		batterySource{name: "slow", read: func(context.Context, func(BatteryStatus)) bool {
+			cancel() // The user quit while this source was reading.
+			return false
+		}},
+		batterySource{name: "next", read: func(context.Context, func(BatteryStatus)) bool { return false }},
+	)
+	if _, err := collectBatteries(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled rather than a missing battery", err)
+	}
+	if !slices.Equal(*tried, []string{"slow"}) {
+		t.Fatalf("tried %v, want the chain abandoned once ctx is done", *tried)
+	}
+}
@@ -0,0 +1,958 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThermalLevelFromTemp(t *testing.T) {
+	tests := []struct {
+		temp float64
+		want ThermalLevel
+	}{
+		{0, ThermalLevelUnknown},
+		{45, ThermalLevelNominal},
+		{62, ThermalLevelFair},
+		{80, ThermalLevelSerious},
+		{95, ThermalLevelCritical},
+	}
+	for _, tt := range tests {
+		if got := thermalLevelFromTemp(tt.temp); got != tt.want {
+			t.Fatalf("thermalLevelFromTemp(%.0f) = %s, want %s", tt.temp, got, tt.want)
+		}
+	}
+}
+
+func TestThermalLevelString(t *testing.T) {
+	if got := ThermalLevelSerious.String(); got != "serious" {
+		t.Fatalf("expected serious, got %s", got)
+	}
+	if got := ThermalLevel(42).String(); got != "unknown" {
+		t.Fatalf("expected unknown for out-of-range level, got %s", got)
+	}
+}
+
+func TestThermalLevelSeverityJSON(t *testing.T) {
+	for level, want := range map[ThermalLevel]string{
+		ThermalLevelNominal:  `{"name":"nominal","severity":0}`,
+		ThermalLevelCritical: `{"name":"critical","severity":3}`,
+		ThermalLevelUnknown:  `{"name":"unknown","severity":-1}`,
+	} {
+		got, err := json.Marshal(ThermalStatus{Level: level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), `"Level":`+want) {
+			t.Errorf("%s marshals as %s, want Level %s", level, got, want)
+		}
+	}
+
+	samples := snapshotMetrics(MetricsSnapshot{Thermal: ThermalStatus{Level: ThermalLevelSerious}})
+	if len(samples) != 1 || samples[0].Name != "thermal_level" || samples[0].Value != 2 || samples[0].Labels[0].Value != "serious" {
+		t.Fatalf("unexpected thermal level export %+v", samples)
+	}
+	if samples := snapshotMetrics(MetricsSnapshot{}); len(samples) != 0 {
+		t.Fatalf("unknown level should not be exported, got %+v", samples)
+	}
+}
+
+func TestParsePMSetClampsPercent(t *testing.T) {
+	raw := `Now drawing from 'AC Power'
+ -InternalBattery-0 (id=1234567)	101%; charged; 0:00 remaining present: true
+ -InternalBattery-1 (id=7654321)	255%; charging; (no estimate) present: true
+`
+	batts := parsePMSet(raw, nil)
+	if len(batts) != 1 {
+		t.Fatalf("expected bogus 255%% reading to be dropped, got %d batteries", len(batts))
+	}
+	if batts[0].Percent != 100 {
+		t.Fatalf("expected 101%% to clamp to 100, got %.1f", batts[0].Percent)
+	}
+}
+
+func TestReadPowerSupplyBatteriesClampsPercent(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "101\n")
+	writeSysfs(t, root, "BAT0/status", "Full\n")
+	writeSysfs(t, root, "BAT1/capacity", "255\n")
+	writeSysfs(t, root, "BAT1/status", "Discharging\n")
+	writeSysfs(t, root, "BAT2/capacity", "-3\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 1 {
+		t.Fatalf("expected only the sane battery, got %+v", batts)
+	}
+	if batts[0].Percent != 100 || batts[0].Status != "Full" {
+		t.Fatalf("unexpected battery %+v", batts[0])
+	}
+}
+
+func TestReadPowerSupplyBatteriesHotplug(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "80\n")
+	if batts := readPowerSupplyBatteries(root); len(batts) != 1 {
+		t.Fatalf("expected one battery before hot-plug, got %+v", batts)
+	}
+
+	writeSysfs(t, root, "BAT1/capacity", "45\n")
+	writeSysfs(t, root, "BAT1/status", "Charging\n")
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 2 || batts[1].Name != "BAT1" || batts[1].Percent != 45 {
+		t.Fatalf("expected the attached battery on the next read, got %+v", batts)
+	}
+
+	if err := os.RemoveAll(filepath.Join(root, "BAT1")); err != nil {
+		t.Fatal(err)
+	}
+	if batts := readPowerSupplyBatteries(root); len(batts) != 1 {
+		t.Fatalf("expected the detached battery to disappear, got %+v", batts)
+	}
+}
+
+func TestReadPowerSupplyBatteriesVoltage(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "80\n")
+	writeSysfs(t, root, "BAT0/voltage_now", "12581000\n")
+	writeSysfs(t, root, "BAT1/capacity", "50\n")
+	writeSysfs(t, root, "BAT1/voltage_now", "garbage\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 2 {
+		t.Fatalf("expected 2 batteries, got %+v", batts)
+	}
+	if batts[0].VoltageV != 12.581 {
+		t.Fatalf("expected 12.581V from microvolts, got %v", batts[0].VoltageV)
+	}
+	if batts[1].VoltageV != 0 {
+		t.Fatalf("expected zero voltage when unreadable, got %v", batts[1].VoltageV)
+	}
+}
+
+func writeSysfs(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSystemProfilerCachePerKeyTTL(t *testing.T) {
+	cache := newSystemProfilerCache(map[string]time.Duration{
+		spPowerDataType:    time.Minute,
+		spHardwareDataType: time.Hour,
+	})
+	now := time.Now()
+	cache.entries[spPowerDataType] = systemProfilerEntry{output: "power", fetchedAt: now}
+	cache.entries[spHardwareDataType] = systemProfilerEntry{output: "hardware", fetchedAt: now.Add(-30 * time.Minute)}
+
+	if got := cache.get(context.Background(), spPowerDataType); got != "power" {
+		t.Fatalf("expected cached power output, got %q", got)
+	}
+	if got := cache.get(context.Background(), spHardwareDataType); got != "hardware" {
+		t.Fatalf("expected hardware output within its longer TTL, got %q", got)
+	}
+}
+
+func TestSystemProfilerCacheRefreshSchedule(t *testing.T) {
+	cache := newSystemProfilerCache(map[string]time.Duration{spPowerDataType: 30 * time.Second})
+	now := time.Now()
+	if !cache.refreshAt(spPowerDataType).IsZero() {
+		t.Fatal("expected an empty entry to be due immediately")
+	}
+	cache.entries[spPowerDataType] = systemProfilerEntry{output: "power", fetchedAt: now}
+	if got, want := cache.refreshAt(spPowerDataType), now.Add(25*time.Second); !got.Equal(want) {
+		t.Fatalf("refreshAt = %v, want %v", got, want)
+	}
+	if got := cache.nextRefresh(now); got != 25*time.Second {
+		t.Fatalf("nextRefresh = %v, want 25s", got)
+	}
+	if got := cache.nextRefresh(now.Add(time.Minute)); got != profilerRetryDelay {
+		t.Fatalf("expected overdue entries to back off by %v, got %v", profilerRetryDelay, got)
+	}
+}
+
+func TestSystemProfilerCacheBackgroundRefresh(t *testing.T) {
+	cache := newSystemProfilerCache(map[string]time.Duration{spPowerDataType: time.Minute})
+	fetched := make(chan struct{}, 1)
+	cache.fetch = func(ctx context.Context, dataType string) (string, error) {
+		select {
+		case fetched <- struct{}{}:
+		default:
+		}
+		return "warm " + dataType, nil
+	}
+
+	cache.Start(context.Background())
+	select {
+	case <-fetched:
+	case <-time.After(2 * time.Second):
+		t.Fatal("background refresher never fetched")
+	}
+	cache.Stop()
+
+	cache.fetch = func(ctx context.Context, dataType string) (string, error) {
+		t.Error("get should hit the warm cache")
+		return "", nil
+	}
+	if got := cache.get(context.Background(), spPowerDataType); got != "warm "+spPowerDataType {
+		t.Fatalf("unexpected cached output %q", got)
+	}
+}
+
+func TestPMSetStatusFlipInvalidatesProfilerCache(t *testing.T) {
+	ResetState()
+	prevRunner, prevFetch := cmdRunner, profilerCache.fetch
+	t.Cleanup(func() {
+		cmdRunner, profilerCache.fetch = prevRunner, prevFetch
+		ResetState()
+	})
+	status := "discharging"
+	cmdRunner = func(_ context.Context, _ []string, name string, args ...string) (string, error) {
+		if name != "pmset" {
+			return "", errors.New("unexpected command " + name)
+		}
+		return "Now drawing from 'Battery Power'\n -InternalBattery-0 (id=1234)\t80%; " + status + "; 3:10 remaining present: true\n", nil
+	}
+	var fetches int
+	profilerCache.fetch = func(context.Context, string) (string, error) {
+		fetches++
+		return "Cycle Count: " + strconv.Itoa(400+fetches) + "\n", nil
+	}
+	power := func(ctx context.Context) []powerData {
+		return parsePowerData(profilerCache.get(ctx, spPowerDataType))
+	}
+
+	for i, step := range []struct {
+		status  string
+		fetches int
+	}{
+		{"discharging", 1}, // First read fills the cache
+		{"discharging", 1}, // Steady state stays within the TTL
+		{"charging", 2},    // Plugged in: fetched again once
+		{"charging", 2},
+	} {
+		status = step.status
+		batts, _ := pmsetBatteries(context.Background(), power)
+		if fetches != step.fetches {
+			t.Fatalf("read %d (%s): %d system_profiler fetches, want %d", i, step.status, fetches, step.fetches)
+		}
+		if len(batts) != 1 || batts[0].CycleCount != 400+fetches {
+			t.Fatalf("read %d (%s): batteries = %+v, want the latest cycle count", i, step.status, batts)
+		}
+	}
+}
+
+func TestSystemProfilerCacheConcurrentGet(t *testing.T) {
+	cache := newSystemProfilerCache(map[string]time.Duration{
+		spPowerDataType:    time.Hour,
+		spHardwareDataType: time.Hour,
+	})
+	var fetches atomic.Int32
+	cache.fetch = func(ctx context.Context, dataType string) (string, error) {
+		fetches.Add(1)
+		return "out " + dataType, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dataType := spPowerDataType
+			if i%2 == 1 {
+				dataType = spHardwareDataType
+			}
+			for range 100 {
+				if got := cache.get(context.Background(), dataType); got != "out "+dataType {
+					t.Errorf("get(%s) = %q", dataType, got)
+					return
+				}
+				cache.nextRefresh(time.Now())
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Within the TTL each data type is fetched once, however many callers race.
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("expected one fetch per data type, got %d", got)
+	}
+}
+
+func TestSystemProfilerCacheFetchesDataTypesIndependently(t *testing.T) {
+	cache := newSystemProfilerCache(map[string]time.Duration{
+		spPowerDataType:    time.Hour,
+		spHardwareDataType: time.Hour,
+	})
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	cache.fetch = func(ctx context.Context, dataType string) (string, error) {
+		if dataType == spHardwareDataType {
+			close(entered)
+			<-unblock
+		}
+		return "out " + dataType, nil
+	}
+
+	hardware := make(chan string, 1)
+	go func() { hardware <- cache.get(context.Background(), spHardwareDataType) }()
+	<-entered
+
+	// The slow SPHardwareDataType fetch holds up neither power reads nor Invalidate.
+	done := make(chan string, 1)
+	go func() {
+		cache.Invalidate(spHardwareDataType)
+		done <- cache.get(context.Background(), spPowerDataType)
+	}()
+	select {
+	case got := <-done:
+		if got != "out "+spPowerDataType {
+			t.Fatalf("power get = %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("power get waited on the hardware fetch")
+	}
+	close(unblock)
+	if got := <-hardware; got != "out "+spHardwareDataType {
+		t.Fatalf("hardware get = %q", got)
+	}
+}
+
+func TestPrimaryBattery(t *testing.T) {
+	batts := []BatteryStatus{
+		{Name: "ExternalPack", Percent: 30},
+		{Name: "InternalBattery-0", Percent: 72},
+		{Name: "InternalBattery-1", Percent: 90},
+	}
+	m := MetricsSnapshot{Batteries: batts}
+
+	b, ok := m.PrimaryBattery()
+	if !ok || b.Name != "InternalBattery-0" {
+		t.Fatalf("expected first internal battery, got %+v", b)
+	}
+
+	primaryBatteryName = "internalbattery-1"
+	t.Cleanup(func() { primaryBatteryName = "" })
+	if b, _ := m.PrimaryBattery(); b.Name != "InternalBattery-1" {
+		t.Fatalf("expected pinned battery, got %+v", b)
+	}
+
+	primaryBatteryName = "missing"
+	if b, _ := m.PrimaryBattery(); b.Name != "InternalBattery-0" {
+		t.Fatalf("expected fallback when pinned battery is absent, got %+v", b)
+	}
+
+	if _, ok := (MetricsSnapshot{}).PrimaryBattery(); ok {
+		t.Fatalf("expected no primary battery without batteries")
+	}
+}
+
+func TestParsePMSetBatteryName(t *testing.T) {
+	raw := " -InternalBattery-0 (id=1234)\t85%; charging; 0:45 remaining present: true\n"
+	batts := parsePMSet(raw, nil)
+	if len(batts) != 1 || batts[0].Name != "InternalBattery-0" {
+		t.Fatalf("unexpected batteries %+v", batts)
+	}
+}
+
+func TestParsePMSetPerBatteryTimeLeft(t *testing.T) {
+	raw := "Now drawing from 'Battery Power'\n" +
+		" -InternalBattery-0 (id=4653155)\t95%; discharging; 3:21 remaining present: true\n" +
+		" -InternalBattery-1 (id=4653156)\t40%; discharging; (no estimate) present: true\n" +
+		" -ExternalBattery-0 (id=4653157)\t70%; discharging; 1:05 remaining present: true\n"
+	batts := parsePMSet(raw, nil)
+	if len(batts) != 3 {
+		t.Fatalf("expected three batteries, got %+v", batts)
+	}
+	for i, want := range []string{"3:21", "", "1:05"} {
+		if batts[i].TimeLeft != want {
+			t.Errorf("%s: TimeLeft %q, want %q", batts[i].Name, batts[i].TimeLeft, want)
+		}
+	}
+}
+
+func TestParsePMSetKeepsPowerDataAlignedAfterDroppedLine(t *testing.T) {
+	raw := "Now drawing from 'Battery Power'\n" +
+		" -InternalBattery-0 (id=4653155)\t--%; discharging; (no estimate) present: true\n" +
+		" -InternalBattery-1 (id=4653156)\t60%; discharging; 2:10 remaining present: true\n"
+	power := []powerData{
+		{Health: "Service Recommended", Cycles: 900},
+		{Health: "Normal", Cycles: 120},
+	}
+	batts := parsePMSet(raw, power)
+	if len(batts) != 1 {
+		t.Fatalf("expected the unreadable battery to be dropped, got %+v", batts)
+	}
+	if b := batts[0]; b.Name != "InternalBattery-1" || b.Health != "Normal" || b.CycleCount != 120 {
+		t.Fatalf("second battery got another battery's power data: %+v", b)
+	}
+}
+
+func TestParseSmartBatteryPower(t *testing.T) {
+	out := `+-o AppleSmartBattery  <class AppleSmartBattery>
+    {
+      "Temperature" = 3055
+      "AdapterDetails" = {"Watts"=96,"Name"="96W USB-C Power Adapter"}
+      "PowerTelemetryData" = {"SystemPowerIn"=12500,"BatteryPower"=18446744073709539616}
+    }`
+	var thermal ThermalStatus
+	temp := parseSmartBatteryPower(out, &thermal)
+	if temp != 30.55 {
+		t.Fatalf("expected battery temp 30.55, got %v", temp)
+	}
+	if thermal.CPUTemp != 0 {
+		t.Fatalf("parser must not set CPUTemp, got %v", thermal.CPUTemp)
+	}
+	if thermal.AdapterPower != 96 || thermal.SystemPower != 12.5 || thermal.BatteryPower != -12 {
+		t.Fatalf("unexpected power fields %+v", thermal)
+	}
+}
+
+func TestParseSmartBatteryVoltage(t *testing.T) {
+	out := `+-o AppleSmartBattery  <class AppleSmartBattery>
+    {
+      "AppleRawBatteryVoltage" = 12400
+      "Voltage" = 12581
+    }`
+	if got := parseSmartBatteryVoltage(out); got != 12.581 {
+		t.Fatalf("expected 12.581V, got %v", got)
+	}
+	if got := parseSmartBatteryVoltage(`"AppleRawBatteryVoltage" = 12400`); got != 0 {
+		t.Fatalf("raw voltage must not be used, got %v", got)
+	}
+}
+
+func TestParsePowerData(t *testing.T) {
+	english := `Power:
+    Battery Information:
+      Health Information:
+          Cycle Count: 187
+          Condition: Normal
+          Maximum Capacity: 91%
+`
+	blocks := parsePowerData(english)
+	if len(blocks) != 1 || blocks[0] != (powerData{Health: "Normal", Cycles: 187, Capacity: 91}) {
+		t.Fatalf("unexpected english parse: %+v", blocks)
+	}
+
+	german := `Strom:
+    Batterieinformationen:
+      Informationen zum Zustand:
+          Anzahl der Ladezyklen: 42
+          Zustand: Normal
+          Maximale Kapazität: 98 %
+`
+	blocks = parsePowerData(german)
+	if len(blocks) != 1 || blocks[0] != (powerData{Health: "Normal", Cycles: 42, Capacity: 98}) {
+		t.Fatalf("unexpected german parse: %+v", blocks)
+	}
+}
+
+func TestParsePowerDataMultipleBatteries(t *testing.T) {
+	out := `Power:
+    Battery Information:
+      Model Information:
+          Serial Number: F5D1234A
+      Health Information:
+          Cycle Count: 812
+          Condition: Service Recommended
+          Maximum Capacity: 74%
+    Battery Information:
+      Model Information:
+          Serial Number: F5D5678B
+      Health Information:
+          Cycle Count: 35
+          Condition: Normal
+          Maximum Capacity: 99%
+`
+	pmset := `Now drawing from 'Battery Power'
+ -InternalBattery-0 (id=1234)	61%; discharging; 3:10 remaining present: true
+ -InternalBattery-1 (id=5678)	88%; discharging; 3:10 remaining present: true`
+
+	batts := parsePMSet(pmset, parsePowerData(out))
+	if len(batts) != 2 {
+		t.Fatalf("expected two batteries, got %+v", batts)
+	}
+	if b := batts[0]; b.Name != "InternalBattery-0" || b.CycleCount != 812 || b.Health != "Service Recommended" || b.Capacity != 74 {
+		t.Fatalf("first battery paired wrongly: %+v", b)
+	}
+	if b := batts[1]; b.Name != "InternalBattery-1" || b.CycleCount != 35 || b.Health != "Normal" || b.Capacity != 99 {
+		t.Fatalf("second battery paired wrongly: %+v", b)
+	}
+}
+
+func TestPmsetHasBattery(t *testing.T) {
+	if pmsetHasBattery("Now drawing from 'AC Power'\n") {
+		t.Fatalf("desktop pmset output has no battery")
+	}
+	if !pmsetHasBattery(" -InternalBattery-0 (id=1234)\tpresent: true\n") {
+		t.Fatalf("expected unparsable battery line to count as present")
+	}
+}
+
+func TestPowerSupplyHasBattery(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "AC/online", "1\n")
+	if powerSupplyHasBattery(root) {
+		t.Fatalf("mains-only tree has no battery")
+	}
+	writeSysfs(t, root, "BAT0/status", "Unknown\n")
+	if !powerSupplyHasBattery(root) {
+		t.Fatalf("expected BAT0 to count as present")
+	}
+	if batts := readPowerSupplyBatteries(root); len(batts) != 0 {
+		t.Fatalf("BAT0 without capacity should be unreadable, got %+v", batts)
+	}
+}
+
+func TestPowerSupplyPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores file permissions")
+	}
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "80\n")
+	if powerSupplyPermissionDenied(root) {
+		t.Fatal("readable capacity reported as denied")
+	}
+	if err := os.Chmod(filepath.Join(root, "BAT0", "capacity"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if !powerSupplyPermissionDenied(root) {
+		t.Fatal("expected permission denied for unreadable capacity")
+	}
+}
+
+func TestErrBatteryPermissionIsUnreadable(t *testing.T) {
+	if !errors.Is(ErrBatteryPermission, ErrBatteryUnreadable) {
+		t.Fatal("permission error must still match ErrBatteryUnreadable")
+	}
+}
+
+func TestReadPowerSupplyBatteriesChargeLimit(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "72\n")
+	writeSysfs(t, root, "BAT0/charge_control_end_threshold", "80\n")
+	writeSysfs(t, root, "BAT1/capacity", "90\n")
+	writeSysfs(t, root, "BAT1/charge_control_end_threshold", "100\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 2 {
+		t.Fatalf("expected 2 batteries, got %+v", batts)
+	}
+	if !batts[0].ChargeLimited || batts[0].EffectiveFullPercent != 80 {
+		t.Fatalf("expected an 80%% limit, got %+v", batts[0])
+	}
+	if batts[1].ChargeLimited || batts[1].EffectiveFullPercent != 0 {
+		t.Fatalf("a 100%% threshold is not a limit, got %+v", batts[1])
+	}
+}
+
+func TestReadPowerSupplyCapacityUnits(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "50\n")
+	writeSysfs(t, root, "BAT0/charge_full_design", "5800000\n")
+	writeSysfs(t, root, "BAT0/charge_full", "5200000\n")
+	writeSysfs(t, root, "BAT0/charge_now", "2600000\n")
+	writeSysfs(t, root, "BAT1/capacity", "80\n")
+	writeSysfs(t, root, "BAT1/energy_full_design", "57000000\n")
+	writeSysfs(t, root, "BAT1/energy_full", "51300000\n")
+	writeSysfs(t, root, "BAT1/energy_now", "41040000\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 2 {
+		t.Fatalf("expected 2 batteries, got %+v", batts)
+	}
+	b := batts[0]
+	if b.CapacityUnit != CapacityMAh || b.DesignCapacity != 5800 || b.FullChargeCapacity != 5200 || b.CurrentCharge != 2600 {
+		t.Fatalf("unexpected charge_* capacity %+v", b)
+	}
+	b = batts[1]
+	if b.CapacityUnit != CapacityWh || b.DesignCapacity != 57 || b.FullChargeCapacity != 51.3 || b.CurrentCharge != 41.04 {
+		t.Fatalf("unexpected energy_* capacity %+v", b)
+	}
+}
+
+func TestParseSmartBatteryCapacity(t *testing.T) {
+	out := `+-o AppleSmartBattery  <class AppleSmartBattery>
+    {
+      "MaxCapacity" = 100
+      "CurrentCapacity" = 72
+      "AppleRawMaxCapacity" = 4520
+      "AppleRawCurrentCapacity" = 3254
+      "DesignCapacity" = 5103
+      "BatteryData" = {"DesignCapacity"=5103}
+    }`
+	var b BatteryStatus
+	parseSmartBatteryCapacity(out, &b)
+	if b.CapacityUnit != CapacityMAh || b.DesignCapacity != 5103 || b.FullChargeCapacity != 4520 || b.CurrentCharge != 3254 {
+		t.Fatalf("unexpected capacity %+v", b)
+	}
+
+	var empty BatteryStatus
+	parseSmartBatteryCapacity(`"Voltage" = 12581`, &empty)
+	if empty.CapacityUnit != "" {
+		t.Fatalf("expected no unit without capacity keys, got %+v", empty)
+	}
+}
+
+func TestSmartBatteryCurrentAveragedForEstimates(t *testing.T) {
+	out := `+-o AppleSmartBattery  <class AppleSmartBattery>
+    {
+      "Voltage" = 12000
+      "Amperage" = 18446744073709550616
+      "InstantAmperage" = -3000
+      "AppleRawCurrentCapacity" = 4000
+    }`
+	b := BatteryStatus{Status: "discharging", VoltageV: 12, CurrentCharge: 4000, CapacityUnit: CapacityMAh}
+	parseSmartBatteryCurrent(out, &b)
+	if b.AverageCurrentA != -1 || b.InstantCurrentA != -3 || b.CurrentA != -1 {
+		t.Fatalf("unexpected currents %+v", b)
+	}
+	// 48Wh at 12W averaged is 4h; the 36W spike would say 1h20m.
+	if got, ok := b.ComputeTimeToEmpty(); !ok || got != 4*time.Hour {
+		t.Fatalf("averaged estimate = %v, %v", got, ok)
+	}
+
+	old := estimateFromInstantCurrent
+	t.Cleanup(func() { estimateFromInstantCurrent = old })
+	estimateFromInstantCurrent = true
+	if got, _ := b.ComputeTimeToEmpty(); got != 80*time.Minute {
+		t.Fatalf("instant estimate = %v, want 1h20m", got)
+	}
+
+	// Without the split (Linux), CurrentA is used as before.
+	linux := BatteryStatus{Status: "discharging", VoltageV: 12, CurrentA: -1, CurrentCharge: 48, CapacityUnit: CapacityWh}
+	if got, _ := linux.ComputeTimeToEmpty(); got != 4*time.Hour {
+		t.Fatalf("linux estimate = %v", got)
+	}
+}
+
+func TestSmartBatteryPropsStatus(t *testing.T) {
+	b, ok := smartBatteryProps{
+		CurrentCapacity:    72,
+		MaxCapacity:        100,
+		DesignCapacity:     5103,
+		RawMaxCapacity:     3900,
+		RawCurrentCapacity: 2808,
+		CycleCount:         412,
+		VoltageMV:          12581,
+		AmperageMA:         -1520,
+		TimeRemainingMin:   185,
+	}.status()
+	if !ok {
+		t.Fatal("expected a reading")
+	}
+	if b.Percent != 72 || b.Status != "discharging" || b.TimeLeft != "3:05" || b.CycleCount != 412 {
+		t.Fatalf("unexpected status %+v", b)
+	}
+	if b.Capacity != 76 || b.Health != "Service Recommended" || b.Source != "iokit" {
+		t.Fatalf("unexpected health %+v", b)
+	}
+	if b.VoltageV != 12.581 || b.CurrentA != -1.52 || b.FullChargeCapacity != 3900 {
+		t.Fatalf("unexpected electrical readings %+v", b)
+	}
+
+	charging, _ := smartBatteryProps{CurrentCapacity: 40, MaxCapacity: 100, IsCharging: true, ExternalConnected: true, TimeRemainingMin: smartBatteryTimeUnknown}.status()
+	if charging.Status != "charging" || charging.TimeLeft != "" {
+		t.Fatalf("unexpected charging status %+v", charging)
+	}
+
+	if _, ok := (smartBatteryProps{}).status(); ok {
+		t.Fatal("expected no reading without MaxCapacity")
+	}
+}
+
+func TestSetCapacityPercents(t *testing.T) {
+	batts := []BatteryStatus{
+		{Name: "sysfs", DesignCapacity: 50, FullChargeCapacity: 44.4, CapacityUnit: CapacityWh},
+		{Name: "macos", Capacity: 87, DesignCapacity: 4382, FullChargeCapacity: 3988},
+		{Name: "no-design", FullChargeCapacity: 4000},
+		{Name: "overfull", DesignCapacity: 5000, FullChargeCapacity: 5100},
+	}
+	setCapacityPercents(batts)
+	want := map[string]int{"sysfs": 89, "macos": 87, "no-design": 0, "overfull": 100}
+	for _, b := range batts {
+		if b.Capacity != want[b.Name] {
+			t.Errorf("%s: Capacity=%d, want %d", b.Name, b.Capacity, want[b.Name])
+		}
+	}
+}
+
+func TestMarkWornBatteries(t *testing.T) {
+	batts := []BatteryStatus{
+		{Name: "raw", DesignCapacity: 5000, FullChargeCapacity: 3900},
+		{Name: "rounded", Capacity: 92},
+		{Name: "reported-worn", Capacity: 70},
+		{Name: "unknown-design", FullChargeCapacity: 1000},
+		{Name: "overfull", DesignCapacity: 5000, FullChargeCapacity: 5100},
+	}
+	markWornBatteries(batts)
+	want := map[string]bool{"raw": true, "rounded": false, "reported-worn": true, "unknown-design": false, "overfull": false}
+	for _, b := range batts {
+		if b.Worn != want[b.Name] {
+			t.Errorf("%s: Worn=%v, want %v", b.Name, b.Worn, want[b.Name])
+		}
+	}
+
+	m := MetricsSnapshot{Batteries: batts[:1]}
+	for _, a := range evaluateAlerts(m) {
+		if a.Name == "battery-worn" && (!a.Firing || a.Message != "Battery health at 78% of design capacity") {
+			t.Fatalf("unexpected worn alert %+v", a)
+		}
+	}
+}
+
+func TestSetPrecisePercents(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "57\n")
+	writeSysfs(t, root, "BAT0/charge_full", "5200000\n")
+	writeSysfs(t, root, "BAT0/charge_now", "2990000\n")
+	batts := readPowerSupplyBatteries(root)
+	batts = append(batts,
+		BatteryStatus{Name: "disagrees", Percent: 40, CurrentCharge: 2600, FullChargeCapacity: 5200},
+		BatteryStatus{Name: "no-raw", Percent: 70},
+	)
+	setPrecisePercents(batts)
+	if b := batts[0]; b.Percent != 57 || math.Abs(b.PercentPrecise-57.5) > 1e-9 || b.DisplayPercent() != b.PercentPrecise {
+		t.Fatalf("want integer Percent with a 57.5%% precise figure, got %+v", batts[0])
+	}
+	for _, b := range batts[1:] {
+		if b.PercentPrecise != 0 || b.DisplayPercent() != b.Percent {
+			t.Errorf("%s: want no precise percent, got %v", b.Name, b.PercentPrecise)
+		}
+	}
+}
+
+func TestBatteryStateBypass(t *testing.T) {
+	root := t.TempDir()
+	// Held at a firmware limit the driver doesn't expose.
+	writeSysfs(t, root, "BAT0/capacity", "62\n")
+	writeSysfs(t, root, "BAT0/status", "Not charging\n")
+	writeSysfs(t, root, "BAT0/current_now", "0\n")
+	// Dock firmware that keeps saying "Charging" while trickling 20 mA.
+	writeSysfs(t, root, "BAT1/capacity", "79\n")
+	writeSysfs(t, root, "BAT1/status", "Charging\n")
+	writeSysfs(t, root, "BAT1/current_now", "20000\n")
+	writeSysfs(t, root, "BAT2/capacity", "45\n")
+	writeSysfs(t, root, "BAT2/status", "Charging\n")
+	writeSysfs(t, root, "BAT2/current_now", "1500000\n")
+	// At its 80% limit, which is full rather than bypass.
+	writeSysfs(t, root, "BAT3/capacity", "80\n")
+	writeSysfs(t, root, "BAT3/status", "Not charging\n")
+	writeSysfs(t, root, "BAT3/charge_control_end_threshold", "80\n")
+	writeSysfs(t, root, "BAT4/capacity", "51\n")
+	writeSysfs(t, root, "BAT4/status", "Discharging\n")
+
+	batts := readPowerSupplyBatteries(root)
+	setBatteryStates(batts)
+	want := map[string]BatteryState{
+		"BAT0": BatteryStateBypass,
+		"BAT1": BatteryStateBypass,
+		"BAT2": BatteryStateCharging,
+		"BAT3": BatteryStateFull,
+		"BAT4": BatteryStateDischarging,
+	}
+	if len(batts) != len(want) {
+		t.Fatalf("expected %d batteries, got %+v", len(want), batts)
+	}
+	for _, b := range batts {
+		if b.State != want[b.Name] {
+			t.Errorf("%s (%s, %.2fA): State=%q, want %q", b.Name, b.Status, b.CurrentA, b.State, want[b.Name])
+		}
+	}
+}
+
+func TestComputeTimeToEmpty(t *testing.T) {
+	tests := []struct {
+		name string
+		b    BatteryStatus
+		want time.Duration
+		ok   bool
+	}{
+		// 3000 mAh at 12 V is 36 Wh; 12 V × 1.5 A is 18 W.
+		{"mAh", BatteryStatus{Status: "discharging", VoltageV: 12, CurrentA: -1.5, CurrentCharge: 3000, CapacityUnit: CapacityMAh}, 2 * time.Hour, true},
+		{"Wh", BatteryStatus{Status: "Discharging", VoltageV: 11.4, CurrentA: -1, CurrentCharge: 17.1, CapacityUnit: CapacityWh}, 90 * time.Minute, true},
+		{"charging", BatteryStatus{Status: "charging", VoltageV: 12, CurrentA: 2, CurrentCharge: 3000, CapacityUnit: CapacityMAh}, 0, false},
+		{"no current", BatteryStatus{Status: "discharging", VoltageV: 12, CurrentCharge: 3000, CapacityUnit: CapacityMAh}, 0, false},
+		{"no charge", BatteryStatus{Status: "discharging", VoltageV: 12, CurrentA: -1.5}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.b.ComputeTimeToEmpty()
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("got %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestReadPowerSupplyBatteriesCurrent(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "50\n")
+	writeSysfs(t, root, "BAT0/status", "Discharging\n")
+	writeSysfs(t, root, "BAT0/voltage_now", "12000000\n")
+	writeSysfs(t, root, "BAT0/current_now", "1500000\n")
+	writeSysfs(t, root, "BAT1/capacity", "50\n")
+	writeSysfs(t, root, "BAT1/status", "Charging\n")
+	writeSysfs(t, root, "BAT1/voltage_now", "10000000\n")
+	writeSysfs(t, root, "BAT1/power_now", "25000000\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 2 {
+		t.Fatalf("expected two batteries, got %+v", batts)
+	}
+	if batts[0].CurrentA != -1.5 {
+		t.Fatalf("discharging current should be negative, got %v", batts[0].CurrentA)
+	}
+	if batts[1].CurrentA != 2.5 {
+		t.Fatalf("power_now ÷ voltage should give 2.5 A, got %v", batts[1].CurrentA)
+	}
+}
+
+func TestReadPowerSupplyBatteriesTimeLeft(t *testing.T) {
+	root := t.TempDir()
+	// energy_* over power_now: 30 Wh at 12 W is 2:30 to empty.
+	writeSysfs(t, root, "BAT0/capacity", "60\n")
+	writeSysfs(t, root, "BAT0/status", "Discharging\n")
+	writeSysfs(t, root, "BAT0/energy_now", "30000000\n")
+	writeSysfs(t, root, "BAT0/energy_full", "50000000\n")
+	writeSysfs(t, root, "BAT0/power_now", "12000000\n")
+	// charge_* over current_now while charging: 2000 mAh to go at 1.5 A is 1:20.
+	writeSysfs(t, root, "BAT1/capacity", "60\n")
+	writeSysfs(t, root, "BAT1/status", "Charging\n")
+	writeSysfs(t, root, "BAT1/charge_now", "3000000\n")
+	writeSysfs(t, root, "BAT1/charge_full", "5000000\n")
+	writeSysfs(t, root, "BAT1/current_now", "1500000\n")
+	// Idle: a zero rate gives no estimate rather than dividing by zero.
+	writeSysfs(t, root, "BAT2/capacity", "60\n")
+	writeSysfs(t, root, "BAT2/status", "Discharging\n")
+	writeSysfs(t, root, "BAT2/energy_now", "30000000\n")
+	writeSysfs(t, root, "BAT2/power_now", "0\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 3 {
+		t.Fatalf("expected three batteries, got %+v", batts)
+	}
+	if batts[0].TimeLeft != "2:30" || batts[0].Watts != 12 {
+		t.Errorf("energy pair: TimeLeft %q, Watts %v; want 2:30 and 12", batts[0].TimeLeft, batts[0].Watts)
+	}
+	if batts[1].TimeLeft != "1:20" || batts[1].Watts != 0 {
+		t.Errorf("charge pair: TimeLeft %q, Watts %v; want 1:20 to full and no power_now", batts[1].TimeLeft, batts[1].Watts)
+	}
+	if batts[2].TimeLeft != "" {
+		t.Errorf("idle battery: TimeLeft %q, want none", batts[2].TimeLeft)
+	}
+}
+
+func TestChargeCurrentUtilization(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "40\n")
+	writeSysfs(t, root, "BAT0/status", "Charging\n")
+	writeSysfs(t, root, "BAT0/current_now", "1500000\n")
+	writeSysfs(t, root, "BAT0/constant_charge_current_max", "3000000\n")
+	writeSysfs(t, root, "BAT1/capacity", "40\n")
+	writeSysfs(t, root, "BAT1/status", "Charging\n")
+	writeSysfs(t, root, "BAT1/current_now", "1500000\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 2 {
+		t.Fatalf("expected two batteries, got %+v", batts)
+	}
+	if share, ok := batts[0].ChargeCurrentUtilization(); !ok || batts[0].MaxChargeCurrentA != 3 || share != 0.5 {
+		t.Fatalf("BAT0: max %vA, utilization %v, %v; want 3A, 0.5", batts[0].MaxChargeCurrentA, share, ok)
+	}
+	if _, ok := batts[1].ChargeCurrentUtilization(); ok || batts[1].MaxChargeCurrentA != 0 {
+		t.Fatalf("BAT1 has no cap, got %+v", batts[1])
+	}
+
+	discharging := batts[0]
+	discharging.Status, discharging.CurrentA = "Discharging", -1.5
+	if _, ok := discharging.ChargeCurrentUtilization(); ok {
+		t.Fatal("utilization only applies while charging")
+	}
+
+	var mac BatteryStatus
+	parseSmartBatteryCurrent(`"Amperage" = 2100
+"MaxChargeCurrent" = 4200`, &mac)
+	if mac.MaxChargeCurrentA != 4.2 {
+		t.Fatalf("ioreg MaxChargeCurrent = %v, want 4.2", mac.MaxChargeCurrentA)
+	}
+}
+
+func TestChargeAnimation(t *testing.T) {
+	tests := []struct {
+		name string
+		b    BatteryStatus
+		want ChargeAnimation
+	}{
+		{"charging", BatteryStatus{Percent: 40, Status: "charging"}, ChargeAnimation{Filling: true, Percent: 40, Target: 100}},
+		{"sysfs case", BatteryStatus{Percent: 40, Status: "Charging"}, ChargeAnimation{Filling: true, Percent: 40, Target: 100}},
+		{"limited", BatteryStatus{Percent: 60, Status: "charging", ChargeLimited: true, EffectiveFullPercent: 80}, ChargeAnimation{Filling: true, Percent: 60, Target: 80}},
+		{"at limit", BatteryStatus{Percent: 80, Status: "charging", ChargeLimited: true, EffectiveFullPercent: 80}, ChargeAnimation{Percent: 80, Target: 80}},
+		{"charged", BatteryStatus{Percent: 100, Status: "charged"}, ChargeAnimation{Percent: 100, Target: 100}},
+		{"discharging", BatteryStatus{Percent: 55, Status: "discharging"}, ChargeAnimation{Percent: 55, Target: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.ChargeAnimation(); got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayCapacity(t *testing.T) {
+	prev := capacityDisplayUnit
+	t.Cleanup(func() { capacityDisplayUnit = prev })
+
+	b := BatteryStatus{DesignCapacity: 5000, FullChargeCapacity: 4500, CurrentCharge: 2250, CapacityUnit: CapacityMAh, VoltageV: 12}
+	if err := setCapacityDisplayUnit("Wh"); err != nil {
+		t.Fatal(err)
+	}
+	c, ok := b.DisplayCapacity()
+	if !ok || c != (BatteryCapacity{Design: 60, Full: 54, Current: 27, Unit: CapacityWh}) {
+		t.Fatalf("unexpected Wh capacity %+v, %v", c, ok)
+	}
+
+	// Without a voltage the reported unit is kept rather than guessing.
+	b.VoltageV = 0
+	if c, ok := b.DisplayCapacity(); !ok || c.Unit != CapacityMAh || c.Full != 4500 {
+		t.Fatalf("unexpected fallback %+v, %v", c, ok)
+	}
+	if err := setCapacityDisplayUnit("joules"); err == nil {
+		t.Fatal("expected an error for an unknown unit")
+	}
+}
+
+func TestSettleNotCharging(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT0/capacity", "80\n")
+	writeSysfs(t, root, "BAT0/status", "Not charging\n")
+	writeSysfs(t, root, "BAT0/charge_control_end_threshold", "80\n")
+	writeSysfs(t, root, "BAT1/capacity", "75\n")
+	writeSysfs(t, root, "BAT1/status", "Not charging\n")
+
+	batts := readPowerSupplyBatteries(root)
+	if len(batts) != 2 {
+		t.Fatalf("expected 2 batteries, got %+v", batts)
+	}
+	if batts[0].Status != "Full" {
+		t.Fatalf("not charging at an 80%% limit is full, got %q", batts[0].Status)
+	}
+	if batts[1].Status != "Not charging" {
+		t.Fatalf("not charging at 75%% without a limit must stay, got %q", batts[1].Status)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const upowerQueryTimeout = time.Second
+
+// upowerStatus maps UPower "state" values onto sysfs spellings.
+var upowerStatus = map[string]string{
+	"charging":          "Charging",
+	"discharging":       "Discharging",
+	"fully-charged":     "Full",
+	"pending-charge":    "Not charging",
+	"pending-discharge": "Not charging",
+	"empty":             "Discharging",
+}
+
+// upowerBatteries lists UPower devices and reads every system battery through
+// `upower -i`. Peripheral batteries (mice, headsets) are skipped.
+func upowerBatteries(ctx context.Context) (batts []BatteryStatus, present bool) {
+	if !commandExists("upower") {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, upowerQueryTimeout)
+	defer cancel()
+	out, err := runCmdEnv(ctx, englishLocaleEnv, "upower", "-e")
+	if err != nil {
+		return nil, false
+	}
+	for line := range strings.Lines(out) {
+		path := strings.TrimSpace(line)
+		if !strings.Contains(path, "/battery_") {
+			continue
+		}
+		info, err := runCmdEnv(ctx, englishLocaleEnv, "upower", "-i", path)
+		if err != nil {
+			continue
+		}
+		b, ok, isBattery := parseUPowerDevice(info)
+		present = present || isBattery
+		if ok {
+			batts = append(batts, b)
+		}
+	}
+	return batts, present
+}
+
+// parseUPowerDevice reads one `upower -i` report. isBattery is set for a present
+// system battery even when its figures are unusable.
+func parseUPowerDevice(out string) (b BatteryStatus, ok, isBattery bool) {
+	fields := make(map[string]string)
+	for line := range strings.Lines(out) {
+		if key, value, found := strings.Cut(line, ":"); found {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if fields["power supply"] != "yes" || fields["present"] != "yes" {
+		return BatteryStatus{}, false, false
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(fields["percentage"], "%"), 64)
+	if err != nil {
+		return BatteryStatus{}, false, true
+	}
+	if percent, ok = normalizeBatteryPercent(percent); !ok {
+		return BatteryStatus{}, false, true
+	}
+	status, known := upowerStatus[fields["state"]]
+	if !known {
+		status = "Unknown"
+	}
+	b = BatteryStatus{
+		Name:    fields["native-path"],
+		Percent: percent,
+		Status:  status,
+		Source:  "upower",
+	}
+	if b.Name == "" {
+		b.Name = "BAT0"
+	}
+	if v, ok := upowerValue(fields["voltage"], "V"); ok {
+		b.VoltageV = v
+	}
+	if cycles, err := strconv.Atoi(fields["charge-cycles"]); err == nil && cycles > 0 {
+		b.CycleCount = cycles
+	}
+	full, fullOK := upowerValue(fields["energy-full"], "Wh")
+	now, nowOK := upowerValue(fields["energy"], "Wh")
+	if fullOK && nowOK && full > 0 {
+		b.FullChargeCapacity = full
+		b.CurrentCharge = now
+		b.CapacityUnit = CapacityWh
+		if design, ok := upowerValue(fields["energy-full-design"], "Wh"); ok {
+			b.DesignCapacity = design
+		}
+	}
+	technology := fields["technology"]
+	if technology == "unknown" {
+		technology = ""
+	}
+	b.setController(BatteryController{
+		Manufacturer: fields["vendor"],
+		Model:        fields["model"],
+		Chemistry:    technology,
+		Serial:       fields["serial"],
+	})
+	b.settleNotCharging()
+	return b, true, true
+}
+
+// upowerValue parses "<n> <unit>", e.g. "12.1 V"; other units are rejected.
+func upowerValue(value, unit string) (float64, bool) {
+	num, u, ok := strings.Cut(value, " ")
+	if !ok || strings.TrimSpace(u) != unit {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	return v, err == nil && v >= 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestParseUPowerDevice(t *testing.T) {
+	out := `  native-path:          BAT0
+  vendor:               SMP
+  model:                5B10W13930
+  power supply:         yes
+  updated:              Tue 14 Oct 2026 10:12:01 AM UTC (12 seconds ago)
+  battery
+    present:             yes
+    rechargeable:        yes
+    state:               discharging
+    energy:              30.5 Wh
+    energy-full:         45 Wh
+    energy-full-design:  50 Wh
+    energy-rate:         8.2 W
+    voltage:             12.1 V
+    charge-cycles:       120
+    time to empty:       3.7 hours
+    percentage:          67%
+    capacity:            90%
+`
+	b, ok, isBattery := parseUPowerDevice(out)
+	if !ok || !isBattery {
+		t.Fatalf("expected a battery, got ok=%v isBattery=%v", ok, isBattery)
+	}
+	if b.Name != "BAT0" || b.Percent != 67 || b.Status != "Discharging" || b.Source != "upower" {
+		t.Fatalf("unexpected battery %+v", b)
+	}
+	if b.VoltageV != 12.1 || b.CycleCount != 120 {
+		t.Fatalf("voltage %v, cycles %d", b.VoltageV, b.CycleCount)
+	}
+	if b.CapacityUnit != CapacityWh || b.FullChargeCapacity != 45 || b.DesignCapacity != 50 || b.CurrentCharge != 30.5 {
+		t.Fatalf("unexpected capacity %+v", b)
+	}
+}
+
+func TestParseUPowerDeviceSkipsPeripherals(t *testing.T) {
+	mouse := `  native-path:          hidpp_battery_0
+  power supply:         no
+  battery
+    present:             yes
+    percentage:          55%
+`
+	if _, ok, isBattery := parseUPowerDevice(mouse); ok || isBattery {
+		t.Fatal("peripheral battery should be skipped")
+	}
+
+	unreadable := `  native-path:          BAT1
+  power supply:         yes
+  battery
+    present:             yes
+    state:               unknown
+    percentage:          ignored
+`
+	if _, ok, isBattery := parseUPowerDevice(unreadable); ok || !isBattery {
+		t.Fatalf("unreadable system battery: ok=%v isBattery=%v", ok, isBattery)
+	}
+}
@@ -0,0 +1,102 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+
+	"github.com/yusufpapurcu/wmi"
+)
+
+// WMI classes, with only the properties read; the wmi package selects by field name.
+type (
+	win32Battery struct {
+		Name                     string
+		EstimatedChargeRemaining uint16
+		BatteryStatus            uint16
+		EstimatedRunTime         uint32
+	}
+	wmiBatteryStatus struct {
+		RemainingCapacity uint32
+		ChargeRate        int32
+		DischargeRate     int32
+		Voltage           uint32
+	}
+	wmiBatteryStaticData    struct{ DesignedCapacity uint32 }
+	wmiBatteryFullCapacity  struct{ FullChargedCapacity uint32 }
+	wmiBatteryCycleCountRow struct{ CycleCount uint32 }
+)
+
+// readWMIBatteries queries Win32_Battery and fills in the root\wmi extras. The
+// root\wmi classes list batteries in the same ACPI order as Win32_Battery, so rows
+// are joined by index; a class that fails or is short just leaves its fields 0,
+// which happens on drivers that only implement Win32_Battery.
+func readWMIBatteries(ctx context.Context) ([]wmiBattery, error) {
+	ctx, cancel := context.WithTimeout(ctx, wmiBatteryTimeout)
+	defer cancel()
+
+	var base []win32Battery
+	if err := wmiQuery(ctx, &base, `root\cimv2`, "Win32_Battery"); err != nil {
+		return nil, err
+	}
+	var (
+		status []wmiBatteryStatus
+		static []wmiBatteryStaticData
+		full   []wmiBatteryFullCapacity
+		cycles []wmiBatteryCycleCountRow
+	)
+	_ = wmiQuery(ctx, &status, `root\wmi`, "BatteryStatus")
+	_ = wmiQuery(ctx, &static, `root\wmi`, "BatteryStaticData")
+	_ = wmiQuery(ctx, &full, `root\wmi`, "BatteryFullChargedCapacity")
+	_ = wmiQuery(ctx, &cycles, `root\wmi`, "BatteryCycleCount")
+
+	out := make([]wmiBattery, len(base))
+	for i, b := range base {
+		w := wmiBattery{
+			Name:                     b.Name,
+			EstimatedChargeRemaining: b.EstimatedChargeRemaining,
+			BatteryStatus:            b.BatteryStatus,
+			EstimatedRunTime:         b.EstimatedRunTime,
+		}
+		if i < len(status) {
+			s := status[i]
+			w.RemainingCapacity, w.VoltageMV, w.ChargeRateMW, w.DischargeRateMW = s.RemainingCapacity, s.Voltage, s.ChargeRate, s.DischargeRate
+		}
+		if i < len(static) {
+			w.DesignedCapacity = static[i].DesignedCapacity
+		}
+		if i < len(full) {
+			w.FullChargedCapacity = full[i].FullChargedCapacity
+		}
+		if i < len(cycles) {
+			w.CycleCount = cycles[i].CycleCount
+		}
+		out[i] = w
+	}
+	return out, nil
+}
+
+// wmiQuery runs a WMI query for class into dst, giving up when ctx is done. The
+// wmi package can't be cancelled, so an abandoned query finishes in the background
+// and its result is dropped.
+func wmiQuery[T any](ctx context.Context, dst *[]T, namespace, class string) error {
+	type result struct {
+		rows []T
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var rows []T
+		err := wmi.QueryNamespace(wmi.CreateQuery(&rows, "", class), &rows, namespace)
+		done <- result{rows, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err == nil {
+			*dst = r.rows
+		}
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// readWMIBatteries is Windows-only; the wmi source is never in the chain elsewhere.
+func readWMIBatteries(context.Context) ([]wmiBattery, error) {
+	return nil, errors.ErrUnsupported
+}
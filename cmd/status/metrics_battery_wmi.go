@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// wmiBatteryTimeout bounds the WMI queries; a wedged WMI service can stall them
+// for minutes.
+const wmiBatteryTimeout = 3 * time.Second
+
+// wmiBattery is one battery as Windows reports it: Win32_Battery from root\cimv2,
+// joined with the root\wmi classes that carry what Win32_Battery leaves out.
+// Capacities are in mWh and 0 when the driver doesn't expose them.
+type wmiBattery struct {
+	Name                     string
+	EstimatedChargeRemaining uint16 // Percent
+	BatteryStatus            uint16 // See wmiBatteryStatusText
+	EstimatedRunTime         uint32 // Minutes; wmiRunTimeUnknown on AC
+
+	CycleCount          uint32 // BatteryCycleCount
+	DesignedCapacity    uint32 // BatteryStaticData
+	FullChargedCapacity uint32 // BatteryFullChargedCapacity
+	RemainingCapacity   uint32 // BatteryStatus (root\wmi)
+	VoltageMV           uint32
+	ChargeRateMW        int32
+	DischargeRateMW     int32
+}
+
+// wmiRunTimeUnknown is the EstimatedRunTime Windows reports while on AC power.
+const wmiRunTimeUnknown = 71582788
+
+// wmiBatteryStatusText maps Win32_Battery.BatteryStatus codes to the status words
+// the sysfs source uses, so the UI needs no per-OS cases. Low and critical are
+// discharging states, and 2 ("on AC, not necessarily charging") is "Not charging",
+// which settleNotCharging turns into Full at 100%.
+func wmiBatteryStatusText(code uint16) string {
+	switch code {
+	case 1, 4, 5: // Other (documented as discharging), Low, Critical
+		return "Discharging"
+	case 2, 11: // On AC, Partially Charged
+		return "Not charging"
+	case 3: // Fully Charged
+		return "Full"
+	case 6, 7, 8, 9: // Charging, and its High/Low/Critical variants
+		return "Charging"
+	}
+	return "Unknown" // 10 Undefined, or anything newer
+}
+
+// status converts w to a BatteryStatus.
+func (w wmiBattery) status() (BatteryStatus, bool) {
+	percent, ok := normalizeBatteryPercent(float64(w.EstimatedChargeRemaining))
+	if !ok {
+		return BatteryStatus{}, false
+	}
+	name := w.Name
+	if name == "" {
+		name = "Battery"
+	}
+	b := BatteryStatus{
+		Name:       name,
+		Percent:    percent,
+		Status:     wmiBatteryStatusText(w.BatteryStatus),
+		CycleCount: int(w.CycleCount),
+		Source:     "wmi",
+	}
+	if w.EstimatedRunTime > 0 && w.EstimatedRunTime != wmiRunTimeUnknown && b.Status == "Discharging" {
+		b.TimeLeft = fmt.Sprintf("%d:%02d", w.EstimatedRunTime/60, w.EstimatedRunTime%60)
+	}
+	if w.DesignedCapacity > 0 && w.FullChargedCapacity > 0 {
+		b.DesignCapacity = float64(w.DesignedCapacity) / 1000
+		b.FullChargeCapacity = float64(w.FullChargedCapacity) / 1000
+		b.CurrentCharge = float64(w.RemainingCapacity) / 1000
+		b.CapacityUnit = CapacityWh
+	}
+	if w.VoltageMV > 0 {
+		b.VoltageV = float64(w.VoltageMV) / 1000
+		// Current follows the sysfs convention: negative while discharging.
+		switch {
+		case w.DischargeRateMW > 0:
+			b.CurrentA = -float64(w.DischargeRateMW) / float64(w.VoltageMV)
+		case w.ChargeRateMW > 0:
+			b.CurrentA = float64(w.ChargeRateMW) / float64(w.VoltageMV)
+		}
+	}
+	b.settleNotCharging()
+	return b, true
+}
+
+// wmiBatteries reads every battery WMI lists; present reports whether Windows
+// listed any, usable or not.
+func wmiBatteries(read func() ([]wmiBattery, error)) (batts []BatteryStatus, present bool) {
+	raw, err := read()
+	if err != nil {
+		return nil, false
+	}
+	for _, w := range raw {
+		if b, ok := w.status(); ok {
+			batts = append(batts, b)
+		}
+	}
+	return batts, len(raw) > 0
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWMIBatteryStatusText(t *testing.T) {
+	for code, want := range map[uint16]string{
+		1: "Discharging", 2: "Not charging", 3: "Full", 4: "Discharging", 5: "Discharging",
+		6: "Charging", 7: "Charging", 8: "Charging", 9: "Charging", 10: "Unknown", 11: "Not charging", 42: "Unknown",
+	} {
+		if got := wmiBatteryStatusText(code); got != want {
+			t.Errorf("code %d: got %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestWMIBatteryStatusDischarging(t *testing.T) {
+	b, ok := wmiBattery{
+		Name: "DELL 7FHP845", EstimatedChargeRemaining: 64, BatteryStatus: 1, EstimatedRunTime: 185,
+		CycleCount: 212, DesignedCapacity: 54000, FullChargedCapacity: 48600, RemainingCapacity: 31100,
+		VoltageMV: 12000, DischargeRateMW: 9000,
+	}.status()
+	if !ok {
+		t.Fatal("expected a battery")
+	}
+	if b.Percent != 64 || b.Status != "Discharging" || b.TimeLeft != "3:05" || b.CycleCount != 212 || b.Source != "wmi" {
+		t.Fatalf("unexpected battery %+v", b)
+	}
+	if h, ok := b.HealthPercent(); !ok || math.Abs(h-90) > 0.01 || b.CapacityUnit != CapacityWh {
+		t.Fatalf("health = %.2f (%v) in %q, want 90%% in Wh", h, ok, b.CapacityUnit)
+	}
+	if math.Abs(b.CurrentA+0.75) > 0.001 || b.VoltageV != 12 {
+		t.Fatalf("current = %.3fA at %.1fV, want -0.75A at 12V", b.CurrentA, b.VoltageV)
+	}
+	if batteryState(b) != BatteryStateDischarging {
+		t.Fatalf("state = %q", batteryState(b))
+	}
+}
+
+func TestWMIBatteryStatusOnAC(t *testing.T) {
+	// On AC Windows reports a sentinel run time, and code 2 at 100% is full.
+	b, ok := wmiBattery{EstimatedChargeRemaining: 100, BatteryStatus: 2, EstimatedRunTime: wmiRunTimeUnknown}.status()
+	if !ok || b.Name != "Battery" || b.TimeLeft != "" || b.Status != "Full" || batteryState(b) != BatteryStateFull {
+		t.Fatalf("unexpected battery %+v", b)
+	}
+	if _, ok := (wmiBattery{EstimatedChargeRemaining: 255}).status(); ok {
+		t.Fatal("an out-of-range percent should be rejected")
+	}
+}
+
+func TestWMIBatteriesPresence(t *testing.T) {
+	if batts, present := wmiBatteries(func() ([]wmiBattery, error) { return nil, errors.New("access denied") }); batts != nil || present {
+		t.Fatalf("a failed query should report nothing, got %v %v", batts, present)
+	}
+	batts, present := wmiBatteries(func() ([]wmiBattery, error) { return []wmiBattery{{EstimatedChargeRemaining: 250}}, nil })
+	if len(batts) != 0 || !present {
+		t.Fatalf("an unreadable battery should still count as present, got %v %v", batts, present)
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"errors"
-	"runtime"
 	"strings"
 	"time"
 )
@@ -13,18 +12,18 @@ const (
 	bluetoothctlTimeout = 1500 * time.Millisecond
 )
 
-func (c *Collector) collectBluetooth(now time.Time) []BluetoothDevice {
+func (c *Collector) collectBluetooth(ctx context.Context, now time.Time) []BluetoothDevice {
 	if len(c.lastBT) > 0 && !c.lastBTAt.IsZero() && now.Sub(c.lastBTAt) < bluetoothCacheTTL {
 		return c.lastBT
 	}
 
-	if devs, err := readSystemProfilerBluetooth(); err == nil && len(devs) > 0 {
+	if devs, err := readSystemProfilerBluetooth(ctx); err == nil && len(devs) > 0 {
 		c.lastBTAt = now
 		c.lastBT = devs
 		return devs
 	}
 
-	if devs, err := readBluetoothCTLDevices(); err == nil && len(devs) > 0 {
+	if devs, err := readBluetoothCTLDevices(ctx); err == nil && len(devs) > 0 {
 		c.lastBTAt = now
 		c.lastBT = devs
 		return devs
@@ -37,27 +36,27 @@ func (c *Collector) collectBluetooth(now time.Time) []BluetoothDevice {
 	return c.lastBT
 }
 
-func readSystemProfilerBluetooth() ([]BluetoothDevice, error) {
-	if runtime.GOOS != "darwin" || !commandExists("system_profiler") {
+func readSystemProfilerBluetooth(ctx context.Context) ([]BluetoothDevice, error) {
+	if probeOS != "darwin" || !commandExists("system_profiler") {
 		return nil, errors.New("system_profiler unavailable")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), systemProfilerTimeout)
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.profilerTimeout(systemProfilerTimeout))
 	defer cancel()
 
-	out, err := runCmd(ctx, "system_profiler", "SPBluetoothDataType")
+	out, err := runCmdEnv(ctx, englishLocaleEnv, "system_profiler", "SPBluetoothDataType")
 	if err != nil {
 		return nil, err
 	}
 	return parseSPBluetooth(out), nil
 }
 
-func readBluetoothCTLDevices() ([]BluetoothDevice, error) {
+func readBluetoothCTLDevices(ctx context.Context) ([]BluetoothDevice, error) {
 	if !commandExists("bluetoothctl") {
 		return nil, errors.New("bluetoothctl unavailable")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), bluetoothctlTimeout)
+	ctx, cancel := context.WithTimeout(ctx, bluetoothctlTimeout)
 	defer cancel()
 
 	out, err := runCmd(ctx, "bluetoothctl", "info")
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const chargerQueryTimeout = 500 * time.Millisecond
+
+// ChargerInfo describes the connected USB-C/MagSafe charger and the negotiated PD profile.
+type ChargerInfo struct {
+	Known        bool // Some source could tell; Connected is meaningless otherwise
+	Connected    bool
+	Description  string  // Adapter description (e.g. "pd charger")
+	Watts        float64 // Adapter rating on macOS, power delivered over USB on Linux; 0 when unknown
+	VoltageV     float64 // Negotiated voltage
+	CurrentA     float64 // Negotiated current
+	FastCharging bool    // Negotiated a high-voltage PD profile
+
+	ExpectedWatts       float64 // Smallest adapter Apple ships with this model; 0 when unknown
+	UnderpoweredCharger bool    // Watts is well below ExpectedWatts
+}
+
+// Profile renders the negotiated PD profile, e.g. "20V/4.7A".
+func (c ChargerInfo) Profile() string {
+	if c.VoltageV <= 0 || c.CurrentA <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%gV/%gA", c.VoltageV, c.CurrentA)
+}
+
+// collectChargerInfo is opt-in because it spawns an extra ioreg query per refresh.
+var collectChargerInfo bool
+
+func collectCharger(ctx context.Context) ChargerInfo {
+	if probeOS != "darwin" {
+		return ChargerInfo{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.commandTimeout(chargerQueryTimeout))
+	defer cancel()
+	out, err := runCmd(ctx, "ioreg", "-rn", "AppleSmartBattery")
+	if err != nil {
+		return ChargerInfo{}
+	}
+	return parseChargerInfo(out)
+}
+
+// parseChargerInfo reads adapter and USB-PD (HVC) details from AppleSmartBattery ioreg output.
+func parseChargerInfo(out string) ChargerInfo {
+	var (
+		info     ChargerInfo
+		hvcIndex = -1
+		hvcMenu  string
+	)
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, `"ExternalConnected" = `):
+			info.Known = true
+			info.Connected = strings.HasSuffix(line, "Yes")
+		case strings.HasPrefix(line, `"AdapterDetails" = {`):
+			if watts, ok := ioregDictInt(line, "Watts"); ok {
+				info.Watts = float64(watts)
+			}
+			if mv, ok := ioregDictInt(line, "AdapterVoltage"); ok {
+				info.VoltageV = float64(mv) / 1000
+			}
+			if ma, ok := ioregDictInt(line, "Current"); ok {
+				info.CurrentA = float64(ma) / 1000
+			}
+			if desc, ok := ioregDictString(line, "Description"); ok {
+				info.Description = desc
+			}
+		case strings.HasPrefix(line, `"UsbHvcHvcIndex" = `):
+			hvcIndex, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, `"UsbHvcHvcIndex" = `)))
+		case strings.HasPrefix(line, `"UsbHvcMenu" = (`):
+			hvcMenu = line
+		}
+	}
+
+	// The HVC menu lists every PD profile the charger offers; the index marks the negotiated one.
+	if hvcIndex >= 0 && hvcMenu != "" {
+		for entry := range strings.SplitSeq(hvcMenu, "}") {
+			idx, ok := ioregDictInt(entry, "Index")
+			if !ok || int(idx) != hvcIndex {
+				continue
+			}
+			if mv, ok := ioregDictInt(entry, "MaxVoltage"); ok {
+				info.VoltageV = float64(mv) / 1000
+			}
+			if ma, ok := ioregDictInt(entry, "MaxCurrent"); ok {
+				info.CurrentA = float64(ma) / 1000
+			}
+		}
+	}
+
+	info.FastCharging = info.Connected && info.VoltageV > 5
+	return info
+}
+
+// ratedAdapterWatts maps MacBook model identifiers to the smallest power adapter
+// Apple bundles with them. Configurations that ship with a bigger adapter still
+// charge from the smaller one, so warning below it avoids false alarms.
+var ratedAdapterWatts = map[string]float64{
+	"MacBookAir8,1": 30, "MacBookAir8,2": 30, "MacBookAir9,1": 30, "MacBookAir10,1": 30,
+	"MacBookPro15,1": 87, "MacBookPro15,3": 87, "MacBookPro16,1": 96, "MacBookPro16,4": 96,
+	"MacBookPro15,2": 61, "MacBookPro15,4": 61, "MacBookPro16,2": 61, "MacBookPro16,3": 61,
+	"MacBookPro17,1": 61,
+	"MacBookPro18,1": 140, "MacBookPro18,2": 140, "MacBookPro18,3": 67, "MacBookPro18,4": 67,
+	"Mac14,2": 30, "Mac14,15": 35, "Mac14,7": 67,
+	"Mac14,5": 67, "Mac14,9": 67, "Mac14,6": 140, "Mac14,10": 140,
+	"Mac15,3": 70, "Mac15,6": 70, "Mac15,8": 96, "Mac15,10": 96,
+	"Mac15,7": 140, "Mac15,9": 140, "Mac15,11": 140,
+	"Mac15,12": 30, "Mac15,13": 35,
+}
+
+// underpoweredRatio is how far below the rated wattage an adapter may fall
+// before it is flagged; a 60W charger on a 61W machine is fine.
+const underpoweredRatio = 0.9
+
+// withDetails layers the opt-in ioreg query over the always-on adapter probe.
+// ioreg wins when it answered, borrowing the probe's wattage if it had none.
+// ioreg keeps stale AdapterDetails around after unplugging, so a disconnected
+// charger reports no watts.
+func (c ChargerInfo) withDetails(d ChargerInfo) ChargerInfo {
+	if !d.Known {
+		return c
+	}
+	switch {
+	case !d.Connected:
+		d.Watts = 0
+	case d.Watts == 0:
+		d.Watts = c.Watts
+	}
+	return d
+}
+
+// checkRating compares the connected adapter against the model's rated wattage.
+func (c *ChargerInfo) checkRating(modelID string) {
+	c.ExpectedWatts = ratedAdapterWatts[modelID]
+	c.UnderpoweredCharger = c.Connected && c.Watts > 0 && c.ExpectedWatts > 0 &&
+		c.Watts < c.ExpectedWatts*underpoweredRatio
+}
+
+// ioregDictInt extracts an integer value for "key"=value from an ioreg inline dictionary.
+func ioregDictInt(s, key string) (int64, bool) {
+	_, after, found := strings.Cut(s, `"`+key+`"=`)
+	if !found {
+		return 0, false
+	}
+	end := strings.IndexAny(after, ",})")
+	if end >= 0 {
+		after = after[:end]
+	}
+	val, err := strconv.ParseInt(strings.TrimSpace(after), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return val, true
+}
+
+// ioregDictString extracts a quoted string value for "key"="value" from an ioreg inline dictionary.
+func ioregDictString(s, key string) (string, bool) {
+	_, after, found := strings.Cut(s, `"`+key+`"="`)
+	if !found {
+		return "", false
+	}
+	val, _, found := strings.Cut(after, `"`)
+	return val, found
+}
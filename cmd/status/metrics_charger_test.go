@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func TestParseChargerInfoPDProfile(t *testing.T) {
+	out := `+-o AppleSmartBattery  <class AppleSmartBattery>
+    {
+      "ExternalConnected" = Yes
+      "AdapterDetails" = {"Watts"=96,"Current"=4700,"AdapterVoltage"=20000,"Description"="pd charger","IsWireless"=No}
+      "UsbHvcMenu" = ({"Index"=0,"MaxVoltage"=5000,"MaxCurrent"=3000},{"Index"=1,"MaxVoltage"=9000,"MaxCurrent"=3000},{"Index"=2,"MaxVoltage"=20000,"MaxCurrent"=4700})
+      "UsbHvcHvcIndex" = 2
+    }`
+	info := parseChargerInfo(out)
+	if !info.Connected || info.Watts != 96 || info.Description != "pd charger" {
+		t.Fatalf("unexpected charger info %+v", info)
+	}
+	if info.Profile() != "20V/4.7A" {
+		t.Fatalf("unexpected profile %q", info.Profile())
+	}
+	if !info.FastCharging {
+		t.Fatalf("expected fast charging on a 20V profile")
+	}
+}
+
+func TestParseChargerInfoWeakCharger(t *testing.T) {
+	out := `      "ExternalConnected" = Yes
+      "AdapterDetails" = {"Watts"=15,"Current"=3000,"AdapterVoltage"=5000}
+      "UsbHvcMenu" = ({"Index"=0,"MaxVoltage"=5000,"MaxCurrent"=3000})
+      "UsbHvcHvcIndex" = 0`
+	info := parseChargerInfo(out)
+	if info.FastCharging {
+		t.Fatalf("5V charger should not report fast charging: %+v", info)
+	}
+	if info.Profile() != "5V/3A" {
+		t.Fatalf("unexpected profile %q", info.Profile())
+	}
+}
+
+func TestParseChargerInfoDisconnected(t *testing.T) {
+	info := parseChargerInfo(`"ExternalConnected" = No`)
+	if info.Connected || info.FastCharging || info.Watts != 0 {
+		t.Fatalf("expected empty charger info, got %+v", info)
+	}
+}
+
+func TestCheckRating(t *testing.T) {
+	tests := []struct {
+		name         string
+		charger      ChargerInfo
+		modelID      string
+		wantWatts    float64
+		wantExpected float64
+		wantWarn     bool
+	}{
+		{name: "30W on 16-inch", charger: ChargerInfo{Connected: true, Watts: 30}, modelID: "MacBookPro18,1", wantWatts: 30, wantExpected: 140, wantWarn: true},
+		{name: "bundled adapter", charger: ChargerInfo{Connected: true, Watts: 96}, modelID: "MacBookPro16,1", wantWatts: 96, wantExpected: 96},
+		{name: "within tolerance", charger: ChargerInfo{Connected: true, Watts: 60}, modelID: "MacBookPro17,1", wantWatts: 60, wantExpected: 61},
+		{name: "unknown model", charger: ChargerInfo{Connected: true, Watts: 20}, modelID: "Mac99,1", wantWatts: 20},
+		{name: "unplugged", charger: ChargerInfo{Known: true}, modelID: "Mac15,3", wantExpected: 70},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.charger
+			c.checkRating(tt.modelID)
+			if c.Watts != tt.wantWatts || c.ExpectedWatts != tt.wantExpected || c.UnderpoweredCharger != tt.wantWarn {
+				t.Fatalf("checkRating = %+v, want watts %v expected %v underpowered %v", c, tt.wantWatts, tt.wantExpected, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestChargerWithDetails(t *testing.T) {
+	probe := ChargerInfo{Known: true, Connected: true, Watts: 30}
+	if got := probe.withDetails(ChargerInfo{}); got != probe {
+		t.Fatalf("no ioreg answer should keep the probe, got %+v", got)
+	}
+	detail := ChargerInfo{Known: true, Connected: true, VoltageV: 15, CurrentA: 2}
+	if got := probe.withDetails(detail); got.Watts != 30 || got.Profile() != "15V/2A" {
+		t.Fatalf("ioreg without watts should borrow the probe's, got %+v", got)
+	}
+	stale := ChargerInfo{Known: true, Watts: 96}
+	if got := probe.withDetails(stale); got.Connected || got.Watts != 0 {
+		t.Fatalf("stale AdapterDetails on battery should report no charger, got %+v", got)
+	}
+}
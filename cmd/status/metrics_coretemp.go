@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Keys as readHwmonSensors and lm-sensors build them; see hwmonSensorKey.
+const (
+	coretempPackagePrefix = "coretemp_packageid" // One per socket
+	coretempCorePrefix    = "coretemp_core"
+	cpuPackageKey         = "cpu_package" // Synthesized by addCPUPackage; no driver uses it
+)
+
+// k10tempPackageKeys are AMD's die-level readings, hottest-first preference:
+// Tdie is the real die temperature, Tctl can carry a fan-control offset.
+var k10tempPackageKeys = []string{"k10temp_tdie", "k10temp_tctl"}
+
+// cpuPackageTemp picks the CPU package temperature out of hwmon or lm-sensors
+// readings: the hottest coretemp package (one per socket), else k10temp's die
+// reading, else the hottest coretemp core. ok is false without any of them.
+func cpuPackageTemp(readings []SensorReading) (float64, bool) {
+	var pkg, core float64
+	for _, r := range readings {
+		switch {
+		case strings.HasPrefix(r.Key, coretempPackagePrefix), r.Key == cpuPackageKey:
+			pkg = max(pkg, r.Value)
+		case strings.HasPrefix(r.Key, coretempCorePrefix):
+			core = max(core, r.Value)
+		}
+	}
+	if pkg > 0 {
+		return pkg, true
+	}
+	for _, key := range k10tempPackageKeys {
+		for _, r := range readings {
+			if r.Key == key && r.Value > 0 {
+				return r.Value, true
+			}
+		}
+	}
+	return core, core > 0
+}
+
+// addCPUPackage appends a "CPU Package" reading, the hottest core, when coretemp
+// reports cores but no package sensor, as some VMs and older CPUs do. The
+// per-core readings are kept as they are, so each core stays told apart by key.
+func addCPUPackage(readings []SensorReading) []SensorReading {
+	var hottest float64
+	cores := 0
+	for _, r := range readings {
+		switch {
+		case strings.HasPrefix(r.Key, coretempPackagePrefix), r.Key == cpuPackageKey:
+			return readings
+		case strings.HasPrefix(r.Key, coretempCorePrefix) && r.Unit == "°C":
+			hottest = max(hottest, r.Value)
+			cores++
+		}
+	}
+	if cores == 0 {
+		return readings
+	}
+	return append(readings, SensorReading{
+		Key:   cpuPackageKey,
+		Label: "CPU Package",
+		Value: hottest,
+		Unit:  "°C",
+		Note:  "max of " + strconv.Itoa(cores) + " cores",
+		Class: SensorClassCPU,
+	})
+}
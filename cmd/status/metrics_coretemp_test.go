@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestCPUPackageTemp(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "hwmon0/name", "coretemp\n")
+	writeSysfs(t, root, "hwmon0/temp1_input", "61000\n")
+	writeSysfs(t, root, "hwmon0/temp1_label", "Package id 0\n")
+	writeSysfs(t, root, "hwmon0/temp2_input", "66000\n")
+	writeSysfs(t, root, "hwmon0/temp2_label", "Core 0\n")
+	writeSysfs(t, root, "hwmon1/name", "coretemp\n")
+	writeSysfs(t, root, "hwmon1/temp1_input", "64000\n")
+	writeSysfs(t, root, "hwmon1/temp1_label", "Package id 1\n")
+
+	readings := readHwmonSensors(root)
+	// The socket sensors win over a hotter core, and the hotter socket wins.
+	if got, ok := cpuPackageTemp(readings); !ok || got != 64 {
+		t.Fatalf("cpuPackageTemp = %v, %v; want 64", got, ok)
+	}
+	if got := addCPUPackage(readings); len(got) != len(readings) {
+		t.Fatalf("package sensor present, expected nothing added: %+v", got)
+	}
+}
+
+func TestAddCPUPackageFromCores(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "hwmon0/name", "coretemp\n")
+	writeSysfs(t, root, "hwmon0/temp2_input", "58000\n")
+	writeSysfs(t, root, "hwmon0/temp2_label", "Core 0\n")
+	writeSysfs(t, root, "hwmon0/temp3_input", "63000\n")
+	writeSysfs(t, root, "hwmon0/temp3_label", "Core 1\n")
+	writeSysfs(t, root, "hwmon0/temp4_input", "180000\n") // Implausible, dropped
+	writeSysfs(t, root, "hwmon0/temp4_label", "Core 2\n")
+
+	got := addCPUPackage(readHwmonSensors(root))
+	if len(got) != 3 {
+		t.Fatalf("expected two cores and the package, got %+v", got)
+	}
+	// Cores keep their own keys and labels.
+	if got[0].Key != "coretemp_core0" || got[0].Label != "Core 0" || got[1].Key != "coretemp_core1" {
+		t.Fatalf("per-core readings changed: %+v", got[:2])
+	}
+	want := SensorReading{Key: cpuPackageKey, Label: "CPU Package", Value: 63, Unit: "°C", Note: "max of 2 cores", Class: SensorClassCPU}
+	if got[2] != want {
+		t.Fatalf("package = %+v, want %+v", got[2], want)
+	}
+	if temp, ok := cpuPackageTemp(got); !ok || temp != 63 {
+		t.Fatalf("cpuPackageTemp = %v, %v; want 63", temp, ok)
+	}
+}
+
+func TestCPUPackageTempK10temp(t *testing.T) {
+	readings := []SensorReading{
+		{Key: "k10temp_tctl", Value: 72},
+		{Key: "k10temp_tdie", Value: 62},
+	}
+	if got, ok := cpuPackageTemp(readings); !ok || got != 62 {
+		t.Fatalf("cpuPackageTemp = %v, %v; want Tdie 62", got, ok)
+	}
+	if _, ok := cpuPackageTemp([]SensorReading{{Key: "nvme_composite", Value: 40}}); ok {
+		t.Fatal("expected no package temperature without CPU sensors")
+	}
+}
@@ -17,13 +17,13 @@ const (
 	cpuSampleInterval = 200 * time.Millisecond
 )
 
-func collectCPU() (CPUStatus, error) {
-	counts, countsErr := cpu.Counts(false)
+func collectCPU(ctx context.Context) (CPUStatus, error) {
+	counts, countsErr := cpu.CountsWithContext(ctx, false)
 	if countsErr != nil || counts == 0 {
 		counts = runtime.NumCPU()
 	}
 
-	logical, logicalErr := cpu.Counts(true)
+	logical, logicalErr := cpu.CountsWithContext(ctx, true)
 	if logicalErr != nil || logical == 0 {
 		logical = runtime.NumCPU()
 	}
@@ -32,13 +32,17 @@ func collectCPU() (CPUStatus, error) {
 	}
 
 	// Two-call pattern for more reliable CPU usage.
-	warmUpCPU()
-	time.Sleep(cpuSampleInterval)
-	percents, err := cpu.Percent(0, true)
+	warmUpCPU(ctx)
+	select {
+	case <-ctx.Done():
+		return CPUStatus{}, ctx.Err()
+	case <-time.After(cpuSampleInterval):
+	}
+	percents, err := cpu.PercentWithContext(ctx, 0, true)
 	var totalPercent float64
 	perCoreEstimated := false
 	if err != nil || len(percents) == 0 {
-		fallbackUsage, fallbackPerCore, fallbackErr := fallbackCPUUtilization(logical)
+		fallbackUsage, fallbackPerCore, fallbackErr := fallbackCPUUtilization(ctx, logical)
 		if fallbackErr != nil {
 			if err != nil {
 				return CPUStatus{}, err
@@ -55,19 +59,19 @@ func collectCPU() (CPUStatus, error) {
 		totalPercent /= float64(len(percents))
 	}
 
-	loadStats, loadErr := load.Avg()
+	loadStats, loadErr := load.AvgWithContext(ctx)
 	var loadAvg load.AvgStat
 	if loadStats != nil {
 		loadAvg = *loadStats
 	}
 	if loadErr != nil || isZeroLoad(loadAvg) {
-		if fallback, err := fallbackLoadAvgFromUptime(); err == nil {
+		if fallback, err := fallbackLoadAvgFromUptime(ctx); err == nil {
 			loadAvg = fallback
 		}
 	}
 
 	// P/E core counts for Apple Silicon.
-	pCores, eCores := getCoreTopology()
+	pCores, eCores := getCoreTopology(ctx)
 
 	return CPUStatus{
 		Usage:            totalPercent,
@@ -95,8 +99,8 @@ var (
 )
 
 // getCoreTopology returns P/E core counts on Apple Silicon.
-func getCoreTopology() (pCores, eCores int) {
-	if runtime.GOOS != "darwin" {
+func getCoreTopology(ctx context.Context) (pCores, eCores int) {
+	if probeOS != "darwin" {
 		return 0, 0
 	}
 
@@ -107,7 +111,7 @@ func getCoreTopology() (pCores, eCores int) {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.commandTimeout(quickQueryTimeout))
 	defer cancel()
 
 	out, err := runCmd(ctx, "sysctl", "-n",
@@ -150,11 +154,11 @@ func getCoreTopology() (pCores, eCores int) {
 	return pCores, eCores
 }
 
-func fallbackLoadAvgFromUptime() (load.AvgStat, error) {
+func fallbackLoadAvgFromUptime(ctx context.Context) (load.AvgStat, error) {
 	if !commandExists("uptime") {
 		return load.AvgStat{}, errors.New("uptime command unavailable")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancel()
 
 	out, err := runCmd(ctx, "uptime")
@@ -202,7 +206,7 @@ func fallbackLoadAvgFromUptime() (load.AvgStat, error) {
 	}, nil
 }
 
-func fallbackCPUUtilization(logical int) (float64, []float64, error) {
+func fallbackCPUUtilization(ctx context.Context, logical int) (float64, []float64, error) {
 	if logical <= 0 {
 		logical = runtime.NumCPU()
 	}
@@ -210,7 +214,7 @@ func fallbackCPUUtilization(logical int) (float64, []float64, error) {
 		logical = 1
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancel()
 
 	out, err := runCmd(ctx, "ps", "-Aceo", "pcpu")
@@ -256,6 +260,6 @@ func fallbackCPUUtilization(logical int) (float64, []float64, error) {
 	return avg, perCore, nil
 }
 
-func warmUpCPU() {
-	cpu.Percent(0, true) //nolint:errcheck
+func warmUpCPU(ctx context.Context) {
+	cpu.PercentWithContext(ctx, 0, true) //nolint:errcheck
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -22,8 +21,8 @@ var skipDiskMounts = map[string]bool{
 	"/dev":                     true,
 }
 
-func collectDisks() ([]DiskStatus, error) {
-	partitions, err := disk.Partitions(false)
+func collectDisks(ctx context.Context) ([]DiskStatus, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, false)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +33,7 @@ func collectDisks() ([]DiskStatus, error) {
 		seenVolume = make(map[string]bool)
 	)
 	for _, part := range partitions {
-		if strings.HasPrefix(part.Device, "/dev/loop") {
+		if deviceIgnored(part.Device, ignoreDiskDevices) {
 			continue
 		}
 		if skipDiskMounts[part.Mountpoint] {
@@ -54,7 +53,7 @@ func collectDisks() ([]DiskStatus, error) {
 		if seenDevice[baseDevice] {
 			continue
 		}
-		usage, err := disk.Usage(part.Mountpoint)
+		usage, err := disk.UsageWithContext(ctx, part.Mountpoint)
 		if err != nil || usage.Total == 0 {
 			continue
 		}
@@ -79,7 +78,7 @@ func collectDisks() ([]DiskStatus, error) {
 		seenVolume[volKey] = true
 	}
 
-	annotateDiskTypes(disks)
+	annotateDiskTypes(ctx, disks)
 
 	sort.Slice(disks, func(i, j int) bool {
 		return disks[i].Total > disks[j].Total
@@ -99,8 +98,8 @@ var (
 	diskCacheTTL    = 2 * time.Minute
 )
 
-func annotateDiskTypes(disks []DiskStatus) {
-	if len(disks) == 0 || runtime.GOOS != "darwin" || !commandExists("diskutil") {
+func annotateDiskTypes(ctx context.Context, disks []DiskStatus) {
+	if len(disks) == 0 || probeOS != "darwin" || !commandExists("diskutil") {
 		return
 	}
 
@@ -122,7 +121,7 @@ func annotateDiskTypes(disks []DiskStatus) {
 			continue
 		}
 
-		external, err := isExternalDisk(base)
+		external, err := isExternalDisk(ctx, base)
 		if err != nil {
 			external = strings.HasPrefix(disks[i].Mount, "/Volumes/")
 		}
@@ -144,8 +143,8 @@ func baseDeviceName(device string) string {
 	return device
 }
 
-func isExternalDisk(device string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+func isExternalDisk(ctx context.Context, device string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
 	out, err := runCmd(ctx, "diskutil", "info", device)
@@ -174,18 +173,26 @@ func isExternalDisk(device string) (bool, error) {
 	return external, nil
 }
 
-func (c *Collector) collectDiskIO(now time.Time) DiskIOStatus {
-	counters, err := disk.IOCounters()
+func (c *Collector) collectDiskIO(ctx context.Context, now time.Time) DiskIOStatus {
+	counters, err := disk.IOCountersWithContext(ctx)
 	if err != nil || len(counters) == 0 {
 		return DiskIOStatus{}
 	}
 
 	var total disk.IOCountersStat
-	for _, v := range counters {
+	for name, v := range counters {
+		if deviceIgnored(name, ignoreDiskDevices) {
+			continue
+		}
 		total.ReadBytes += v.ReadBytes
 		total.WriteBytes += v.WriteBytes
 	}
 
+	return c.diskIORates(total, now)
+}
+
+// diskIORates turns cumulative disk counters into per-second rates since the last sample.
+func (c *Collector) diskIORates(total disk.IOCountersStat, now time.Time) DiskIOStatus {
 	if c.lastDiskAt.IsZero() {
 		c.prevDiskIO = total
 		c.lastDiskAt = now
@@ -197,18 +204,11 @@ func (c *Collector) collectDiskIO(now time.Time) DiskIOStatus {
 		elapsed = 1
 	}
 
-	readRate := float64(total.ReadBytes-c.prevDiskIO.ReadBytes) / 1024 / 1024 / elapsed
-	writeRate := float64(total.WriteBytes-c.prevDiskIO.WriteBytes) / 1024 / 1024 / elapsed
+	readRate := counterRateMB(total.ReadBytes, c.prevDiskIO.ReadBytes, elapsed)
+	writeRate := counterRateMB(total.WriteBytes, c.prevDiskIO.WriteBytes, elapsed)
 
 	c.prevDiskIO = total
 	c.lastDiskAt = now
 
-	if readRate < 0 {
-		readRate = 0
-	}
-	if writeRate < 0 {
-		writeRate = 0
-	}
-
 	return DiskIOStatus{ReadRate: readRate, WriteRate: writeRate}
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+func TestDiskIORatesCounterReset(t *testing.T) {
+	c := NewCollector()
+	start := time.Now()
+
+	c.diskIORates(disk.IOCountersStat{ReadBytes: 100 << 20, WriteBytes: 50 << 20}, start)
+	got := c.diskIORates(disk.IOCountersStat{ReadBytes: 110 << 20, WriteBytes: 55 << 20}, start.Add(time.Second))
+	if got.ReadRate != 10 || got.WriteRate != 5 {
+		t.Fatalf("unexpected disk rates %+v", got)
+	}
+
+	got = c.diskIORates(disk.IOCountersStat{ReadBytes: 1 << 20, WriteBytes: 1 << 20}, start.Add(2*time.Second))
+	if got.ReadRate != 0 || got.WriteRate != 0 {
+		t.Fatalf("expected zero disk rates after counter reset, got %+v", got)
+	}
+}
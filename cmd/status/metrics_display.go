@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const displayQueryTimeout = 500 * time.Millisecond
+
+// Linux backlight and LED class roots.
+const (
+	backlightRoot = "/sys/class/backlight"
+	ledsRoot      = "/sys/class/leds"
+)
+
+// DisplayStatus is screen and keyboard backlight state, context for battery drain.
+// Each level is only meaningful when its Has flag is set.
+type DisplayStatus struct {
+	BrightnessPercent float64 // Built-in display brightness, 0–100
+	BacklightOn       bool    // False when the panel backlight is off (lid closed, display asleep)
+	HasBrightness     bool
+	KeyboardPercent   float64 // Keyboard backlight level, 0–100
+	HasKeyboard       bool
+	Source            string // ioreg or sysfs
+}
+
+// collectDisplayInfo is opt-in because brightness only matters when explaining drain.
+var collectDisplayInfo bool
+
+// collectDisplay reads brightness from ioreg IODisplayParameters on macOS and from
+// the backlight and LED classes on Linux. nil means nothing was exposed. macOS
+// doesn't publish keyboard backlight levels in ioreg, so HasKeyboard stays false there.
+func collectDisplay(ctx context.Context) *DisplayStatus {
+	switch probeOS {
+	case "darwin":
+		ctx, cancel := context.WithTimeout(ctx, collectOptions.commandTimeout(displayQueryTimeout))
+		defer cancel()
+		out, err := runCmd(ctx, "ioreg", "-r", "-k", "IODisplayParameters", "-d", "1")
+		if err != nil {
+			return nil
+		}
+		return parseIODisplayBrightness(out)
+	case "linux":
+		return readSysfsBacklight(backlightRoot, ledsRoot)
+	}
+	return nil
+}
+
+// parseIODisplayBrightness reads the first display's
+// "brightness"={"max"=N,"min"=N,"value"=N} entry from IODisplayParameters.
+func parseIODisplayBrightness(out string) *DisplayStatus {
+	for line := range strings.Lines(out) {
+		if !strings.Contains(line, `"IODisplayParameters" = {`) {
+			continue
+		}
+		_, after, found := strings.Cut(line, `"brightness"={`)
+		if !found {
+			continue
+		}
+		entry, _, _ := strings.Cut(after, "}")
+		lo, _ := ioregDictInt(entry, "min")
+		hi, okMax := ioregDictInt(entry, "max")
+		v, okValue := ioregDictInt(entry, "value")
+		if !okMax || !okValue || hi <= lo {
+			continue
+		}
+		pct := scalePercent(v-lo, hi-lo)
+		return &DisplayStatus{BrightnessPercent: pct, BacklightOn: pct > 0, HasBrightness: true, Source: "ioreg"}
+	}
+	return nil
+}
+
+// readSysfsBacklight reads the first backlight device and the first keyboard
+// backlight LED. bl_power is 0 when the panel backlight is on.
+func readSysfsBacklight(backlights, leds string) *DisplayStatus {
+	var d *DisplayStatus
+	devices, _ := filepath.Glob(filepath.Join(backlights, "*"))
+	for _, dir := range devices {
+		pct, ok := readSysfsLevel(dir)
+		if !ok {
+			continue
+		}
+		d = &DisplayStatus{BrightnessPercent: pct, BacklightOn: pct > 0, HasBrightness: true, Source: "sysfs"}
+		if power, ok := readSysfsInt(filepath.Join(dir, "bl_power")); ok && power != 0 {
+			d.BacklightOn = false
+		}
+		break
+	}
+	keyboards, _ := filepath.Glob(filepath.Join(leds, "*kbd_backlight"))
+	for _, dir := range keyboards {
+		pct, ok := readSysfsLevel(dir)
+		if !ok {
+			continue
+		}
+		if d == nil {
+			// No panel backlight (external monitor only); keep the keyboard reading.
+			d = &DisplayStatus{Source: "sysfs"}
+		}
+		d.KeyboardPercent, d.HasKeyboard = pct, true
+		break
+	}
+	return d
+}
+
+// readSysfsLevel reads brightness over max_brightness as a percent.
+func readSysfsLevel(dir string) (float64, bool) {
+	hi, okMax := readSysfsInt(filepath.Join(dir, "max_brightness"))
+	v, okValue := readSysfsInt(filepath.Join(dir, "brightness"))
+	if !okMax || !okValue || hi <= 0 {
+		return 0, false
+	}
+	return scalePercent(v, hi), true
+}
+
+// scalePercent maps v out of span onto 0–100, clamped.
+func scalePercent(v, span int64) float64 {
+	return min(max(float64(v)*100/float64(span), 0), 100)
+}
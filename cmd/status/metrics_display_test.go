@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestParseIODisplayBrightness(t *testing.T) {
+	out := `+-o AppleCLCD2  <class AppleCLCD2, id 0x100000abc, registered, matched, active, busy 0 (0 ms), retain 12>
+    {
+      "IODisplayParameters" = {"commit"={"reg"=0},"brightness"={"max"=65536,"min"=0,"value"=52429},"linear-brightness"={"max"=1024,"min"=0,"value"=640}}
+    }`
+	d := parseIODisplayBrightness(out)
+	if d == nil || !d.HasBrightness || !d.BacklightOn || d.Source != "ioreg" {
+		t.Fatalf("unexpected display %+v", d)
+	}
+	if d.BrightnessPercent < 79.9 || d.BrightnessPercent > 80.1 {
+		t.Fatalf("brightness = %v, want ~80", d.BrightnessPercent)
+	}
+	if parseIODisplayBrightness(`"IODisplayParameters" = {"commit"={"reg"=0}}`) != nil {
+		t.Fatal("expected nil without a brightness entry")
+	}
+}
+
+func TestReadSysfsBacklight(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "backlight/intel_backlight/brightness", "19200\n")
+	writeSysfs(t, root, "backlight/intel_backlight/max_brightness", "96000\n")
+	writeSysfs(t, root, "backlight/intel_backlight/bl_power", "0\n")
+	writeSysfs(t, root, "leds/tpacpi::kbd_backlight/brightness", "1\n")
+	writeSysfs(t, root, "leds/tpacpi::kbd_backlight/max_brightness", "2\n")
+	writeSysfs(t, root, "leds/input3::capslock/brightness", "1\n")
+	writeSysfs(t, root, "leds/input3::capslock/max_brightness", "1\n")
+
+	d := readSysfsBacklight(root+"/backlight", root+"/leds")
+	if d == nil {
+		t.Fatal("expected a display status")
+	}
+	want := DisplayStatus{BrightnessPercent: 20, BacklightOn: true, HasBrightness: true, KeyboardPercent: 50, HasKeyboard: true, Source: "sysfs"}
+	if *d != want {
+		t.Fatalf("got %+v, want %+v", *d, want)
+	}
+	if got := displayText(*d); got != "Display 20% · Keyboard 50%" {
+		t.Fatalf("displayText = %q", got)
+	}
+
+	writeSysfs(t, root, "backlight/intel_backlight/bl_power", "4\n")
+	if d := readSysfsBacklight(root+"/backlight", root+"/leds"); d.BacklightOn {
+		t.Fatalf("bl_power 4 means the backlight is off: %+v", d)
+	}
+	if d := readSysfsBacklight(t.TempDir(), t.TempDir()); d != nil {
+		t.Fatalf("expected nil with no devices, got %+v", d)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// energyImpactQueryTimeout covers top's two samples a second apart plus startup.
+const energyImpactQueryTimeout = 3 * time.Second
+
+// ProcessEnergy is one process's macOS "energy impact", the unitless score
+// Activity Monitor shows: a weighted mix of CPU, wakeups, GPU and I/O.
+type ProcessEnergy struct {
+	PID    int
+	Name   string
+	Impact float64
+}
+
+// Energy impact settings; set from --energy-impact and --energy-top.
+var (
+	collectEnergyImpact bool
+	energyImpactTopN    = 5
+)
+
+// energyImpactRefresh is how old the energy impact list may get before a new top
+// sample is started.
+const energyImpactRefresh = 5 * time.Second
+
+// energySampler keeps top off the Collect path: its two samples take over a
+// second, so a stale list is served while one background run replaces it. The
+// first Collect after start has no list yet.
+type energySampler struct {
+	mu      sync.Mutex
+	procs   []ProcessEnergy
+	at      time.Time
+	running bool
+	sample  func(context.Context) []ProcessEnergy
+}
+
+func newEnergySampler() *energySampler {
+	return &energySampler{sample: collectProcessEnergy}
+}
+
+// get returns the latest list, starting a refresh when it is older than
+// energyImpactRefresh and none is running.
+func (s *energySampler) get(now time.Time) []ProcessEnergy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running && (s.at.IsZero() || now.Sub(s.at) >= energyImpactRefresh) {
+		s.running = true
+		go func() {
+			procs := s.sample(context.Background())
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.procs, s.at, s.running = procs, time.Now(), false
+		}()
+	}
+	return slices.Clone(s.procs)
+}
+
+// collectProcessEnergy lists the energyImpactTopN processes with the highest energy
+// impact. top's first sample has no history and reports 0 for everyone, so two
+// samples are taken and only the second is read. nil off macOS or when top fails.
+func collectProcessEnergy(ctx context.Context) []ProcessEnergy {
+	if probeOS != "darwin" || energyImpactTopN <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, energyImpactQueryTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "top", "-l", "2", "-s", "1", "-o", "power", "-n", strconv.Itoa(energyImpactTopN), "-stats", "pid,power,command")
+	if err != nil {
+		return nil
+	}
+	return topEnergyImpact(parseTopPower(out), energyImpactTopN)
+}
+
+// parseTopPower reads the last "PID POWER COMMAND" table of `top -l N -stats
+// pid,power,command`. The command comes last because it may contain spaces.
+func parseTopPower(out string) []ProcessEnergy {
+	var procs []ProcessEnergy
+	inTable := false
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "PID" && fields[1] == "POWER" {
+			// A later sample's table replaces the earlier one.
+			procs, inTable = procs[:0], true
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		if len(fields) < 3 {
+			inTable = false
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			inTable = false
+			continue
+		}
+		impact, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, ProcessEnergy{PID: pid, Name: strings.Join(fields[2:], " "), Impact: impact})
+	}
+	return procs
+}
+
+// topEnergyImpact orders procs by impact, highest first, and keeps the first n
+// with a nonzero score.
+func topEnergyImpact(procs []ProcessEnergy, n int) []ProcessEnergy {
+	procs = slices.DeleteFunc(procs, func(p ProcessEnergy) bool { return p.Impact <= 0 })
+	slices.SortStableFunc(procs, func(a, b ProcessEnergy) int { return cmp.Compare(b.Impact, a.Impact) })
+	return procs[:min(len(procs), n)]
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseTopPowerReadsLastSample(t *testing.T) {
+	out := `Processes: 512 total, 3 running, 509 sleeping, 2533 threads
+2026/10/14 10:00:00
+Load Avg: 2.10, 2.35, 2.41
+
+PID    POWER COMMAND
+0      0.0   kernel_task
+412    0.0   WindowServer
+Processes: 512 total, 2 running, 510 sleeping, 2530 threads
+2026/10/14 10:00:01
+Load Avg: 2.10, 2.35, 2.41
+
+PID    POWER COMMAND
+981    42.1  Google Chrome Helper (Renderer)
+412    12.3  WindowServer
+0      8.0   kernel_task
+77     0.0   launchd
+`
+	got := topEnergyImpact(parseTopPower(out), 2)
+	want := []ProcessEnergy{
+		{PID: 981, Name: "Google Chrome Helper (Renderer)", Impact: 42.1},
+		{PID: 412, Name: "WindowServer", Impact: 12.3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestTopEnergyImpactDropsIdle(t *testing.T) {
+	got := topEnergyImpact([]ProcessEnergy{{PID: 1, Name: "a", Impact: 0}, {PID: 2, Name: "b", Impact: 1.5}, {PID: 3, Name: "c", Impact: 3}}, 5)
+	if len(got) != 2 || got[0].PID != 3 || got[1].PID != 2 {
+		t.Fatalf("got %+v", got)
+	}
+	if text := energyImpactText(got); text != "Energy: c 3.0 · b 1.5" {
+		t.Fatalf("energyImpactText = %q", text)
+	}
+}
+
+func TestEnergySamplerDoesNotBlockCollect(t *testing.T) {
+	release := make(chan struct{})
+	var runs atomic.Int32
+	s := &energySampler{sample: func(context.Context) []ProcessEnergy {
+		runs.Add(1)
+		<-release
+		return []ProcessEnergy{{PID: 7, Name: "Safari", Impact: 12}}
+	}}
+	now := time.Now()
+	if got := s.get(now); len(got) != 0 {
+		t.Fatalf("first call should return at once with nothing, got %+v", got)
+	}
+	// Still sampling: nothing blocks and no second top is started.
+	if got := s.get(now.Add(energyImpactRefresh)); len(got) != 0 {
+		t.Fatalf("want nothing while the first sample runs, got %+v", got)
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if got := s.get(now); len(got) == 1 && got[0].Name == "Safari" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background sample never landed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if runs.Load() != 1 {
+		t.Fatalf("want one top run, got %d", runs.Load())
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+// FanControl says whether the fans follow the firmware's curve or were forced by a
+// tool such as Macs Fan Control, which explains RPMs that don't track temperature.
+// Empty means it couldn't be read; only macOS reports it.
+type FanControl string
+
+const (
+	FanControlUnknown FanControl = ""
+	FanControlAuto    FanControl = "Auto"
+	FanControlManual  FanControl = "Manual"
+)
+
+// smcFanModeForced is the per-fan FnMd value for a fan driven at a fixed target.
+const smcFanModeForced = 1
+
+// smcFanModes is what the SMC reports about fan control. Modes holds the FnMd value
+// of each fan that has one; Forced is the older Intel "FS! " bitmask, bit n for fan n.
+type smcFanModes struct {
+	Modes     []int
+	Forced    int
+	HasForced bool
+}
+
+// mode is Manual when any fan is forced. Per-fan FnMd wins over FS!, which newer
+// machines no longer update.
+func (s smcFanModes) mode() FanControl {
+	if len(s.Modes) > 0 {
+		for _, m := range s.Modes {
+			if m == smcFanModeForced {
+				return FanControlManual
+			}
+		}
+		return FanControlAuto
+	}
+	if !s.HasForced {
+		return FanControlUnknown
+	}
+	if s.Forced != 0 {
+		return FanControlManual
+	}
+	return FanControlAuto
+}
+
+// readFanControl reads the SMC fan mode keys; read-only, fans are never changed.
+func readFanControl() FanControl {
+	modes, ok := readSMCFanModes()
+	if !ok {
+		return FanControlUnknown
+	}
+	return modes.mode()
+}
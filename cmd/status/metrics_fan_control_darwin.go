@@ -0,0 +1,136 @@
+//go:build darwin && cgo
+
+package main
+
+/*
+#cgo LDFLAGS: -framework IOKit
+#include <IOKit/IOKitLib.h>
+#include <mach/mach.h>
+#include <string.h>
+
+// AppleSMC user client call layout, shared by every SMC tool.
+typedef struct {
+	char major, minor, build, reserved;
+	UInt16 release;
+} moleSMCVers;
+
+typedef struct {
+	UInt16 version, length;
+	UInt32 cpuPLimit, gpuPLimit, memPLimit;
+} moleSMCPLimit;
+
+typedef struct {
+	UInt32 dataSize;
+	UInt32 dataType;
+	char dataAttributes;
+} moleSMCKeyInfo;
+
+typedef struct {
+	UInt32 key;
+	moleSMCVers vers;
+	moleSMCPLimit pLimitData;
+	moleSMCKeyInfo keyInfo;
+	char result, status, data8;
+	UInt32 data32;
+	unsigned char bytes[32];
+} moleSMCData;
+
+enum { moleSMCIndex = 2, moleSMCReadBytes = 5, moleSMCReadKeyInfo = 9, moleSMCMaxFans = 8 };
+
+typedef struct {
+	int ok;
+	int fans;
+	int modes[moleSMCMaxFans];
+	int hasModes[moleSMCMaxFans];
+	int forced;
+	int hasForced;
+} moleFanModes;
+
+static UInt32 moleFourCC(const char *key) {
+	return ((UInt32)key[0] << 24) | ((UInt32)key[1] << 16) | ((UInt32)key[2] << 8) | (UInt32)key[3];
+}
+
+static int moleSMCCall(io_connect_t conn, moleSMCData *in, moleSMCData *out) {
+	size_t outSize = sizeof(moleSMCData);
+	return IOConnectCallStructMethod(conn, moleSMCIndex, in, sizeof(moleSMCData), out, &outSize) == kIOReturnSuccess && out->result == 0;
+}
+
+// moleSMCReadInt reads an unsigned big-endian key of up to 4 bytes; -1 if absent.
+static long moleSMCReadInt(io_connect_t conn, const char *key) {
+	moleSMCData in, out;
+	memset(&in, 0, sizeof(in));
+	memset(&out, 0, sizeof(out));
+	in.key = moleFourCC(key);
+	in.data8 = moleSMCReadKeyInfo;
+	if (!moleSMCCall(conn, &in, &out)) {
+		return -1;
+	}
+	UInt32 size = out.keyInfo.dataSize;
+	if (size == 0 || size > 4) {
+		return -1;
+	}
+	in.keyInfo.dataSize = size;
+	in.data8 = moleSMCReadBytes;
+	memset(&out, 0, sizeof(out));
+	if (!moleSMCCall(conn, &in, &out)) {
+		return -1;
+	}
+	long v = 0;
+	for (UInt32 i = 0; i < size; i++) {
+		v = (v << 8) | out.bytes[i];
+	}
+	return v;
+}
+
+static moleFanModes moleReadFanModes(void) {
+	moleFanModes m;
+	memset(&m, 0, sizeof(m));
+	io_service_t svc = IOServiceGetMatchingService(MACH_PORT_NULL, IOServiceMatching("AppleSMC"));
+	if (svc == IO_OBJECT_NULL) {
+		return m;
+	}
+	io_connect_t conn = IO_OBJECT_NULL;
+	kern_return_t kr = IOServiceOpen(svc, mach_task_self(), 0, &conn);
+	IOObjectRelease(svc);
+	if (kr != KERN_SUCCESS) {
+		return m;
+	}
+	long fans = moleSMCReadInt(conn, "FNum");
+	if (fans > 0) {
+		m.fans = fans < moleSMCMaxFans ? (int)fans : moleSMCMaxFans;
+		for (int i = 0; i < m.fans; i++) {
+			char key[5] = {'F', (char)('0' + i), 'M', 'd', 0};
+			long mode = moleSMCReadInt(conn, key);
+			if (mode >= 0) {
+				m.modes[i] = (int)mode;
+				m.hasModes[i] = 1;
+			}
+		}
+	}
+	long forced = moleSMCReadInt(conn, "FS! ");
+	if (forced >= 0) {
+		m.forced = (int)forced;
+		m.hasForced = 1;
+	}
+	IOServiceClose(conn);
+	m.ok = 1;
+	return m;
+}
+*/
+import "C"
+
+// readSMCFanModes opens the AppleSMC user client and reads FNum, each FnMd and FS!.
+func readSMCFanModes() (smcFanModes, bool) {
+	m := C.moleReadFanModes()
+	if m.ok == 0 {
+		return smcFanModes{}, false
+	}
+	var s smcFanModes
+	for i := 0; i < int(m.fans); i++ {
+		if m.hasModes[i] != 0 {
+			s.Modes = append(s.Modes, int(m.modes[i]))
+		}
+	}
+	s.Forced, s.HasForced = int(m.forced), m.hasForced != 0
+	return s, true
+}
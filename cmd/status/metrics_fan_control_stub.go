@@ -0,0 +1,8 @@
+//go:build !darwin || !cgo
+
+package main
+
+// readSMCFanModes is unavailable without the SMC; FanControl stays unknown.
+func readSMCFanModes() (smcFanModes, bool) {
+	return smcFanModes{}, false
+}
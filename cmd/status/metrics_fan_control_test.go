@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestSMCFanModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		modes smcFanModes
+		want  FanControl
+	}{
+		{"nothing read", smcFanModes{}, FanControlUnknown},
+		{"all auto", smcFanModes{Modes: []int{0, 0}}, FanControlAuto},
+		{"one fan forced", smcFanModes{Modes: []int{0, 1}}, FanControlManual},
+		{"FnMd beats stale FS!", smcFanModes{Modes: []int{0}, Forced: 1, HasForced: true}, FanControlAuto},
+		{"FS! bitmask", smcFanModes{Forced: 0b10, HasForced: true}, FanControlManual},
+		{"FS! clear", smcFanModes{HasForced: true}, FanControlAuto},
+	}
+	for _, tt := range tests {
+		if got := tt.modes.mode(); got != tt.want {
+			t.Errorf("%s: mode() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,8 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"path/filepath"
 	"regexp"
-	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,17 +18,21 @@ const (
 	powermetricsTimeout   = 2 * time.Second
 )
 
+// noGPUMetricsName names the placeholder row collectGPU returns when no
+// source is available, so the GPU card still explains why it is blank.
+const noGPUMetricsName = "No GPU metrics available"
+
 // Regex for GPU usage parsing.
 var (
 	gpuActiveResidencyRe = regexp.MustCompile(`GPU HW active residency:\s+([\d.]+)%`)
 	gpuIdleResidencyRe   = regexp.MustCompile(`GPU idle residency:\s+([\d.]+)%`)
 )
 
-func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
-	if runtime.GOOS == "darwin" {
+func (c *Collector) collectGPU(ctx context.Context, now time.Time) ([]GPUStatus, error) {
+	if probeOS == "darwin" {
 		// Static GPU info (cached 10 min).
 		if len(c.cachedGPU) == 0 || c.lastGPUAt.IsZero() || now.Sub(c.lastGPUAt) >= macGPUInfoTTL {
-			if gpus, err := readMacGPUInfo(); err == nil && len(gpus) > 0 {
+			if gpus, err := readMacGPUInfo(ctx); err == nil && len(gpus) > 0 {
 				c.cachedGPU = gpus
 				c.lastGPUAt = now
 			}
@@ -35,7 +40,7 @@ func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
 
 		// Real-time GPU usage.
 		if len(c.cachedGPU) > 0 {
-			usage := getMacGPUUsage()
+			usage := getMacGPUUsage(ctx)
 			result := make([]GPUStatus, len(c.cachedGPU))
 			copy(result, c.cachedGPU)
 			// Apply usage to first GPU (Apple Silicon).
@@ -46,17 +51,22 @@ func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, 600*time.Millisecond)
 	defer cancel()
 
 	if !commandExists("nvidia-smi") {
+		if probeOS == "linux" {
+			if gpus := readHwmonGPUs(hwmonRoot); len(gpus) > 0 {
+				return gpus, nil
+			}
+		}
 		return []GPUStatus{{
-			Name: "No GPU metrics available",
+			Name: noGPUMetricsName,
 			Note: "Install nvidia-smi or use platform-specific metrics",
 		}}, nil
 	}
 
-	out, err := runCmd(ctx, "nvidia-smi", "--query-gpu=utilization.gpu,memory.used,memory.total,name", "--format=csv,noheader,nounits")
+	out, err := runCmd(ctx, "nvidia-smi", "--query-gpu=utilization.gpu,memory.used,memory.total,name,temperature.gpu", "--format=csv,noheader,nounits")
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +81,18 @@ func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
 		memUsed, _ := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
 		memTotal, _ := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
 		name := strings.TrimSpace(fields[3])
+		var temp float64
+		if len(fields) > 4 {
+			// "[N/A]" on GPUs without a sensor parses as 0.
+			temp, _ = strconv.ParseFloat(strings.TrimSpace(fields[4]), 64)
+		}
 
 		gpus = append(gpus, GPUStatus{
 			Name:        name,
 			Usage:       util,
 			MemoryUsed:  memUsed,
 			MemoryTotal: memTotal,
+			TempC:       temp,
 		})
 	}
 
@@ -90,8 +106,68 @@ func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
 	return gpus, nil
 }
 
-func readMacGPUInfo() ([]GPUStatus, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), systemProfilerTimeout)
+// hwmonGPUChips are the hwmon drivers that belong to a discrete or integrated GPU.
+var hwmonGPUChips = []string{"amdgpu", "radeon", "nouveau"}
+
+// readHwmonGPUs returns one GPU per amdgpu/radeon/nouveau hwmon chip, named by
+// driver and PCI slot ("amdgpu 0000:03:00.0"), with the edge temperature.
+func readHwmonGPUs(root string) []GPUStatus {
+	chips, _ := filepath.Glob(filepath.Join(root, "hwmon*"))
+	slices.SortFunc(chips, func(a, b string) int { return hwmonChipIndex(a) - hwmonChipIndex(b) })
+	var gpus []GPUStatus
+	for _, chipDir := range chips {
+		chip := readSysfsString(filepath.Join(chipDir, "name"))
+		if !slices.Contains(hwmonGPUChips, chip) {
+			continue
+		}
+		name := chip
+		if dev, err := filepath.EvalSymlinks(filepath.Join(chipDir, "device")); err == nil {
+			name += " " + filepath.Base(dev)
+		}
+		gpus = append(gpus, GPUStatus{Name: name, TempC: hwmonGPUTemp(chipDir)})
+	}
+	return gpus
+}
+
+// hwmonGPUTemp prefers the input labelled "edge" (amdgpu also reports junction
+// and memory) and falls back to temp1. 0 means no reading.
+func hwmonGPUTemp(chipDir string) float64 {
+	inputs, _ := filepath.Glob(filepath.Join(chipDir, "temp*_input"))
+	slices.SortFunc(inputs, func(a, b string) int { return hwmonIndex(a) - hwmonIndex(b) })
+	var temp float64
+	for _, input := range inputs {
+		milli, ok := readSysfsInt(input)
+		if !ok || milli <= 0 {
+			continue
+		}
+		label := readSysfsString(strings.TrimSuffix(input, "_input") + "_label")
+		if strings.EqualFold(label, "edge") {
+			return float64(milli) / 1000.0
+		}
+		if temp == 0 {
+			temp = float64(milli) / 1000.0
+		}
+	}
+	return temp
+}
+
+// hwmonChipIndex returns N from a .../hwmonN path so hwmon10 sorts after hwmon9.
+func hwmonChipIndex(path string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "hwmon"))
+	return n
+}
+
+// hottestGPUTemp is the highest GPU temperature, or 0 when no GPU reports one.
+func hottestGPUTemp(gpus []GPUStatus) float64 {
+	var hottest float64
+	for _, g := range gpus {
+		hottest = max(hottest, g.TempC)
+	}
+	return hottest
+}
+
+func readMacGPUInfo(ctx context.Context) ([]GPUStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.profilerTimeout(systemProfilerTimeout))
 	defer cancel()
 
 	if !commandExists("system_profiler") {
@@ -151,14 +227,11 @@ func readMacGPUInfo() ([]GPUStatus, error) {
 	return gpus, nil
 }
 
-// getMacGPUUsage reads GPU active residency from powermetrics.
-func getMacGPUUsage() float64 {
-	ctx, cancel := context.WithTimeout(context.Background(), powermetricsTimeout)
-	defer cancel()
-
+// getMacGPUUsage reads GPU active residency from the shared powermetrics sample.
+func getMacGPUUsage(ctx context.Context) float64 {
 	// powermetrics may require root.
-	out, err := runCmd(ctx, "powermetrics", "--samplers", "gpu_power", "-i", "500", "-n", "1")
-	if err != nil {
+	out, ok := powermetricsShared.get(ctx)
+	if !ok {
 		return -1
 	}
 
@@ -8,26 +8,22 @@ import (
 	"time"
 )
 
-func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
-	if runtime.GOOS != "darwin" {
+func collectHardware(ctx context.Context, totalRAM uint64, disks []DiskStatus) HardwareInfo {
+	if probeOS != "darwin" {
 		return HardwareInfo{
 			Model:       "Unknown",
 			CPUModel:    runtime.GOARCH,
 			TotalRAM:    humanBytes(totalRAM),
 			DiskSize:    "Unknown",
-			OSVersion:   runtime.GOOS,
+			OSVersion:   probeOS,
 			RefreshRate: "",
 		}
 	}
 
-	// Model and CPU from system_profiler.
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	var model, modelID, cpuModel, osVersion, refreshRate string
 
-	var model, cpuModel, osVersion, refreshRate string
-
-	out, err := runCmd(ctx, "system_profiler", "SPHardwareDataType")
-	if err == nil {
+	// Model and CPU from cached system_profiler.
+	if out := getSystemProfilerOutput(ctx, spHardwareDataType); out != "" {
 		for line := range strings.Lines(out) {
 			lower := strings.ToLower(strings.TrimSpace(line))
 			// Prefer "Model Name" over "Model Identifier".
@@ -37,6 +33,10 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 					model = strings.TrimSpace(parts[1])
 				}
 			}
+			if strings.Contains(lower, "model identifier:") {
+				_, id, _ := strings.Cut(line, ":")
+				modelID = strings.TrimSpace(id)
+			}
 			if strings.Contains(lower, "chip:") {
 				parts := strings.Split(line, ":")
 				if len(parts) == 2 {
@@ -52,7 +52,7 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 		}
 	}
 
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 1*time.Second)
+	ctx2, cancel2 := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel2()
 	out2, err := runCmd(ctx2, "sw_vers", "-productVersion")
 	if err == nil {
@@ -60,7 +60,7 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 	}
 
 	// Get refresh rate from display info (use mini detail to keep it fast).
-	ctx3, cancel3 := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx3, cancel3 := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel3()
 	out3, err := runCmd(ctx3, "system_profiler", "-detailLevel", "mini", "SPDisplaysDataType")
 	if err == nil {
@@ -74,6 +74,7 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 
 	return HardwareInfo{
 		Model:       model,
+		ModelID:     modelID,
 		CPUModel:    cpuModel,
 		TotalRAM:    humanBytes(totalRAM),
 		DiskSize:    diskSize,
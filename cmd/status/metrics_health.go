@@ -29,8 +29,9 @@ const (
 	diskCritThreshold = 90.0
 
 	// Thermal.
-	thermalNormalThreshold = 60.0
-	thermalHighThreshold   = 85.0
+	thermalNormalThreshold  = 60.0
+	thermalSeriousThreshold = 75.0
+	thermalHighThreshold    = 85.0
 
 	// Disk IO (MB/s).
 	ioNormalThreshold = 50.0
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const hwmonRoot = "/sys/class/hwmon"
+
+// hwmonCPUChips are drivers whose own labels ("Package id 0", "Core 3", "Tctl")
+// already say what they measure, so the chip name isn't prefixed.
+var hwmonCPUChips = []string{"coretemp", "k10temp", "zenpower"}
+
+// readHwmonSensors reads hwmon*/temp*_input (millidegrees Celsius), naming each from
+// tempN_label when present and from the chip name plus index otherwise, e.g.
+// "Core 0", "nvme Composite", "acpitz 1". Duplicate names get a numeric suffix.
+func readHwmonSensors(root string) []SensorReading {
+	chips, _ := filepath.Glob(filepath.Join(root, "hwmon*"))
+	var out []SensorReading
+	seen := make(map[string]int)
+	seenKeys := make(map[string]int)
+	unit := detectTempUnit(sensorSourceHwmon, nil, false)
+	for _, chipDir := range chips {
+		chip := readSysfsString(filepath.Join(chipDir, "name"))
+		if chip == "" {
+			chip = filepath.Base(chipDir)
+		}
+		inputs, _ := filepath.Glob(filepath.Join(chipDir, "temp*_input"))
+		slices.SortFunc(inputs, func(a, b string) int { return hwmonIndex(a) - hwmonIndex(b) })
+		for _, input := range inputs {
+			milli, ok := readSysfsInt(input)
+			if !ok {
+				continue
+			}
+			temp := toCelsius(float64(milli)/1000.0, unit)
+			if !plausibleCelsius(temp) {
+				continue
+			}
+			idx := hwmonIndex(input)
+			raw := readSysfsString(strings.TrimSuffix(input, "_input") + "_label")
+			name := hwmonSensorName(chip, raw, idx)
+			seen[name]++
+			if n := seen[name]; n > 1 {
+				name += " " + strconv.Itoa(n)
+			}
+			key := hwmonSensorKey(chip, raw, idx)
+			seenKeys[key]++
+			if n := seenKeys[key]; n > 1 {
+				key += "_" + strconv.Itoa(n)
+			}
+			out = append(out, SensorReading{
+				Key:   key,
+				Label: prettifyLabel(name),
+				Value: temp,
+				Unit:  "°C",
+				Class: classifySensor(chip + " " + raw),
+			})
+		}
+	}
+	return out
+}
+
+// readHwmonFans reads hwmon*/fan*_input (RPM) in chip and index order. count is
+// every fan input that read, spinning or not; rpms holds the nonzero ones, since
+// a 0 is a stopped fan or an unpopulated header rather than a speed.
+func readHwmonFans(root string) (rpms []int, count int) {
+	chips, _ := filepath.Glob(filepath.Join(root, "hwmon*"))
+	for _, chipDir := range chips {
+		inputs, _ := filepath.Glob(filepath.Join(chipDir, "fan*_input"))
+		slices.SortFunc(inputs, func(a, b string) int { return hwmonIndex(a) - hwmonIndex(b) })
+		for _, input := range inputs {
+			rpm, ok := readSysfsInt(input)
+			if !ok || rpm < 0 {
+				continue
+			}
+			count++
+			if rpm > 0 {
+				rpms = append(rpms, int(rpm))
+			}
+		}
+	}
+	return rpms, count
+}
+
+// hwmonSensorName builds the display name for one tempN input.
+func hwmonSensorName(chip, label string, idx int) string {
+	switch {
+	case label == "":
+		return chip + " " + strconv.Itoa(idx)
+	case slices.Contains(hwmonCPUChips, chip):
+		return label
+	default:
+		return chip + " " + label
+	}
+}
+
+// hwmonSensorKey is the raw key for one tempN input: chip and label with spaces
+// dropped, e.g. "coretemp_core0", or "nvme_temp1" when the input has no label.
+func hwmonSensorKey(chip, label string, idx int) string {
+	if label == "" {
+		label = "temp" + strconv.Itoa(idx)
+	}
+	return strings.ToLower(chip + "_" + strings.ReplaceAll(label, " ", ""))
+}
+
+// hwmonIndex returns N from a .../tempN_input or fanN_input path, or 0 when it
+// doesn't parse.
+func hwmonIndex(path string) int {
+	base := strings.TrimLeft(strings.TrimSuffix(filepath.Base(path), "_input"), "abcdefghijklmnopqrstuvwxyz")
+	n, _ := strconv.Atoi(base)
+	return n
+}
+
+// readSysfsString reads a trimmed text attribute; missing files read as "".
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
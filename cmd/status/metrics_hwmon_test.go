@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadHwmonSensors(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "hwmon0/name", "coretemp\n")
+	writeSysfs(t, root, "hwmon0/temp1_input", "61000\n")
+	writeSysfs(t, root, "hwmon0/temp1_label", "Package id 0\n")
+	writeSysfs(t, root, "hwmon0/temp2_input", "58000\n")
+	writeSysfs(t, root, "hwmon0/temp2_label", "Core 0\n")
+	writeSysfs(t, root, "hwmon0/temp10_input", "57000\n")
+	writeSysfs(t, root, "hwmon0/temp10_label", "Core 8\n")
+	writeSysfs(t, root, "hwmon1/name", "nvme\n")
+	writeSysfs(t, root, "hwmon1/temp1_input", "42850\n")
+	writeSysfs(t, root, "hwmon1/temp1_label", "Composite\n")
+	writeSysfs(t, root, "hwmon2/name", "nvme\n")
+	writeSysfs(t, root, "hwmon2/temp1_input", "39850\n")
+	writeSysfs(t, root, "hwmon2/temp1_label", "Composite\n")
+	writeSysfs(t, root, "hwmon3/name", "acpitz\n")
+	writeSysfs(t, root, "hwmon3/temp1_input", "27800\n")
+	writeSysfs(t, root, "hwmon3/temp2_input", "0\n")
+
+	got := readHwmonSensors(root)
+	want := []SensorReading{
+		{Key: "coretemp_packageid0", Label: "Package id 0", Value: 61, Unit: "°C", Class: SensorClassCPU},
+		{Key: "coretemp_core0", Label: "Core 0", Value: 58, Unit: "°C", Class: SensorClassCPU},
+		{Key: "coretemp_core8", Label: "Core 8", Value: 57, Unit: "°C", Class: SensorClassCPU},
+		{Key: "nvme_composite", Label: "nvme Composite", Value: 42.85, Unit: "°C", Class: SensorClassStorage},
+		{Key: "nvme_composite_2", Label: "nvme Composite 2", Value: 39.85, Unit: "°C", Class: SensorClassStorage},
+		{Key: "acpitz_temp1", Label: "acpitz 1", Value: 27.8, Unit: "°C", Class: SensorClassOther},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d readings, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("reading %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadHwmonGPUs(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "hwmon0/name", "k10temp\n")
+	writeSysfs(t, root, "hwmon0/temp1_input", "55000\n")
+	writeSysfs(t, root, "hwmon1/name", "amdgpu\n")
+	writeSysfs(t, root, "hwmon1/temp2_input", "71000\n")
+	writeSysfs(t, root, "hwmon1/temp2_label", "junction\n")
+	writeSysfs(t, root, "hwmon1/temp1_input", "64000\n")
+	writeSysfs(t, root, "hwmon1/temp1_label", "edge\n")
+	writeSysfs(t, root, "hwmon10/name", "nouveau\n")
+	writeSysfs(t, root, "hwmon10/temp1_input", "48000\n")
+	writeSysfs(t, root, "devices/0000:03:00.0/vendor", "0x1002\n")
+	if err := os.Symlink(filepath.Join(root, "devices/0000:03:00.0"), filepath.Join(root, "hwmon1/device")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readHwmonGPUs(root)
+	want := []GPUStatus{{Name: "amdgpu 0000:03:00.0", TempC: 64}, {Name: "nouveau", TempC: 48}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if hottestGPUTemp(got) != 64 {
+		t.Fatalf("unexpected hottest GPU temp %v", hottestGPUTemp(got))
+	}
+}
+
+func TestReadHwmonFans(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "hwmon0/name", "thinkpad\n")
+	writeSysfs(t, root, "hwmon0/fan1_input", "2710\n")
+	writeSysfs(t, root, "hwmon0/fan2_input", "0\n") // Stopped
+	writeSysfs(t, root, "hwmon0/fan10_input", "1830\n")
+	writeSysfs(t, root, "hwmon1/name", "coretemp\n")
+	writeSysfs(t, root, "hwmon1/temp1_input", "48000\n")
+
+	var thermal ThermalStatus
+	applyHwmonFans(&thermal, root)
+	if !slices.Equal(thermal.FanSpeeds, []int{2710, 1830}) {
+		t.Fatalf("FanSpeeds = %v, want spinning fans in index order", thermal.FanSpeeds)
+	}
+	if thermal.FanSpeed != 2710 || thermal.FanCount != 3 {
+		t.Fatalf("FanSpeed %d, FanCount %d; want 2710 and 3", thermal.FanSpeed, thermal.FanCount)
+	}
+
+	// Most desktops' hwmon chips have no fan inputs at all.
+	desktop := t.TempDir()
+	writeSysfs(t, desktop, "hwmon0/name", "coretemp\n")
+	writeSysfs(t, desktop, "hwmon0/temp1_input", "48000\n")
+	var none ThermalStatus
+	applyHwmonFans(&none, desktop)
+	if none.FanSpeed != 0 || none.FanCount != 0 || none.FanSpeeds != nil {
+		t.Fatalf("expected no fan data, got %+v", none)
+	}
+
+	// lm-sensors readings win when they found fans.
+	lm := ThermalStatus{FanSpeed: 3000, FanCount: 1, FanSpeeds: []int{3000}}
+	applyHwmonFans(&lm, root)
+	if lm.FanSpeed != 3000 || len(lm.FanSpeeds) != 1 {
+		t.Fatalf("hwmon overrode lm-sensors: %+v", lm)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const lmSensorsTimeout = time.Second
+
+// lmSensorsReadings runs `sensors -j` (lm-sensors). ok is false when the tool is
+// missing or its output doesn't parse, so callers fall back to raw hwmon.
+func lmSensorsReadings(ctx context.Context) (readings []SensorReading, ok bool) {
+	if !commandExists("sensors") {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, lmSensorsTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "sensors", "-j")
+	if err != nil {
+		return nil, false
+	}
+	readings, err = parseLMSensorsJSON([]byte(out))
+	return readings, err == nil && len(readings) > 0
+}
+
+// parseLMSensorsJSON reads the chip → feature → subfeature tree of `sensors -j`:
+//
+//	{"coretemp-isa-0000": {"Adapter": "ISA adapter", "Core 0": {"temp2_input": 45.0, "temp2_max": 80.0}}}
+//
+// Each feature's tempN_input, fanN_input or inN_input becomes one reading; limits
+// and alarms are ignored. Names and keys follow readHwmonSensors, so a sensor keeps
+// its key whichever of the two read it.
+func parseLMSensorsJSON(data []byte) ([]SensorReading, error) {
+	var chips map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &chips); err != nil {
+		return nil, err
+	}
+	var out []SensorReading
+	seen := make(map[string]int)
+	seenKeys := make(map[string]int)
+	unit := detectTempUnit(sensorSourceLMSensors, nil, false)
+	for _, chipID := range slices.Sorted(maps.Keys(chips)) {
+		chip, _, _ := strings.Cut(chipID, "-")
+		features := chips[chipID]
+		for _, feature := range slices.Sorted(maps.Keys(features)) {
+			var subs map[string]float64
+			if json.Unmarshal(features[feature], &subs) != nil {
+				continue // "Adapter" is a string, not a feature
+			}
+			r, idx, ok := lmSensorsInput(subs, unit)
+			if !ok {
+				continue
+			}
+			name := hwmonSensorName(chip, feature, idx)
+			seen[name]++
+			if n := seen[name]; n > 1 {
+				name += " " + strconv.Itoa(n)
+			}
+			key := hwmonSensorKey(chip, feature, idx)
+			seenKeys[key]++
+			if n := seenKeys[key]; n > 1 {
+				key += "_" + strconv.Itoa(n)
+			}
+			r.Key, r.Label = key, prettifyLabel(name)
+			if r.Class == "" {
+				r.Class = classifySensor(chip + " " + feature)
+			}
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// lmSensorsInput picks the measured value out of one feature's subfeatures.
+func lmSensorsInput(subs map[string]float64, unit TempUnit) (r SensorReading, idx int, ok bool) {
+	for name, v := range subs {
+		kind, rest, found := strings.Cut(name, "_")
+		if !found || rest != "input" {
+			continue
+		}
+		prefix := strings.TrimRight(kind, "0123456789")
+		idx, _ = strconv.Atoi(kind[len(prefix):])
+		switch prefix {
+		case "temp":
+			if v = toCelsius(v, unit); !plausibleCelsius(v) {
+				return r, 0, false
+			}
+			return SensorReading{Value: v, Unit: "°C"}, idx, true
+		case "fan":
+			if v < 0 {
+				return r, 0, false
+			}
+			return SensorReading{Value: v, Unit: "RPM", Class: SensorClassFan}, idx, true
+		case "in":
+			if v <= 0 {
+				return r, 0, false
+			}
+			return SensorReading{Value: v, Unit: "V", Class: SensorClassVoltage}, idx, true
+		}
+	}
+	return r, 0, false
+}
+
+// applyFanReadings sets FanSpeed to the fastest fan and FanCount to the number of
+// fans when the thermal probe found none of its own.
+func applyFanReadings(t *ThermalStatus, readings []SensorReading) {
+	if t.FanSpeed > 0 {
+		return
+	}
+	for _, r := range readings {
+		if r.Unit != "RPM" {
+			continue
+		}
+		t.FanCount++
+		t.FanSpeed = max(t.FanSpeed, int(r.Value))
+		if r.Value > 0 {
+			t.FanSpeeds = append(t.FanSpeeds, int(r.Value))
+		}
+	}
+}
+
+// applyHwmonFans sets the fan fields from hwmon when lm-sensors found no fans.
+// FanSpeed is the fastest fan; with no fan inputs at all everything stays zero.
+func applyHwmonFans(t *ThermalStatus, root string) {
+	if t.FanCount > 0 {
+		return
+	}
+	rpms, count := readHwmonFans(root)
+	t.FanCount, t.FanSpeeds = count, rpms
+	if len(rpms) > 0 {
+		t.FanSpeed = slices.Max(rpms)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+const lmSensorsSample = `{
+  "coretemp-isa-0000": {
+    "Adapter": "ISA adapter",
+    "Package id 0": {"temp1_input": 52.000, "temp1_max": 100.000, "temp1_crit": 100.000, "temp1_crit_alarm": 0.000},
+    "Core 0": {"temp2_input": 49.000, "temp2_max": 100.000}
+  },
+  "thinkpad-isa-0000": {
+    "Adapter": "ISA adapter",
+    "fan1": {"fan1_input": 2430.000},
+    "CPU": {"temp1_input": 51.000},
+    "GPU": {"temp2_input": -128.000}
+  },
+  "nct6798-isa-0290": {
+    "Adapter": "ISA adapter",
+    "Vcore": {"in0_input": 1.032, "in0_min": 0.000, "in0_alarm": 0.000},
+    "intrusion0": {"intrusion0_alarm": 1.000}
+  }
+}`
+
+func TestParseLMSensorsJSON(t *testing.T) {
+	readings, err := parseLMSensorsJSON([]byte(lmSensorsSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SensorReading{
+		{Key: "coretemp_core0", Label: "Core 0", Value: 49, Unit: "°C", Class: SensorClassCPU},
+		{Key: "coretemp_packageid0", Label: "Package id 0", Value: 52, Unit: "°C", Class: SensorClassCPU},
+		{Key: "nct6798_vcore", Label: "nct6798 Vcore", Value: 1.032, Unit: "V", Class: SensorClassVoltage},
+		{Key: "thinkpad_cpu", Label: "thinkpad CPU", Value: 51, Unit: "°C", Class: classifySensor("thinkpad CPU")},
+		{Key: "thinkpad_fan1", Label: "thinkpad fan1", Value: 2430, Unit: "RPM", Class: SensorClassFan},
+	}
+	if len(readings) != len(want) {
+		t.Fatalf("got %d readings, want %d: %+v", len(readings), len(want), readings)
+	}
+	for i := range want {
+		if readings[i] != want[i] {
+			t.Errorf("reading %d = %+v, want %+v", i, readings[i], want[i])
+		}
+	}
+
+	var thermal ThermalStatus
+	applyFanReadings(&thermal, readings)
+	if thermal.FanSpeed != 2430 || thermal.FanCount != 1 {
+		t.Fatalf("unexpected fans %d RPM × %d", thermal.FanSpeed, thermal.FanCount)
+	}
+
+	if _, err := parseLMSensorsJSON([]byte("No sensors found!")); err == nil {
+		t.Fatal("want an error for non-JSON output")
+	}
+}
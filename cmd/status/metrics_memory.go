@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -10,19 +9,19 @@ import (
 	"github.com/shirou/gopsutil/v4/mem"
 )
 
-func collectMemory() (MemoryStatus, error) {
-	vm, err := mem.VirtualMemory()
+func collectMemory(ctx context.Context) (MemoryStatus, error) {
+	vm, err := mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
 		return MemoryStatus{}, err
 	}
 
-	swap, _ := mem.SwapMemory()
-	pressure := getMemoryPressure()
+	swap, _ := mem.SwapMemoryWithContext(ctx)
+	pressure := getMemoryPressure(ctx)
 
 	// On macOS, vm.Cached is 0, so we calculate from file-backed pages.
 	cached := vm.Cached
-	if runtime.GOOS == "darwin" && cached == 0 {
-		cached = getFileBackedMemory()
+	if probeOS == "darwin" && cached == 0 {
+		cached = getFileBackedMemory(ctx)
 	}
 
 	return MemoryStatus{
@@ -36,8 +35,8 @@ func collectMemory() (MemoryStatus, error) {
 	}, nil
 }
 
-func getFileBackedMemory() uint64 {
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+func getFileBackedMemory(ctx context.Context) uint64 {
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancel()
 	out, err := runCmd(ctx, "vm_stat")
 	if err != nil {
@@ -75,11 +74,11 @@ func getFileBackedMemory() uint64 {
 	return 0
 }
 
-func getMemoryPressure() string {
-	if runtime.GOOS != "darwin" {
+func getMemoryPressure(ctx context.Context) string {
+	if probeOS != "darwin" {
 		return ""
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancel()
 	out, err := runCmd(ctx, "memory_pressure")
 	if err != nil {
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sysClassNet = "/sys/class/net"
+
+var (
+	// Hardware port names from networksetup; they only change when adapters are added.
+	hardwarePortMu      sync.Mutex
+	hardwarePortCache   map[string]string
+	hardwarePortCacheAt time.Time
+	hardwarePortTTL     = 5 * time.Minute
+)
+
+// hardwarePortLabels returns device → hardware port name (en0 → Wi-Fi) on macOS.
+func hardwarePortLabels(ctx context.Context) map[string]string {
+	if probeOS != "darwin" {
+		return nil
+	}
+	hardwarePortMu.Lock()
+	defer hardwarePortMu.Unlock()
+	if hardwarePortCache != nil && time.Since(hardwarePortCacheAt) < hardwarePortTTL {
+		return hardwarePortCache
+	}
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	out, err := runCmdEnv(ctx, englishLocaleEnv, "networksetup", "-listallhardwareports")
+	if err != nil {
+		return hardwarePortCache
+	}
+	hardwarePortCache = parseHardwarePorts(out)
+	hardwarePortCacheAt = time.Now()
+	return hardwarePortCache
+}
+
+// parseHardwarePorts reads "Hardware Port: Wi-Fi" / "Device: en0" pairs.
+func parseHardwarePorts(out string) map[string]string {
+	ports := make(map[string]string)
+	var port string
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		if v, ok := strings.CutPrefix(line, "Hardware Port:"); ok {
+			port = strings.TrimSpace(v)
+		} else if v, ok := strings.CutPrefix(line, "Device:"); ok && port != "" {
+			ports[strings.TrimSpace(v)] = port
+			port = ""
+		}
+	}
+	return ports
+}
+
+// interfaceLabel maps a raw interface name to a friendly label, falling back to the raw name.
+func interfaceLabel(name string, ports map[string]string) string {
+	if label, ok := ports[name]; ok {
+		return label
+	}
+	// macOS names (en0 can be Wi-Fi or Ethernet) are only trustworthy via networksetup.
+	if probeOS == "darwin" {
+		return name
+	}
+	return linuxInterfaceLabel(name)
+}
+
+// linuxInterfaceLabel guesses a label from systemd/kernel naming conventions.
+func linuxInterfaceLabel(name string) string {
+	// VLAN children (eth0.100) keep the parent's label plus the tag.
+	if parent, tag, ok := strings.Cut(name, "."); ok {
+		if label := linuxInterfaceLabel(parent); label != parent {
+			return label + " VLAN " + tag
+		}
+		return name
+	}
+	switch {
+	case strings.HasPrefix(name, "wl"):
+		return "Wi-Fi"
+	case strings.HasPrefix(name, "eth"), strings.HasPrefix(name, "en"):
+		return "Ethernet"
+	case strings.HasPrefix(name, "ww"):
+		return "Cellular"
+	case strings.HasPrefix(name, "bond"):
+		return "Bond"
+	case strings.HasPrefix(name, "wg"), strings.HasPrefix(name, "tun"), strings.HasPrefix(name, "tailscale"):
+		return "VPN"
+	}
+	return name
+}
+
+// canonicalizeNetwork fills Label on each interface. When two interfaces share a
+// label the raw name is appended so they stay distinguishable.
+func canonicalizeNetwork(stats []NetworkStatus, ports map[string]string) {
+	counts := make(map[string]int)
+	for i := range stats {
+		stats[i].Label = interfaceLabel(stats[i].Name, ports)
+		counts[stats[i].Label]++
+	}
+	for i := range stats {
+		if counts[stats[i].Label] > 1 && stats[i].Label != stats[i].Name {
+			stats[i].Label = fmt.Sprintf("%s (%s)", stats[i].Label, stats[i].Name)
+		}
+	}
+}
+
+// isBondMember reports whether a Linux interface is enslaved to a bond or team,
+// whose own counters already include its traffic.
+func isBondMember(root, name string) bool {
+	_, err := os.Stat(filepath.Join(root, name, "bonding_slave"))
+	return err == nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestParseHardwarePorts(t *testing.T) {
+	out := `
+Hardware Port: Wi-Fi
+Device: en0
+Ethernet Address: aa:bb:cc:dd:ee:ff
+
+Hardware Port: Thunderbolt Bridge
+Device: bridge0
+Ethernet Address: N/A
+
+VLAN Configurations
+===================
+`
+	ports := parseHardwarePorts(out)
+	if ports["en0"] != "Wi-Fi" || ports["bridge0"] != "Thunderbolt Bridge" || len(ports) != 2 {
+		t.Fatalf("unexpected ports %v", ports)
+	}
+}
+
+func TestLinuxInterfaceLabel(t *testing.T) {
+	tests := map[string]string{
+		"eth0":      "Ethernet",
+		"enp3s0":    "Ethernet",
+		"wlan0":     "Wi-Fi",
+		"wlp2s0":    "Wi-Fi",
+		"wwan0":     "Cellular",
+		"bond0":     "Bond",
+		"eth0.100":  "Ethernet VLAN 100",
+		"wg0":       "VPN",
+		"ib0":       "ib0",
+		"custom.10": "custom.10",
+	}
+	for name, want := range tests {
+		if got := linuxInterfaceLabel(name); got != want {
+			t.Errorf("linuxInterfaceLabel(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCanonicalizeNetworkDisambiguates(t *testing.T) {
+	ports := map[string]string{"en0": "Wi-Fi", "en5": "USB LAN", "en6": "USB LAN"}
+	stats := []NetworkStatus{{Name: "en0"}, {Name: "en5"}, {Name: "en6"}}
+	canonicalizeNetwork(stats, ports)
+	want := []string{"Wi-Fi", "USB LAN (en5)", "USB LAN (en6)"}
+	for i, w := range want {
+		if stats[i].Label != w || stats[i].Name == "" {
+			t.Fatalf("stats[%d] = %+v, want label %q", i, stats[i], w)
+		}
+	}
+}
+
+func TestIsBondMember(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "eth1/bonding_slave/state", "active\n")
+	writeSysfs(t, root, "bond0/operstate", "up\n")
+	if !isBondMember(root, "eth1") {
+		t.Fatal("expected eth1 to be a bond member")
+	}
+	if isBondMember(root, "bond0") {
+		t.Fatal("bond master must not be treated as a member")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"net/url"
 	"os"
-	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,21 +12,28 @@ import (
 	"github.com/shirou/gopsutil/v4/net"
 )
 
-func (c *Collector) collectNetwork(now time.Time) ([]NetworkStatus, error) {
-	stats, err := net.IOCounters(true)
+func (c *Collector) collectNetwork(ctx context.Context, now time.Time) ([]NetworkStatus, error) {
+	stats, err := net.IOCountersWithContext(ctx, true)
 	if err != nil {
 		return nil, err
 	}
 
 	// Map interface IPs.
-	ifAddrs := getInterfaceIPs()
+	ifAddrs := getInterfaceIPs(ctx)
 
+	result := c.networkRates(stats, ifAddrs, now)
+	canonicalizeNetwork(result, hardwarePortLabels(ctx))
+	return result, nil
+}
+
+// networkRates turns cumulative interface counters into per-second rates since the last sample.
+func (c *Collector) networkRates(stats []net.IOCountersStat, ifAddrs map[string]string, now time.Time) []NetworkStatus {
 	if c.lastNetAt.IsZero() {
 		c.lastNetAt = now
 		for _, s := range stats {
 			c.prevNet[s.Name] = s
 		}
-		return nil, nil
+		return nil
 	}
 
 	elapsed := now.Sub(c.lastNetAt).Seconds()
@@ -37,21 +43,15 @@ func (c *Collector) collectNetwork(now time.Time) ([]NetworkStatus, error) {
 
 	var result []NetworkStatus
 	for _, cur := range stats {
-		if isNoiseInterface(cur.Name) {
+		if isNoiseInterface(cur.Name) || deviceIgnored(cur.Name, ignoreNetDevices) || isBondMember(sysClassNet, cur.Name) {
 			continue
 		}
 		prev, ok := c.prevNet[cur.Name]
 		if !ok {
 			continue
 		}
-		rx := float64(cur.BytesRecv-prev.BytesRecv) / 1024.0 / 1024.0 / elapsed
-		tx := float64(cur.BytesSent-prev.BytesSent) / 1024.0 / 1024.0 / elapsed
-		if rx < 0 {
-			rx = 0
-		}
-		if tx < 0 {
-			tx = 0
-		}
+		rx := counterRateMB(cur.BytesRecv, prev.BytesRecv, elapsed)
+		tx := counterRateMB(cur.BytesSent, prev.BytesSent, elapsed)
 		result = append(result, NetworkStatus{
 			Name:      cur.Name,
 			RxRateMBs: rx,
@@ -82,12 +82,21 @@ func (c *Collector) collectNetwork(now time.Time) ([]NetworkStatus, error) {
 	c.rxHistoryBuf.Add(totalRx)
 	c.txHistoryBuf.Add(totalTx)
 
-	return result, nil
+	return result
+}
+
+// counterRateMB returns the MB/s delta between two cumulative byte counters.
+// A counter that went backwards (interface reset, VPN reconnect) yields zero for that tick.
+func counterRateMB(cur, prev uint64, elapsed float64) float64 {
+	if cur < prev || elapsed <= 0 {
+		return 0
+	}
+	return float64(cur-prev) / 1024.0 / 1024.0 / elapsed
 }
 
-func getInterfaceIPs() map[string]string {
+func getInterfaceIPs(ctx context.Context) map[string]string {
 	result := make(map[string]string)
-	ifaces, err := net.Interfaces()
+	ifaces, err := net.InterfacesWithContext(ctx)
 	if err != nil {
 		return result
 	}
@@ -115,14 +124,14 @@ func isNoiseInterface(name string) bool {
 	return false
 }
 
-func collectProxy() ProxyStatus {
+func collectProxy(ctx context.Context) ProxyStatus {
 	if proxy := collectProxyFromEnv(os.Getenv); proxy.Enabled {
 		return proxy
 	}
 
 	// macOS: check system proxy via scutil.
-	if runtime.GOOS == "darwin" {
-		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	if probeOS == "darwin" {
+		ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 		defer cancel()
 		out, err := runCmd(ctx, "scutil", "--proxy")
 		if err == nil {
@@ -131,7 +140,7 @@ func collectProxy() ProxyStatus {
 			}
 		}
 
-		if proxy := collectProxyFromTunInterfaces(); proxy.Enabled {
+		if proxy := collectProxyFromTunInterfaces(ctx); proxy.Enabled {
 			return proxy
 		}
 	}
@@ -213,8 +222,8 @@ func collectProxyFromScutilOutput(out string) ProxyStatus {
 	return ProxyStatus{Enabled: false}
 }
 
-func collectProxyFromTunInterfaces() ProxyStatus {
-	stats, err := net.IOCounters(true)
+func collectProxyFromTunInterfaces(ctx context.Context) ProxyStatus {
+	stats, err := net.IOCountersWithContext(ctx, true)
 	if err != nil {
 		return ProxyStatus{Enabled: false}
 	}
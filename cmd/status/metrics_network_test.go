@@ -1,6 +1,11 @@
 package main
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
 
 func TestCollectProxyFromEnvSupportsAllProxy(t *testing.T) {
 	env := map[string]string{
@@ -58,3 +63,31 @@ func TestCollectProxyFromScutilOutputHTTPHostPort(t *testing.T) {
 		t.Fatalf("unexpected host: %s", got.Host)
 	}
 }
+
+func TestNetworkRatesCounterReset(t *testing.T) {
+	c := NewCollector()
+	start := time.Now()
+	sample := func(recv, sent uint64) []net.IOCountersStat {
+		return []net.IOCountersStat{{Name: "en0", BytesRecv: recv, BytesSent: sent}}
+	}
+
+	if got := c.networkRates(sample(10<<20, 5<<20), nil, start); got != nil {
+		t.Fatalf("expected priming sample to return nil, got %+v", got)
+	}
+	got := c.networkRates(sample(12<<20, 6<<20), nil, start.Add(time.Second))
+	if len(got) != 1 || got[0].RxRateMBs != 2 || got[0].TxRateMBs != 1 {
+		t.Fatalf("unexpected rates %+v", got)
+	}
+
+	// Interface bounced: counters restart near zero.
+	got = c.networkRates(sample(1<<20, 1<<10), nil, start.Add(2*time.Second))
+	if len(got) != 1 || got[0].RxRateMBs != 0 || got[0].TxRateMBs != 0 {
+		t.Fatalf("expected zero rates after counter reset, got %+v", got)
+	}
+
+	// Next tick measures from the reset baseline again.
+	got = c.networkRates(sample(4<<20, 1<<10), nil, start.Add(3*time.Second))
+	if len(got) != 1 || got[0].RxRateMBs != 3 {
+		t.Fatalf("expected rates to resume after reset, got %+v", got)
+	}
+}
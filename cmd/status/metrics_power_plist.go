@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// useProfilerXML requests `system_profiler -xml` for power data. Its keys are
+// stable identifiers rather than localized headings, so it is immune to locale
+// and layout changes; the text parser remains the fallback.
+var useProfilerXML bool
+
+// profilerXMLSuffix marks a profiler cache key whose output is fetched as XML.
+const profilerXMLSuffix = "+xml"
+
+// spPowerDataTypeXML is the cache key for the XML form of SPPowerDataType.
+const spPowerDataTypeXML = spPowerDataType + profilerXMLSuffix
+
+// profilerArgs maps a cache key to system_profiler arguments.
+func profilerArgs(key string) []string {
+	if dataType, ok := strings.CutSuffix(key, profilerXMLSuffix); ok {
+		return []string{"-xml", dataType}
+	}
+	return []string{key}
+}
+
+var errNoPowerItems = errors.New("no power items in plist")
+
+// spAdapter is the AC charger section of SPPowerDataType.
+type spAdapter struct {
+	Connected bool
+	Watts     float64
+}
+
+// parsePowerDataXML extracts per-battery health and the adapter from
+// `system_profiler -xml SPPowerDataType`. Batteries are listed in report order.
+func parsePowerDataXML(out string) ([]powerData, spAdapter, error) {
+	root, err := decodePlist(strings.NewReader(out))
+	if err != nil {
+		return nil, spAdapter{}, err
+	}
+	var (
+		batts   []powerData
+		adapter spAdapter
+		found   bool
+	)
+	for _, item := range plistItems(root) {
+		switch plistString(item, "_name") {
+		case "spbattery_information":
+			found = true
+			health, _ := item["sppower_battery_health_info"].(map[string]any)
+			pd := powerData{
+				Health: plistString(health, "sppower_battery_health"),
+				Cycles: int(plistInt(health, "sppower_battery_cycle_count")),
+			}
+			capacity := strings.TrimSpace(strings.TrimSuffix(plistString(health, "sppower_battery_health_maximum_capacity"), "%"))
+			pd.Capacity, _ = strconv.Atoi(capacity)
+			batts = append(batts, pd)
+		case "sppower_ac_charger_information":
+			found = true
+			adapter.Connected = plistString(item, "sppower_battery_charger_connected") == "TRUE"
+			adapter.Watts = float64(plistInt(item, "sppower_ac_charger_watts"))
+		}
+	}
+	if !found {
+		return nil, spAdapter{}, errNoPowerItems
+	}
+	return batts, adapter, nil
+}
+
+// plistItems returns the dicts under every "_items" array in a system_profiler report.
+func plistItems(v any) []map[string]any {
+	var items []map[string]any
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			items = append(items, plistItems(e)...)
+		}
+	case map[string]any:
+		list, _ := v["_items"].([]any)
+		for _, e := range list {
+			if d, ok := e.(map[string]any); ok {
+				items = append(items, d)
+			}
+		}
+	}
+	return items
+}
+
+// plistString returns d[key] as text; system_profiler stores most numbers as strings.
+func plistString(d map[string]any, key string) string {
+	switch v := d[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return ""
+}
+
+// plistInt returns d[key] as an integer whether it is stored as <integer> or <string>.
+func plistInt(d map[string]any, key string) int64 {
+	switch v := d[key].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n
+	}
+	return 0
+}
+
+// decodePlist decodes an XML property list into dicts (map[string]any), arrays
+// ([]any), strings, int64, float64 and bool. <data> and <date> stay as text.
+func decodePlist(r io.Reader) (any, error) {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local != "plist" {
+			return decodePlistValue(dec, start)
+		}
+	}
+}
+
+func decodePlistValue(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		d := map[string]any{}
+		var key string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := dec.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				v, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				d[key] = v
+			case xml.EndElement:
+				return d, nil
+			}
+		}
+	case "array":
+		var list []any
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				v, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			case xml.EndElement:
+				return list, nil
+			}
+		}
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := dec.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case "real":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	}
+	return text, nil
+}
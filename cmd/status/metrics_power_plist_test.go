@@ -0,0 +1,81 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+const spPowerXMLFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<array>
+	<dict>
+		<key>_dataType</key>
+		<string>SPPowerDataType</string>
+		<key>_items</key>
+		<array>
+			<dict>
+				<key>_name</key>
+				<string>spbattery_information</string>
+				<key>sppower_battery_charge_info</key>
+				<dict>
+					<key>sppower_battery_fully_charged</key>
+					<string>FALSE</string>
+					<key>sppower_battery_state_of_charge</key>
+					<integer>72</integer>
+				</dict>
+				<key>sppower_battery_health_info</key>
+				<dict>
+					<key>sppower_battery_cycle_count</key>
+					<integer>312</integer>
+					<key>sppower_battery_health</key>
+					<string>Good</string>
+					<key>sppower_battery_health_maximum_capacity</key>
+					<string>87%</string>
+				</dict>
+			</dict>
+			<dict>
+				<key>_name</key>
+				<string>sppower_ac_charger_information</string>
+				<key>sppower_ac_charger_watts</key>
+				<string>96</string>
+				<key>sppower_battery_charger_connected</key>
+				<string>TRUE</string>
+				<key>sppower_battery_is_charging</key>
+				<false/>
+			</dict>
+		</array>
+	</dict>
+</array>
+</plist>
+`
+
+func TestParsePowerDataXML(t *testing.T) {
+	batts, adapter, err := parsePowerDataXML(spPowerXMLFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(batts, []powerData{{Health: "Good", Cycles: 312, Capacity: 87}}) {
+		t.Fatalf("unexpected batteries %+v", batts)
+	}
+	if adapter != (spAdapter{Connected: true, Watts: 96}) {
+		t.Fatalf("unexpected adapter %+v", adapter)
+	}
+}
+
+func TestParsePowerDataXMLInvalid(t *testing.T) {
+	for _, out := range []string{"Power:\n  Cycle Count: 12\n", `<plist><array><dict><key>_items</key><array/></dict></array></plist>`} {
+		if _, _, err := parsePowerDataXML(out); err == nil {
+			t.Fatalf("expected an error for %q", out)
+		}
+	}
+}
+
+func TestProfilerArgs(t *testing.T) {
+	if got := profilerArgs(spPowerDataTypeXML); !slices.Equal(got, []string{"-xml", "SPPowerDataType"}) {
+		t.Fatalf("unexpected xml args %v", got)
+	}
+	if got := profilerArgs(spHardwareDataType); !slices.Equal(got, []string{"SPHardwareDataType"}) {
+		t.Fatalf("unexpected text args %v", got)
+	}
+}
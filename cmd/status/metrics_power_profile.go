@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const powerProfileQueryTimeout = 500 * time.Millisecond
+
+// PowerProfile is the OS power mode, which shifts fan, clock and battery behaviour.
+// Empty means the platform has no such setting or it couldn't be read.
+type PowerProfile string
+
+const (
+	PowerProfileUnknown     PowerProfile = ""
+	PowerProfileLowPower    PowerProfile = "Low Power"
+	PowerProfileBalanced    PowerProfile = "Balanced"
+	PowerProfilePerformance PowerProfile = "High Performance"
+)
+
+// collectPowerProfile reads Low Power Mode from pmset on macOS, and the
+// power-profiles-daemon profile (falling back to TLP) on Linux.
+func collectPowerProfile(ctx context.Context) PowerProfile {
+	ctx, cancel := context.WithTimeout(ctx, powerProfileQueryTimeout)
+	defer cancel()
+	switch probeOS {
+	case "darwin":
+		if out, err := runCmd(ctx, "pmset", "-g"); err == nil {
+			return parsePMSetPowerMode(out)
+		}
+	case "linux":
+		if commandExists("powerprofilesctl") {
+			if out, err := runCmd(ctx, "powerprofilesctl", "get"); err == nil {
+				if p := linuxPowerProfile(strings.TrimSpace(out)); p != PowerProfileUnknown {
+					return p
+				}
+			}
+		}
+		if commandExists("tlp-stat") {
+			if out, err := runCmdEnv(ctx, englishLocaleEnv, "tlp-stat", "-s"); err == nil {
+				return parseTLPStat(out)
+			}
+		}
+	}
+	return PowerProfileUnknown
+}
+
+// parsePMSetPowerMode reads the active settings from `pmset -g`. Newer macOS on
+// Apple Silicon reports "powermode" (0 automatic, 1 low, 2 high); older releases
+// only have the "lowpowermode" switch.
+func parsePMSetPowerMode(out string) PowerProfile {
+	profile := PowerProfileUnknown
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "powermode":
+			switch fields[1] {
+			case "0":
+				return PowerProfileBalanced
+			case "1":
+				return PowerProfileLowPower
+			case "2":
+				return PowerProfilePerformance
+			}
+		case "lowpowermode":
+			profile = PowerProfileBalanced
+			if fields[1] == "1" {
+				profile = PowerProfileLowPower
+			}
+		}
+	}
+	return profile
+}
+
+// linuxPowerProfile maps power-profiles-daemon names, which TLP 1.6+ reuses.
+func linuxPowerProfile(name string) PowerProfile {
+	switch name {
+	case "power-saver":
+		return PowerProfileLowPower
+	case "balanced":
+		return PowerProfileBalanced
+	case "performance":
+		return PowerProfilePerformance
+	}
+	return PowerProfileUnknown
+}
+
+// parseTLPStat reads "Power profile = balanced/AC" from `tlp-stat -s`. Older TLP
+// only reports AC/battery mode, which isn't a profile, so it stays unknown.
+func parseTLPStat(out string) PowerProfile {
+	for line := range strings.Lines(out) {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != "Power profile" {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimSpace(value), "/")
+		return linuxPowerProfile(name)
+	}
+	return PowerProfileUnknown
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestParsePMSetPowerMode(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want PowerProfile
+	}{
+		{"low power switch on", "System-wide power settings:\nCurrently in use:\n standby              1\n lowpowermode         1\n sleep                1\n", PowerProfileLowPower},
+		{"low power switch off", " lowpowermode         0\n", PowerProfileBalanced},
+		{"power mode high", " lowpowermode         0\n powermode            2\n", PowerProfilePerformance},
+		{"power mode low", " powermode            1\n lowpowermode         0\n", PowerProfileLowPower},
+		{"no setting", " sleep                1\n", PowerProfileUnknown},
+	}
+	for _, tt := range tests {
+		if got := parsePMSetPowerMode(tt.out); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTLPStat(t *testing.T) {
+	out := `--- TLP 1.6.1 --------------------------------------------
+
++++ TLP Status
+State          = enabled
+Mode           = battery
+Power profile  = power-saver/BAT
+`
+	if got := parseTLPStat(out); got != PowerProfileLowPower {
+		t.Fatalf("got %q, want low power", got)
+	}
+	if got := parseTLPStat("State          = enabled\nMode           = AC\n"); got != PowerProfileUnknown {
+		t.Fatalf("pre-1.6 TLP should stay unknown, got %q", got)
+	}
+	if got := linuxPowerProfile("performance"); got != PowerProfilePerformance {
+		t.Fatalf("performance mapped to %q", got)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// collectPowerSource is the always-on adapter probe behind ChargerInfo's Known,
+// Connected and Watts; collectCharger adds the PD details when enabled.
+func collectPowerSource(ctx context.Context) ChargerInfo {
+	switch probeOS {
+	case "linux":
+		if !localProbes {
+			return ChargerInfo{}
+		}
+		return readPowerSupplySource(powerSupplyRoot)
+	case "darwin":
+		return readMacPowerSource(ctx)
+	}
+	return ChargerInfo{}
+}
+
+// readPowerSupplySource reads the Mains and USB entries under root. Any online one
+// means AC; delivered watts are voltage_now × current_now summed over online USB
+// supplies, since Mains adapters don't report either.
+func readPowerSupplySource(root string) ChargerInfo {
+	var ps ChargerInfo
+	entries, _ := filepath.Glob(filepath.Join(root, "*"))
+	for _, dir := range entries {
+		t := readPowerSupplyType(dir)
+		if t != powerSupplyTypeMains && !(t.usb() && !isPowerSupplyBattery(dir)) {
+			continue
+		}
+		online, ok := readSysfsInt(filepath.Join(dir, "online"))
+		if !ok {
+			continue
+		}
+		ps.Known = true
+		if online == 0 {
+			continue
+		}
+		ps.Connected = true
+		uv, _ := readSysfsInt(filepath.Join(dir, "voltage_now"))
+		ua, _ := readSysfsInt(filepath.Join(dir, "current_now"))
+		if uv > 0 && ua > 0 {
+			ps.Watts += FromMicro(uv) * FromMicro(ua)
+		}
+	}
+	return ps
+}
+
+// pmsetACTimeout bounds `pmset -g ac`, the fallback when system_profiler has nothing.
+const pmsetACTimeout = pmsetTimeout
+
+// readMacPowerSource reads the cached SPPowerDataType charger section, falling
+// back to `pmset -g ac` while the cache is empty.
+func readMacPowerSource(ctx context.Context) ChargerInfo {
+	if useProfilerXML {
+		if out := getSystemProfilerOutput(ctx, spPowerDataTypeXML); out != "" {
+			if _, adapter, err := parsePowerDataXML(out); err == nil {
+				return adapterPowerSource(adapter)
+			}
+		}
+	}
+	if adapter, ok := parseSPACCharger(getSystemPowerOutput(ctx)); ok {
+		return adapterPowerSource(adapter)
+	}
+	ctx, cancel := context.WithTimeout(ctx, pmsetACTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "pmset", "-g", "ac")
+	if err != nil {
+		return ChargerInfo{}
+	}
+	return parsePMSetAC(out)
+}
+
+func adapterPowerSource(a spAdapter) ChargerInfo {
+	ps := ChargerInfo{Known: true, Connected: a.Connected}
+	if a.Connected {
+		ps.Watts = a.Watts
+	}
+	return ps
+}
+
+// parseSPACCharger reads the "AC Charger Information" section of SPPowerDataType
+// text, which runs until the next line indented no deeper than its heading; ok
+// is false when the section is missing.
+func parseSPACCharger(out string) (adapter spAdapter, ok bool) {
+	indent := -1
+	for line := range strings.Lines(out) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < 0 {
+			if trimmed == "AC Charger Information:" {
+				indent, ok = depth, true
+			}
+			continue
+		}
+		if depth <= indent {
+			break
+		}
+		key, value, _ := strings.Cut(trimmed, ":")
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Connected":
+			adapter.Connected = value == "Yes"
+		case "Wattage (W)":
+			adapter.Watts, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+	return adapter, ok
+}
+
+// parsePMSetAC reads `pmset -g ac`: "No adapter attached." on battery, otherwise
+// key = value lines including "Wattage = 96W".
+func parsePMSetAC(out string) ChargerInfo {
+	if strings.TrimSpace(out) == "" {
+		return ChargerInfo{}
+	}
+	if strings.Contains(out, "No adapter attached") {
+		return ChargerInfo{Known: true}
+	}
+	ps := ChargerInfo{Known: true, Connected: true}
+	for line := range strings.Lines(out) {
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) != "Wattage" {
+			continue
+		}
+		value = strings.TrimSuffix(strings.TrimSpace(value), "W")
+		if w, err := strconv.ParseFloat(value, 64); err == nil && w > 0 {
+			ps.Watts = w
+		}
+	}
+	return ps
+}
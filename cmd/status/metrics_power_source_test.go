@@ -0,0 +1,74 @@
+package main
+
+import "testing"
+
+func TestReadPowerSupplySource(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "AC/type", "Mains\n")
+	writeSysfs(t, root, "AC/online", "0\n")
+	writeSysfs(t, root, "ucsi-source-psy-USBC000:001/type", "USB\n")
+	writeSysfs(t, root, "ucsi-source-psy-USBC000:001/online", "1\n")
+	writeSysfs(t, root, "ucsi-source-psy-USBC000:001/voltage_now", "20000000\n")
+	writeSysfs(t, root, "ucsi-source-psy-USBC000:001/current_now", "2250000\n")
+	writeSysfs(t, root, "BAT0/type", "Battery\n")
+	writeSysfs(t, root, "BAT0/capacity", "80\n")
+
+	got := readPowerSupplySource(root)
+	if want := (ChargerInfo{Known: true, Connected: true, Watts: 45}); got != want {
+		t.Fatalf("readPowerSupplySource = %+v, want %+v", got, want)
+	}
+
+	// A plain Mains adapter: online, but no watts to report.
+	root = t.TempDir()
+	writeSysfs(t, root, "ADP1/type", "Mains\n")
+	writeSysfs(t, root, "ADP1/online", "1\n")
+	if got := readPowerSupplySource(root); got != (ChargerInfo{Known: true, Connected: true}) {
+		t.Fatalf("Mains only = %+v", got)
+	}
+	if got := readPowerSupplySource(t.TempDir()); got.Known {
+		t.Fatalf("no supplies should be unknown, got %+v", got)
+	}
+}
+
+func TestParseSPACCharger(t *testing.T) {
+	out := `Power:
+
+    Battery Information:
+
+      Charge Information:
+          Fully Charged: No
+          Connected: No
+
+    AC Charger Information:
+
+      Connected: Yes
+      ID: 0x7030
+      Wattage (W): 96
+      Charging: No
+
+    Hardware Configuration:
+
+      UPS Installed: No
+`
+	adapter, ok := parseSPACCharger(out)
+	if !ok || !adapter.Connected || adapter.Watts != 96 {
+		t.Fatalf("parseSPACCharger = %+v, %v; want connected 96W", adapter, ok)
+	}
+	if _, ok := parseSPACCharger("Power:\n\n    Battery Information:\n"); ok {
+		t.Fatal("expected no charger section")
+	}
+}
+
+func TestParsePMSetAC(t *testing.T) {
+	out := ` Wattage = 96W
+ Current = 4900mA
+ Voltage = 20000mV
+ AdapterID = 28677
+`
+	if got := parsePMSetAC(out); got != (ChargerInfo{Known: true, Connected: true, Watts: 96}) {
+		t.Fatalf("parsePMSetAC = %+v", got)
+	}
+	if got := parsePMSetAC("No adapter attached.\n"); got != (ChargerInfo{Known: true}) {
+		t.Fatalf("on battery = %+v", got)
+	}
+}
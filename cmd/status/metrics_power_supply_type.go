@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// powerSupplyType is the kernel's classification of a power_supply entry, from
+// its type file: Battery, Mains, UPS, Wireless, or one of the USB variants
+// (USB, USB_PD, USB_PD_DRP, USB_C, ...). Older drivers and some test trees have
+// no type file at all.
+type powerSupplyType string
+
+const (
+	powerSupplyTypeUnknown powerSupplyType = ""
+	powerSupplyTypeBattery powerSupplyType = "Battery"
+	powerSupplyTypeMains   powerSupplyType = "Mains"
+	powerSupplyTypeUPS     powerSupplyType = "UPS"
+)
+
+// usb reports whether t is any USB source; the kernel names them USB, USB_PD,
+// USB_PD_DRP, USB_C and so on.
+func (t powerSupplyType) usb() bool {
+	return strings.HasPrefix(string(t), "USB")
+}
+
+// readPowerSupplyType reads dir's type file.
+func readPowerSupplyType(dir string) powerSupplyType {
+	data, err := os.ReadFile(filepath.Join(dir, "type"))
+	if err != nil {
+		return powerSupplyTypeUnknown
+	}
+	return powerSupplyType(strings.TrimSpace(string(data)))
+}
+
+// isPowerSupplyBattery decides whether the power_supply entry at dir holds
+// charge the machine runs on:
+//   - Battery: yes, unless its scope is Device. Mice, keyboards and headsets
+//     report as Battery with scope Device, and aren't the machine's battery.
+//   - USB*: only when it also reports a capacity. A dock or power bank behind
+//     USB-PD can expose its own pack's charge this way; a plain charger can't.
+//   - Mains, UPS, Wireless and other types: no. UPSes come from NUT or apcupsd.
+//   - No type file: the BAT* naming convention, as before types were read.
+func isPowerSupplyBattery(dir string) bool {
+	t := readPowerSupplyType(dir)
+	switch {
+	case t == powerSupplyTypeUnknown:
+		return strings.HasPrefix(filepath.Base(dir), "BAT")
+	case t == powerSupplyTypeBattery:
+		return !powerSupplyDeviceScope(dir)
+	case t.usb():
+		_, err := os.Stat(filepath.Join(dir, "capacity"))
+		return err == nil && !powerSupplyDeviceScope(dir)
+	}
+	return false
+}
+
+// powerSupplyDeviceScope reports whether dir powers a peripheral rather than the
+// system, per its scope file; entries without one are system supplies.
+func powerSupplyDeviceScope(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "scope"))
+	return err == nil && strings.EqualFold(strings.TrimSpace(string(data)), "Device")
+}
+
+// powerSupplyBatteryDirs lists, in name order, the entries under root that
+// isPowerSupplyBattery accepts.
+func powerSupplyBatteryDirs(root string) []string {
+	entries, _ := filepath.Glob(filepath.Join(root, "*"))
+	var dirs []string
+	for _, dir := range entries {
+		if isPowerSupplyBattery(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
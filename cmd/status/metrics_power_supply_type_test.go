@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeMixedPowerSupplyTree lays out a laptop on a USB-C dock: mains, a USB-PD
+// charger port, the internal pack, a dock with its own battery behind USB-PD, a
+// wireless mouse, and a pack whose driver doesn't name it BAT*.
+func writeMixedPowerSupplyTree(t *testing.T, root string) {
+	t.Helper()
+	writeSysfs(t, root, "AC/type", "Mains\n")
+	writeSysfs(t, root, "AC/online", "1\n")
+
+	writeSysfs(t, root, "ucsi-source-psy-USBC000:001/type", "USB\n")
+	writeSysfs(t, root, "ucsi-source-psy-USBC000:001/usb_type", "C [PD] PD_PPS\n")
+	writeSysfs(t, root, "ucsi-source-psy-USBC000:001/online", "1\n")
+
+	writeSysfs(t, root, "BAT0/type", "Battery\n")
+	writeSysfs(t, root, "BAT0/scope", "System\n")
+	writeSysfs(t, root, "BAT0/capacity", "81\n")
+	writeSysfs(t, root, "BAT0/status", "Charging\n")
+
+	writeSysfs(t, root, "dock-pd-battery/type", "USB_PD\n")
+	writeSysfs(t, root, "dock-pd-battery/capacity", "64\n")
+	writeSysfs(t, root, "dock-pd-battery/status", "Discharging\n")
+
+	writeSysfs(t, root, "hidpp_battery_0/type", "Battery\n")
+	writeSysfs(t, root, "hidpp_battery_0/scope", "Device\n")
+	writeSysfs(t, root, "hidpp_battery_0/capacity", "30\n")
+	writeSysfs(t, root, "hidpp_battery_0/status", "Discharging\n")
+
+	writeSysfs(t, root, "CMB0/type", "Battery\n")
+	writeSysfs(t, root, "CMB0/capacity", "95\n")
+	writeSysfs(t, root, "CMB0/status", "Full\n")
+}
+
+func TestPowerSupplyBatteriesRespectType(t *testing.T) {
+	root := t.TempDir()
+	writeMixedPowerSupplyTree(t, root)
+
+	var names []string
+	for _, b := range readPowerSupplyBatteries(root) {
+		names = append(names, b.Name)
+	}
+	if want := []string{"BAT0", "CMB0", "dock-pd-battery"}; !slices.Equal(names, want) {
+		t.Fatalf("batteries = %v, want %v", names, want)
+	}
+	if !powerSupplyHasBattery(root) {
+		t.Fatal("expected the tree to count as having a battery")
+	}
+}
+
+func TestPowerSupplyWithoutTypeUsesName(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "BAT1/capacity", "50\n")
+	writeSysfs(t, root, "ADP1/online", "1\n")
+	writeSysfs(t, root, "ADP1/capacity", "100\n") // Nonsense from a buggy driver; not a battery either way
+	if got := powerSupplyBatteryDirs(root); len(got) != 1 || filepath.Base(got[0]) != "BAT1" {
+		t.Fatalf("untyped entries: got %v, want only BAT1", got)
+	}
+}
+
+func TestMainsAndChargersAreNotBatteries(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "AC/type", "Mains\n")
+	writeSysfs(t, root, "AC/online", "1\n")
+	writeSysfs(t, root, "USB-C/type", "USB_PD\n") // A charger port reports no capacity
+	writeSysfs(t, root, "USB-C/online", "1\n")
+	writeSysfs(t, root, "hidpp_battery_0/type", "Battery\n")
+	writeSysfs(t, root, "hidpp_battery_0/scope", "Device\n")
+	writeSysfs(t, root, "hidpp_battery_0/capacity", "30\n")
+	if powerSupplyHasBattery(root) {
+		t.Fatalf("a desktop with a charger port and a wireless mouse has no battery: %v", powerSupplyBatteryDirs(root))
+	}
+}
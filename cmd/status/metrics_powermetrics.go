@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// powermetricsSamplers lists what the shared powermetrics run samples: GPU
+// residency always, plus CPU power with --powermetrics and, on Intel, the SMC
+// die temperature (Apple Silicon has no smc sampler).
+func powermetricsSamplers(arch macArch) string {
+	switch {
+	case !collectPowermetrics:
+		return "gpu_power"
+	case arch == macArchIntel:
+		return "cpu_power,gpu_power,smc"
+	default:
+		return "cpu_power,gpu_power"
+	}
+}
+
+// samplePowermetrics runs powermetrics once with every sampler a tick needs; ok
+// is false on any error, including not running as root.
+func samplePowermetrics(ctx context.Context) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, powermetricsTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "powermetrics", "--samplers", powermetricsSamplers(detectMacArch(ctx)), "-i", "500", "-n", "1")
+	return out, err == nil
+}
+
+// powermetricsShared hands one sample to GPU usage, CPU power and the Intel die
+// temperature, which read it from separate collectors in the same tick. Ticks
+// are at least refreshInterval apart, so the window never spans two of them.
+var powermetricsShared = &sharedRead[string]{
+	fetch:  samplePowermetrics,
+	window: func() time.Duration { return refreshInterval / 2 },
+	now:    time.Now,
+}
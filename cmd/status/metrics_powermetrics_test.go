@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPowermetricsSampledOncePerTick(t *testing.T) {
+	fakeSysctl(t, map[string]string{"hw.optional.arm64": "0"})
+	prev := collectPowermetrics
+	collectPowermetrics = true
+	t.Cleanup(func() { collectPowermetrics = prev })
+	sysctl := cmdRunner
+	var runs []string
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		if name != "powermetrics" {
+			return sysctl(ctx, env, name, args...)
+		}
+		runs = append(runs, strings.Join(args, " "))
+		return "GPU HW active residency:  12.50%\n" +
+			"Intel energy model derived package power (CPUs+GT+SA): 3.45W\n" +
+			"CPU die temperature: 63.50 C\n", nil
+	}
+
+	ctx := context.Background()
+	if got := getMacGPUUsage(ctx); got != 12.5 {
+		t.Fatalf("GPU usage = %v, want 12.5", got)
+	}
+	if temp, ok := readPowermetricsCPUTemp(ctx); !ok || temp != 63.5 {
+		t.Fatalf("die temperature = %v, %v; want 63.5", temp, ok)
+	}
+	if out, _ := powermetricsShared.get(ctx); parsePowermetricsWatts(out) != 3.45 {
+		t.Fatalf("package power missing from the shared sample:\n%s", out)
+	}
+	if len(runs) != 1 || runs[0] != "--samplers cpu_power,gpu_power,smc -i 500 -n 1" {
+		t.Fatalf("want one combined powermetrics run, got %q", runs)
+	}
+}
+
+func TestPowermetricsSamplers(t *testing.T) {
+	prev := collectPowermetrics
+	t.Cleanup(func() { collectPowermetrics = prev })
+	collectPowermetrics = false
+	if got := powermetricsSamplers(macArchIntel); got != "gpu_power" {
+		t.Fatalf("without --powermetrics got %q", got)
+	}
+	collectPowermetrics = true
+	if got := powermetricsSamplers(macArchAppleSilicon); got != "cpu_power,gpu_power" {
+		t.Fatalf("Apple Silicon has no smc sampler, got %q", got)
+	}
+}
@@ -2,17 +2,16 @@ package main
 
 import (
 	"context"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func collectTopProcesses() []ProcessInfo {
-	if runtime.GOOS != "darwin" {
+func collectTopProcesses(ctx context.Context) []ProcessInfo {
+	if probeOS != "darwin" {
 		return nil
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel()
 
 	// Use ps to get top processes by CPU.
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const powercapRoot = "/sys/class/powercap"
+
+// raplCounter is one package's cumulative energy counter and the value it wraps at.
+type raplCounter struct {
+	EnergyUJ uint64
+	MaxUJ    uint64
+}
+
+// readRAPLPackages reads the package-level RAPL zones (intel-rapl:N, used by AMD too).
+// Subzones such as intel-rapl:0:0 (core, uncore, dram) are skipped so power isn't
+// counted twice. energy_uj is root-only on most kernels; unreadable zones are skipped.
+func readRAPLPackages(root string) map[string]raplCounter {
+	counters := make(map[string]raplCounter)
+	matches, _ := filepath.Glob(filepath.Join(root, "intel-rapl:*"))
+	for _, dir := range matches {
+		zone := filepath.Base(dir)
+		if strings.Count(zone, ":") != 1 {
+			continue
+		}
+		if name, err := os.ReadFile(filepath.Join(dir, "name")); err == nil && !strings.HasPrefix(strings.TrimSpace(string(name)), "package") {
+			continue
+		}
+		energy, ok := readSysfsInt(filepath.Join(dir, "energy_uj"))
+		if !ok || energy < 0 {
+			continue
+		}
+		maxRange, _ := readSysfsInt(filepath.Join(dir, "max_energy_range_uj"))
+		counters[zone] = raplCounter{EnergyUJ: uint64(energy), MaxUJ: uint64(max(maxRange, 0))}
+	}
+	return counters
+}
+
+// raplEnergyDelta is the energy used between two readings, across at most one wrap.
+// Without a known range a backwards counter is treated as a reset and yields zero.
+func raplEnergyDelta(cur, prev raplCounter) uint64 {
+	if cur.EnergyUJ >= prev.EnergyUJ {
+		return cur.EnergyUJ - prev.EnergyUJ
+	}
+	if cur.MaxUJ == 0 || prev.EnergyUJ > cur.MaxUJ {
+		return 0
+	}
+	return cur.MaxUJ - prev.EnergyUJ + cur.EnergyUJ
+}
+
+// collectCPUPower reports package power in watts from the energy used since the last call.
+// Like the throughput collectors, the first call only records a baseline and returns 0.
+func (c *Collector) collectCPUPower(now time.Time) float64 {
+	if probeOS != "linux" {
+		return 0
+	}
+	return c.cpuPowerRates(readRAPLPackages(powercapRoot), now)
+}
+
+// cpuPowerRates converts cumulative RAPL counters into watts summed over packages.
+func (c *Collector) cpuPowerRates(cur map[string]raplCounter, now time.Time) float64 {
+	prev, elapsed := c.prevRAPL, now.Sub(c.lastRAPLAt).Seconds()
+	first := c.lastRAPLAt.IsZero()
+	c.prevRAPL, c.lastRAPLAt = cur, now
+	if first || elapsed <= 0 {
+		return 0
+	}
+
+	var usedUJ uint64
+	for zone, counter := range cur {
+		if p, ok := prev[zone]; ok {
+			usedUJ += raplEnergyDelta(counter, p)
+		}
+	}
+	return float64(usedUJ) / 1e6 / elapsed
+}
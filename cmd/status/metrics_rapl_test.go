@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReadRAPLPackages(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "intel-rapl:0/name", "package-0\n")
+	writeSysfs(t, root, "intel-rapl:0/energy_uj", "123456789\n")
+	writeSysfs(t, root, "intel-rapl:0/max_energy_range_uj", "262143328850\n")
+	writeSysfs(t, root, "intel-rapl:0:0/name", "core\n")
+	writeSysfs(t, root, "intel-rapl:0:0/energy_uj", "5000\n")
+	writeSysfs(t, root, "intel-rapl:1/name", "psys\n")
+	writeSysfs(t, root, "intel-rapl:1/energy_uj", "9000\n")
+
+	got := readRAPLPackages(root)
+	if len(got) != 1 {
+		t.Fatalf("expected only the package zone, got %+v", got)
+	}
+	if c := got["intel-rapl:0"]; c.EnergyUJ != 123456789 || c.MaxUJ != 262143328850 {
+		t.Fatalf("unexpected counter %+v", c)
+	}
+}
+
+func TestRAPLEnergyDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		cur, prev raplCounter
+		want      uint64
+	}{
+		{"forward", raplCounter{EnergyUJ: 1500, MaxUJ: 10000}, raplCounter{EnergyUJ: 500}, 1000},
+		{"wraparound", raplCounter{EnergyUJ: 200, MaxUJ: 10000}, raplCounter{EnergyUJ: 9800}, 400},
+		{"reset without range", raplCounter{EnergyUJ: 200}, raplCounter{EnergyUJ: 9800}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := raplEnergyDelta(tt.cur, tt.prev); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCPUPowerRates(t *testing.T) {
+	c := NewCollector()
+	start := time.Now()
+	if w := c.cpuPowerRates(map[string]raplCounter{"intel-rapl:0": {EnergyUJ: 1_000_000, MaxUJ: 50_000_000}}, start); w != 0 {
+		t.Fatalf("first sample should be a baseline, got %v", w)
+	}
+	// 15 J over 2 s.
+	w := c.cpuPowerRates(map[string]raplCounter{"intel-rapl:0": {EnergyUJ: 16_000_000, MaxUJ: 50_000_000}}, start.Add(2*time.Second))
+	if math.Abs(w-7.5) > 1e-9 {
+		t.Fatalf("got %v W, want 7.5", w)
+	}
+	// 35 J over 1 s, across the wrap at 50 J.
+	w = c.cpuPowerRates(map[string]raplCounter{"intel-rapl:0": {EnergyUJ: 1_000_000, MaxUJ: 50_000_000}}, start.Add(3*time.Second))
+	if math.Abs(w-35) > 1e-9 {
+		t.Fatalf("wrapped counter: got %v W, want 35", w)
+	}
+}
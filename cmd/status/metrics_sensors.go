@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// SensorClass groups sensors by the component they measure.
+type SensorClass string
+
+const (
+	SensorClassCPU       SensorClass = "CPU"
+	SensorClassGPU       SensorClass = "GPU"
+	SensorClassBattery   SensorClass = "Battery"
+	SensorClassStorage   SensorClass = "Storage"
+	SensorClassAmbient   SensorClass = "Ambient"
+	SensorClassEnclosure SensorClass = "Enclosure"
+	SensorClassFan       SensorClass = "Fan"     // RPM, from lm-sensors
+	SensorClassVoltage   SensorClass = "Voltage" // Volts, from lm-sensors
+	SensorClassOther     SensorClass = "Other"
+)
+
+// sensorClassOrder is the display order for summaries.
+var sensorClassOrder = []SensorClass{
+	SensorClassCPU,
+	SensorClassGPU,
+	SensorClassBattery,
+	SensorClassStorage,
+	SensorClassAmbient,
+	SensorClassEnclosure,
+	SensorClassFan,
+	SensorClassVoltage,
+	SensorClassOther,
+}
+
+func collectSensors(ctx context.Context) ([]SensorReading, error) {
+	// lm-sensors and hwmon label files give readable names; gopsutil's SensorKey is
+	// often cryptic.
+	if probeOS == "linux" {
+		if readings, ok := lmSensorsShared.get(ctx); ok {
+			return addCPUPackage(readings), nil
+		}
+		if !localProbes {
+			return nil, nil
+		}
+		if readings, ok := hwmonShared.get(ctx); ok {
+			return addCPUPackage(readings), nil
+		}
+	}
+	// gopsutil reads this machine's SMC or hwmon; a remote host has no readings
+	// beyond lm-sensors.
+	if !localProbes {
+		return nil, nil
+	}
+	temps, err := sensorTemps.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unit := gopsutilTempUnit(temps)
+	var out []SensorReading
+	for _, t := range temps {
+		c := toCelsius(t.Temperature, unit)
+		if !plausibleCelsius(c) {
+			continue
+		}
+		out = append(out, SensorReading{
+			Key:   strings.TrimSpace(t.SensorKey),
+			Label: prettifyLabel(t.SensorKey),
+			Value: c,
+			Unit:  "°C",
+			Class: classifySensor(t.SensorKey),
+		})
+	}
+	return out, nil
+}
+
+// smcSensorNames names well-known SMC temperature keys; add new keys here. Keys
+// not listed fall back to smcFriendlyPrefixes, then to trimming.
+var smcSensorNames = map[string]string{
+	"TC0P": "CPU Proximity",
+	"TC0D": "CPU Die",
+	"TC0H": "CPU Heatsink",
+	"TG0P": "GPU Proximity",
+	"TG0D": "GPU Die",
+	"TG0H": "GPU Heatsink",
+	"TB0T": "Battery",
+	"TB1T": "Battery 1",
+	"TB2T": "Battery 2",
+	"TM0P": "Memory Proximity",
+	"TN0P": "Northbridge Proximity",
+	"TN0D": "Northbridge Die",
+	"TPCD": "PCH Die",
+	"TW0P": "Wi-Fi Proximity",
+	"TL0P": "Display Proximity",
+	"TI0P": "Thunderbolt Proximity",
+	"Tm0P": "Mainboard Proximity",
+	"Tp0P": "Power Supply Proximity",
+}
+
+// smcFriendlyPrefixes names SMC sensor families whose raw keys mean nothing to users.
+var smcFriendlyPrefixes = map[string]string{
+	"TA": "Ambient",   // air intake / ambient
+	"Ts": "Enclosure", // palm rest / skin
+}
+
+// prettifyLabel turns a raw sensor key into a display name: smcSensorNames, then
+// the SMC family, then the key with "TC" and underscores trimmed.
+func prettifyLabel(key string) string {
+	key = strings.TrimSpace(key)
+	if name, ok := smcSensorNames[key]; ok {
+		return name
+	}
+	if name, ok := smcFriendlyPrefixes[smcFamily(key)]; ok {
+		if idx := key[2]; idx != '0' {
+			return fmt.Sprintf("%s %d", name, idx-'0'+1)
+		}
+		return name
+	}
+	key = strings.TrimPrefix(key, "TC")
+	key = strings.ReplaceAll(key, "_", " ")
+	return key
+}
+
+// sensorDisplayDecimals is the precision used when rendering sensor values for humans.
+// Exporters always use the raw Value.
+var sensorDisplayDecimals = 1
+
+// sensorSummaryOnly shows one row per sensor class instead of one per sensor in
+// --table; set from --sensor-summary.
+var sensorSummaryOnly bool
+
+// DisplayValue renders the reading rounded for display, e.g. "54.3°C" or
+// "2100.0 RPM", with temperatures in displayTempUnit.
+func (r SensorReading) DisplayValue() string {
+	value, unit := r.Value, r.Unit
+	if unit == "°C" {
+		value, unit = displayTempUnit.Convert(value), displayTempUnit.Symbol()
+	}
+	text := strconv.FormatFloat(roundTo(value, sensorDisplayDecimals), 'f', max(sensorDisplayDecimals, 0), 64)
+	if unit == "" || strings.HasPrefix(unit, "°") {
+		return text + unit
+	}
+	return text + " " + unit
+}
+
+// roundTo rounds v half away from zero to the given number of decimals.
+func roundTo(v float64, decimals int) float64 {
+	decimals = max(decimals, 0)
+	scale := math.Pow10(decimals)
+	return math.Round(v*scale) / scale
+}
+
+// classifySensor infers the sensor class from a raw SMC/hwmon key.
+func classifySensor(key string) SensorClass {
+	key = strings.TrimSpace(key)
+	lower := strings.ToLower(key)
+
+	// macOS SMC keys: T + component letter + index + suffix (e.g. TC0P, TG0D).
+	if len(key) == 4 && key[0] == 'T' {
+		switch key[1] {
+		case 'C':
+			return SensorClassCPU
+		case 'G':
+			return SensorClassGPU
+		case 'B':
+			return SensorClassBattery
+		case 'H':
+			return SensorClassStorage
+		case 'A':
+			return SensorClassAmbient
+		case 's':
+			return SensorClassEnclosure
+		}
+	}
+
+	switch {
+	case containsAny(lower, "coretemp", "k10temp", "zenpower", "package id", "core ", "cpu", "tdie", "tctl", "soc"):
+		return SensorClassCPU
+	case containsAny(lower, "gpu", "amdgpu", "radeon", "nouveau"):
+		return SensorClassGPU
+	case containsAny(lower, "battery", "bat"):
+		return SensorClassBattery
+	case containsAny(lower, "nvme", "drivetemp", "ssd", "nand"):
+		return SensorClassStorage
+	default:
+		return SensorClassOther
+	}
+}
+
+// smcFamily returns the two-letter family of a 4-character SMC key with a
+// numeric index (e.g. "TA" for TA0P), or "" for anything else.
+func smcFamily(key string) string {
+	if len(key) != 4 || key[0] != 'T' || key[2] < '0' || key[2] > '9' {
+		return ""
+	}
+	return key[:2]
+}
+
+// smcCPUTempKeys lists Intel Mac SMC CPU temperature keys, best first:
+// TC0D is the CPU die diode, TC0E/TC0F are filtered die readings on newer
+// Intel parts, TC0H is the heatsink and TC0P the proximity sensor near the
+// package. The first key with a plausible value wins.
+var smcCPUTempKeys = []string{"TC0D", "TC0E", "TC0F", "TC0H", "TC0P"}
+
+// pickSMCCPUTemp returns the highest-priority valid SMC CPU temperature.
+func pickSMCCPUTemp(temps []sensors.TemperatureStat) (float64, bool) {
+	unit := gopsutilTempUnit(temps)
+	byKey := make(map[string]float64, len(temps))
+	for _, t := range temps {
+		if c := toCelsius(t.Temperature, unit); plausibleCelsius(c) {
+			byKey[strings.TrimSpace(t.SensorKey)] = c
+		}
+	}
+	for _, key := range smcCPUTempKeys {
+		if v, ok := byKey[key]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// gopsutilTempUnit is the unit of a gopsutil batch, declared or guessed.
+func gopsutilTempUnit(temps []sensors.TemperatureStat) TempUnit {
+	batch := make([]float64, len(temps))
+	for i, t := range temps {
+		batch[i] = t.Temperature
+	}
+	return detectTempUnit(sensorSourceGopsutil, batch, true)
+}
+
+// mergeSensorReadings appends extra readings whose labels aren't already present.
+func mergeSensorReadings(base, extra []SensorReading) []SensorReading {
+	seen := make(map[string]bool, len(base))
+	for _, r := range base {
+		seen[r.Label] = true
+	}
+	for _, r := range extra {
+		if !seen[r.Label] {
+			base = append(base, r)
+			seen[r.Label] = true
+		}
+	}
+	return base
+}
+
+// enclosureTemp returns the hottest enclosure reading, or 0 when there is none.
+func enclosureTemp(readings []SensorReading) float64 {
+	var hottest float64
+	for _, r := range readings {
+		if r.Class == SensorClassEnclosure {
+			hottest = max(hottest, r.Value)
+		}
+	}
+	return hottest
+}
+
+// applyCPUTempSpread sets CPUTempMax and CPUTempAvg over every CPU-class
+// temperature, and raises CPUTemp to the max so one cool sensor can't hide a hot
+// die under asymmetric load. Without CPU sensors both follow CPUTemp. A sensor
+// hotter than an estimate replaces it, so Estimated is cleared.
+func applyCPUTempSpread(t *ThermalStatus, readings []SensorReading) {
+	var sum float64
+	var n int
+	for _, r := range readings {
+		if r.Class != SensorClassCPU || r.Unit != "°C" || r.Value <= 0 {
+			continue
+		}
+		t.CPUTempMax = max(t.CPUTempMax, r.Value)
+		sum += r.Value
+		n++
+	}
+	if n == 0 {
+		t.CPUTempMax, t.CPUTempAvg = t.CPUTemp, t.CPUTemp
+		return
+	}
+	t.CPUTempAvg = sum / float64(n)
+	if t.CPUTempMax > t.CPUTemp {
+		t.CPUTemp, t.Estimated = t.CPUTempMax, false
+	}
+	t.CPUTempMax = t.CPUTemp
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// SensorSummary aggregates the readings of one sensor class, or of one location
+// for SummarizeSensorsByLocation.
+type SensorSummary struct {
+	Class    SensorClass
+	Location SensorLocation // Set instead of Class when grouped by location
+	Count    int
+	Min      float64
+	Max      float64
+	Avg      float64
+	Unit     string
+}
+
+// String renders the summary as "CPU: 4 sensors, 52–61°C (avg 56)".
+func (s SensorSummary) String() string {
+	name := string(s.Class)
+	if s.Location != "" {
+		name = string(s.Location)
+	}
+	noun := "sensors"
+	if s.Count == 1 {
+		noun = "sensor"
+	}
+	if s.Min == s.Max {
+		return fmt.Sprintf("%s: %d %s, %.0f%s", name, s.Count, noun, s.Max, s.Unit)
+	}
+	return fmt.Sprintf("%s: %d %s, %.0f–%.0f%s (avg %.0f)", name, s.Count, noun, s.Min, s.Max, s.Unit, s.Avg)
+}
+
+// SummarizeSensors collapses readings into per-class min/max/avg, ordered by class.
+func SummarizeSensors(readings []SensorReading) []SensorSummary {
+	return summarizeSensors(readings, sensorClassOrder, func(r SensorReading) (SensorSummary, SensorClass, bool) {
+		class := r.Class
+		if class == "" {
+			class = SensorClassOther
+		}
+		return SensorSummary{Class: class}, class, true
+	})
+}
+
+// summarizeSensors aggregates readings into the groups group puts them in, in
+// order; group returns the new summary's identity and false to leave a reading out.
+func summarizeSensors[K comparable](readings []SensorReading, order []K, group func(SensorReading) (SensorSummary, K, bool)) []SensorSummary {
+	byKey := make(map[K]*SensorSummary)
+	for _, r := range readings {
+		id, key, ok := group(r)
+		if !ok {
+			continue
+		}
+		s, ok := byKey[key]
+		if !ok {
+			id.Min, id.Max, id.Unit = r.Value, r.Value, r.Unit
+			s = &id
+			byKey[key] = s
+		}
+		s.Count++
+		s.Min = min(s.Min, r.Value)
+		s.Max = max(s.Max, r.Value)
+		s.Avg += r.Value
+	}
+
+	var out []SensorSummary
+	for _, key := range order {
+		s, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		s.Avg /= float64(s.Count)
+		out = append(out, *s)
+	}
+	return out
+}
+
+// SensorRollup is a one-glance summary of every sensor in a snapshot.
+type SensorRollup struct {
+	Total    int
+	Warn     int           // Temperatures at serious thermal level
+	Critical int           // Temperatures at critical thermal level
+	Hottest  SensorReading // Hottest °C reading; zero Value when there is none
+}
+
+// rollupSensors counts readings and grades each temperature with the same
+// thresholds as the CPU thermal level.
+func rollupSensors(readings []SensorReading) SensorRollup {
+	r := SensorRollup{Total: len(readings)}
+	for _, s := range readings {
+		if s.Unit != "°C" {
+			continue
+		}
+		switch thermalLevelFromTemp(s.Value) {
+		case ThermalLevelSerious:
+			r.Warn++
+		case ThermalLevelCritical:
+			r.Critical++
+		}
+		if s.Value > r.Hottest.Value {
+			r.Hottest = s
+		}
+	}
+	return r
+}
+
+// Nominal reports whether no temperature is at warn or critical level.
+func (r SensorRollup) Nominal() bool {
+	return r.Warn == 0 && r.Critical == 0
+}
+
+// String renders the rollup as "12 sensors, all nominal, hottest CPU Die 61°C",
+// in displayTempUnit.
+func (r SensorRollup) String() string {
+	if r.Total == 0 {
+		return "no sensors"
+	}
+	noun := "sensors"
+	if r.Total == 1 {
+		noun = "sensor"
+	}
+	parts := []string{fmt.Sprintf("%d %s", r.Total, noun)}
+	if r.Nominal() {
+		parts = append(parts, "all nominal")
+	}
+	if r.Warn > 0 {
+		parts = append(parts, fmt.Sprintf("%d warn", r.Warn))
+	}
+	if r.Critical > 0 {
+		parts = append(parts, fmt.Sprintf("%d critical", r.Critical))
+	}
+	if r.Hottest.Value > 0 {
+		parts = append(parts, "hottest "+r.Hottest.Label+" "+displayTempUnit.formatPrec(r.Hottest.Value, 0))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+func TestClassifySensor(t *testing.T) {
+	tests := []struct {
+		key  string
+		want SensorClass
+	}{
+		{"TC0P", SensorClassCPU},
+		{"TG0D", SensorClassGPU},
+		{"TB1T", SensorClassBattery},
+		{"TA0P", SensorClassAmbient},
+		{"Ts0P", SensorClassEnclosure},
+		{"coretemp_core_0", SensorClassCPU},
+		{"k10temp_tctl", SensorClassCPU},
+		{"amdgpu_edge", SensorClassGPU},
+		{"nvme_composite", SensorClassStorage},
+		{"acpitz", SensorClassOther},
+	}
+	for _, tt := range tests {
+		if got := classifySensor(tt.key); got != tt.want {
+			t.Fatalf("classifySensor(%q) = %s, want %s", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestPrettifyLabelAmbientEnclosure(t *testing.T) {
+	tests := map[string]string{
+		"TA0P":        "Ambient",
+		"TA1P":        "Ambient 2",
+		"Ts0P":        "Enclosure",
+		"Ts1S":        "Enclosure 2",
+		"TC1C":        "1C",
+		"nvme_sensor": "nvme sensor",
+	}
+	for key, want := range tests {
+		if got := prettifyLabel(key); got != want {
+			t.Errorf("prettifyLabel(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestPrettifyLabelKnownSMCKeys(t *testing.T) {
+	tests := map[string]string{
+		"TC0P":   "CPU Proximity",
+		"TG0D":   "GPU Die",
+		"TB1T":   "Battery 1",
+		"TPCD":   "PCH Die",
+		" TC0D ": "CPU Die",
+		"TCXC":   "XC",        // Unknown CPU key: trimmed as before
+		"Tx9Q":   "Tx9Q",      // Unknown family: left alone
+		"acpi_1": "acpi 1",    // Non-SMC keys keep the underscore cleanup
+		"TA2P":   "Ambient 3", // Families still number unlisted keys
+	}
+	for key, want := range tests {
+		if got := prettifyLabel(key); got != want {
+			t.Errorf("prettifyLabel(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestEnclosureTemp(t *testing.T) {
+	readings := []SensorReading{
+		{Label: "Ambient", Value: 30, Class: SensorClassAmbient},
+		{Label: "Enclosure", Value: 38.5, Class: SensorClassEnclosure},
+		{Label: "Enclosure 2", Value: 41, Class: SensorClassEnclosure},
+	}
+	if got := enclosureTemp(readings); got != 41 {
+		t.Fatalf("enclosureTemp = %v, want 41", got)
+	}
+	if got := enclosureTemp(nil); got != 0 {
+		t.Fatalf("enclosureTemp(nil) = %v, want 0", got)
+	}
+}
+
+func TestApplyCPUTempSpread(t *testing.T) {
+	readings := []SensorReading{
+		{Label: "P-Cluster", Value: 92, Unit: "°C", Class: SensorClassCPU},
+		{Label: "E-Cluster", Value: 60, Unit: "°C", Class: SensorClassCPU},
+		{Label: "Package", Value: 70, Unit: "°C", Class: SensorClassCPU},
+		{Label: "GPU", Value: 99, Unit: "°C", Class: SensorClassGPU},
+		{Label: "CPU Fan", Value: 2400, Unit: "RPM", Class: SensorClassCPU},
+	}
+	th := ThermalStatus{CPUTemp: 70}
+	applyCPUTempSpread(&th, readings)
+	if th.CPUTemp != 92 || th.CPUTempMax != 92 || th.CPUTempAvg != 74 {
+		t.Fatalf("spread = temp %v, max %v, avg %v; want 92, 92, 74", th.CPUTemp, th.CPUTempMax, th.CPUTempAvg)
+	}
+
+	// No CPU sensors: both follow the single CPUTemp.
+	single := ThermalStatus{CPUTemp: 55}
+	applyCPUTempSpread(&single, readings[3:4])
+	if single.CPUTemp != 55 || single.CPUTempMax != 55 || single.CPUTempAvg != 55 {
+		t.Fatalf("single reading = %+v", single)
+	}
+}
+
+func TestSummarizeSensors(t *testing.T) {
+	readings := []SensorReading{
+		{Label: "GPU", Value: 48, Unit: "°C", Class: SensorClassGPU},
+		{Label: "Core 0", Value: 52, Unit: "°C", Class: SensorClassCPU},
+		{Label: "Core 1", Value: 61, Unit: "°C", Class: SensorClassCPU},
+		{Label: "Core 2", Value: 55, Unit: "°C", Class: SensorClassCPU},
+		{Label: "Core 3", Value: 56, Unit: "°C", Class: SensorClassCPU},
+	}
+	got := SummarizeSensors(readings)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 classes, got %+v", got)
+	}
+	cpu := got[0]
+	if cpu.Class != SensorClassCPU || cpu.Count != 4 || cpu.Min != 52 || cpu.Max != 61 || cpu.Avg != 56 {
+		t.Fatalf("unexpected CPU summary %+v", cpu)
+	}
+	if s := cpu.String(); s != "CPU: 4 sensors, 52–61°C (avg 56)" {
+		t.Fatalf("unexpected summary string %q", s)
+	}
+	if s := got[1].String(); s != "GPU: 1 sensor, 48°C" {
+		t.Fatalf("unexpected single-sensor summary %q", s)
+	}
+}
+
+func TestSummarizeSensorsEmpty(t *testing.T) {
+	if got := SummarizeSensors(nil); len(got) != 0 {
+		t.Fatalf("expected no summaries, got %+v", got)
+	}
+}
+
+func TestSensorReadingDisplayValue(t *testing.T) {
+	r := SensorReading{Value: 54.3124, Unit: "°C"}
+	if got := r.DisplayValue(); got != "54.3°C" {
+		t.Fatalf("expected default single decimal, got %q", got)
+	}
+
+	sensorDisplayDecimals = 0
+	t.Cleanup(func() { sensorDisplayDecimals = 1 })
+	if got := r.DisplayValue(); got != "54°C" {
+		t.Fatalf("expected whole degrees, got %q", got)
+	}
+	if r.Value != 54.3124 {
+		t.Fatalf("display rounding must not modify the raw value")
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct {
+		v        float64
+		decimals int
+		want     float64
+	}{
+		{54.3124, 1, 54.3},
+		{54.35, 1, 54.4},
+		{-1.25, 1, -1.3},
+		{61.9, 0, 62},
+		{61.9, -2, 62},
+	}
+	for _, tt := range tests {
+		if got := roundTo(tt.v, tt.decimals); got != tt.want {
+			t.Fatalf("roundTo(%v, %d) = %v, want %v", tt.v, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestPickSMCCPUTemp(t *testing.T) {
+	temps := []sensors.TemperatureStat{
+		{SensorKey: "TC0P", Temperature: 55},
+		{SensorKey: "TC0H", Temperature: 60},
+		{SensorKey: "TC0D", Temperature: 0},
+		{SensorKey: "TC0E", Temperature: 71.5},
+	}
+	if got, ok := pickSMCCPUTemp(temps); !ok || got != 71.5 {
+		t.Fatalf("expected TC0E 71.5 when TC0D is invalid, got %v %v", got, ok)
+	}
+	temps = append(temps, sensors.TemperatureStat{SensorKey: "TC0D", Temperature: 68})
+	if got, _ := pickSMCCPUTemp(temps); got != 68 {
+		t.Fatalf("expected TC0D to win, got %v", got)
+	}
+	if _, ok := pickSMCCPUTemp([]sensors.TemperatureStat{{SensorKey: "TG0D", Temperature: 50}}); ok {
+		t.Fatal("expected no CPU temp without CPU keys")
+	}
+}
+
+func TestRollupSensors(t *testing.T) {
+	readings := []SensorReading{
+		{Label: "CPU Die", Value: 61, Unit: "°C", Class: SensorClassCPU},
+		{Label: "GPU", Value: 58, Unit: "°C", Class: SensorClassGPU},
+		{Label: "Fan", Value: 2100, Unit: "RPM"},
+	}
+	r := rollupSensors(readings)
+	if r.Total != 3 || !r.Nominal() || r.Hottest.Label != "CPU Die" {
+		t.Fatalf("unexpected rollup %+v", r)
+	}
+	if got := r.String(); got != "3 sensors, all nominal, hottest CPU Die 61°C" {
+		t.Fatalf("String() = %q", got)
+	}
+
+	readings = append(readings,
+		SensorReading{Label: "SSD", Value: 78, Unit: "°C"},
+		SensorReading{Label: "VRM", Value: 92, Unit: "°C"},
+	)
+	r = rollupSensors(readings)
+	if r.Warn != 1 || r.Critical != 1 || r.Nominal() {
+		t.Fatalf("expected one warn and one critical, got %+v", r)
+	}
+	if got := r.String(); got != "5 sensors, 1 warn, 1 critical, hottest VRM 92°C" {
+		t.Fatalf("String() = %q", got)
+	}
+	if got := PromptLine(MetricsSnapshot{SensorRollup: r}, PromptOptions{Segments: []PromptSegment{PromptSensors}, Glyphs: GlyphASCII}); got != "S 5/92C!" {
+		t.Fatalf("prompt segment = %q", got)
+	}
+	prevUnit := displayTempUnit
+	displayTempUnit = TempFahrenheit
+	got := r.String()
+	displayTempUnit = prevUnit
+	if got != "5 sensors, 1 warn, 1 critical, hottest VRM 198°F" {
+		t.Fatalf("String() in Fahrenheit = %q", got)
+	}
+	if got := rollupSensors(nil).String(); got != "no sensors" {
+		t.Fatalf("empty rollup = %q", got)
+	}
+}
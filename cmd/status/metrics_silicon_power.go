@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const siliconPowerQueryTimeout = 500 * time.Millisecond
+
+// collectSiliconPowerCounters enables the GPU and Neural Engine energy counters.
+// Opt-in: it spawns two ioreg queries per refresh, and the counter keys below are
+// unconfirmed against real hardware, so a machine may simply report nothing.
+var collectSiliconPowerCounters bool
+
+// appleEnergyCounter is a cumulative energy counter that an Apple Silicon driver
+// is expected to publish in its ioreg PerformanceStatistics, readable without root.
+// No captured ioreg output confirms the names yet, so each subsystem lists the
+// plausible spellings; the first one present wins.
+type appleEnergyCounter struct {
+	subsystem string
+	class     string   // IOService class passed to `ioreg -c`
+	keys      []string // Candidate PerformanceStatistics keys, cumulative millijoules
+}
+
+var appleEnergyCounters = []appleEnergyCounter{
+	{subsystem: "gpu", class: "AGXAccelerator", keys: []string{"GPU Energy", "gpuEnergy"}},
+	{subsystem: "ane", class: "H11ANEIn", keys: []string{"ANE Energy", "aneEnergy"}},
+}
+
+// siliconPower is per-subsystem draw derived from the energy counters. The counters
+// update on the driver's own schedule, not ours, so the watts are approximate
+// averages over the refresh interval rather than instantaneous readings.
+type siliconPower struct {
+	GPUWatts float64
+	ANEWatts float64
+}
+
+// collectSiliconPower reports GPU and Neural Engine watts on Apple Silicon from the
+// energy used since the last call. As with RAPL, the first call only records a
+// baseline. Machines without the counters, and every other platform, report zero.
+func (c *Collector) collectSiliconPower(ctx context.Context, now time.Time) siliconPower {
+	if probeOS != "darwin" || runtime.GOARCH != "arm64" {
+		return siliconPower{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.commandTimeout(siliconPowerQueryTimeout))
+	defer cancel()
+	cur := make(map[string]int64, len(appleEnergyCounters))
+	for _, counter := range appleEnergyCounters {
+		out, err := runCmd(ctx, "ioreg", "-r", "-c", counter.class, "-d", "1")
+		if err != nil {
+			continue
+		}
+		if mj, ok := parseAppleEnergyCounter(out, counter.keys); ok {
+			cur[counter.subsystem] = mj
+		}
+	}
+	return c.siliconPowerRates(cur, now)
+}
+
+// parseAppleEnergyCounter reads the first of keys from a PerformanceStatistics dictionary.
+func parseAppleEnergyCounter(out string, keys []string) (int64, bool) {
+	for line := range strings.Lines(out) {
+		if !strings.Contains(line, `"PerformanceStatistics" = {`) {
+			continue
+		}
+		for _, key := range keys {
+			if v, ok := ioregDictInt(line, key); ok && v >= 0 {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// siliconPowerRates turns cumulative millijoule counters into watts. A counter that
+// went backwards (driver reload) reads as zero for that interval.
+func (c *Collector) siliconPowerRates(cur map[string]int64, now time.Time) siliconPower {
+	prev, elapsed := c.prevSiliconEnergy, now.Sub(c.lastSiliconAt).Seconds()
+	first := c.lastSiliconAt.IsZero()
+	c.prevSiliconEnergy, c.lastSiliconAt = cur, now
+	if first || elapsed <= 0 {
+		return siliconPower{}
+	}
+	watts := func(subsystem string) float64 {
+		p, okPrev := prev[subsystem]
+		v, okCur := cur[subsystem]
+		if !okPrev || !okCur || v < p {
+			return 0
+		}
+		return float64(v-p) / 1000 / elapsed
+	}
+	return siliconPower{GPUWatts: watts("gpu"), ANEWatts: watts("ane")}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SystemWatts is whole-machine power draw. Estimated marks readings that only
+// approximate it: battery discharge, which misses conversion losses and any
+// external power, and CPU package power, which misses everything but the CPU.
+type SystemWatts struct {
+	Watts     float64
+	Source    string // ioreg, powermetrics, battery or rapl; empty when unknown
+	Estimated bool
+	// GPUWatts and ANEWatts break out the GPU and Neural Engine on Apple Silicon,
+	// from ioreg energy counters without root. Approximate; 0 when not found.
+	GPUWatts float64
+	ANEWatts float64
+}
+
+// collectPowermetrics enables the powermetrics power reading on macOS. Off by
+// default: powermetrics needs root and takes half a second to sample.
+var collectPowermetrics bool
+
+// String renders the draw as "12.3W", with a leading "~" on estimates; "" when unknown.
+func (s SystemWatts) String() string {
+	if s.Watts <= 0 {
+		return ""
+	}
+	prefix := ""
+	if s.Estimated {
+		prefix = "~"
+	}
+	return fmt.Sprintf("%s%.1fW", prefix, s.Watts)
+}
+
+// withSubsystems adds the Apple Silicon GPU and Neural Engine breakdown.
+func (s SystemWatts) withSubsystems(p siliconPower) SystemWatts {
+	s.GPUWatts, s.ANEWatts = p.GPUWatts, p.ANEWatts
+	return s
+}
+
+// Breakdown renders the subsystem split as "GPU ~1.2W · ANE ~0.3W"; "" without one.
+// The "~" is always there: the counters only approximate the draw.
+func (s SystemWatts) Breakdown() string {
+	var parts []string
+	if s.GPUWatts > 0 {
+		parts = append(parts, fmt.Sprintf("GPU ~%.1fW", s.GPUWatts))
+	}
+	if s.ANEWatts > 0 {
+		parts = append(parts, fmt.Sprintf("ANE ~%.1fW", s.ANEWatts))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// systemWatts picks the best whole-machine figure. Measured sources come first:
+// SMC system input on macOS, then powermetrics' combined CPU, GPU and ANE power.
+// After that, the primary battery's discharge rate stands in for the whole machine
+// while it's unplugged, and RAPL package power is the last resort.
+func systemWatts(t ThermalStatus, batts []BatteryStatus, powermetricsWatts float64) SystemWatts {
+	switch {
+	case t.SystemPower > 0:
+		return SystemWatts{Watts: t.SystemPower, Source: "ioreg"}
+	case powermetricsWatts > 0:
+		return SystemWatts{Watts: powermetricsWatts, Source: "powermetrics"}
+	}
+	if w := dischargeWatts(t, batts); w > 0 {
+		return SystemWatts{Watts: w, Source: "battery", Estimated: true}
+	}
+	if t.CPUPower > 0 {
+		return SystemWatts{Watts: t.CPUPower, Source: "rapl", Estimated: true}
+	}
+	return SystemWatts{}
+}
+
+// dischargeWatts is the primary system battery's output while discharging: the
+// ioreg figure on macOS, else sysfs power_now, else V × |I|, since the current
+// is negative while discharging.
+func dischargeWatts(t ThermalStatus, batts []BatteryStatus) float64 {
+	b, ok := primaryBattery(batts)
+	if !ok || b.Kind != BatteryKindSystem || !strings.EqualFold(b.Status, "discharging") {
+		return 0
+	}
+	if t.BatteryPower > 0 {
+		return t.BatteryPower
+	}
+	if b.Watts > 0 {
+		return b.Watts
+	}
+	return b.VoltageV * math.Abs(b.estimateCurrentA())
+}
+
+// readPowermetricsWatts reads CPU power from the shared powermetrics sample. Any
+// error, including not running as root, reads as 0.
+func readPowermetricsWatts(ctx context.Context) float64 {
+	if probeOS != "darwin" {
+		return 0
+	}
+	out, ok := powermetricsShared.get(ctx)
+	if !ok {
+		return 0
+	}
+	return parsePowermetricsWatts(out)
+}
+
+// parsePowermetricsWatts reads "Combined Power (CPU + GPU + ANE): 5123 mW" on Apple
+// Silicon, or the Intel "package power (CPUs+GT+SA): 3.45W" line.
+func parsePowermetricsWatts(out string) float64 {
+	for line := range strings.Lines(out) {
+		label, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(label, "Combined Power") && !strings.Contains(label, "package power") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		scale := 1.0
+		if num, found := strings.CutSuffix(value, "mW"); found {
+			value, scale = num, 0.001
+		} else {
+			value = strings.TrimSuffix(value, "W")
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && v > 0 {
+			return v * scale
+		}
+	}
+	return 0
+}
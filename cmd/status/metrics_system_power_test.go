@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystemWatts(t *testing.T) {
+	discharging := []BatteryStatus{{Name: "BAT0", Percent: 60, Status: "Discharging", VoltageV: 12, CurrentA: 1.25}}
+	// sysfs and ioreg report a negative current while discharging.
+	signed := []BatteryStatus{{Name: "BAT0", Percent: 60, Status: "Discharging", VoltageV: 12, CurrentA: -1.25}}
+	charging := []BatteryStatus{{Name: "BAT0", Percent: 60, Status: "Charging", VoltageV: 12, CurrentA: 1.25}}
+	tests := []struct {
+		name    string
+		thermal ThermalStatus
+		batts   []BatteryStatus
+		pm      float64
+		want    SystemWatts
+		text    string
+	}{
+		{"smc wins", ThermalStatus{SystemPower: 18.4, CPUPower: 6}, discharging, 9, SystemWatts{Watts: 18.4, Source: "ioreg"}, "18.4W"},
+		{"powermetrics", ThermalStatus{CPUPower: 6}, charging, 9, SystemWatts{Watts: 9, Source: "powermetrics"}, "9.0W"},
+		{"battery proxy", ThermalStatus{CPUPower: 6}, discharging, 0, SystemWatts{Watts: 15, Source: "battery", Estimated: true}, "~15.0W"},
+		{"battery proxy, signed current", ThermalStatus{CPUPower: 6}, signed, 0, SystemWatts{Watts: 15, Source: "battery", Estimated: true}, "~15.0W"},
+		{"rapl on ac", ThermalStatus{CPUPower: 6}, charging, 0, SystemWatts{Watts: 6, Source: "rapl", Estimated: true}, "~6.0W"},
+		{"unknown", ThermalStatus{}, nil, 0, SystemWatts{}, ""},
+	}
+	for _, tt := range tests {
+		got := systemWatts(tt.thermal, tt.batts, tt.pm)
+		if got != tt.want || got.String() != tt.text {
+			t.Errorf("%s: got %+v %q, want %+v %q", tt.name, got, got.String(), tt.want, tt.text)
+		}
+	}
+}
+
+func TestParsePowermetricsWatts(t *testing.T) {
+	apple := "CPU Power: 1234 mW\nGPU Power: 200 mW\nANE Power: 0 mW\nCombined Power (CPU + GPU + ANE): 1434 mW\n"
+	if got := parsePowermetricsWatts(apple); got != 1.434 {
+		t.Fatalf("apple silicon: got %v", got)
+	}
+	intel := "Intel energy model derived package power (CPUs+GT+SA): 3.45W\n"
+	if got := parsePowermetricsWatts(intel); got != 3.45 {
+		t.Fatalf("intel: got %v", got)
+	}
+	if got := parsePowermetricsWatts("powermetrics must be invoked as the superuser\n"); got != 0 {
+		t.Fatalf("error output: got %v", got)
+	}
+}
+
+func TestSiliconPowerFromEnergyCounters(t *testing.T) {
+	gpu := `+-o AGXAcceleratorG13X  <class AGXAcceleratorG13X>
+    {
+      "PerformanceStatistics" = {"Device Utilization %"=12,"GPU Energy"=1500000,"In use system memory"=123}
+    }`
+	if got, ok := parseAppleEnergyCounter(gpu, appleEnergyCounters[0].keys); !ok || got != 1500000 {
+		t.Fatalf("gpu counter = %d, %v", got, ok)
+	}
+	if _, ok := parseAppleEnergyCounter(`"PerformanceStatistics" = {"Device Utilization %"=12}`, appleEnergyCounters[0].keys); ok {
+		t.Fatal("missing counter should be omitted")
+	}
+
+	c := NewCollector()
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	if got := c.siliconPowerRates(map[string]int64{"gpu": 1500000, "ane": 20000}, start); got != (siliconPower{}) {
+		t.Fatalf("baseline = %+v", got)
+	}
+	// 4 J of GPU and 0.6 J of ANE energy over 2 s.
+	got := c.siliconPowerRates(map[string]int64{"gpu": 1504000, "ane": 20600}, start.Add(2*time.Second))
+	if got.GPUWatts != 2 || got.ANEWatts != 0.3 {
+		t.Fatalf("rates = %+v, want 2W and 0.3W", got)
+	}
+	// A counter reset reads as zero rather than a negative draw.
+	if got := c.siliconPowerRates(map[string]int64{"gpu": 10}, start.Add(4*time.Second)); got.GPUWatts != 0 || got.ANEWatts != 0 {
+		t.Fatalf("after reset = %+v", got)
+	}
+
+	w := SystemWatts{Watts: 9, Source: "ioreg"}.withSubsystems(siliconPower{GPUWatts: 1.24, ANEWatts: 0.3})
+	if w.Breakdown() != "GPU ~1.2W · ANE ~0.3W" || (SystemWatts{}).Breakdown() != "" {
+		t.Fatalf("breakdown = %q", w.Breakdown())
+	}
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
 	"slices"
 	"testing"
+	"time"
 )
 
 func TestNewRingBuffer(t *testing.T) {
@@ -179,3 +185,138 @@ func TestRingBuffer_NegativeAndZeroValues(t *testing.T) {
 		t.Errorf("Slice() with negative/zero values = %v, want %v", got, want)
 	}
 }
+
+func TestCollectorPrimeSkip(t *testing.T) {
+	c := NewCollector()
+	start := time.Now()
+	c.Prime(context.Background(), 0)
+	if !c.lastNetAt.IsZero() || !c.lastDiskAt.IsZero() {
+		t.Fatal("Prime(0) must not take a baseline")
+	}
+
+	c.lastNetAt = start
+	c.Prime(context.Background(), time.Hour)
+	if time.Since(start) > time.Second {
+		t.Fatal("Prime must not sleep once a baseline exists")
+	}
+}
+
+func TestMetricsSnapshotFresh(t *testing.T) {
+	if (MetricsSnapshot{}).Fresh(time.Hour) {
+		t.Fatal("unstamped snapshot must never be fresh")
+	}
+	m := MetricsSnapshot{CollectedAt: time.Now()}
+	if !m.Fresh(time.Minute) {
+		t.Fatal("expected a just-collected snapshot to be fresh")
+	}
+	if m.Fresh(0) {
+		t.Fatal("zero max age must never be fresh")
+	}
+	old := MetricsSnapshot{CollectedAt: time.Now().Add(-2 * time.Minute)}
+	if old.Fresh(time.Minute) {
+		t.Fatal("expected a two-minute-old snapshot to be stale")
+	}
+}
+
+func TestCancellationAbortsSlowCommand(t *testing.T) {
+	orig := cmdRunner
+	// The run is detached from the cancelled caller; stop it before restoring.
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop); waitCommands(); cmdRunner = orig })
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-stop:
+			return "", errors.New("test over")
+		case <-time.After(10 * time.Second):
+			return "too slow", nil
+		}
+	}
+
+	cache := newSystemProfilerCache(map[string]time.Duration{spPowerDataType: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	if got := cache.get(ctx, spPowerDataType); got != "" {
+		t.Fatalf("expected no output from a cancelled probe, got %q", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("cancellation took %v; probe should abort well before its 3s timeout", elapsed)
+	}
+}
+
+func TestRunCmdSkipsCancelledContext(t *testing.T) {
+	orig := cmdRunner
+	t.Cleanup(func() { cmdRunner = orig })
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		t.Errorf("%s spawned after its context was cancelled", name)
+		return "", nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 20 {
+		if _, err := runCmd(ctx, "pmset", "-g", "batt"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", err)
+		}
+	}
+
+	c := NewCollector()
+	if _, err := c.Collect(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Collect err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRunCmdBackstopTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script stub")
+	}
+	prevPaths, prevTimeout := commandPaths, defaultCommandTimeout
+	t.Cleanup(func() { waitCommands(); commandPaths, defaultCommandTimeout = prevPaths, prevTimeout })
+	commandPaths = map[string]string{}
+	defaultCommandTimeout = 100 * time.Millisecond
+
+	// The child sleeps in the background holding stdout, so killing the shell
+	// alone isn't enough; WaitDelay has to give up on the pipe too.
+	stub := filepath.Join(t.TempDir(), "hang")
+	if err := os.WriteFile(stub, []byte("#!/bin/sh\nsleep 600 &\nwait\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	const name = "mole-test-hang"
+	if err := setCommandPath(name + "=" + stub); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err := runCmd(context.Background(), name)
+	if err == nil {
+		t.Fatal("expected a hanging command to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("runCmd took %v; the backstop timeout didn't stop it", elapsed)
+	}
+}
+
+func TestRunCmdKeepsCallerDeadline(t *testing.T) {
+	prevRunner, prevTimeout := cmdRunner, defaultCommandTimeout
+	t.Cleanup(func() { waitCommands(); cmdRunner, defaultCommandTimeout = prevRunner, prevTimeout })
+	defaultCommandTimeout = time.Hour
+
+	var got time.Time
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		got, _ = ctx.Deadline()
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if _, err := runCmd(ctx, "mole-test-sleep"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	waitCommands() // runCmd returns at the deadline, maybe before the fake does
+	if !got.Equal(want) {
+		t.Fatalf("command saw deadline %v, want the caller's %v", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build darwin && cgo
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation
+#import <Foundation/Foundation.h>
+
+static int moleThermalState(void) {
+	@autoreleasepool {
+		NSProcessInfo *info = [NSProcessInfo processInfo];
+		if (![info respondsToSelector:@selector(thermalState)]) {
+			return -1;
+		}
+		return (int)[info thermalState];
+	}
+}
+*/
+import "C"
+
+// readThermalState returns NSProcessInfo.thermalState mapped to a ThermalLevel.
+func readThermalState() (ThermalLevel, bool) {
+	switch C.moleThermalState() {
+	case 0:
+		return ThermalLevelNominal, true
+	case 1:
+		return ThermalLevelFair, true
+	case 2:
+		return ThermalLevelSerious, true
+	case 3:
+		return ThermalLevelCritical, true
+	default:
+		return ThermalLevelUnknown, false
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// CPUTempSource records where ThermalStatus.CPUTemp came from, so a proxy or an
+// estimate can be told apart from a die reading.
+type CPUTempSource string
+
+const (
+	CPUTempSourceUnknown      CPUTempSource = ""
+	CPUTempSourceSMC          CPUTempSource = "smc"          // Intel Mac SMC die/proximity keys
+	CPUTempSourceHID          CPUTempSource = "hid"          // Apple Silicon die sensors via IOHID
+	CPUTempSourcePowermetrics CPUTempSource = "powermetrics" // Intel die temperature from powermetrics' SMC sampler (root)
+	CPUTempSourceXCPM         CPUTempSource = "xcpm-level"   // Estimate from Intel's XCPM thermal level
+	CPUTempSourceBattery      CPUTempSource = "battery"      // Battery temperature standing in for the CPU
+	CPUTempSourceThermalZone  CPUTempSource = "thermal-zone" // Linux thermal_zone
+	CPUTempSourceHwmon        CPUTempSource = "hwmon"        // Linux coretemp/k10temp package via hwmon
+)
+
+// Estimate reports whether the source isn't a CPU reading at all;
+// --disable-temp-fallback skips these.
+func (s CPUTempSource) Estimate() bool {
+	return s == CPUTempSourceXCPM || s == CPUTempSourceBattery
+}
+
+// macArch is the Mac's CPU family, which decides where a CPU temperature lives.
+type macArch string
+
+const (
+	macArchIntel        macArch = "intel"
+	macArchAppleSilicon macArch = "apple-silicon"
+)
+
+// macArchCache holds the detected architecture; it can't change while running.
+var macArchCache struct {
+	mu   sync.Mutex
+	arch macArch
+}
+
+// detectMacArch asks the kernel rather than trusting runtime.GOARCH: an amd64 build
+// under Rosetta runs on Apple Silicon, where the Intel SMC keys and XCPM don't
+// exist. hw.optional.arm64 is 1 on Apple Silicon and missing on Intel, where
+// sysctl exits non-zero; GOARCH is only the fallback when sysctl can't be run.
+func detectMacArch(ctx context.Context) macArch {
+	macArchCache.mu.Lock()
+	defer macArchCache.mu.Unlock()
+	if macArchCache.arch != "" {
+		return macArchCache.arch
+	}
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.commandTimeout(quickQueryTimeout))
+	defer cancel()
+	out, err := runCmd(ctx, "sysctl", "-n", "hw.optional.arm64")
+	switch {
+	case err == nil && strings.TrimSpace(out) == "1":
+		macArchCache.arch = macArchAppleSilicon
+	case err == nil, errors.As(err, new(*exec.ExitError)):
+		macArchCache.arch = macArchIntel
+	case runtime.GOARCH == "arm64":
+		return macArchAppleSilicon // Not cached: sysctl may work next time
+	default:
+		return macArchIntel
+	}
+	return macArchCache.arch
+}
+
+func resetMacArch() {
+	macArchCache.mu.Lock()
+	macArchCache.arch = ""
+	macArchCache.mu.Unlock()
+}
+
+// macCPUTempSources is the order CPU temperature sources are tried in, per arch.
+// Intel has real die readings in the SMC, then powermetrics (with --powermetrics),
+// and the XCPM level as an estimate; Apple Silicon has none of these, but publishes
+// die sensors through IOHID without root. The battery proxy is the last resort on
+// both.
+func macCPUTempSources(arch macArch) []CPUTempSource {
+	if arch == macArchAppleSilicon {
+		return []CPUTempSource{CPUTempSourceHID, CPUTempSourceBattery}
+	}
+	return []CPUTempSource{CPUTempSourceSMC, CPUTempSourcePowermetrics, CPUTempSourceXCPM, CPUTempSourceBattery}
+}
+
+// readMacCPUTemp returns the first plausible CPU temperature from the sources for
+// arch, and which source gave it. batteryTemp is the already-read battery
+// temperature. Estimates are skipped with --disable-temp-fallback, and powermetrics
+// is only run with --powermetrics since it needs root.
+func readMacCPUTemp(ctx context.Context, arch macArch, batteryTemp float64) (float64, CPUTempSource) {
+	for _, src := range macCPUTempSources(arch) {
+		if disableTempFallback && src.Estimate() {
+			continue
+		}
+		if !localProbes && (src == CPUTempSourceSMC || src == CPUTempSourceHID) {
+			continue // gopsutil reads this Mac's sensors, not a remote one's
+		}
+		var temp float64
+		var ok bool
+		switch src {
+		case CPUTempSourceSMC:
+			if temps, err := sensorTemps.get(ctx); err == nil {
+				temp, ok = pickSMCCPUTemp(temps)
+			}
+		case CPUTempSourceHID:
+			if temps, err := sensorTemps.get(ctx); err == nil {
+				temp, ok = pickAppleSiliconCPUTemp(temps)
+			}
+		case CPUTempSourcePowermetrics:
+			if collectPowermetrics {
+				temp, ok = readPowermetricsCPUTemp(ctx)
+			}
+		case CPUTempSourceXCPM:
+			temp, ok = readXCPMTemp(ctx)
+		case CPUTempSourceBattery:
+			temp, ok = batteryTemp, batteryTemp > 0
+		}
+		if ok {
+			return temp, src
+		}
+	}
+	return 0, CPUTempSourceUnknown
+}
+
+// readXCPMTemp maps Intel's machdep.xcpm.cpu_thermal_level onto a rough
+// temperature: 45°C at level 0, half a degree per level.
+func readXCPMTemp(ctx context.Context) (float64, bool) {
+	ctx, cancel := context.WithTimeout(ctx, collectOptions.commandTimeout(quickQueryTimeout))
+	defer cancel()
+	out, err := runCmd(ctx, "sysctl", "-n", "machdep.xcpm.cpu_thermal_level")
+	if err != nil {
+		return 0, false
+	}
+	level, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil || level < 0 {
+		return 0, false
+	}
+	return 45 + float64(level)*0.5, true
+}
+
+// readPowermetricsCPUTemp reads the Intel CPU die temperature from the shared
+// powermetrics sample. Any error, including not running as root, reads as no
+// reading.
+func readPowermetricsCPUTemp(ctx context.Context) (float64, bool) {
+	out, ok := powermetricsShared.get(ctx)
+	if !ok {
+		return 0, false
+	}
+	return parsePowermetricsCPUTemp(out)
+}
+
+// parsePowermetricsCPUTemp reads the "CPU die temperature: 52.31 C" line.
+func parsePowermetricsCPUTemp(out string) (float64, bool) {
+	for line := range strings.Lines(out) {
+		label, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(label), "CPU die temperature") {
+			continue
+		}
+		value = strings.TrimSuffix(strings.TrimSpace(value), "C")
+		if c, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && plausibleCelsius(c) {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// appleSiliconDieSensors are the IOHID names of CPU die sensors: "PMU tdie<N>"
+// on the SoC power manager, and the per-cluster "pACC/eACC MTR Temp Sensor<N>".
+var appleSiliconDieSensors = []string{"PMU tdie", "pACC MTR Temp Sensor", "eACC MTR Temp Sensor"}
+
+// pickAppleSiliconCPUTemp returns the hottest Apple Silicon CPU die sensor.
+func pickAppleSiliconCPUTemp(temps []sensors.TemperatureStat) (float64, bool) {
+	unit := gopsutilTempUnit(temps)
+	var hottest float64
+	for _, t := range temps {
+		key := strings.TrimSpace(t.SensorKey)
+		if !containsAny(key, appleSiliconDieSensors...) {
+			continue
+		}
+		if c := toCelsius(t.Temperature, unit); plausibleCelsius(c) {
+			hottest = max(hottest, c)
+		}
+	}
+	return hottest, hottest > 0
+}
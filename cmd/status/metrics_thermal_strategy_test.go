@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// fakeSysctl answers `sysctl -n <name>` from values; missing names fail like
+// sysctl does. It also restores sensorTemps.fetch for tests that replace it.
+func fakeSysctl(t *testing.T, values map[string]string) {
+	t.Helper()
+	prev, prevFetch := cmdRunner, sensorTemps.fetch
+	t.Cleanup(func() {
+		cmdRunner, sensorTemps.fetch = prev, prevFetch
+		ResetState()
+	})
+	ResetState()
+	cmdRunner = func(_ context.Context, _ []string, name string, args ...string) (string, error) {
+		if name != "sysctl" || len(args) != 2 {
+			return "", errors.New("unexpected command " + name + " " + strings.Join(args, " "))
+		}
+		if v, ok := values[args[1]]; ok {
+			return v + "\n", nil
+		}
+		return "", errors.New("sysctl: unknown oid '" + args[1] + "'")
+	}
+}
+
+func TestDetectMacArch(t *testing.T) {
+	fakeSysctl(t, map[string]string{"hw.optional.arm64": "1"})
+	if got := detectMacArch(context.Background()); got != macArchAppleSilicon {
+		t.Fatalf("arm64=1: got %q", got)
+	}
+
+	fakeSysctl(t, map[string]string{"hw.optional.arm64": "0"})
+	if got := detectMacArch(context.Background()); got != macArchIntel {
+		t.Fatalf("arm64=0: got %q", got)
+	}
+}
+
+func TestDetectMacArchCachesMissingOid(t *testing.T) {
+	// sysctl exits 1 for an unknown oid; sh stands in for it here.
+	exit1 := exec.Command("sh", "-c", "exit 1").Run()
+	if exit1 == nil {
+		t.Skip("sh not available")
+	}
+	fakeSysctl(t, nil)
+	var calls int
+	cmdRunner = func(context.Context, []string, string, ...string) (string, error) {
+		calls++
+		return "", exit1
+	}
+	for range 3 {
+		if got := detectMacArch(context.Background()); got != macArchIntel {
+			t.Fatalf("missing hw.optional.arm64: got %q", got)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("sysctl ran %d times, want once: an Intel answer should be cached", calls)
+	}
+}
+
+func TestMacCPUTempSourcesPerArch(t *testing.T) {
+	if got := macCPUTempSources(macArchAppleSilicon); slices.Contains(got, CPUTempSourceSMC) || slices.Contains(got, CPUTempSourceXCPM) {
+		t.Fatalf("Apple Silicon should not try Intel-only sources: %v", got)
+	}
+	if got := macCPUTempSources(macArchIntel); got[0] != CPUTempSourceSMC || got[len(got)-1] != CPUTempSourceBattery {
+		t.Fatalf("Intel order = %v", got)
+	}
+}
+
+func TestReadMacCPUTempFallsBackToXCPMOnIntel(t *testing.T) {
+	fakeSysctl(t, map[string]string{"hw.optional.arm64": "0", "machdep.xcpm.cpu_thermal_level": "20"})
+	sensorTemps.fetch = func(context.Context) ([]sensors.TemperatureStat, error) { return nil, nil }
+
+	temp, src := readMacCPUTemp(context.Background(), detectMacArch(context.Background()), 31)
+	if src != CPUTempSourceXCPM || temp != 55 {
+		t.Fatalf("got %.1f from %q, want 55 from xcpm-level", temp, src)
+	}
+
+	prev := disableTempFallback
+	disableTempFallback = true
+	t.Cleanup(func() { disableTempFallback = prev })
+	if temp, src := readMacCPUTemp(context.Background(), macArchIntel, 31); temp != 0 || src != CPUTempSourceUnknown {
+		t.Fatalf("with fallbacks disabled got %.1f from %q", temp, src)
+	}
+}
+
+func TestReadMacCPUTempUsesDieSensorsOnAppleSilicon(t *testing.T) {
+	fakeSysctl(t, map[string]string{"hw.optional.arm64": "1"})
+	sensorTemps.fetch = func(context.Context) ([]sensors.TemperatureStat, error) {
+		return []sensors.TemperatureStat{
+			{SensorKey: "PMU tdie1", Temperature: 48.2},
+			{SensorKey: "PMU tdie4", Temperature: 61.5},
+			{SensorKey: "gas gauge battery", Temperature: 31},
+			{SensorKey: "TC0D", Temperature: 99}, // Not an Apple Silicon key
+		}, nil
+	}
+
+	temp, src := readMacCPUTemp(context.Background(), detectMacArch(context.Background()), 31)
+	if src != CPUTempSourceHID || temp != 61.5 {
+		t.Fatalf("got %.1f from %q, want 61.5 from hid", temp, src)
+	}
+}
+
+func TestParsePowermetricsCPUTemp(t *testing.T) {
+	out := "**** SMC sensors ****\n\nCPU Thermal level: 12\nGPU die temperature: 44.10 C\nCPU die temperature: 52.31 C\nFan: 1832.61 rpm\n"
+	if got, ok := parsePowermetricsCPUTemp(out); !ok || got != 52.31 {
+		t.Fatalf("got %.2f, %v; want 52.31", got, ok)
+	}
+	if _, ok := parsePowermetricsCPUTemp("CPU die temperature: 0.00 C\n"); ok {
+		t.Fatal("an implausible reading should be rejected")
+	}
+}
+
+func TestReadMacCPUTempPrefersPowermetricsOverXCPM(t *testing.T) {
+	fakeSysctl(t, map[string]string{"machdep.xcpm.cpu_thermal_level": "20"})
+	sensorTemps.fetch = func(context.Context) ([]sensors.TemperatureStat, error) { return nil, nil }
+	sysctl := cmdRunner
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		if name == "powermetrics" {
+			return "CPU die temperature: 63.50 C\n", nil
+		}
+		return sysctl(ctx, env, name, args...)
+	}
+
+	// Without --powermetrics the estimate is all there is.
+	if _, src := readMacCPUTemp(context.Background(), macArchIntel, 31); src != CPUTempSourceXCPM {
+		t.Fatalf("without --powermetrics got %q", src)
+	}
+	prev := collectPowermetrics
+	collectPowermetrics = true
+	t.Cleanup(func() { collectPowermetrics = prev })
+	if temp, src := readMacCPUTemp(context.Background(), macArchIntel, 31); src != CPUTempSourcePowermetrics || temp != 63.5 {
+		t.Fatalf("got %.1f from %q, want 63.5 from powermetrics", temp, src)
+	}
+}
+
+func TestApplyCPUTempSpreadClearsEstimate(t *testing.T) {
+	th := ThermalStatus{CPUTemp: 55, CPUTempSource: CPUTempSourceXCPM, Estimated: true}
+	applyCPUTempSpread(&th, []SensorReading{{Class: SensorClassCPU, Unit: "°C", Value: 50}})
+	if !th.Estimated || th.CPUTemp != 55 {
+		t.Fatalf("a cooler sensor should leave the estimate: %+v", th)
+	}
+	applyCPUTempSpread(&th, []SensorReading{{Class: SensorClassCPU, Unit: "°C", Value: 68}})
+	if th.Estimated || th.CPUTemp != 68 {
+		t.Fatalf("a hotter die sensor should replace the estimate: %+v", th)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !darwin || !cgo
+
+package main
+
+// readThermalState is unavailable without cgo on macOS; callers fall back to the estimate.
+func readThermalState() (ThermalLevel, bool) {
+	return ThermalLevelUnknown, false
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const thermalZoneRoot = "/sys/class/thermal"
+
+// thermalZoneNames maps common sysfs thermal zone types to friendly labels.
+var thermalZoneNames = map[string]string{
+	"x86_pkg_temp":   "CPU Package",
+	"cpu-thermal":    "CPU",
+	"cpu_thermal":    "CPU",
+	"tcpu":           "CPU",
+	"soc_thermal":    "SoC",
+	"gpu-thermal":    "GPU",
+	"gpu_thermal":    "GPU",
+	"acpitz":         "ACPI",
+	"pch_skylake":    "PCH",
+	"pch_cannonlake": "PCH",
+	"iwlwifi_1":      "Wi-Fi",
+	"sen1":           "Board",
+}
+
+type thermalZone struct {
+	Type    string // Raw sysfs type, e.g. x86_pkg_temp
+	Reading SensorReading
+}
+
+// collectLinuxThermal fills CPUTemp from the CPU package zone (or the hottest
+// CPU-ish zone) and returns every readable zone as a sensor.
+func collectLinuxThermal(root string) ThermalStatus {
+	var thermal ThermalStatus
+	zones, denied := readThermalZones(root)
+	thermal.PermissionDenied = denied
+	thermal.CPUTemp = pickCPUZone(zones, !disableTempFallback)
+	thermal.Level = thermalLevelFromTemp(thermal.CPUTemp)
+	for _, z := range zones {
+		thermal.Zones = append(thermal.Zones, z.Reading)
+	}
+	return thermal
+}
+
+// readThermalZones reads thermal_zone*/{type,temp}; temp is in millidegrees Celsius.
+// denied reports whether any zone was skipped because its temp file returned EACCES.
+func readThermalZones(root string) (zones []thermalZone, denied bool) {
+	dirs, _ := filepath.Glob(filepath.Join(root, "thermal_zone*"))
+	seen := make(map[string]int)
+	seenKeys := make(map[string]int)
+	unit := detectTempUnit(sensorSourceThermalZone, nil, false)
+	for _, dir := range dirs {
+		raw, err := os.ReadFile(filepath.Join(dir, "temp"))
+		if errors.Is(err, fs.ErrPermission) {
+			denied = true
+			continue
+		}
+		milli, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+		if err != nil {
+			continue
+		}
+		temp := toCelsius(float64(milli)/1000.0, unit)
+		if !plausibleCelsius(temp) {
+			continue
+		}
+		typeData, _ := os.ReadFile(filepath.Join(dir, "type"))
+		zoneType := strings.TrimSpace(string(typeData))
+		if zoneType == "" {
+			zoneType = filepath.Base(dir)
+		}
+		label := thermalZoneLabel(zoneType)
+		seen[label]++
+		if n := seen[label]; n > 1 {
+			label = label + " " + strconv.Itoa(n)
+		}
+		key := strings.ToLower(zoneType)
+		seenKeys[key]++
+		if n := seenKeys[key]; n > 1 {
+			key += "_" + strconv.Itoa(n)
+		}
+		zones = append(zones, thermalZone{
+			Type: zoneType,
+			Reading: SensorReading{
+				Key:   key,
+				Label: label,
+				Value: temp,
+				Unit:  "°C",
+				Class: thermalZoneClass(zoneType),
+			},
+		})
+	}
+	return zones, denied
+}
+
+func thermalZoneLabel(zoneType string) string {
+	if name, ok := thermalZoneNames[strings.ToLower(zoneType)]; ok {
+		return name
+	}
+	return zoneType
+}
+
+func thermalZoneClass(zoneType string) SensorClass {
+	lower := strings.ToLower(zoneType)
+	if lower == "x86_pkg_temp" || lower == "tcpu" || strings.HasPrefix(lower, "cpu") || strings.HasPrefix(lower, "soc") {
+		return SensorClassCPU
+	}
+	return classifySensor(zoneType)
+}
+
+// pickCPUZone prefers x86_pkg_temp, then the hottest CPU-class zone, then (if
+// fallback is allowed) the hottest zone overall.
+func pickCPUZone(zones []thermalZone, fallback bool) float64 {
+	var cpu, hottest float64
+	for _, z := range zones {
+		if z.Type == "x86_pkg_temp" {
+			return z.Reading.Value
+		}
+		if z.Reading.Class == SensorClassCPU {
+			cpu = max(cpu, z.Reading.Value)
+		}
+		hottest = max(hottest, z.Reading.Value)
+	}
+	if cpu > 0 || !fallback {
+		return cpu
+	}
+	return hottest
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectLinuxThermalPrefersPackage(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, "thermal_zone0/type", "acpitz\n")
+	writeSysfs(t, root, "thermal_zone0/temp", "71000\n")
+	writeSysfs(t, root, "thermal_zone1/type", "x86_pkg_temp\n")
+	writeSysfs(t, root, "thermal_zone1/temp", "58500\n")
+	writeSysfs(t, root, "thermal_zone2/type", "acpitz\n")
+	writeSysfs(t, root, "thermal_zone2/temp", "27800\n")
+	writeSysfs(t, root, "thermal_zone3/type", "iwlwifi_1\n")
+	writeSysfs(t, root, "thermal_zone3/temp", "-274000\n")
+
+	thermal := collectLinuxThermal(root)
+	if thermal.CPUTemp != 58.5 {
+		t.Fatalf("expected x86_pkg_temp 58.5, got %v", thermal.CPUTemp)
+	}
+	if thermal.Level != ThermalLevelNominal {
+		t.Fatalf("expected nominal level, got %v", thermal.Level)
+	}
+	want := []string{"ACPI", "CPU Package", "ACPI 2"}
+	if len(thermal.Zones) != len(want) {
+		t.Fatalf("expected %d zones, got %+v", len(want), thermal.Zones)
+	}
+	wantKeys := []string{"acpitz", "x86_pkg_temp", "acpitz_2"}
+	for i, label := range want {
+		if thermal.Zones[i].Label != label {
+			t.Fatalf("zone %d label = %q, want %q", i, thermal.Zones[i].Label, label)
+		}
+		if thermal.Zones[i].Key != wantKeys[i] {
+			t.Fatalf("zone %d key = %q, want %q", i, thermal.Zones[i].Key, wantKeys[i])
+		}
+	}
+	if thermal.Zones[1].Class != SensorClassCPU {
+		t.Fatalf("expected package zone classed as CPU, got %s", thermal.Zones[1].Class)
+	}
+}
+
+func TestPickCPUZoneFallback(t *testing.T) {
+	zones := []thermalZone{
+		{Type: "acpitz", Reading: SensorReading{Value: 44, Class: SensorClassOther}},
+		{Type: "pch_skylake", Reading: SensorReading{Value: 52, Class: SensorClassOther}},
+	}
+	if got := pickCPUZone(zones, true); got != 52 {
+		t.Fatalf("expected hottest zone 52, got %v", got)
+	}
+	if got := pickCPUZone(zones, false); got != 0 {
+		t.Fatalf("expected no CPU temp without fallback, got %v", got)
+	}
+	zones = append(zones, thermalZone{Type: "cpu-thermal", Reading: SensorReading{Value: 49, Class: SensorClassCPU}})
+	if got := pickCPUZone(zones, true); got != 49 {
+		t.Fatalf("expected CPU zone 49, got %v", got)
+	}
+}
+
+func TestMergeSensorReadings(t *testing.T) {
+	base := []SensorReading{{Label: "CPU Package", Value: 60}}
+	got := mergeSensorReadings(base, []SensorReading{{Label: "CPU Package", Value: 58}, {Label: "ACPI", Value: 40}})
+	if len(got) != 2 || got[0].Value != 60 || got[1].Label != "ACPI" {
+		t.Fatalf("unexpected merge %+v", got)
+	}
+}
+
+func TestReadThermalZonesPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores file permissions")
+	}
+	root := t.TempDir()
+	writeSysfs(t, root, "thermal_zone0/type", "x86_pkg_temp\n")
+	writeSysfs(t, root, "thermal_zone0/temp", "50000\n")
+	if err := os.Chmod(filepath.Join(root, "thermal_zone0", "temp"), 0); err != nil {
+		t.Fatal(err)
+	}
+	zones, denied := readThermalZones(root)
+	if len(zones) != 0 || !denied {
+		t.Fatalf("expected denied with no zones, got %+v denied=%v", zones, denied)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BatteryKind separates the machine's own battery from backup power.
+type BatteryKind string
+
+const (
+	BatteryKindSystem BatteryKind = ""    // Laptop or device battery
+	BatteryKindUPS    BatteryKind = "ups" // Uninterruptible power supply via NUT or apcupsd
+)
+
+// UPSInput is the mains line feeding a UPS, for spotting brownouts.
+type UPSInput struct {
+	VoltageV    float64 // 0 during an outage
+	NominalV    float64 // Rated line voltage; 0 when the backend doesn't say
+	FrequencyHz float64 // 0 when not reported
+}
+
+// brownoutRatio is the fraction of nominal voltage below which the line counts as sagging.
+const brownoutRatio = 0.9
+
+// Low reports a line voltage sagging below brownoutRatio of nominal, outages included.
+func (in UPSInput) Low() bool {
+	return in.NominalV > 0 && in.VoltageV < in.NominalV*brownoutRatio
+}
+
+// upsInput builds an UPSInput from parsed values; nil unless the voltage was reported.
+func upsInput(voltage, nominal, freq float64, voltageErr error) *UPSInput {
+	if voltageErr != nil || voltage < 0 {
+		return nil
+	}
+	return &UPSInput{VoltageV: voltage, NominalV: max(nominal, 0), FrequencyHz: max(freq, 0)}
+}
+
+// collectUPS reads every UPS that NUT (upsc) or apcupsd (apcaccess) knows about.
+// NUT wins when both are installed, since apcupsd setups rarely run upsd too.
+func collectUPS(ctx context.Context) []BatteryStatus {
+	if commandExists("upsc") {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		if out, err := runCmd(ctx, "upsc", "-l"); err == nil {
+			var upses []BatteryStatus
+			for name := range strings.FieldsSeq(out) {
+				if vars, err := runCmd(ctx, "upsc", name); err == nil {
+					if b, ok := parseUPSC(name, vars); ok {
+						upses = append(upses, b)
+					}
+				}
+			}
+			if len(upses) > 0 {
+				return upses
+			}
+		}
+	}
+	if commandExists("apcaccess") {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		if out, err := runCmd(ctx, "apcaccess", "status"); err == nil {
+			if b, ok := parseApcaccess(out); ok {
+				return []BatteryStatus{b}
+			}
+		}
+	}
+	return nil
+}
+
+// parseUPSC maps `upsc <name>` variables ("battery.charge: 100") onto a BatteryStatus.
+func parseUPSC(name, out string) (BatteryStatus, bool) {
+	vars := map[string]string{}
+	for line := range strings.Lines(out) {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	percent, err := strconv.ParseFloat(vars["battery.charge"], 64)
+	if err != nil {
+		return BatteryStatus{}, false
+	}
+	percent, ok := normalizeBatteryPercent(percent)
+	if !ok {
+		return BatteryStatus{}, false
+	}
+	b := BatteryStatus{
+		Name:    name,
+		Kind:    BatteryKindUPS,
+		Percent: percent,
+		Status:  upsStatus(strings.Fields(vars["ups.status"])),
+		Source:  "nut",
+	}
+	b.LoadPercent, _ = strconv.ParseFloat(vars["ups.load"], 64)
+	b.VoltageV, _ = strconv.ParseFloat(vars["battery.voltage"], 64)
+	volts, err := strconv.ParseFloat(vars["input.voltage"], 64)
+	nominal, _ := strconv.ParseFloat(vars["input.voltage.nominal"], 64)
+	freq, _ := strconv.ParseFloat(vars["input.frequency"], 64)
+	b.Input = upsInput(volts, nominal, freq, err)
+	if secs, err := strconv.ParseFloat(vars["battery.runtime"], 64); err == nil && secs > 0 {
+		b.TimeLeft = formatUPSRuntime(time.Duration(secs) * time.Second)
+	}
+	return b, true
+}
+
+// upsStatus translates NUT status flags (OL, OB, LB, CHRG, DISCHRG) into pmset-style words.
+func upsStatus(flags []string) string {
+	has := func(flag string) bool {
+		for _, f := range flags {
+			if strings.EqualFold(f, flag) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("OB"), has("DISCHRG"):
+		return "discharging"
+	case has("CHRG"):
+		return "charging"
+	case has("OL"):
+		return "AC attached"
+	default:
+		return "Unknown"
+	}
+}
+
+// parseApcaccess maps `apcaccess status` ("BCHARGE  : 100.0 Percent") onto a BatteryStatus.
+func parseApcaccess(out string) (BatteryStatus, bool) {
+	vars := map[string]string{}
+	for line := range strings.Lines(out) {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	// Values carry a unit word: "45.2 Minutes", "12.0 Percent".
+	number := func(key string) (float64, error) {
+		field, _, _ := strings.Cut(vars[key], " ")
+		return strconv.ParseFloat(field, 64)
+	}
+	percent, err := number("BCHARGE")
+	if err != nil {
+		return BatteryStatus{}, false
+	}
+	percent, ok := normalizeBatteryPercent(percent)
+	if !ok {
+		return BatteryStatus{}, false
+	}
+	name := vars["UPSNAME"]
+	if name == "" {
+		name = "UPS"
+	}
+	b := BatteryStatus{
+		Name:    name,
+		Kind:    BatteryKindUPS,
+		Percent: percent,
+		Status:  "AC attached",
+		Source:  "apcupsd",
+	}
+	status := strings.ToUpper(vars["STATUS"])
+	switch {
+	case strings.Contains(status, "ONBATT"):
+		b.Status = "discharging"
+	case !strings.Contains(status, "ONLINE"):
+		b.Status = "Unknown"
+	}
+	b.LoadPercent, _ = number("LOADPCT")
+	b.VoltageV, _ = number("BATTV")
+	volts, err := number("LINEV")
+	nominal, _ := number("NOMINV")
+	freq, _ := number("LINEFREQ")
+	b.Input = upsInput(volts, nominal, freq, err)
+	if mins, err := number("TIMELEFT"); err == nil && mins > 0 {
+		b.TimeLeft = formatUPSRuntime(time.Duration(mins * float64(time.Minute)))
+	}
+	if secs, err := number("TONBATT"); err == nil && secs > 0 {
+		b.OnBatteryFor = time.Duration(secs) * time.Second
+	}
+	return b, true
+}
+
+// formatUPSRuntime renders a runtime estimate like pmset does, "H:MM".
+func formatUPSRuntime(d time.Duration) string {
+	return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// mergeUPS adds UPS readings to the batteries. A desktop with a UPS but no
+// battery of its own no longer reports ErrNoBattery.
+func mergeUPS(batts []BatteryStatus, err error, upses []BatteryStatus) ([]BatteryStatus, error) {
+	if len(upses) == 0 {
+		return batts, err
+	}
+	if errors.Is(err, ErrNoBattery) {
+		err = nil
+	}
+	return append(batts, upses...), err
+}
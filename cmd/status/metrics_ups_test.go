@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUPSC(t *testing.T) {
+	out := "battery.charge: 87\nbattery.runtime: 2730\nbattery.voltage: 13.4\nups.load: 23\nups.status: OB DISCHRG\nups.model: Back-UPS ES 700G\n"
+	b, ok := parseUPSC("myups", out)
+	if !ok {
+		t.Fatal("expected a UPS reading")
+	}
+	want := BatteryStatus{Name: "myups", Kind: BatteryKindUPS, Percent: 87, Status: "discharging", TimeLeft: "0:45", VoltageV: 13.4, LoadPercent: 23, Source: "nut"}
+	if b != want {
+		t.Fatalf("got %+v, want %+v", b, want)
+	}
+	if _, ok := parseUPSC("myups", "ups.status: OL\n"); ok {
+		t.Fatal("a UPS without battery.charge must be skipped")
+	}
+}
+
+func TestParseApcaccess(t *testing.T) {
+	out := `APC      : 001,036,0862
+UPSNAME  : rack
+STATUS   : ONBATT
+LINEV    : 0.0 Volts
+LOADPCT  : 12.0 Percent
+BCHARGE  : 100.0 Percent
+TIMELEFT : 95.5 Minutes
+BATTV    : 27.1 Volts
+TONBATT  : 42 Seconds
+`
+	b, ok := parseApcaccess(out)
+	if !ok {
+		t.Fatal("expected a UPS reading")
+	}
+	if b.Name != "rack" || b.Kind != BatteryKindUPS || b.Percent != 100 || b.Status != "discharging" {
+		t.Fatalf("unexpected UPS %+v", b)
+	}
+	if b.LoadPercent != 12 || b.TimeLeft != "1:35" || b.OnBatteryFor != 42*time.Second || b.VoltageV != 27.1 {
+		t.Fatalf("unexpected UPS details %+v", b)
+	}
+	// On battery the line reads 0V, which is an outage rather than a missing value.
+	if b.Input == nil || b.Input.VoltageV != 0 || b.Input.Low() {
+		t.Fatalf("unexpected input %+v", b.Input)
+	}
+}
+
+func TestUPSInput(t *testing.T) {
+	out := "battery.charge: 100\nups.status: OL\ninput.voltage: 198.0\ninput.voltage.nominal: 230\ninput.frequency: 49.9\n"
+	b, ok := parseUPSC("myups", out)
+	if !ok || b.Input == nil {
+		t.Fatalf("expected a UPS with input readings, got %+v", b)
+	}
+	if *b.Input != (UPSInput{VoltageV: 198, NominalV: 230, FrequencyHz: 49.9}) || !b.Input.Low() {
+		t.Fatalf("want a 198V brownout on a 230V line, got %+v", *b.Input)
+	}
+
+	apc := "UPSNAME  : rack\nSTATUS   : ONLINE\nBCHARGE  : 100.0 Percent\nLINEV    : 121.0 Volts\nNOMINV   : 120 Volts\nLINEFREQ : 60.0 Hz\n"
+	b, ok = parseApcaccess(apc)
+	if !ok || b.Input == nil || *b.Input != (UPSInput{VoltageV: 121, NominalV: 120, FrequencyHz: 60}) || b.Input.Low() {
+		t.Fatalf("unexpected apcupsd input %+v", b.Input)
+	}
+}
+
+func TestMergeUPS(t *testing.T) {
+	ups := []BatteryStatus{{Name: "rack", Kind: BatteryKindUPS, Percent: 100}}
+	batts, err := mergeUPS(nil, ErrNoBattery, ups)
+	if err != nil || len(batts) != 1 {
+		t.Fatalf("UPS-only desktop: got %v, %v", batts, err)
+	}
+	if _, err := mergeUPS(nil, ErrBatteryPermission, ups); err != ErrBatteryPermission {
+		t.Fatalf("other battery errors must be kept, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NagiosState is a plugin result; its value is the process exit code Nagios/Icinga expect.
+type NagiosState int
+
+const (
+	NagiosOK NagiosState = iota
+	NagiosWarning
+	NagiosCritical
+	NagiosUnknown
+)
+
+func (s NagiosState) String() string {
+	switch s {
+	case NagiosOK:
+		return "OK"
+	case NagiosWarning:
+		return "WARNING"
+	case NagiosCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// worse orders states by severity; UNKNOWN only wins over OK.
+func (s NagiosState) worse(o NagiosState) NagiosState {
+	rank := func(x NagiosState) int {
+		switch x {
+		case NagiosCritical:
+			return 3
+		case NagiosWarning:
+			return 2
+		case NagiosUnknown:
+			return 1
+		}
+		return 0
+	}
+	if rank(o) > rank(s) {
+		return o
+	}
+	return s
+}
+
+// NagiosThresholds are warn/crit levels: battery fires at or below, temperature at or above.
+type NagiosThresholds struct {
+	BatteryWarn, BatteryCrit float64
+	TempWarn, TempCrit       float64
+}
+
+// nagiosThresholds default to the alert thresholds, plus a warning step before battery-low.
+var nagiosThresholds = NagiosThresholds{
+	BatteryWarn: 2 * alertBatteryLowPercent,
+	BatteryCrit: alertBatteryLowPercent,
+	TempWarn:    thermalSeriousThreshold,
+	TempCrit:    thermalHighThreshold,
+}
+
+// NagiosCheck evaluates battery and CPU temperature and returns the state and the
+// plugin output line, e.g. "OK - battery 72%, cpu 54C | battery=72%;@~:20;@~:10;0;100 cpu_temp=54;@75:;@85:".
+// A charging battery never alerts. With neither metric available the state is UNKNOWN.
+// The perfdata ranges use "@", which alerts inside the range endpoints included, so
+// they fire on the same at-or-below and at-or-above boundaries as the check; the
+// plain "20:" and "75" forms would only fire below 20 and above 75.
+func NagiosCheck(m MetricsSnapshot, th NagiosThresholds) (NagiosState, string) {
+	state := NagiosOK
+	var summary, perfdata []string
+	measured := false
+
+	if b, ok := m.PrimaryBattery(); ok {
+		measured = true
+		if !batteryCharging(b.Status) {
+			switch {
+			case b.Percent <= th.BatteryCrit:
+				state = state.worse(NagiosCritical)
+			case b.Percent <= th.BatteryWarn:
+				state = state.worse(NagiosWarning)
+			}
+		}
+		summary = append(summary, fmt.Sprintf("battery %.0f%%", b.Percent))
+		perfdata = append(perfdata, fmt.Sprintf("battery=%s%%;@~:%s;@~:%s;0;100",
+			nagiosNumber(b.Percent), nagiosNumber(th.BatteryWarn), nagiosNumber(th.BatteryCrit)))
+	}
+
+	if t := m.Thermal.CPUTemp; t > 0 {
+		measured = true
+		switch {
+		case t >= th.TempCrit:
+			state = state.worse(NagiosCritical)
+		case t >= th.TempWarn:
+			state = state.worse(NagiosWarning)
+		}
+		summary = append(summary, fmt.Sprintf("cpu %.0fC", t))
+		// Perfdata has no unit for degrees; the label carries it.
+		perfdata = append(perfdata, fmt.Sprintf("cpu_temp=%s;@%s:;@%s:",
+			nagiosNumber(t), nagiosNumber(th.TempWarn), nagiosNumber(th.TempCrit)))
+	}
+
+	if !measured {
+		return NagiosUnknown, NagiosUnknown.String() + " - no battery or CPU temperature available"
+	}
+	return state, fmt.Sprintf("%s - %s | %s", state, strings.Join(summary, ", "), strings.Join(perfdata, " "))
+}
+
+// nagiosNumber formats perfdata values without exponents or trailing zeros.
+func nagiosNumber(v float64) string {
+	return strconv.FormatFloat(roundTo(v, 1), 'f', -1, 64)
+}
+
+// parseNagiosPair parses "warn,crit" for a --nagios-* threshold flag.
+func parseNagiosPair(raw string) (warn, crit float64, err error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("want warn,crit, got %q", raw)
+	}
+	if warn, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil {
+		return 0, 0, fmt.Errorf("bad warning threshold %q", parts[0])
+	}
+	if crit, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err != nil {
+		return 0, 0, fmt.Errorf("bad critical threshold %q", parts[1])
+	}
+	return warn, crit, nil
+}
+
+// runNagios collects one snapshot, prints the plugin line and returns the exit code.
+func runNagios(ctx context.Context, w io.Writer) int {
+	data, _ := NewCollector().Collect(ctx)
+	if ctx.Err() != nil {
+		fmt.Fprintln(w, NagiosUnknown.String()+" - check interrupted")
+		return int(NagiosUnknown)
+	}
+	state, line := NagiosCheck(data, nagiosThresholds)
+	fmt.Fprintln(w, line)
+	return int(state)
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestNagiosCheck(t *testing.T) {
+	th := NagiosThresholds{BatteryWarn: 20, BatteryCrit: 10, TempWarn: 75, TempCrit: 85}
+	tests := []struct {
+		name  string
+		m     MetricsSnapshot
+		state NagiosState
+		line  string
+	}{
+		{
+			name:  "ok",
+			m:     MetricsSnapshot{Batteries: []BatteryStatus{{Percent: 72, Status: "discharging"}}, Thermal: ThermalStatus{CPUTemp: 54.23}},
+			state: NagiosOK,
+			line:  "OK - battery 72%, cpu 54C | battery=72%;@~:20;@~:10;0;100 cpu_temp=54.2;@75:;@85:",
+		},
+		{
+			name:  "hot cpu",
+			m:     MetricsSnapshot{Thermal: ThermalStatus{CPUTemp: 80}},
+			state: NagiosWarning,
+			line:  "WARNING - cpu 80C | cpu_temp=80;@75:;@85:",
+		},
+		{
+			name:  "low battery beats warm cpu",
+			m:     MetricsSnapshot{Batteries: []BatteryStatus{{Percent: 8, Status: "discharging"}}, Thermal: ThermalStatus{CPUTemp: 78}},
+			state: NagiosCritical,
+		},
+		{
+			name:  "battery on the warning boundary",
+			m:     MetricsSnapshot{Batteries: []BatteryStatus{{Percent: 20, Status: "discharging"}}},
+			state: NagiosWarning,
+			line:  "WARNING - battery 20% | battery=20%;@~:20;@~:10;0;100",
+		},
+		{
+			name:  "cpu on the critical boundary",
+			m:     MetricsSnapshot{Thermal: ThermalStatus{CPUTemp: 85}},
+			state: NagiosCritical,
+			line:  "CRITICAL - cpu 85C | cpu_temp=85;@75:;@85:",
+		},
+		{
+			name:  "charging never alerts",
+			m:     MetricsSnapshot{Batteries: []BatteryStatus{{Percent: 5, Status: "charging"}}},
+			state: NagiosOK,
+		},
+		{
+			name:  "nothing measurable",
+			m:     MetricsSnapshot{},
+			state: NagiosUnknown,
+			line:  "UNKNOWN - no battery or CPU temperature available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, line := NagiosCheck(tt.m, th)
+			if state != tt.state {
+				t.Fatalf("state %s, want %s (%s)", state, tt.state, line)
+			}
+			if tt.line != "" && line != tt.line {
+				t.Fatalf("line %q, want %q", line, tt.line)
+			}
+		})
+	}
+}
+
+func TestParseNagiosPair(t *testing.T) {
+	if w, c, err := parseNagiosPair("30, 15"); err != nil || w != 30 || c != 15 {
+		t.Fatalf("got %v, %v, %v", w, c, err)
+	}
+	for _, raw := range []string{"30", "a,15", "30,b", "1,2,3"} {
+		if _, _, err := parseNagiosPair(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
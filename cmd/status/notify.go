@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notifier delivers a desktop notification.
+type Notifier interface {
+	Notify(title, body string) error
+}
+
+// NotifierKind selects a Notifier implementation.
+type NotifierKind string
+
+const (
+	NotifierNone  NotifierKind = "none"
+	NotifierAuto  NotifierKind = "auto"
+	NotifierMacOS NotifierKind = "macos"
+	NotifierLinux NotifierKind = "linux"
+)
+
+// notifyTimeout bounds each notification subprocess.
+const notifyTimeout = 2 * time.Second
+
+// newNotifier returns the notifier for kind; auto picks the current platform's.
+func newNotifier(kind NotifierKind) (Notifier, error) {
+	if kind == NotifierAuto {
+		switch runtime.GOOS {
+		case "darwin":
+			kind = NotifierMacOS
+		case "linux":
+			kind = NotifierLinux
+		default:
+			kind = NotifierNone
+		}
+	}
+	switch kind {
+	case NotifierNone:
+		return nopNotifier{}, nil
+	case NotifierMacOS:
+		return macNotifier{}, nil
+	case NotifierLinux:
+		return linuxNotifier{}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier %q (want none, auto, macos, linux)", kind)
+}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(string, string) error { return nil }
+
+// macNotifier prefers terminal-notifier and falls back to osascript.
+type macNotifier struct{}
+
+func (macNotifier) Notify(title, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if commandExists("terminal-notifier") {
+		_, err := runCmd(ctx, "terminal-notifier", "-title", title, "-message", body, "-group", "mole-status")
+		return err
+	}
+	script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))
+	_, err := runCmd(ctx, "osascript", "-e", script)
+	return err
+}
+
+type linuxNotifier struct{}
+
+func (linuxNotifier) Notify(title, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	_, err := runCmd(ctx, "notify-send", "--app-name=Mole", title, body)
+	return err
+}
+
+// alertDispatcher notifies only when an alert goes from clear to firing, so a
+// condition that stays true doesn't re-notify every tick.
+type alertDispatcher struct {
+	notifier Notifier
+	firing   map[string]bool
+	eval     *alertEvaluator
+}
+
+func newAlertDispatcher(n Notifier) *alertDispatcher {
+	return &alertDispatcher{notifier: n, firing: make(map[string]bool), eval: newAlertEvaluator()}
+}
+
+// Observe evaluates the alerts for a new snapshot and dispatches them.
+func (d *alertDispatcher) Observe(m MetricsSnapshot) {
+	d.Dispatch(d.eval.Evaluate(m))
+}
+
+// Dispatch records the latest alert states and notifies on new firings.
+// Notification failures are ignored; the next transition will try again.
+func (d *alertDispatcher) Dispatch(alerts []Alert) {
+	for _, a := range alerts {
+		was := d.firing[a.Name]
+		d.firing[a.Name] = a.Firing
+		if a.Firing && !was {
+			_ = d.notifier.Notify("Mole: "+alertTitle(a.Name), a.Message)
+		}
+	}
+}
+
+// alertTitle turns "disk-full:/Volumes/X" into "Disk full".
+func alertTitle(name string) string {
+	name, _, _ = strings.Cut(name, ":")
+	name = strings.ReplaceAll(name, "-", " ")
+	if name == "" {
+		return "Alert"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+type fakeNotifier struct {
+	titles []string
+	bodies []string
+}
+
+func (f *fakeNotifier) Notify(title, body string) error {
+	f.titles = append(f.titles, title)
+	f.bodies = append(f.bodies, body)
+	return nil
+}
+
+func TestAlertDispatcherNotifiesOnTransition(t *testing.T) {
+	fake := &fakeNotifier{}
+	d := newAlertDispatcher(fake)
+
+	firing := []Alert{{Name: "battery-low", Firing: true, Message: "Battery at 8%"}}
+	clear := []Alert{{Name: "battery-low"}}
+
+	d.Dispatch(firing)
+	d.Dispatch(firing)
+	if len(fake.titles) != 1 {
+		t.Fatalf("expected one notification while firing, got %d", len(fake.titles))
+	}
+	if fake.titles[0] != "Mole: Battery low" || fake.bodies[0] != "Battery at 8%" {
+		t.Fatalf("unexpected notification %q / %q", fake.titles[0], fake.bodies[0])
+	}
+
+	d.Dispatch(clear)
+	d.Dispatch(firing)
+	if len(fake.titles) != 2 {
+		t.Fatalf("expected re-notification after clearing, got %d", len(fake.titles))
+	}
+}
+
+func TestBatteryLowNotifiesOncePerCrossing(t *testing.T) {
+	prev := maps.Clone(alertThresholds)
+	t.Cleanup(func() { alertThresholds = prev })
+	if err := setBatteryLowAlert("20"); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeNotifier{}
+	d := newAlertDispatcher(fake)
+	observe := func(pct float64, state BatteryState) {
+		d.Observe(MetricsSnapshot{Batteries: []BatteryStatus{{Name: "BAT0", Percent: pct, State: state}}})
+	}
+	for _, pct := range []float64{30, 21, 19, 15, 12} {
+		observe(pct, BatteryStateDischarging)
+	}
+	if len(fake.bodies) != 1 || fake.bodies[0] != "Battery at 19%" {
+		t.Fatalf("expected one notification at the crossing, got %q", fake.bodies)
+	}
+
+	// Plugged in but held below full: not discharging, so no alert, and the
+	// next drop below the threshold is a new crossing.
+	observe(12, BatteryStateBypass)
+	observe(11, BatteryStateDischarging)
+	if len(fake.bodies) != 2 || fake.bodies[1] != "Battery at 11%" {
+		t.Fatalf("expected a second notification after the bypass, got %q", fake.bodies)
+	}
+
+	if err := setBatteryLowAlert("0"); err != nil {
+		t.Fatal(err)
+	}
+	observe(30, BatteryStateDischarging)
+	observe(1, BatteryStateDischarging)
+	if len(fake.bodies) != 2 {
+		t.Fatalf("--battery-low 0 should turn the alert off, got %q", fake.bodies)
+	}
+	for _, bad := range []string{"-1", "100", "low"} {
+		if setBatteryLowAlert(bad) == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestEvaluateAlerts(t *testing.T) {
+	m := MetricsSnapshot{
+		Batteries: []BatteryStatus{{Name: "BAT0", Percent: 7, Status: "Discharging"}},
+		Thermal:   ThermalStatus{CPUTemp: 91},
+		Disks:     []DiskStatus{{Mount: "/", UsedPercent: 95}, {Mount: "/data", UsedPercent: 40}},
+	}
+	got := make(map[string]bool)
+	for _, a := range evaluateAlerts(m) {
+		got[a.Name] = a.Firing
+	}
+	want := map[string]bool{"battery-low": true, "thermal-critical": true, "disk-full:/": true, "disk-full:/data": false}
+	for name, firing := range want {
+		if f, ok := got[name]; !ok || f != firing {
+			t.Errorf("alert %s firing=%v (present=%v), want %v", name, f, ok, firing)
+		}
+	}
+
+	m.Batteries[0].Status = "Charging"
+	for _, a := range evaluateAlerts(m) {
+		if a.Name == "battery-low" && a.Firing {
+			t.Fatal("charging battery must not fire battery-low")
+		}
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	if n, err := newNotifier(NotifierNone); err != nil || n != (nopNotifier{}) {
+		t.Fatalf("expected no-op notifier, got %v, %v", n, err)
+	}
+	if _, err := newNotifier("pager"); err == nil {
+		t.Fatal("expected error for unknown notifier")
+	}
+}
+
+func TestAlertHysteresisDeadband(t *testing.T) {
+	firingFor := func(e *alertEvaluator, m MetricsSnapshot, name string) bool {
+		for _, a := range e.Evaluate(m) {
+			if a.Name == name {
+				return a.Firing
+			}
+		}
+		t.Fatalf("alert %s missing", name)
+		return false
+	}
+	temp := func(c float64) MetricsSnapshot { return MetricsSnapshot{Thermal: ThermalStatus{CPUTemp: c}} }
+
+	e := newAlertEvaluator()
+	for _, c := range []float64{84, 82, 84.9, 81} {
+		if firingFor(e, temp(c), "thermal-critical") {
+			t.Fatalf("%v°C fired before reaching the trigger", c)
+		}
+	}
+	if !firingFor(e, temp(86), "thermal-critical") {
+		t.Fatal("86°C should fire")
+	}
+	for _, c := range []float64{84, 81, 84.9, 80.5, 83} {
+		if !firingFor(e, temp(c), "thermal-critical") {
+			t.Fatalf("%v°C in the deadband cleared a firing alert", c)
+		}
+	}
+	if firingFor(e, temp(80), "thermal-critical") {
+		t.Fatal("80°C should clear")
+	}
+	if firingFor(e, temp(84), "thermal-critical") {
+		t.Fatal("84°C re-fired after clearing")
+	}
+
+	battery := func(pct float64) MetricsSnapshot {
+		return MetricsSnapshot{Batteries: []BatteryStatus{{Name: "BAT0", Percent: pct, Status: "Discharging"}}}
+	}
+	if !firingFor(e, battery(9), "battery-low") {
+		t.Fatal("9% should fire battery-low")
+	}
+	for _, pct := range []float64{11, 14, 12} {
+		if !firingFor(e, battery(pct), "battery-low") {
+			t.Fatalf("%v%% in the deadband cleared battery-low", pct)
+		}
+	}
+	if firingFor(e, battery(15), "battery-low") {
+		t.Fatal("15% should clear battery-low")
+	}
+}
+
+func TestAlertThresholdEqualClearKeepsDirection(t *testing.T) {
+	saved := maps.Clone(alertThresholds)
+	t.Cleanup(func() { alertThresholds = saved })
+	if err := setAlertThreshold("battery-low=20:20"); err != nil {
+		t.Fatal(err)
+	}
+	rule := alertThresholds["battery-low"]
+	if !rule.Falling {
+		t.Fatalf("battery-low=20:20 lost its direction: %+v", rule)
+	}
+	firing := false
+	for _, step := range []struct {
+		percent float64
+		want    bool
+	}{{50, false}, {20, true}, {20, true}, {19, true}, {21, false}} {
+		if firing = rule.next(firing, step.percent); firing != step.want {
+			t.Fatalf("at %v%%: firing = %v, want %v", step.percent, firing, step.want)
+		}
+	}
+
+	if err := setAlertThreshold("thermal-critical=90:90"); err != nil {
+		t.Fatal(err)
+	}
+	if rule := alertThresholds["thermal-critical"]; rule.Falling || !rule.next(false, 92) || rule.next(true, 89) {
+		t.Fatalf("thermal-critical=90:90 = %+v", rule)
+	}
+}
+
+func TestSetAlertThreshold(t *testing.T) {
+	saved := maps.Clone(alertThresholds)
+	t.Cleanup(func() { alertThresholds = saved })
+
+	if err := setAlertThreshold("thermal-critical=90:85"); err != nil {
+		t.Fatal(err)
+	}
+	if got := alertThresholds["thermal-critical"]; got != (AlertThreshold{Trigger: 90, Clear: 85}) {
+		t.Fatalf("thermal-critical = %+v", got)
+	}
+	if err := setAlertThreshold("battery-low=20"); err != nil {
+		t.Fatal(err)
+	}
+	if got := alertThresholds["battery-low"]; got != (AlertThreshold{Trigger: 20, Clear: 25, Falling: true}) {
+		t.Fatalf("battery-low default clear = %+v", got)
+	}
+	for _, bad := range []string{"thermal-critical=90:95", "battery-low=20:10", "fan-loud=3000", "disk-full=x", "disk-full"} {
+		if err := setAlertThreshold(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
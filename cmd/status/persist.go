@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// stateFiles writes the state kept between runs (battery history, calibration) to
+// the user cache dir. After the first failure, e.g. a read-only home or a locked-down
+// container, further writes are skipped: the state then lives in memory for the
+// rest of the process, and collection carries on unaffected.
+type stateFiles struct {
+	mu       sync.Mutex
+	disabled bool
+}
+
+var persisted stateFiles
+
+// write replaces path with data through a temp file and rename, so a crash never
+// leaves a half-written file. Only the failure that disables persistence is returned.
+func (s *stateFiles) write(path string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path == "" || s.disabled {
+		return nil
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		s.disabled = true
+		debugf("cannot write %s, keeping state in memory only: %v", path, err)
+		return err
+	}
+	return nil
+}
+
+func (s *stateFiles) reset() {
+	s.mu.Lock()
+	s.disabled = false
+	s.mu.Unlock()
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
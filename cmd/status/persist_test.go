@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUnwritableCacheDirKeepsStateInMemory(t *testing.T) {
+	t.Cleanup(persisted.reset)
+	// A regular file where the cache dir should be fails like a read-only home,
+	// even when the tests run as root.
+	root := t.TempDir()
+	blocker := filepath.Join(root, "cache")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	historyPath := filepath.Join(blocker, "mole", "battery_history.json")
+	calibPath := filepath.Join(blocker, "mole", "calibration.json")
+	batts := []BatteryStatus{{Name: "BAT0", CycleCount: 12}}
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if err := saveBatteryHistory(historyPath, batts, now); err == nil {
+		t.Fatal("first write into an unwritable dir should report the failure")
+	}
+	if !persisted.disabled {
+		t.Fatal("persistence should be disabled after a failed write")
+	}
+	// Later writes are skipped quietly instead of failing every time.
+	if err := saveBatteryHistory(historyPath, batts, now); err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+	if err := saveCalibration(calibPath, calibration{Samples: 40}); err != nil {
+		t.Fatalf("calibration write after failure: %v", err)
+	}
+	if h := loadBatteryHistory(historyPath); len(h.Batteries) != 0 {
+		t.Fatalf("nothing should have been written, loaded %+v", h)
+	}
+
+	persisted.reset()
+	ok := filepath.Join(root, "writable", "battery_history.json")
+	if err := saveBatteryHistory(ok, batts, now); err != nil {
+		t.Fatalf("after reset: %v", err)
+	}
+	if h := loadBatteryHistory(ok); len(h.Batteries) != 1 {
+		t.Fatalf("loaded %+v", h)
+	}
+}
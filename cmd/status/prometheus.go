@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// prometheusPrefix namespaces every exported metric name.
+const prometheusPrefix = "mole_"
+
+// prometheusContentType is the text exposition format, version 0.0.4.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusScrapeTimeout bounds a scrape when Prometheus doesn't say how long it waits.
+const prometheusScrapeTimeout = 10 * time.Second
+
+// formatPrometheus renders samples in the Prometheus text format. Samples sharing
+// a name are grouped under one HELP/TYPE header, in first-seen order.
+func formatPrometheus(samples []metricSample) string {
+	var names []string
+	byName := make(map[string][]metricSample)
+	for _, s := range samples {
+		if _, seen := byName[s.Name]; !seen {
+			names = append(names, s.Name)
+		}
+		byName[s.Name] = append(byName[s.Name], s)
+	}
+
+	var b strings.Builder
+	for _, name := range names {
+		group := byName[name]
+		full := prometheusPrefix + sanitizePrometheusName(name)
+		b.WriteString("# HELP " + full + " " + escapePrometheusHelp(group[0].Help) + "\n")
+		b.WriteString("# TYPE " + full + " gauge\n")
+		for _, s := range group {
+			b.WriteString(full)
+			if len(s.Labels) > 0 {
+				b.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					b.WriteString(sanitizePrometheusName(l.Key) + `="` + escapePrometheusLabel(l.Value) + `"`)
+				}
+				b.WriteByte('}')
+			}
+			b.WriteString(" " + strconv.FormatFloat(s.Value, 'g', -1, 64) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// sanitizePrometheusName maps characters outside [a-zA-Z0-9_] to underscores.
+func sanitizePrometheusName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+var (
+	prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	prometheusHelpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapePrometheusLabel(s string) string { return prometheusLabelEscaper.Replace(s) }
+func escapePrometheusHelp(s string) string  { return prometheusHelpEscaper.Replace(s) }
+
+// promExporter collects on every scrape rather than in a background loop, so the
+// numbers are as fresh as the scrape and nothing runs while nobody is scraping.
+// system_profiler output comes from its TTL cache, so frequent scrapes don't
+// re-run it. Scrapes are serialized: the collector keeps rate baselines.
+type promExporter struct {
+	mu        sync.Mutex
+	collector *Collector
+	primed    bool
+}
+
+func (p *promExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), prometheusTimeout(r.Header))
+	defer cancel()
+
+	p.mu.Lock()
+	if !p.primed {
+		p.collector.Prime(ctx, primeInterval)
+		p.primed = true
+	}
+	// Partial failures still yield useful gauges.
+	data, _ := p.collector.Collect(ctx)
+	p.mu.Unlock()
+	if ctx.Err() != nil {
+		http.Error(w, "scrape timed out", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", prometheusContentType)
+	_, _ = w.Write([]byte(formatPrometheus(snapshotMetrics(data))))
+}
+
+// prometheusTimeout is the scrape's own deadline, from the header Prometheus
+// sends, less a little headroom for the response.
+func prometheusTimeout(h http.Header) time.Duration {
+	secs, err := strconv.ParseFloat(h.Get("X-Prometheus-Scrape-Timeout-Seconds"), 64)
+	if err != nil || secs <= 0 {
+		return prometheusScrapeTimeout
+	}
+	return max(time.Duration(secs*float64(time.Second))-250*time.Millisecond, time.Second)
+}
+
+// runPrometheus serves metrics on http://addr/metrics until ctx is done.
+func runPrometheus(ctx context.Context, addr string) error {
+	collectSensorReadings = true
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", &promExporter{collector: NewCollector()})
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
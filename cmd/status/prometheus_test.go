@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatPrometheus(t *testing.T) {
+	m := MetricsSnapshot{
+		Batteries: []BatteryStatus{
+			{Name: "InternalBattery-0", Percent: 72, CycleCount: 412},
+			{Name: "InternalBattery-1", Percent: 40.5, CycleCount: 88},
+		},
+		Thermal: ThermalStatus{CPUTemp: 54.5, FanSpeed: 2100},
+		Sensors: []SensorReading{{Key: "coretemp_core0", Label: `Core "0"`, Value: 61, Unit: "°C"}},
+	}
+	got := formatPrometheus(snapshotMetrics(m))
+
+	for _, want := range []string{
+		"# HELP mole_battery_percent Battery charge level in percent.\n# TYPE mole_battery_percent gauge\n" +
+			"mole_battery_percent{battery=\"0\"} 72\nmole_battery_percent{battery=\"1\"} 40.5\n",
+		`mole_battery_cycle_count{battery="1"} 88`,
+		"mole_cpu_temperature_celsius 54.5\n",
+		"mole_fan_speed_rpm 2100\n",
+		`mole_sensor_temperature_celsius{sensor="coretemp_core0",label="Core \"0\""} 61`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "# TYPE mole_battery_percent "); n != 1 {
+		t.Errorf("expected one TYPE line per metric, got %d", n)
+	}
+}
+
+func TestPromExporterCollectsPerScrape(t *testing.T) {
+	exporter := &promExporter{collector: NewCollector()}
+	srv := httptest.NewServer(exporter)
+	defer srv.Close()
+
+	for _, temp := range []float64{48, 52} {
+		exporter.mu.Lock()
+		exporter.collector.replay = &MetricsSnapshot{Thermal: ThermalStatus{CPUTemp: temp}}
+		exporter.mu.Unlock()
+
+		resp, err := http.Get(srv.URL + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+			t.Fatalf("Content-Type = %q", ct)
+		}
+		if want := "mole_cpu_temperature_celsius " + strconv.FormatFloat(temp, 'g', -1, 64) + "\n"; !strings.Contains(string(body), want) {
+			t.Fatalf("scrape with CPU at %v: missing %q in:\n%s", temp, want, body)
+		}
+	}
+}
+
+func TestPrometheusTimeout(t *testing.T) {
+	h := http.Header{}
+	if got := prometheusTimeout(h); got != prometheusScrapeTimeout {
+		t.Fatalf("no header: %v", got)
+	}
+	h.Set("X-Prometheus-Scrape-Timeout-Seconds", "5")
+	if got := prometheusTimeout(h); got != 4750*time.Millisecond {
+		t.Fatalf("5s scrape: %v", got)
+	}
+}
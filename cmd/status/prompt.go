@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PromptSegment selects one piece of the compact prompt line.
+type PromptSegment string
+
+const (
+	PromptBattery PromptSegment = "battery"
+	PromptTemp    PromptSegment = "temp"
+	PromptCPU     PromptSegment = "cpu"
+	PromptMemory  PromptSegment = "mem"
+	PromptSensors PromptSegment = "sensors"
+)
+
+// GlyphStyle controls which symbols prefix each prompt segment.
+type GlyphStyle string
+
+const (
+	GlyphEmoji GlyphStyle = "emoji"
+	GlyphNerd  GlyphStyle = "nerd"
+	GlyphASCII GlyphStyle = "ascii"
+)
+
+var defaultPromptSegments = []PromptSegment{PromptBattery, PromptTemp}
+
+// promptCollectors is the one collector each segment reads from.
+var promptCollectors = map[PromptSegment]CollectorKind{
+	PromptBattery: CollectBattery,
+	PromptTemp:    CollectThermal,
+	PromptCPU:     CollectCPU,
+	PromptMemory:  CollectMemory,
+	PromptSensors: CollectSensors,
+}
+
+var promptGlyphs = map[GlyphStyle]map[PromptSegment]string{
+	GlyphEmoji: {PromptBattery: "🔋", PromptTemp: "🌡", PromptCPU: "⚙", PromptMemory: "🧠", PromptSensors: "📟"},
+	GlyphNerd:  {PromptBattery: "\uf240 ", PromptTemp: "\uf2c9 ", PromptCPU: "\uf2db ", PromptMemory: "\uf538 ", PromptSensors: "\uf2c8 "},
+	GlyphASCII: {PromptBattery: "BAT ", PromptTemp: "T ", PromptCPU: "CPU ", PromptMemory: "MEM ", PromptSensors: "S "},
+}
+
+// PromptOptions configures PromptLine.
+type PromptOptions struct {
+	Segments []PromptSegment // Defaults to battery and temp
+	Glyphs   GlyphStyle      // Defaults to emoji
+	TempUnit TempUnit        // Defaults to Celsius
+}
+
+// PromptLine renders an ultra-compact single-line status for shell prompts and tmux.
+// Segments without data are omitted, and the result never contains newlines.
+func PromptLine(m MetricsSnapshot, opts PromptOptions) string {
+	segments := opts.Segments
+	if len(segments) == 0 {
+		segments = defaultPromptSegments
+	}
+	glyphs, ok := promptGlyphs[opts.Glyphs]
+	if !ok {
+		glyphs = promptGlyphs[GlyphEmoji]
+	}
+	ascii := opts.Glyphs == GlyphASCII
+
+	var parts []string
+	for _, seg := range segments {
+		var value string
+		switch seg {
+		case PromptBattery:
+			b, ok := m.PrimaryBattery()
+			if !ok {
+				continue
+			}
+			value = fmt.Sprintf("%.0f%%", b.Percent)
+			if strings.EqualFold(b.Status, "charging") {
+				if ascii {
+					value += "+"
+				} else {
+					value += "⚡"
+				}
+			}
+		case PromptTemp:
+			if m.Thermal.CPUTemp <= 0 {
+				continue
+			}
+			temp := opts.TempUnit.Convert(m.Thermal.CPUTemp)
+			if ascii {
+				value = fmt.Sprintf("%.0f%s", temp, opts.TempUnit.letter())
+			} else {
+				value = fmt.Sprintf("%.0f°", temp)
+			}
+		case PromptCPU:
+			value = fmt.Sprintf("%.0f%%", m.CPU.Usage)
+		case PromptMemory:
+			if m.Memory.Total == 0 {
+				continue
+			}
+			value = fmt.Sprintf("%.0f%%", m.Memory.UsedPercent)
+		case PromptSensors:
+			// Sensor count and hottest reading, with "!" once any is at warn level.
+			r := m.SensorRollup
+			if r.Total == 0 {
+				continue
+			}
+			value = fmt.Sprintf("%d", r.Total)
+			if r.Hottest.Value > 0 {
+				hottest := opts.TempUnit.Convert(r.Hottest.Value)
+				if ascii {
+					value += fmt.Sprintf("/%.0f%s", hottest, opts.TempUnit.letter())
+				} else {
+					value += fmt.Sprintf("/%.0f°", hottest)
+				}
+			}
+			if !r.Nominal() {
+				value += "!"
+			}
+		default:
+			continue
+		}
+		parts = append(parts, glyphs[seg]+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// parsePromptSegments parses a comma-separated segment list such as "battery,temp".
+func parsePromptSegments(raw string) ([]PromptSegment, error) {
+	var segments []PromptSegment
+	for name := range strings.SplitSeq(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		seg := PromptSegment(name)
+		switch seg {
+		case PromptBattery, PromptTemp, PromptCPU, PromptMemory, PromptSensors:
+			segments = append(segments, seg)
+		default:
+			return nil, fmt.Errorf("unknown prompt segment %q", name)
+		}
+	}
+	return segments, nil
+}
+
+// runPrompt collects a single snapshot and prints its prompt line.
+func runPrompt(ctx context.Context, segmentsRaw, glyphs string) error {
+	segments, err := parsePromptSegments(segmentsRaw)
+	if err != nil {
+		return err
+	}
+	style := GlyphStyle(strings.ToLower(strings.TrimSpace(glyphs)))
+	if _, ok := promptGlyphs[style]; !ok {
+		return fmt.Errorf("unknown glyph style %q", glyphs)
+	}
+
+	// Only run the collectors the segments need; partial collection errors are
+	// fine here, missing segments are simply omitted.
+	collector := NewCollector()
+	for _, kind := range collectorKinds {
+		collector.SetEnabled(kind, false)
+	}
+	if len(segments) == 0 {
+		segments = defaultPromptSegments
+	}
+	for _, seg := range segments {
+		collector.SetEnabled(promptCollectors[seg], true)
+		if seg == PromptSensors {
+			collectSensorReadings = true
+		}
+	}
+	data, _ := collector.Collect(ctx)
+	fmt.Println(PromptLine(data, PromptOptions{Segments: segments, Glyphs: style, TempUnit: displayTempUnit}))
+	return nil
+}
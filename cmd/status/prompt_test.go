@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptLineDefaultSegments(t *testing.T) {
+	m := MetricsSnapshot{
+		Batteries: []BatteryStatus{{Percent: 72, Status: "discharging"}},
+		Thermal:   ThermalStatus{CPUTemp: 54.4},
+	}
+	got := PromptLine(m, PromptOptions{})
+	if got != "🔋72% 🌡54°" {
+		t.Fatalf("unexpected prompt line %q", got)
+	}
+}
+
+func TestPromptLineASCIIAndCharging(t *testing.T) {
+	m := MetricsSnapshot{
+		Batteries: []BatteryStatus{{Percent: 40, Status: "charging"}},
+		Thermal:   ThermalStatus{CPUTemp: 61},
+		CPU:       CPUStatus{Usage: 12.3},
+	}
+	got := PromptLine(m, PromptOptions{
+		Segments: []PromptSegment{PromptCPU, PromptBattery, PromptTemp},
+		Glyphs:   GlyphASCII,
+	})
+	if got != "CPU 12% BAT 40%+ T 61C" {
+		t.Fatalf("unexpected prompt line %q", got)
+	}
+}
+
+func TestPromptLineFahrenheit(t *testing.T) {
+	m := MetricsSnapshot{Thermal: ThermalStatus{CPUTemp: 100}}
+	opts := PromptOptions{Segments: []PromptSegment{PromptTemp}, Glyphs: GlyphASCII, TempUnit: TempFahrenheit}
+	if got := PromptLine(m, opts); got != "T 212F" {
+		t.Fatalf("unexpected prompt line %q", got)
+	}
+}
+
+func TestPromptLineSensorsFahrenheit(t *testing.T) {
+	m := MetricsSnapshot{
+		Thermal:      ThermalStatus{CPUTemp: 100},
+		SensorRollup: SensorRollup{Total: 3, Hottest: SensorReading{Label: "CPU Die", Value: 100, Unit: "°C"}},
+	}
+	opts := PromptOptions{Segments: []PromptSegment{PromptTemp, PromptSensors}, Glyphs: GlyphASCII, TempUnit: TempFahrenheit}
+	got := PromptLine(m, opts)
+	if !strings.HasPrefix(got, "T 212F ") || !strings.Contains(got, "3/212F") {
+		t.Fatalf("prompt line mixes units: %q", got)
+	}
+}
+
+func TestPromptLineSkipsMissingData(t *testing.T) {
+	got := PromptLine(MetricsSnapshot{}, PromptOptions{})
+	if got != "" {
+		t.Fatalf("expected empty prompt line without data, got %q", got)
+	}
+	if strings.Contains(PromptLine(MetricsSnapshot{CPU: CPUStatus{Usage: 5}}, PromptOptions{Segments: []PromptSegment{PromptCPU}}), "\n") {
+		t.Fatalf("prompt line must not wrap")
+	}
+}
+
+func TestParsePromptSegments(t *testing.T) {
+	got, err := parsePromptSegments(" battery, TEMP ,,mem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 || got[0] != PromptBattery || got[1] != PromptTemp || got[2] != PromptMemory {
+		t.Fatalf("unexpected segments %v", got)
+	}
+	if _, err := parsePromptSegments("battery,gpu"); err == nil {
+		t.Fatalf("expected error for unknown segment")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoCPUTemp means no CPU temperature source produced a reading.
+var ErrNoCPUTemp = errors.New("cpu temperature unavailable")
+
+// BatteryPercent runs only the battery probe and returns the primary battery's charge.
+// It shares collectBatteries' parsing and the system_profiler cache with Collect.
+func BatteryPercent(ctx context.Context) (float64, error) {
+	return primaryBatteryPercent(collectBatteries(ctx))
+}
+
+func primaryBatteryPercent(batts []BatteryStatus, err error) (float64, error) {
+	if b, ok := primaryBattery(batts); ok {
+		return b.Percent, nil
+	}
+	if err == nil {
+		err = ErrNoBattery
+	}
+	return 0, err
+}
+
+// CPUTempC runs only the thermal probe and returns the CPU temperature in Celsius.
+func CPUTempC(ctx context.Context) (float64, error) {
+	return cpuTemp(collectThermal(ctx))
+}
+
+func cpuTemp(thermal ThermalStatus) (float64, error) {
+	if thermal.CPUTemp > 0 {
+		return thermal.CPUTemp, nil
+	}
+	return 0, ErrNoCPUTemp
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPrimaryBatteryPercent(t *testing.T) {
+	got, err := primaryBatteryPercent([]BatteryStatus{{Name: "hid-mouse", Percent: 20}, {Name: "BAT0", Percent: 64}}, nil)
+	if err != nil || got != 64 {
+		t.Fatalf("got %v, %v; want the internal battery", got, err)
+	}
+	if _, err := primaryBatteryPercent(nil, nil); !errors.Is(err, ErrNoBattery) {
+		t.Fatalf("expected ErrNoBattery, got %v", err)
+	}
+	if _, err := primaryBatteryPercent(nil, ErrBatteryPermission); !errors.Is(err, ErrBatteryUnreadable) {
+		t.Fatalf("expected the probe error to pass through, got %v", err)
+	}
+}
+
+func TestCPUTemp(t *testing.T) {
+	if got, err := cpuTemp(ThermalStatus{CPUTemp: 58.5}); err != nil || got != 58.5 {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if _, err := cpuTemp(ThermalStatus{}); !errors.Is(err, ErrNoCPUTemp) {
+		t.Fatalf("expected ErrNoCPUTemp, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Remote fleet settings; set from --remote and --remote-timeout.
+var (
+	remoteHosts   []string
+	remoteTimeout = 10 * time.Second
+)
+
+// errRemoteUnreachable means ssh itself failed (exit 255): the host is down, the
+// name doesn't resolve, or key authentication was refused.
+var errRemoteUnreachable = errors.New("ssh connection failed")
+
+// setRemoteHosts parses a comma-separated list of ssh destinations, e.g.
+// "mini.local,admin@build-01". Duplicates are dropped.
+func setRemoteHosts(raw string) error {
+	var hosts []string
+	for host := range strings.SplitSeq(raw, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if strings.HasPrefix(host, "-") {
+			return fmt.Errorf("invalid remote host %q", host)
+		}
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("remote host list is empty")
+	}
+	remoteHosts = hosts
+	return nil
+}
+
+// sshRunner has cmdRunner's shape but runs each command on host through the local
+// ssh client. BatchMode keeps ssh from prompting for a password on a headless run.
+// With controlPath set, the first command opens a master connection there and the
+// rest reuse it, so a Collect's dozen commands cost one handshake.
+type sshRunner struct {
+	host        string
+	timeout     time.Duration
+	controlPath string
+	exec        commandRunner
+}
+
+func (r sshRunner) options() []string {
+	connect := max(int(r.timeout/time.Second), 1)
+	opts := []string{
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=" + strconv.Itoa(connect),
+	}
+	if r.controlPath != "" {
+		opts = append(opts,
+			"-o", "ControlMaster=auto",
+			"-o", "ControlPath="+r.controlPath,
+			"-o", "ControlPersist="+strconv.Itoa(max(connect*2, remoteControlPersist)),
+		)
+	}
+	return opts
+}
+
+func (r sshRunner) run(ctx context.Context, env []string, name string, args ...string) (string, error) {
+	sshArgs := append(r.options(), r.host, "--", remoteCommandLine(env, name, args))
+	out, err := r.exec(ctx, nil, "ssh", sshArgs...)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 255 {
+		return "", fmt.Errorf("%s: %w", r.host, errRemoteUnreachable)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %s: %w", r.host, name, err)
+	}
+	return out, nil
+}
+
+// close shuts the master connection down rather than leaving it to ControlPersist.
+func (r sshRunner) close(ctx context.Context) {
+	if r.controlPath == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, _ = r.exec(ctx, nil, "ssh", "-o", "ControlPath="+r.controlPath, "-O", "exit", r.host)
+}
+
+// remoteControlPersist is how many seconds, at least, an idle master connection
+// outlives its last command; close normally shuts it down sooner.
+const remoteControlPersist = 30
+
+// remoteCommandLine joins a command into one line for the remote shell. Every word
+// is quoted, so arguments reach the remote tool exactly as the local parsers expect.
+func remoteCommandLine(env []string, name string, args []string) string {
+	words := make([]string, 0, len(env)+len(args)+2)
+	if len(env) > 0 {
+		words = append(words, "env")
+		for _, kv := range env {
+			words = append(words, shellQuote(kv))
+		}
+	}
+	words = append(words, shellQuote(name))
+	for _, a := range args {
+		words = append(words, shellQuote(a))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote wraps s in single quotes for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// RemoteReport is what one remote probe found. Err is set when the host couldn't
+// be reached or identified; missing tools only leave their fields empty.
+type RemoteReport struct {
+	Host        string
+	OS          string // uname -s, e.g. Darwin or Linux
+	CPUTemp     float64
+	BatteryTemp float64
+	Batteries   []BatteryStatus
+	Err         error
+}
+
+// remoteGOOS maps uname -s to the GOOS whose probes can read that host.
+var remoteGOOS = map[string]string{"Darwin": "darwin", "Linux": "linux"}
+
+// remoteBatteryChain is the battery chain for remote hosts: the command-based
+// sources only, since IOKit, sysfs and /proc belong to this machine.
+var remoteBatteryChain = []string{"pmset", "upower"}
+
+// remoteCollectors are what a remote Collect runs. The others read this machine
+// through gopsutil or would only repeat work the report doesn't show.
+var remoteCollectors = []CollectorKind{CollectBattery, CollectThermal, CollectSensors}
+
+// remoteMu serialises remote Collects: useRemoteProbes swaps process-wide state.
+var remoteMu sync.Mutex
+
+// useRemoteProbes points the probes at host, running goos, with run as the
+// command runner, and returns a function that puts this machine back. Caches are
+// cleared on both sides and commands are shared per host, so no reading crosses
+// between hosts. Caller holds remoteMu.
+func useRemoteProbes(host, goos string, run commandRunner) (restore func()) {
+	prevRunner, prevHost, prevOS, prevLocal := cmdRunner, cmdHost, probeOS, localProbes
+	prevChain, prevSensors := batteryChain, collectSensorReadings
+	ResetState()
+	cmdRunner, cmdHost, probeOS, localProbes = run, host, goos, false
+	batteryChain, collectSensorReadings = remoteBatteryChain, true
+	return func() {
+		// Runs left behind by a timed-out Collect finish before the swap back.
+		waitCommands()
+		cmdRunner, cmdHost, probeOS, localProbes = prevRunner, prevHost, prevOS, prevLocal
+		batteryChain, collectSensorReadings = prevChain, prevSensors
+		ResetState()
+	}
+}
+
+// remoteOS identifies host with uname -s; the first command also opens the
+// ssh master connection.
+func remoteOS(ctx context.Context, host string, run commandRunner) (string, error) {
+	out, err := run(ctx, nil, "uname", "-s")
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(out)
+	if _, ok := remoteGOOS[name]; !ok {
+		return name, fmt.Errorf("%s: unsupported remote OS %q", host, name)
+	}
+	return name, nil
+}
+
+// collectRemoteOS runs a Collector against host, already identified as osName,
+// with every command going through run. Only command-based probes can reach it,
+// so Linux hosts report temperatures through lm-sensors and batteries through
+// UPower.
+func collectRemoteOS(ctx context.Context, host, osName string, run commandRunner) RemoteReport {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	restore := useRemoteProbes(host, remoteGOOS[osName], run)
+	defer restore()
+
+	c := NewCollector()
+	for _, kind := range collectorKinds {
+		c.SetEnabled(kind, slices.Contains(remoteCollectors, kind))
+	}
+	m, _ := c.Collect(ctx)
+	cpuTemp := m.Thermal.CPUTemp
+	if m.Thermal.Estimated {
+		cpuTemp = 0 // A battery or thermal-level stand-in, not a die reading
+	}
+	return RemoteReport{
+		Host:        host,
+		OS:          osName,
+		CPUTemp:     cpuTemp,
+		BatteryTemp: m.Thermal.BatteryTemp,
+		Batteries:   m.Batteries,
+	}
+}
+
+// collectRemote identifies host and collects from it through run, which is
+// usually an sshRunner.
+func collectRemote(ctx context.Context, host string, run commandRunner) RemoteReport {
+	osName, err := remoteOS(ctx, host, run)
+	if err != nil {
+		return RemoteReport{Host: host, OS: osName, Err: err}
+	}
+	return collectRemoteOS(ctx, host, osName, run)
+}
+
+// String renders one report line, e.g. "mini.local  Darwin  battery 31.2°C  72% discharging".
+func (r RemoteReport) String() string {
+	if r.Err != nil {
+		return r.Host + "  error: " + r.Err.Error()
+	}
+	parts := []string{r.Host, r.OS}
+	if r.CPUTemp > 0 {
+		parts = append(parts, fmt.Sprintf("cpu %.1f°C", r.CPUTemp))
+	}
+	if r.BatteryTemp > 0 {
+		parts = append(parts, fmt.Sprintf("battery %.1f°C", r.BatteryTemp))
+	}
+	if b, ok := primaryBattery(r.Batteries); ok {
+		parts = append(parts, fmt.Sprintf("%.0f%% %s", b.Percent, b.Status))
+	}
+	if len(parts) == 2 {
+		parts = append(parts, "no readings")
+	}
+	return strings.Join(parts, "  ")
+}
+
+// runRemote connects to every host in parallel, then collects from them one at a
+// time over the open master connections, each step bounded by timeout. It prints
+// one line per host in the order given and reports whether every host answered.
+func runRemote(ctx context.Context, w io.Writer, hosts []string, timeout time.Duration) bool {
+	// Masters live in a private directory; ssh's socket paths must stay short.
+	controlDir, err := os.MkdirTemp("/tmp", "mole-ssh-")
+	if err != nil {
+		controlDir = ""
+	} else {
+		defer os.RemoveAll(controlDir)
+	}
+	local := cmdRunner
+	runners := make([]sshRunner, len(hosts))
+	reports := make([]RemoteReport, len(hosts))
+	withTimeout := func(i int, probe func(ctx context.Context)) {
+		hostCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		probe(hostCtx)
+		if errors.Is(hostCtx.Err(), context.DeadlineExceeded) {
+			reports[i].Err = fmt.Errorf("%s: timed out after %v", hosts[i], timeout)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		runners[i] = sshRunner{host: host, timeout: timeout, exec: local}
+		if controlDir != "" {
+			runners[i].controlPath = filepath.Join(controlDir, strconv.Itoa(i))
+		}
+		reports[i].Host = host
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			withTimeout(i, func(ctx context.Context) {
+				reports[i].OS, reports[i].Err = remoteOS(ctx, host, runners[i].run)
+			})
+		}()
+	}
+	wg.Wait()
+	for i, host := range hosts {
+		if reports[i].Err == nil {
+			withTimeout(i, func(ctx context.Context) {
+				reports[i] = collectRemoteOS(ctx, host, reports[i].OS, runners[i].run)
+			})
+		}
+		runners[i].close(ctx)
+	}
+
+	ok := true
+	for _, r := range reports {
+		fmt.Fprintln(w, r)
+		ok = ok && r.Err == nil
+	}
+	return ok
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRemoteCommandLineQuotes(t *testing.T) {
+	got := remoteCommandLine(englishLocaleEnv, "upower", []string{"-i", "/org/freedesktop/UPower/devices/battery_BAT0", "it's"})
+	want := `env LANG=C LC_ALL=C upower -i /org/freedesktop/UPower/devices/battery_BAT0 'it'\''s'`
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := shellQuote(""); got != "''" {
+		t.Fatalf("empty word quoted as %q", got)
+	}
+	if got := shellQuote("a;rm -rf ~"); got != "'a;rm -rf ~'" {
+		t.Fatalf("metacharacters not quoted: %q", got)
+	}
+}
+
+func TestSetRemoteHosts(t *testing.T) {
+	prev := remoteHosts
+	t.Cleanup(func() { remoteHosts = prev })
+
+	if err := setRemoteHosts(" mini.local, admin@build-01 ,mini.local,"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(remoteHosts, ",") != "mini.local,admin@build-01" {
+		t.Fatalf("hosts = %v", remoteHosts)
+	}
+	if err := setRemoteHosts("-oProxyCommand=x"); err == nil {
+		t.Fatal("expected an option-looking host to be rejected")
+	}
+	if err := setRemoteHosts(" , "); err == nil {
+		t.Fatal("expected an empty list to be rejected")
+	}
+}
+
+// fakeRemote answers remote command lines from a table, as ssh would hand them back.
+func fakeRemote(t *testing.T, outputs map[string]string) commandRunner {
+	return func(_ context.Context, _ []string, name string, args ...string) (string, error) {
+		if name != "ssh" || len(args) < 2 || args[len(args)-2] != "--" {
+			t.Fatalf("unexpected local command %s %v", name, args)
+		}
+		out, ok := outputs[args[len(args)-1]]
+		if !ok {
+			return "", errors.New("exit status 127")
+		}
+		return out, nil
+	}
+}
+
+func TestCollectRemoteDarwin(t *testing.T) {
+	runner := sshRunner{host: "mini.local", timeout: 5 * time.Second, exec: fakeRemote(t, map[string]string{
+		"uname -s":                    "Darwin\n",
+		"pmset -g batt":               "Now drawing from 'Battery Power'\n -InternalBattery-0 (id=123)\t72%; discharging; 3:10 remaining present: true\n",
+		"ioreg -rn AppleSmartBattery": "    \"Temperature\" = 3120\n",
+	})}
+	r := collectRemote(context.Background(), runner.host, runner.run)
+	if r.Err != nil || r.OS != "Darwin" {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	if len(r.Batteries) != 1 || r.Batteries[0].Percent != 72 || r.BatteryTemp != 31.2 {
+		t.Fatalf("unexpected readings %+v", r)
+	}
+	if got := r.String(); got != "mini.local  Darwin  battery 31.2°C  72% discharging" {
+		t.Fatalf("line = %q", got)
+	}
+}
+
+func TestCollectRemoteLinuxWithoutTools(t *testing.T) {
+	runner := sshRunner{host: "build-01", timeout: 5 * time.Second, exec: fakeRemote(t, map[string]string{
+		"uname -s":   "Linux\n",
+		"sensors -j": `{"coretemp-isa-0000": {"Adapter": "ISA adapter", "Package id 0": {"temp1_input": 61.0}, "Core 0": {"temp2_input": 55.0}}}`,
+	})}
+	r := collectRemote(context.Background(), runner.host, runner.run)
+	if r.Err != nil {
+		t.Fatalf("a missing upower should not fail the host: %v", r.Err)
+	}
+	if r.CPUTemp != 61 || len(r.Batteries) != 0 {
+		t.Fatalf("unexpected readings %+v", r)
+	}
+}
+
+func TestSSHRunnerReportsUnreachableHost(t *testing.T) {
+	// ssh exits 255 when it can't connect; sh stands in for it here.
+	exit255 := exec.Command("sh", "-c", "exit 255").Run()
+	if exit255 == nil {
+		t.Skip("sh not available")
+	}
+	runner := sshRunner{host: "gone.local", timeout: time.Second, exec: func(context.Context, []string, string, ...string) (string, error) {
+		return "", exit255
+	}}
+	r := collectRemote(context.Background(), runner.host, runner.run)
+	if !errors.Is(r.Err, errRemoteUnreachable) {
+		t.Fatalf("err = %v, want errRemoteUnreachable", r.Err)
+	}
+	if got := r.String(); got != "gone.local  error: gone.local: ssh connection failed" {
+		t.Fatalf("line = %q", got)
+	}
+}
+
+func TestRunRemoteTimesOutPerHost(t *testing.T) {
+	prev := cmdRunner
+	t.Cleanup(func() { cmdRunner = prev })
+	cmdRunner = func(ctx context.Context, _ []string, _ string, args ...string) (string, error) {
+		if args[len(args)-3] == "slow" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "Linux\n", nil
+	}
+
+	var out strings.Builder
+	start := time.Now()
+	if runRemote(context.Background(), &out, []string{"slow", "fast"}, 50*time.Millisecond) {
+		t.Fatal("expected the slow host to fail the run")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatal("per-host timeout not applied")
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "slow: timed out") || lines[1] != "fast  Linux  no readings" {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestRunCmdDoesNotShareRunsBetweenHosts(t *testing.T) {
+	prevRunner, prevHost := cmdRunner, cmdHost
+	unblock := make(chan struct{})
+	t.Cleanup(func() { waitCommands(); cmdRunner, cmdHost = prevRunner, prevHost })
+
+	entered := make(chan struct{})
+	cmdRunner, cmdHost = func(context.Context, []string, string, ...string) (string, error) {
+		close(entered)
+		<-unblock
+		return "host a", nil
+	}, "a"
+	go func() { _, _ = runCmd(context.Background(), "pmset", "-g", "batt") }()
+	<-entered
+
+	// Host a's run is still going; the same command for host b runs on its own.
+	cmdRunner, cmdHost = func(context.Context, []string, string, ...string) (string, error) {
+		return "host b", nil
+	}, "b"
+	// A cancel rather than a deadline, so a call that wrongly joined returns.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer time.AfterFunc(time.Second, cancel).Stop()
+	out, err := runCmd(ctx, "pmset", "-g", "batt")
+	close(unblock)
+	if err != nil || out != "host b" {
+		t.Fatalf("host b got %q, %v", out, err)
+	}
+}
+
+func TestCollectRemoteRestoresLocalProbes(t *testing.T) {
+	runner := sshRunner{host: "build-01", timeout: 5 * time.Second, exec: fakeRemote(t, map[string]string{
+		"uname -s": "Linux\n",
+	})}
+	if r := collectRemote(context.Background(), runner.host, runner.run); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+	if probeOS != runtime.GOOS || !localProbes || batteryChain != nil {
+		t.Fatalf("local probes not restored: os %q, local %v, chain %v", probeOS, localProbes, batteryChain)
+	}
+}
+
+func TestSSHRunnerSharesMasterConnection(t *testing.T) {
+	var calls [][]string
+	runner := sshRunner{host: "mini.local", timeout: 5 * time.Second, controlPath: "/tmp/mole-ssh-1/0",
+		exec: func(_ context.Context, _ []string, _ string, args ...string) (string, error) {
+			calls = append(calls, args)
+			return "", nil
+		}}
+	if _, err := runner.run(context.Background(), nil, "uname", "-s"); err != nil {
+		t.Fatal(err)
+	}
+	runner.close(context.Background())
+	got := strings.Join(calls[0], " ")
+	for _, opt := range []string{"ControlMaster=auto", "ControlPath=/tmp/mole-ssh-1/0", "ControlPersist=30"} {
+		if !strings.Contains(got, "-o "+opt) {
+			t.Errorf("ssh args %q lack %s", got, opt)
+		}
+	}
+	if len(calls) != 2 || strings.Join(calls[1], " ") != "-o ControlPath=/tmp/mole-ssh-1/0 -O exit mini.local" {
+		t.Fatalf("close = %q, want the master told to exit", calls[1:])
+	}
+}
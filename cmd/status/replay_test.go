@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// replayCapture is one testdata/replay file: recorded command output plus the expected result.
+type replayCapture struct {
+	outputs map[string]string // Command line → stdout
+	want    string
+}
+
+// loadReplayCapture parses the "-- name --" section format described in testdata/replay/README.md.
+func loadReplayCapture(t *testing.T, path string) replayCapture {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	capture := replayCapture{outputs: make(map[string]string)}
+	var (
+		section string
+		body    strings.Builder
+	)
+	flush := func() {
+		switch section {
+		case "":
+			// Notes before the first section.
+		case "want.json":
+			capture.want = body.String()
+		default:
+			capture.outputs[section] = body.String()
+		}
+		body.Reset()
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "-- "); ok && strings.HasSuffix(name, " --") {
+			flush()
+			section = strings.TrimSuffix(name, " --")
+			continue
+		}
+		body.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	flush()
+	if capture.want == "" {
+		t.Fatalf("%s: missing want.json section", path)
+	}
+	return capture
+}
+
+// run replays recorded output; commands without a capture fail like a broken tool.
+func (c replayCapture) run(_ context.Context, _ []string, name string, args ...string) (string, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	if out, ok := c.outputs[line]; ok {
+		return out, nil
+	}
+	return "", fmt.Errorf("no capture for %q", line)
+}
+
+func TestReplayCaptures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "replay", "*.txt"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no replay captures found: %v", err)
+	}
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txt"), func(t *testing.T) {
+			capture := loadReplayCapture(t, path)
+			prev := cmdRunner
+			cmdRunner = capture.run
+			t.Cleanup(func() { cmdRunner = prev })
+
+			// system_profiler goes through runCmd directly: profilerCache is darwin-only.
+			power := func(ctx context.Context) []powerData {
+				out, _ := runCmdEnv(ctx, englishLocaleEnv, "system_profiler", spPowerDataType)
+				return parsePowerData(out)
+			}
+
+			type result struct {
+				Present   bool
+				Batteries []BatteryStatus
+			}
+			var got, want result
+			got.Batteries, got.Present = pmsetBatteries(context.Background(), power)
+			if err := json.Unmarshal([]byte(capture.want), &want); err != nil {
+				t.Fatalf("bad want.json: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				t.Fatalf("parsed result differs from want.json; got:\n%s", gotJSON)
+			}
+		})
+	}
+}
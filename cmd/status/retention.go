@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultHistorySamples is five minutes at the default 1s refresh.
+const defaultHistorySamples = 300
+
+// HistoryRetention bounds every in-memory sample history (network sparklines,
+// sensor trends, battery drain) by count and optionally by age, so memory stays
+// flat however long a session runs. Trend and sparkline resolution depend on it: a
+// sparkline can't be wider than the samples kept, and a trend judged over fewer
+// samples than its window reacts faster but noisier.
+type HistoryRetention struct {
+	Samples int           // Most samples any history keeps
+	MaxAge  time.Duration // Oldest sample kept; 0 means no age limit
+}
+
+// historyRetention is the active policy; set from --history-samples and --history-age.
+var historyRetention = HistoryRetention{Samples: defaultHistorySamples}
+
+// SetHistoryRetention replaces the policy. Fixed-size buffers such as the network
+// history are sized when a Collector is created; the others apply from the next sample.
+func SetHistoryRetention(samples int, maxAge time.Duration) error {
+	if samples < 1 {
+		return fmt.Errorf("history must keep at least 1 sample, got %d", samples)
+	}
+	if maxAge < 0 {
+		return fmt.Errorf("history age must not be negative, got %v", maxAge)
+	}
+	historyRetention = HistoryRetention{Samples: samples, MaxAge: maxAge}
+	return nil
+}
+
+// capacity is how many samples a history without timestamps may keep: Samples,
+// or fewer when MaxAge covers fewer refreshes than that.
+func (r HistoryRetention) capacity() int {
+	n := r.Samples
+	if r.MaxAge > 0 && refreshInterval > 0 {
+		n = min(n, int(r.MaxAge/refreshInterval))
+	}
+	return max(n, 1)
+}
+
+// window caps a timestamped history's own window at MaxAge.
+func (r HistoryRetention) window(own time.Duration) time.Duration {
+	if r.MaxAge > 0 {
+		return min(own, r.MaxAge)
+	}
+	return own
+}
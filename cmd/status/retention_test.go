@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryRetention(t *testing.T) {
+	old := historyRetention
+	t.Cleanup(func() { historyRetention = old })
+
+	if err := SetHistoryRetention(0, 0); err == nil {
+		t.Fatal("want an error for a zero sample count")
+	}
+	if err := SetHistoryRetention(10, -time.Second); err == nil {
+		t.Fatal("want an error for a negative age")
+	}
+	if err := SetHistoryRetention(300, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got := historyRetention.capacity(); got != int(time.Minute/refreshInterval) {
+		t.Fatalf("age should cap the sample count, got %d", got)
+	}
+
+	if err := SetHistoryRetention(4, 0); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCollector()
+	for i := range 10 {
+		c.rxHistoryBuf.Add(float64(i))
+	}
+	if got := c.rxHistoryBuf.Slice(); len(got) != 4 || got[0] != 6 {
+		t.Fatalf("network history should keep the newest 4 samples, got %v", got)
+	}
+
+	var d drainTracker
+	start := time.Now()
+	for i := range 10 {
+		batts := []BatteryStatus{{Name: "BAT0", Percent: 90 - float64(i), Status: "Discharging"}}
+		d.observe(batts, start.Add(time.Duration(i)*time.Second), 10, false)
+	}
+	if hist := d.samples["BAT0"]; len(hist) != 4 || hist[0].percent != 84 {
+		t.Fatalf("drain history should keep the newest 4 samples, got %+v", hist)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is one point of the battery and CPU temperature history.
+type Sample struct {
+	At             time.Time
+	BatteryPercent float64 // Primary battery; meaningless unless HasBattery
+	HasBattery     bool
+	CPUTemp        float64 // °C; 0 when unknown
+}
+
+// sampleOf picks the sampled fields out of a snapshot.
+func sampleOf(m MetricsSnapshot) Sample {
+	s := Sample{At: m.CollectedAt, CPUTemp: m.Thermal.CPUTemp}
+	if b, ok := m.PrimaryBattery(); ok {
+		s.BatteryPercent, s.HasBattery = b.Percent, true
+	}
+	return s
+}
+
+// Sampler keeps the last N samples in a fixed-size circular buffer, so memory
+// stays flat however long it runs. The collector adds while the renderer reads;
+// both are safe to call from any goroutine.
+type Sampler struct {
+	mu    sync.RWMutex
+	buf   []Sample
+	next  int // Insert position; the oldest sample once full
+	count int
+}
+
+// NewSampler returns a sampler holding at most capacity samples (at least 1).
+func NewSampler(capacity int) *Sampler {
+	return &Sampler{buf: make([]Sample, max(capacity, 1))}
+}
+
+// Add records s, overwriting the oldest sample once the buffer is full.
+func (s *Sampler) Add(sample Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf[s.next] = sample
+	s.next = (s.next + 1) % len(s.buf)
+	s.count = min(s.count+1, len(s.buf))
+}
+
+// Recent returns up to n of the newest samples, oldest first. The slice is a
+// copy; n <= 0 returns everything kept.
+func (s *Sampler) Recent(n int) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if n <= 0 || n > s.count {
+		n = s.count
+	}
+	out := make([]Sample, n)
+	start := s.next - n
+	if start < 0 {
+		start += len(s.buf)
+	}
+	copied := copy(out, s.buf[start:min(start+n, len(s.buf))])
+	copy(out[copied:], s.buf)
+	return out
+}
+
+// Len is how many samples are kept.
+func (s *Sampler) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.count
+}
+
+// batteryRate is the primary battery's change in %/h between the first and last
+// samples that have one: negative while draining. ok is false with fewer than two
+// such samples or no time between them.
+func batteryRate(samples []Sample) (perHour float64, ok bool) {
+	var first, last *Sample
+	for i := range samples {
+		if !samples[i].HasBattery {
+			continue
+		}
+		if first == nil {
+			first = &samples[i]
+		}
+		last = &samples[i]
+	}
+	if first == nil || first == last {
+		return 0, false
+	}
+	hours := last.At.Sub(first.At).Hours()
+	if hours <= 0 {
+		return 0, false
+	}
+	return (last.BatteryPercent - first.BatteryPercent) / hours, true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSamplerWrapsAtCapacity(t *testing.T) {
+	s := NewSampler(3)
+	base := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		s.Add(Sample{At: base.Add(time.Duration(i) * time.Second), BatteryPercent: float64(90 - i), HasBattery: true})
+	}
+	if s.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", s.Len())
+	}
+	got := s.Recent(0)
+	if len(got) != 3 || got[0].BatteryPercent != 88 || got[2].BatteryPercent != 86 {
+		t.Fatalf("Recent(0) = %+v, want 88, 87, 86", got)
+	}
+	if got := s.Recent(2); len(got) != 2 || got[0].BatteryPercent != 87 || got[1].BatteryPercent != 86 {
+		t.Fatalf("Recent(2) = %+v, want 87, 86", got)
+	}
+	if got := s.Recent(10); len(got) != 3 {
+		t.Fatalf("Recent(10) returned %d samples, want all 3", len(got))
+	}
+
+	// The result is a copy: later samples don't change it.
+	got[0].BatteryPercent = 0
+	if s.Recent(3)[0].BatteryPercent != 88 {
+		t.Fatal("Recent shares memory with the buffer")
+	}
+}
+
+func TestSamplerConcurrentAddRecent(t *testing.T) {
+	s := NewSampler(16)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range 1000 {
+			s.Add(Sample{CPUTemp: float64(i)})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 1000 {
+			recent := s.Recent(8)
+			for i := 1; i < len(recent); i++ {
+				if recent[i].CPUTemp <= recent[i-1].CPUTemp {
+					t.Errorf("samples out of order: %+v", recent)
+					return
+				}
+			}
+		}
+	}()
+	wg.Wait()
+	if s.Len() != 16 || s.Recent(1)[0].CPUTemp != 999 {
+		t.Fatalf("Len = %d, newest = %+v", s.Len(), s.Recent(1))
+	}
+}
+
+func TestBatteryRate(t *testing.T) {
+	base := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{At: base, CPUTemp: 50},
+		{At: base.Add(10 * time.Minute), BatteryPercent: 80, HasBattery: true},
+		{At: base.Add(40 * time.Minute), BatteryPercent: 78, HasBattery: true},
+	}
+	if rate, ok := batteryRate(samples); !ok || rate != -4 {
+		t.Fatalf("batteryRate = %v, %v; want -4 %%/h", rate, ok)
+	}
+	if _, ok := batteryRate(samples[:2]); ok {
+		t.Fatal("expected no rate from a single battery sample")
+	}
+}
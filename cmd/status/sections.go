@@ -0,0 +1,108 @@
+package main
+
+import "errors"
+
+// SectionState is the outcome of one collector in a snapshot.
+type SectionState string
+
+const (
+	SectionOK      SectionState = "ok"
+	SectionEmpty   SectionState = "empty"   // Ran fine but the machine has nothing to report
+	SectionFailed  SectionState = "failed"  // Ran and hit an error; Detail says which
+	SectionSkipped SectionState = "skipped" // Disabled, or not requested in this mode
+)
+
+// SectionStatus tells consumers why a snapshot section has no data.
+type SectionStatus struct {
+	State  SectionState `json:"state"`
+	Detail string       `json:"detail,omitempty"`
+}
+
+// String renders the status for display, e.g. "failed (permission denied)".
+func (s SectionStatus) String() string {
+	if s.Detail == "" {
+		return string(s.State)
+	}
+	return string(s.State) + " (" + s.Detail + ")"
+}
+
+// snapshotSections resolves every collector's status from whether it was
+// started, the error it returned, and whether m ended up with data for it.
+func snapshotSections(started map[CollectorKind]bool, errs map[CollectorKind]error, m MetricsSnapshot) map[CollectorKind]SectionStatus {
+	sections := make(map[CollectorKind]SectionStatus, len(collectorKinds))
+	for _, kind := range collectorKinds {
+		if !started[kind] {
+			sections[kind] = SectionStatus{State: SectionSkipped}
+			continue
+		}
+		if err := errs[kind]; err != nil {
+			if errors.Is(err, ErrNoBattery) {
+				sections[kind] = SectionStatus{State: SectionEmpty, Detail: err.Error()}
+			} else {
+				sections[kind] = SectionStatus{State: SectionFailed, Detail: err.Error()}
+			}
+			continue
+		}
+		sections[kind] = sectionContent(kind, m)
+	}
+	return sections
+}
+
+// sectionContent reports ok or empty for a collector that ran without error.
+func sectionContent(kind CollectorKind, m MetricsSnapshot) SectionStatus {
+	empty := false
+	detail := ""
+	switch kind {
+	case CollectCPU:
+		empty = m.CPU.LogicalCPU == 0
+	case CollectMemory:
+		empty = m.Memory.Total == 0
+	case CollectDisks:
+		empty = len(m.Disks) == 0
+	case CollectNetwork:
+		empty = len(m.Network) == 0
+	case CollectProxy:
+		empty, detail = !m.Proxy.Enabled, "no proxy"
+	case CollectBattery:
+		empty = len(m.Batteries) == 0
+	case CollectUPS:
+		empty, detail = true, "no UPS"
+		for _, b := range m.Batteries {
+			if b.Kind == BatteryKindUPS {
+				empty = false
+			}
+		}
+	case CollectThermal:
+		if m.Thermal.PermissionDenied && m.Thermal.CPUTemp == 0 {
+			return SectionStatus{State: SectionFailed, Detail: "permission denied"}
+		}
+		empty = m.Thermal.CPUTemp == 0 && m.Thermal.FanSpeed == 0 && m.Thermal.Level == ThermalLevelUnknown
+	case CollectPower:
+		// RAPL needs two samples, so the first snapshot is empty even where it works.
+		empty = m.Thermal.CPUPower == 0
+	case CollectSensors:
+		empty = len(m.Sensors) == 0
+	case CollectGPU:
+		empty = len(m.GPU) == 0
+		// The placeholder row only explains the blank card; it isn't data.
+		if len(m.GPU) == 1 && m.GPU[0].Name == noGPUMetricsName {
+			empty, detail = true, "no GPU metrics source"
+		}
+	case CollectBluetooth:
+		empty = len(m.Bluetooth) == 0
+	case CollectDisplay:
+		empty, detail = m.Display == nil, "no backlight exposed"
+	case CollectAssertions:
+		empty, detail = len(m.Assertions) == 0, "no active assertions"
+	case CollectEnergy:
+		empty = len(m.ProcessEnergy) == 0
+	case CollectHost:
+		empty = m.Host == ""
+	case CollectProcesses:
+		empty = len(m.TopProcesses) == 0
+	}
+	if empty {
+		return SectionStatus{State: SectionEmpty, Detail: detail}
+	}
+	return SectionStatus{State: SectionOK}
+}
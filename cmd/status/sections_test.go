@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSnapshotSections(t *testing.T) {
+	started := map[CollectorKind]bool{
+		CollectCPU:     true,
+		CollectDisks:   true,
+		CollectBattery: true,
+		CollectSensors: true,
+		CollectThermal: true,
+	}
+	errs := map[CollectorKind]error{
+		CollectBattery: ErrNoBattery,
+		CollectSensors: errors.New("permission denied"),
+	}
+	m := MetricsSnapshot{
+		CPU:     CPUStatus{LogicalCPU: 8},
+		Thermal: ThermalStatus{PermissionDenied: true},
+	}
+	got := snapshotSections(started, errs, m)
+
+	want := map[CollectorKind]SectionStatus{
+		CollectCPU:     {State: SectionOK},
+		CollectDisks:   {State: SectionEmpty},
+		CollectBattery: {State: SectionEmpty, Detail: "no battery present"},
+		CollectSensors: {State: SectionFailed, Detail: "permission denied"},
+		CollectThermal: {State: SectionFailed, Detail: "permission denied"},
+	}
+	for kind, status := range want {
+		if got[kind] != status {
+			t.Errorf("%s: got %v, want %v", kind, got[kind], status)
+		}
+	}
+	if got[CollectGPU].State != SectionSkipped {
+		t.Errorf("gpu: got %v, want skipped", got[CollectGPU])
+	}
+	if len(got) != len(collectorKinds) {
+		t.Errorf("want a status for every collector, got %v", got)
+	}
+	if s := got[CollectSensors].String(); s != "failed (permission denied)" {
+		t.Errorf("unexpected rendering %q", s)
+	}
+}
+
+func TestSnapshotSectionsGPUPlaceholderIsEmpty(t *testing.T) {
+	started := map[CollectorKind]bool{CollectGPU: true}
+	m := MetricsSnapshot{GPU: []GPUStatus{{Name: noGPUMetricsName}}}
+	if got := snapshotSections(started, nil, m)[CollectGPU]; got.State != SectionEmpty {
+		t.Fatalf("placeholder GPU row: got %v, want empty", got)
+	}
+	m.GPU = []GPUStatus{{Name: "Apple M2", Usage: 12}}
+	if got := snapshotSections(started, nil, m)[CollectGPU]; got.State != SectionOK {
+		t.Fatalf("real GPU row: got %v, want ok", got)
+	}
+}
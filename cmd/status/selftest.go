@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// selfTestProbe runs one collector and describes what it found.
+type selfTestProbe struct {
+	name     string
+	required bool // A failure here means the platform's primary source is broken.
+	run      func(ctx context.Context) (detail string, err error)
+}
+
+func selfTestProbes() []selfTestProbe {
+	darwin := runtime.GOOS == "darwin"
+	return []selfTestProbe{
+		{name: "cpu", required: true, run: func(ctx context.Context) (string, error) {
+			cpu, err := collectCPU(ctx)
+			if err != nil {
+				return "", err
+			}
+			via := "gopsutil"
+			if cpu.PerCoreEstimated {
+				via = "ps"
+			}
+			return fmt.Sprintf("via %s, %d logical cores", via, cpu.LogicalCPU), nil
+		}},
+		{name: "memory", required: true, run: func(ctx context.Context) (string, error) {
+			mem, err := collectMemory(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("via gopsutil, %s total", humanBytes(mem.Total)), nil
+		}},
+		{name: "disks", required: true, run: func(ctx context.Context) (string, error) {
+			disks, err := collectDisks(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("via gopsutil, %d volumes", len(disks)), nil
+		}},
+		{name: "battery", run: func(ctx context.Context) (string, error) {
+			batts, err := collectBatteries(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("via %s, %d batteries", batts[0].Source, len(batts)), nil
+		}},
+		{name: "thermal", required: darwin, run: func(ctx context.Context) (string, error) {
+			thermal := collectThermal(ctx)
+			if thermal.CPUTemp <= 0 && thermal.Level == ThermalLevelUnknown {
+				return "", fmt.Errorf("no temperature or thermal level")
+			}
+			return fmt.Sprintf("cpu %.1f°C, level %s", thermal.CPUTemp, thermal.Level), nil
+		}},
+		{name: "sensors", run: func(ctx context.Context) (string, error) {
+			sensors, err := collectSensors(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d readings", len(sensors)), nil
+		}},
+		{name: "hardware", required: darwin, run: func(ctx context.Context) (string, error) {
+			hw := collectHardware(ctx, 0, nil)
+			if hw.Model == "" {
+				return "", fmt.Errorf("model unavailable")
+			}
+			return hw.Model, nil
+		}},
+	}
+}
+
+// runSelfTest runs every probe once and reports whether all required probes succeeded.
+func runSelfTest(ctx context.Context, w io.Writer, probes []selfTestProbe) bool {
+	ok := true
+	for _, p := range probes {
+		start := time.Now()
+		detail, err := p.run(ctx)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		switch {
+		case err == nil:
+			fmt.Fprintf(w, "%-9s ok %s (%s)\n", p.name+":", detail, elapsed)
+		case p.required:
+			ok = false
+			fmt.Fprintf(w, "%-9s FAIL %v (%s)\n", p.name+":", err, elapsed)
+		default:
+			fmt.Fprintf(w, "%-9s unavailable: %v (%s)\n", p.name+":", err, elapsed)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	probes := []selfTestProbe{
+		{name: "battery", run: func(context.Context) (string, error) { return "via pmset, 1 batteries", nil }},
+		{name: "sensors", run: func(context.Context) (string, error) { return "", errors.New("not supported") }},
+	}
+	var out strings.Builder
+	if !runSelfTest(context.Background(), &out, probes) {
+		t.Fatalf("optional probe failure must not fail the self-test")
+	}
+	if !strings.Contains(out.String(), "battery:  ok via pmset, 1 batteries") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "sensors:  unavailable: not supported") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	probes = append(probes, selfTestProbe{name: "cpu", required: true, run: func(context.Context) (string, error) {
+		return "", errors.New("boom")
+	}})
+	out.Reset()
+	if runSelfTest(context.Background(), &out, probes) {
+		t.Fatalf("required probe failure must fail the self-test")
+	}
+	if !strings.Contains(out.String(), "cpu:      FAIL boom") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
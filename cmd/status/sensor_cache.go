@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// sensorCache rate-limits gopsutil temperature reads, which walk every SMC key on
+// macOS and can take tens of milliseconds. The sensor and thermal collectors share
+// one cache; once --sensor-cache-ttl sets a window, a Collect that runs both
+// queries the hardware once. With the default zero TTL each of them reads fresh.
+type sensorCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	fetchedAt time.Time
+	temps     []sensors.TemperatureStat
+	err       error
+	fetch     func(ctx context.Context) ([]sensors.TemperatureStat, error)
+	now       func() time.Time
+}
+
+// sensorTemps is the process-wide cache. A zero TTL, the default, reads fresh every
+// call, so nothing is shared until a TTL is set.
+var sensorTemps = &sensorCache{fetch: sensors.TemperaturesWithContext, now: time.Now}
+
+// SetTTL sets how long a reading is reused. Reads inside the window, errors
+// included, are served from the cache.
+func (c *sensorCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = max(ttl, 0)
+}
+
+// TTL returns the current reuse window.
+func (c *sensorCache) TTL() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ttl
+}
+
+// reset drops the cached reading so the next get reads the hardware.
+func (c *sensorCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.temps, c.err, c.fetchedAt = nil, nil, time.Time{}
+}
+
+// get returns the cached temperatures, fetching them when the TTL has passed.
+// Concurrent callers wait for one fetch rather than each starting their own.
+func (c *sensorCache) get(ctx context.Context) ([]sensors.TemperatureStat, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.ttl <= 0 || c.fetchedAt.IsZero() || now.Sub(c.fetchedAt) >= c.ttl {
+		temps, err := c.fetch(ctx)
+		// A cancelled read says nothing about the sensors; don't cache it.
+		if ctx.Err() != nil {
+			return temps, err
+		}
+		c.temps, c.err, c.fetchedAt = temps, err, now
+	}
+	return slices.Clone(c.temps), c.err
+}
+
+// linuxSensorShare is how long one lm-sensors or hwmon read is reused. On Linux
+// collectThermal and collectSensors both read them every tick; sharing keeps that
+// to one `sensors -j` and one hwmon walk. With temperature sampling the window
+// stays under the sample spacing, so each sample is still a fresh read.
+func linuxSensorShare() time.Duration {
+	const share = 250 * time.Millisecond
+	if spacing := sampleSpacing(tempSamples, tempSampleWindow); spacing > 0 {
+		return min(share, spacing/2)
+	}
+	return share
+}
+
+// sharedRead serves one fetch to every caller inside its window, counted from
+// when the fetch finished. Concurrent callers wait for the first rather than
+// starting their own.
+type sharedRead[T any] struct {
+	mu        sync.Mutex
+	fetchedAt time.Time
+	val       T
+	ok        bool
+	fetch     func(ctx context.Context) (T, bool)
+	window    func() time.Duration
+	clone     func(T) T // Copies val for callers that modify the result; nil shares it
+	now       func() time.Time
+}
+
+func (s *sharedRead[T]) get(ctx context.Context) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fetchedAt.IsZero() || s.now().Sub(s.fetchedAt) >= s.window() {
+		val, ok := s.fetch(ctx)
+		if ctx.Err() != nil {
+			return val, ok
+		}
+		s.val, s.ok, s.fetchedAt = val, ok, s.now()
+	}
+	if s.clone != nil {
+		return s.clone(s.val), s.ok
+	}
+	return s.val, s.ok
+}
+
+// value is get without the ok flag.
+func (s *sharedRead[T]) value(ctx context.Context) T {
+	val, _ := s.get(ctx)
+	return val
+}
+
+func (s *sharedRead[T]) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var zero T
+	s.val, s.ok, s.fetchedAt = zero, false, time.Time{}
+}
+
+// lmSensorsShared and hwmonShared are the shared Linux reads. Callers append to
+// the result (addCPUPackage), so each gets its own copy.
+var (
+	lmSensorsShared = &sharedRead[[]SensorReading]{
+		fetch:  lmSensorsReadings,
+		window: linuxSensorShare,
+		clone:  slices.Clone[[]SensorReading],
+		now:    time.Now,
+	}
+	hwmonShared = &sharedRead[[]SensorReading]{
+		fetch: func(context.Context) ([]SensorReading, bool) {
+			readings := readHwmonSensors(hwmonRoot)
+			return readings, len(readings) > 0
+		},
+		window: linuxSensorShare,
+		clone:  slices.Clone[[]SensorReading],
+		now:    time.Now,
+	}
+)
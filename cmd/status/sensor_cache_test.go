@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+func TestSensorCacheTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var fetches int
+	c := &sensorCache{
+		now: func() time.Time { return now },
+		fetch: func(context.Context) ([]sensors.TemperatureStat, error) {
+			fetches++
+			return []sensors.TemperatureStat{{SensorKey: "TC0P", Temperature: float64(50 + fetches)}}, nil
+		},
+	}
+	ctx := context.Background()
+
+	// Zero TTL reads every time.
+	c.get(ctx)
+	c.get(ctx)
+	if fetches != 2 {
+		t.Fatalf("zero TTL: want 2 fetches, got %d", fetches)
+	}
+
+	c.SetTTL(2 * time.Second)
+	temps, _ := c.get(ctx)
+	now = now.Add(time.Second)
+	cached, _ := c.get(ctx)
+	if fetches != 2 || cached[0].Temperature != temps[0].Temperature {
+		t.Fatalf("within TTL: want the cached reading, got %d fetches and %v", fetches, cached)
+	}
+	now = now.Add(2 * time.Second)
+	if fresh, _ := c.get(ctx); fetches != 3 || fresh[0].Temperature != 53 {
+		t.Fatalf("after TTL: want a fresh fetch, got %d fetches and %v", fetches, fresh)
+	}
+}
+
+func TestSensorCacheSkipsCancelledReads(t *testing.T) {
+	fail := errors.New("smc timeout")
+	c := &sensorCache{
+		now: time.Now,
+		fetch: func(ctx context.Context) ([]sensors.TemperatureStat, error) {
+			if ctx.Err() != nil {
+				return nil, fail
+			}
+			return []sensors.TemperatureStat{{SensorKey: "TC0P", Temperature: 60}}, nil
+		},
+	}
+	c.SetTTL(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.get(ctx); err != fail {
+		t.Fatalf("want the cancelled error, got %v", err)
+	}
+	if temps, err := c.get(context.Background()); err != nil || len(temps) != 1 {
+		t.Fatalf("cancelled read must not be cached: %v, %v", temps, err)
+	}
+}
+
+func TestSharedReadServesBothCollectors(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var fetches int
+	s := &sharedRead[[]SensorReading]{
+		window: linuxSensorShare,
+		clone:  slices.Clone[[]SensorReading],
+		now:    func() time.Time { return now },
+		fetch: func(context.Context) ([]SensorReading, bool) {
+			fetches++
+			return []SensorReading{{Key: "coretemp_core_0", Value: 50, Unit: "°C"}}, true
+		},
+	}
+	ctx := context.Background()
+
+	first, _ := s.get(ctx)
+	_ = append(first[:1], SensorReading{Key: "cpu_package"})
+	second, ok := s.get(ctx)
+	if fetches != 1 || !ok || len(second) != 1 || second[0].Key != "coretemp_core_0" {
+		t.Fatalf("same tick: want one fetch and an untouched copy, got %d fetches and %+v", fetches, second)
+	}
+	now = now.Add(time.Second)
+	s.get(ctx)
+	if fetches != 2 {
+		t.Fatalf("next tick: want a fresh fetch, got %d", fetches)
+	}
+}
+
+func TestLinuxSensorShareStaysUnderSampleSpacing(t *testing.T) {
+	defer func(n int, w time.Duration) { tempSamples, tempSampleWindow = n, w }(tempSamples, tempSampleWindow)
+	tempSamples, tempSampleWindow = 5, 200*time.Millisecond
+	if got := linuxSensorShare(); got >= sampleSpacing(tempSamples, tempSampleWindow) {
+		t.Fatalf("share window %v would merge samples %v apart", got, sampleSpacing(tempSamples, tempSampleWindow))
+	}
+}
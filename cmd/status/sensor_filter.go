@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// SensorFilter narrows the sensor list to what the user cares about. Patterns
+// match the display label, case-insensitively: a pattern with glob characters
+// uses path.Match syntax against the whole label, anything else is a substring.
+// A non-empty Include keeps only matching sensors; Exclude always drops matches.
+// It only narrows what is displayed and exported: CPU spread, enclosure
+// temperature, trends, the rollup and the critical-temperature watch all see
+// every sensor.
+type SensorFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// sensorFilter is the active filter; set from --sensors-include and --sensors-exclude.
+var sensorFilter SensorFilter
+
+// keep reports whether a sensor labelled label passes the filter.
+func (f SensorFilter) keep(label string) bool {
+	if len(f.Include) > 0 && !sensorLabelMatches(label, f.Include) {
+		return false
+	}
+	return !sensorLabelMatches(label, f.Exclude)
+}
+
+// apply returns the readings the filter keeps; readings itself is left alone.
+func (f SensorFilter) apply(readings []SensorReading) []SensorReading {
+	if len(f.Include) == 0 && len(f.Exclude) == 0 {
+		return readings
+	}
+	var out []SensorReading
+	for _, r := range readings {
+		if f.keep(r.Label) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// sensorLabelMatches reports whether label matches any pattern.
+func sensorLabelMatches(label string, patterns []string) bool {
+	label = strings.ToLower(label)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if strings.ContainsAny(p, `*?[\`) {
+			if ok, _ := path.Match(p, label); ok {
+				return true
+			}
+		} else if strings.Contains(label, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSensorFilter(t *testing.T) {
+	readings := []SensorReading{
+		{Label: "CPU Die"}, {Label: "GPU Proximity"}, {Label: "Ambient 2"},
+		{Label: "Fan Controller"}, {Label: "Core 0"},
+	}
+	labels := func(rs []SensorReading) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.Label)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter SensorFilter
+		want   []string
+	}{
+		{"empty keeps all", SensorFilter{}, []string{"CPU Die", "GPU Proximity", "Ambient 2", "Fan Controller", "Core 0"}},
+		{"include substring", SensorFilter{Include: []string{"cpu", "GPU"}}, []string{"CPU Die", "GPU Proximity"}},
+		{"include glob", SensorFilter{Include: []string{"core *"}}, []string{"Core 0"}},
+		{"exclude", SensorFilter{Exclude: []string{"fan", "ambient*"}}, []string{"CPU Die", "GPU Proximity", "Core 0"}},
+		{"exclude beats include", SensorFilter{Include: []string{"proximity", "die"}, Exclude: []string{"gpu*"}}, []string{"CPU Die"}},
+	} {
+		got := labels(tc.filter.apply(slices.Clone(readings)))
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSensorFilterKeepsCriticalWatch(t *testing.T) {
+	prevProbe, prevReadings, prevFilter := sensorProbe, collectSensorReadings, sensorFilter
+	prevTemp, prevSamples := criticalTempCelsius, criticalTempSamples
+	t.Cleanup(func() {
+		sensorProbe, collectSensorReadings, sensorFilter = prevProbe, prevReadings, prevFilter
+		criticalTempCelsius, criticalTempSamples = prevTemp, prevSamples
+	})
+	collectSensorReadings = true
+	criticalTempCelsius, criticalTempSamples = 95, 1
+	sensorFilter = SensorFilter{Exclude: []string{"gpu"}}
+	sensorProbe = func(context.Context) ([]SensorReading, error) {
+		return []SensorReading{
+			{Key: "TC0P", Label: "CPU Proximity", Value: 60, Unit: "°C", Class: SensorClassCPU},
+			{Key: "TG0D", Label: "GPU Die", Value: 104, Unit: "°C", Class: SensorClassGPU},
+		}, nil
+	}
+
+	c := NewCollector()
+	for _, kind := range collectorKinds {
+		c.SetEnabled(kind, kind == CollectSensors)
+	}
+	m, _ := c.Collect(context.Background())
+	if len(m.Sensors) != 1 || m.Sensors[0].Label != "CPU Proximity" {
+		t.Fatalf("displayed sensors = %+v, want the GPU hidden", m.Sensors)
+	}
+	if !m.Thermal.CriticalSustained || m.Thermal.CriticalPeak != 104 {
+		t.Fatalf("a hidden 104°C sensor must still trip the critical watch: %+v", m.Thermal)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import "strings"
+
+// SensorLocation is where in the machine a sensor sits, finer than its class:
+// two Ambient sensors can be the front intake and the rear exhaust.
+type SensorLocation string
+
+const (
+	SensorLocationNone         SensorLocation = ""
+	SensorLocationCPUPackage   SensorLocation = "CPU package"
+	SensorLocationCPUCore      SensorLocation = "CPU core"
+	SensorLocationDIMM         SensorLocation = "DIMM"
+	SensorLocationPCH          SensorLocation = "PCH"
+	SensorLocationVRM          SensorLocation = "VRM"
+	SensorLocationFrontAmbient SensorLocation = "Ambient front"
+	SensorLocationRearAmbient  SensorLocation = "Ambient rear"
+	SensorLocationAmbient      SensorLocation = "Ambient"
+)
+
+// sensorLocationOrder is the display order for summaries: silicon first, then
+// airflow from intake to exhaust.
+var sensorLocationOrder = []SensorLocation{
+	SensorLocationCPUPackage,
+	SensorLocationCPUCore,
+	SensorLocationDIMM,
+	SensorLocationPCH,
+	SensorLocationVRM,
+	SensorLocationFrontAmbient,
+	SensorLocationAmbient,
+	SensorLocationRearAmbient,
+}
+
+// sensorLocationHints maps label fragments to locations, checked in order so the
+// specific hint wins: "Front Panel Ambient" is the front, not just ambient. The
+// fragments follow coretemp, k10temp, jc42/spd5118, IPMI SDR and SMC naming.
+var sensorLocationHints = []struct {
+	location  SensorLocation
+	fragments []string
+}{
+	{SensorLocationCPUPackage, []string{"package id", "cpu package", "x86_pkg_temp", "tctl", "tdie"}},
+	{SensorLocationVRM, []string{"vrm", "vr temp", "vcore"}},
+	{SensorLocationCPUCore, []string{"core "}},
+	{SensorLocationDIMM, []string{"dimm", "jc42", "spd5118", "memory temp"}},
+	{SensorLocationPCH, []string{"pch"}},
+	{SensorLocationFrontAmbient, []string{"front", "inlet", "intake"}},
+	{SensorLocationRearAmbient, []string{"rear", "exhaust", "outlet"}},
+	{SensorLocationAmbient, []string{"ambient"}},
+}
+
+// sensorLocations enables Location on readings; set from --sensor-locations.
+var sensorLocations bool
+
+// sensorLocation guesses r's location from its key and label, or returns
+// SensorLocationNone when neither gives a hint.
+func sensorLocation(r SensorReading) SensorLocation {
+	text := strings.ToLower(r.Key + " " + r.Label)
+	for _, h := range sensorLocationHints {
+		if containsAny(text, h.fragments...) {
+			return h.location
+		}
+	}
+	return SensorLocationNone
+}
+
+// applySensorLocations sets Location on every temperature in place. Fans and
+// voltages are left alone: "front" on a fan says nothing about air temperature.
+func applySensorLocations(readings []SensorReading) {
+	for i := range readings {
+		if readings[i].Unit == "°C" {
+			readings[i].Location = sensorLocation(readings[i])
+		}
+	}
+}
+
+// SummarizeSensorsByLocation collapses temperatures into per-location
+// min/max/avg, e.g. "Ambient front: 1 sensor, 22°C". Readings without a
+// location are left out.
+func SummarizeSensorsByLocation(readings []SensorReading) []SensorSummary {
+	return summarizeSensors(readings, sensorLocationOrder, func(r SensorReading) (SensorSummary, SensorLocation, bool) {
+		if r.Location == SensorLocationNone || r.Unit != "°C" {
+			return SensorSummary{}, "", false
+		}
+		return SensorSummary{Location: r.Location}, r.Location, true
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSensorLocationHeuristics(t *testing.T) {
+	for _, tc := range []struct {
+		r    SensorReading
+		want SensorLocation
+	}{
+		{SensorReading{Key: "coretemp_package_id_0", Label: "Package id 0"}, SensorLocationCPUPackage},
+		{SensorReading{Key: "k10temp_tctl", Label: "Tctl"}, SensorLocationCPUPackage},
+		{SensorReading{Key: "coretemp_core_3", Label: "Core 3"}, SensorLocationCPUCore},
+		{SensorReading{Key: "jc42-i2c-0-18 temp1", Label: "jc42 temp1"}, SensorLocationDIMM},
+		{SensorReading{Label: "DIMM A1 Temp"}, SensorLocationDIMM},
+		{SensorReading{Key: "pch_cannonlake", Label: "pch cannonlake"}, SensorLocationPCH},
+		{SensorReading{Label: "Vcore Temp"}, SensorLocationVRM},
+		{SensorReading{Label: "Front Panel Ambient"}, SensorLocationFrontAmbient},
+		{SensorReading{Label: "Inlet Temp"}, SensorLocationFrontAmbient},
+		{SensorReading{Label: "Exhaust Temp"}, SensorLocationRearAmbient},
+		{SensorReading{Key: "TA0P", Label: "Ambient"}, SensorLocationAmbient},
+		{SensorReading{Key: "nvme_composite", Label: "nvme Composite"}, SensorLocationNone},
+		{SensorReading{Key: "TG0D", Label: "TG0D"}, SensorLocationNone},
+	} {
+		if got := sensorLocation(tc.r); got != tc.want {
+			t.Errorf("%q / %q: got %q, want %q", tc.r.Key, tc.r.Label, got, tc.want)
+		}
+	}
+}
+
+func TestSummarizeSensorsByLocation(t *testing.T) {
+	readings := []SensorReading{
+		{Label: "Exhaust Temp", Value: 31, Unit: "°C", Class: SensorClassAmbient},
+		{Label: "Inlet Temp", Value: 22, Unit: "°C", Class: SensorClassAmbient},
+		{Label: "DIMM A1", Value: 40, Unit: "°C"},
+		{Label: "DIMM B1", Value: 44, Unit: "°C"},
+		{Label: "nvme Composite", Value: 38, Unit: "°C"},
+		{Label: "Front fan", Value: 1200, Unit: "RPM", Class: SensorClassFan},
+	}
+	applySensorLocations(readings)
+	if readings[5].Location != SensorLocationNone {
+		t.Fatalf("a fan shouldn't get an ambient location, got %q", readings[5].Location)
+	}
+	var got []string
+	for _, s := range SummarizeSensorsByLocation(readings) {
+		got = append(got, s.String())
+	}
+	want := "DIMM: 2 sensors, 40–44°C (avg 42) | Ambient front: 1 sensor, 22°C | Ambient rear: 1 sensor, 31°C"
+	if strings.Join(got, " | ") != want {
+		t.Fatalf("got  %s\nwant %s", strings.Join(got, " | "), want)
+	}
+}
+
+func TestTableLocationSectionOnlyWithLocations(t *testing.T) {
+	m := MetricsSnapshot{Sensors: []SensorReading{{Label: "Inlet Temp", Value: 22, Unit: "°C", Class: SensorClassAmbient}}}
+	if strings.Contains(RenderTable(m, TableOptions{}), "SENSOR LOCATIONS") {
+		t.Fatal("default output shouldn't group by location")
+	}
+	applySensorLocations(m.Sensors)
+	if out := RenderTable(m, TableOptions{}); !strings.Contains(out, "SENSOR LOCATIONS") || !strings.Contains(out, "Ambient front") {
+		t.Fatalf("missing location summary:\n%s", out)
+	}
+}
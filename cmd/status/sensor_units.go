@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Temperature sources whose unit can be declared with --sensor-unit.
+const (
+	sensorSourceGopsutil    = "gopsutil"
+	sensorSourceHwmon       = "hwmon"
+	sensorSourceLMSensors   = "lm-sensors"
+	sensorSourceThermalZone = "thermal-zone"
+)
+
+var sensorSources = []string{sensorSourceGopsutil, sensorSourceHwmon, sensorSourceLMSensors, sensorSourceThermalZone}
+
+// maxPlausibleCelsius is the sanity ceiling for any sensor reading; higher values
+// are raw ADC counts or a wrong unit rather than heat.
+const maxPlausibleCelsius = 150.0
+
+// sensorSourceUnits holds units declared per source; undeclared sources are
+// Celsius, except for the gopsutil heuristic in detectTempUnit.
+var sensorSourceUnits = map[string]TempUnit{}
+
+// setSensorSourceUnit parses one source=unit declaration, e.g. "gopsutil=F".
+func setSensorSourceUnit(raw string) error {
+	source, unit, ok := strings.Cut(raw, "=")
+	source = strings.ToLower(strings.TrimSpace(source))
+	if !ok || !slices.Contains(sensorSources, source) {
+		return fmt.Errorf("want source=unit with source one of %s; got %q", strings.Join(sensorSources, ", "), raw)
+	}
+	u, err := parseTempUnit(unit)
+	if err != nil {
+		return err
+	}
+	sensorSourceUnits[source] = u
+	return nil
+}
+
+// toCelsius converts a raw reading taken in unit.
+func toCelsius(v float64, unit TempUnit) float64 {
+	if unit == TempFahrenheit {
+		return FahrenheitToCelsius(v)
+	}
+	return v
+}
+
+// plausibleCelsius is the sanity filter applied after unit conversion.
+func plausibleCelsius(c float64) bool {
+	return c > 0 && c <= maxPlausibleCelsius
+}
+
+// detectTempUnit returns the declared unit for source, or guesses from a whole
+// batch of readings when allowed: a batch with nothing plausible as Celsius but
+// something plausible as Fahrenheit is taken as Fahrenheit. A single 80° reading
+// can't be told apart, so the guess only fires when every reading is too hot to be
+// Celsius; declare the unit for hardware known to misreport.
+func detectTempUnit(source string, batch []float64, guess bool) TempUnit {
+	if u, ok := sensorSourceUnits[source]; ok {
+		return u
+	}
+	if !guess {
+		return TempCelsius
+	}
+	fahrenheit := false
+	for _, v := range batch {
+		if plausibleCelsius(v) {
+			return TempCelsius
+		}
+		fahrenheit = fahrenheit || plausibleCelsius(FahrenheitToCelsius(v))
+	}
+	if fahrenheit {
+		return TempFahrenheit
+	}
+	return TempCelsius
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+func TestDetectTempUnit(t *testing.T) {
+	t.Cleanup(func() { sensorSourceUnits = map[string]TempUnit{} })
+
+	// 80°F passes the Celsius range, so only a declaration can catch it.
+	if u := detectTempUnit(sensorSourceGopsutil, []float64{80, 140}, true); u != TempCelsius {
+		t.Fatalf("mixed batch should stay Celsius, got %s", u)
+	}
+	if u := detectTempUnit(sensorSourceGopsutil, []float64{161.6, 172.4}, true); u != TempFahrenheit {
+		t.Fatalf("batch too hot for Celsius should be Fahrenheit, got %s", u)
+	}
+	if u := detectTempUnit(sensorSourceGopsutil, []float64{4023}, true); u != TempCelsius {
+		t.Fatalf("raw ADC counts aren't Fahrenheit, got %s", u)
+	}
+	if u := detectTempUnit(sensorSourceHwmon, []float64{161.6}, false); u != TempCelsius {
+		t.Fatalf("hwmon is never guessed, got %s", u)
+	}
+
+	if err := setSensorSourceUnit("gopsutil=F"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setSensorSourceUnit("smc=F"); err == nil {
+		t.Fatal("want an error for an unknown source")
+	}
+	c, ok := pickSMCCPUTemp([]sensors.TemperatureStat{{SensorKey: "TC0P", Temperature: 80}})
+	if !ok || math.Abs(c-26.7) > 0.05 {
+		t.Fatalf("declared Fahrenheit should convert 80°F to 26.7°C, got %v", c)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// snapshotFileVersion is bumped when a saved snapshot can no longer be read back
+// field for field.
+const snapshotFileVersion = 1
+
+// snapshotFile is a saved snapshot: the WebSocket/Unix socket JSON plus what is
+// needed to render it as the user saw it.
+type snapshotFile struct {
+	snapshotJSON
+	FileVersion int       `json:"mole_snapshot_version"`
+	SavedAt     time.Time `json:"saved_at"`
+	TempUnit    TempUnit  `json:"temp_unit,omitempty"`
+}
+
+// SaveSnapshot writes m to path for a bug report or demo, with unit as the
+// temperature unit it was displayed in.
+func SaveSnapshot(path string, m MetricsSnapshot, unit TempUnit) error {
+	f := snapshotFile{snapshotJSON: snapshotJSON{MetricsSnapshot: m}, FileVersion: snapshotFileVersion, SavedAt: time.Now(), TempUnit: unit}
+	if m.BatteryErr != nil {
+		f.BatteryErr = m.BatteryErr.Error()
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadSnapshot reads a file written by SaveSnapshot, or a single snapshot captured
+// from the WebSocket or Unix socket stream. unit is "" when the file doesn't say.
+func LoadSnapshot(path string) (m MetricsSnapshot, unit TempUnit, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MetricsSnapshot{}, "", err
+	}
+	var f snapshotFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return MetricsSnapshot{}, "", fmt.Errorf("%s: %w", path, err)
+	}
+	if f.FileVersion > snapshotFileVersion {
+		return MetricsSnapshot{}, "", fmt.Errorf("%s: snapshot version %d is newer than this build reads (%d)", path, f.FileVersion, snapshotFileVersion)
+	}
+	m = f.MetricsSnapshot
+	m.BatteryErr = batteryErrFromText(f.BatteryErr)
+	return m, f.TempUnit, nil
+}
+
+// batteryErrFromText restores a saved BatteryErr, as the sentinel it was when the
+// text matches one so the UI words it the same way.
+func batteryErrFromText(text string) error {
+	if text == "" {
+		return nil
+	}
+	for _, sentinel := range []error{ErrBatteryPermission, ErrBatteryUnreadable, ErrNoBattery} {
+		if text == sentinel.Error() {
+			return sentinel
+		}
+	}
+	return errors.New(text)
+}
+
+// runSaveSnapshot collects once with every output mode's collectors enabled, so the
+// saved file can stand in for --table, the exporters and the UI alike, and saves it.
+func runSaveSnapshot(ctx context.Context, path string) error {
+	collectSensorReadings = true
+	collector := NewCollector()
+	collector.Prime(ctx, primeInterval)
+	snap, _ := collector.Collect(ctx)
+	return SaveSnapshot(path, snap, displayTempUnit)
+}
+
+// replaySnapshot, set from --replay, is returned by every Collect in place of a
+// live collection, so the UI and exporters run against a saved machine.
+var replaySnapshot *MetricsSnapshot
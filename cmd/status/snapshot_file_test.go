@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func replayFixture() MetricsSnapshot {
+	full := time.Date(2026, 10, 13, 22, 0, 0, 0, time.UTC)
+	return MetricsSnapshot{
+		CollectedAt: time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC),
+		Host:        "mbp",
+		Hardware:    HardwareInfo{Model: "MacBook Pro 14-inch, 2021", ModelID: "MacBookPro18,3"},
+		Batteries: []BatteryStatus{{
+			Name: "InternalBattery-0", Kind: BatteryKindSystem, Percent: 72, Status: "discharging", TimeLeft: "3:10",
+			CycleCount: 412, Trend: &BatteryTrend{CycleDelta: 12, Since: full.Add(-7 * 24 * time.Hour)}, LastFullCharge: &full,
+			Longevity: &BatteryLongevity{RatedCycles: 1000, CyclesRemaining: 588, CyclesPerMonth: 20},
+		}},
+		BatteryErr: ErrBatteryPermission,
+		Thermal:    ThermalStatus{CPUTemp: 54.5, CPUTempSource: CPUTempSourceHID, CPUTempTrend: TrendRising, Level: ThermalLevelSerious, FanSpeed: 2100},
+		Sensors:    []SensorReading{{Key: "TC0P", Label: "CPU Proximity", Value: 61, Unit: "°C", Trend: TrendFalling}},
+		Sections:   map[CollectorKind]SectionStatus{CollectBattery: {State: SectionFailed, Detail: "permission denied"}},
+	}
+}
+
+func TestSnapshotFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	want := replayFixture()
+	if err := SaveSnapshot(path, want, TempFahrenheit); err != nil {
+		t.Fatal(err)
+	}
+	got, unit, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit != TempFahrenheit {
+		t.Fatalf("unit = %q, want F", unit)
+	}
+	if !errors.Is(got.BatteryErr, ErrBatteryUnreadable) {
+		t.Fatalf("BatteryErr = %v, want the permission sentinel back", got.BatteryErr)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip changed the snapshot:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLoadSnapshotAcceptsStreamedJSON(t *testing.T) {
+	// A line captured from --unix-socket has no file header and no unit.
+	data, err := encodeSnapshot(replayFixture())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "line.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, unit, err := LoadSnapshot(path)
+	if err != nil || unit != "" || got.Host != "mbp" || got.Thermal.Level != ThermalLevelSerious {
+		t.Fatalf("got host %q level %v unit %q err %v", got.Host, got.Thermal.Level, unit, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"mole_snapshot_version": 99}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadSnapshot(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("err = %v, want a version error", err)
+	}
+}
+
+func TestCollectorReplaysSnapshot(t *testing.T) {
+	snap := replayFixture()
+	replaySnapshot = &snap
+	t.Cleanup(func() { replaySnapshot = nil })
+
+	c := NewCollector()
+	c.Prime(context.Background(), time.Hour) // Must not wait or probe anything.
+	got, err := c.Collect(context.Background())
+	if err != nil || !reflect.DeepEqual(got, snap) {
+		t.Fatalf("Collect under replay = %+v, %v", got, err)
+	}
+	table := RenderTable(got, TableOptions{TempUnit: TempFahrenheit})
+	if !strings.Contains(table, "130.1°F") || !strings.Contains(table, "InternalBattery-0") {
+		t.Fatalf("table doesn't show the replayed machine:\n%s", table)
+	}
+}
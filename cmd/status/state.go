@@ -0,0 +1,30 @@
+package main
+
+import "time"
+
+// ResetState clears every process-wide cache: system_profiler output (stopping
+// its background refresher), the last pmset power state, the sensor cache, the
+// shared lm-sensors, hwmon and powermetrics reads, command health, core topology,
+// disk types, hardware port names, the detected Mac architecture and a disabled
+// state-file writer. Configuration set from flags is left alone, and
+// per-Collector state is cleared with Collector.Reset. Meant for tests and for
+// embedders that need a clean slate without restarting the process.
+func ResetState() {
+	profilerCache.reset()
+	pmsetPowerState.reset()
+	sensorTemps.reset()
+	lmSensorsShared.reset()
+	hwmonShared.reset()
+	powermetricsShared.reset()
+	commands.reset()
+
+	lastTopologyAt, cachedP, cachedE = time.Time{}, 0, 0
+	lastDiskCacheAt, diskTypeCache = time.Time{}, make(map[string]bool)
+
+	hardwarePortMu.Lock()
+	hardwarePortCache, hardwarePortCacheAt = nil, time.Time{}
+	hardwarePortMu.Unlock()
+
+	persisted.reset()
+	resetMacArch()
+}
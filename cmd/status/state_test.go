@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResetState(t *testing.T) {
+	t.Cleanup(ResetState)
+	profilerCache.mu.Lock()
+	profilerCache.entries[spPowerDataType] = systemProfilerEntry{output: "cached", fetchedAt: time.Now()}
+	profilerCache.mu.Unlock()
+	sensorTemps.mu.Lock()
+	sensorTemps.fetchedAt = time.Now()
+	sensorTemps.mu.Unlock()
+	commands.record(context.Background(), "ioreg", []string{"-rn", "AppleSmartBattery"}, errors.New("exit status 1"))
+	diskTypeCache["disk4"] = true
+	cachedP, cachedE = 8, 4
+
+	ResetState()
+
+	if len(profilerCache.entries) != 0 || !sensorTemps.fetchedAt.IsZero() {
+		t.Fatal("caches survived ResetState")
+	}
+	if len(commands.health) != 0 || len(diskTypeCache) != 0 || cachedP != 0 || cachedE != 0 {
+		t.Fatal("package state survived ResetState")
+	}
+}
+
+func TestCollectorResetKeepsEnabled(t *testing.T) {
+	c := NewCollector()
+	c.SetEnabled(CollectGPU, false)
+	c.SetBatteryHistory(batteryHistory{Batteries: []batterySample{{Name: "BAT0", CycleCount: 10}}})
+	c.lastCollectAt = time.Now()
+
+	c.Reset()
+
+	if len(c.history.Batteries) != 0 || !c.lastCollectAt.IsZero() {
+		t.Fatal("collector state survived Reset")
+	}
+	if c.Enabled(CollectGPU) {
+		t.Fatal("Reset should keep disabled collectors")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsD packets stay below a typical MTU to avoid fragmentation.
+const statsdMaxPacket = 1432
+
+// StatsDConfig configures the StatsD/DogStatsD sink.
+type StatsDConfig struct {
+	Addr      string        // host:port of the agent
+	Prefix    string        // metric name prefix, e.g. "mole"
+	Tags      []string      // constant tags ("env:prod"), DogStatsD only
+	DogStatsD bool          // emit labels as DogStatsD tags instead of name segments
+	Interval  time.Duration // flush interval
+}
+
+// formatStatsD renders samples as StatsD gauge lines.
+func formatStatsD(samples []metricSample, cfg StatsDConfig) []string {
+	lines := make([]string, 0, len(samples))
+	for _, s := range samples {
+		name := s.Name
+		if cfg.Prefix != "" {
+			name = cfg.Prefix + "." + name
+		}
+		var tags []string
+		if cfg.DogStatsD {
+			tags = append(tags, cfg.Tags...)
+			for _, l := range s.Labels {
+				tags = append(tags, sanitizeStatsD(l.Key)+":"+sanitizeStatsD(l.Value))
+			}
+		} else {
+			// Plain StatsD has no tags, so labels become name segments.
+			for _, l := range s.Labels {
+				name += "." + sanitizeStatsD(l.Value)
+			}
+		}
+		line := sanitizeStatsD(name) + ":" + strconv.FormatFloat(s.Value, 'f', -1, 64) + "|g"
+		if len(tags) > 0 {
+			line += "|#" + strings.Join(tags, ",")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// sanitizeStatsD replaces characters that StatsD treats as separators.
+func sanitizeStatsD(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// packStatsD groups lines into newline-separated packets under the size limit.
+func packStatsD(lines []string, limit int) []string {
+	var (
+		packets []string
+		current strings.Builder
+	)
+	for _, line := range lines {
+		if current.Len() > 0 && current.Len()+1+len(line) > limit {
+			packets = append(packets, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		packets = append(packets, current.String())
+	}
+	return packets
+}
+
+// runStatsD collects on every interval and pushes gauges until ctx is cancelled.
+func runStatsD(ctx context.Context, cfg StatsDConfig) error {
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("statsd dial %s: %w", cfg.Addr, err)
+	}
+	defer conn.Close()
+
+	collectSensorReadings = true
+	collector := NewCollector()
+	collector.Prime(ctx, primeInterval)
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Partial failures still yield useful gauges.
+		data, _ := collector.Collect(ctx)
+		for _, packet := range packStatsD(formatStatsD(snapshotMetrics(data), cfg), statsdMaxPacket) {
+			// UDP is fire-and-forget; a missing agent must not stop the loop.
+			_, _ = conn.Write([]byte(packet))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseStatsDTags splits a comma-separated tag list.
+func parseStatsDTags(raw string) []string {
+	var tags []string
+	for tag := range strings.SplitSeq(raw, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
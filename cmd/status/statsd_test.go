@@ -0,0 +1,89 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFormatStatsD(t *testing.T) {
+	m := MetricsSnapshot{
+		Batteries: []BatteryStatus{{Percent: 72, CycleCount: 120}},
+		Thermal:   ThermalStatus{CPUTemp: 54.5, FanSpeed: 2100},
+		Sensors:   []SensorReading{{Label: "CPU Die", Value: 61}},
+	}
+	samples := snapshotMetrics(m)
+
+	plain := formatStatsD(samples, StatsDConfig{Prefix: "mole"})
+	wantPlain := []string{
+		"mole.battery_percent.0:72|g",
+		"mole.battery_cycle_count.0:120|g",
+		"mole.cpu_temperature_celsius:54.5|g",
+		"mole.fan_speed_rpm:2100|g",
+		"mole.sensor_temperature_celsius.CPU_Die:61|g",
+	}
+	if !slices.Equal(plain, wantPlain) {
+		t.Fatalf("unexpected plain statsd lines:\n%s", strings.Join(plain, "\n"))
+	}
+
+	dog := formatStatsD(samples, StatsDConfig{Prefix: "mole", DogStatsD: true, Tags: []string{"env:test"}})
+	if dog[0] != "mole.battery_percent:72|g|#env:test,battery:0" {
+		t.Fatalf("unexpected dogstatsd line %q", dog[0])
+	}
+	if dog[4] != "mole.sensor_temperature_celsius:61|g|#env:test,sensor:CPU_Die" {
+		t.Fatalf("unexpected dogstatsd sensor line %q", dog[4])
+	}
+}
+
+func TestSensorMetricsUseRawKey(t *testing.T) {
+	m := MetricsSnapshot{Sensors: []SensorReading{
+		{Key: "TC0P", Label: "CPU Proximity", Value: 58},
+		{Label: "CPU Die", Value: 61},
+	}}
+	var got []string
+	for _, s := range snapshotMetrics(m) {
+		got = append(got, s.Labels[0].Value)
+	}
+	if !slices.Equal(got, []string{"TC0P", "CPU Die"}) {
+		t.Fatalf("sensor labels = %v, want the raw key with Label as fallback", got)
+	}
+}
+
+func TestPackStatsD(t *testing.T) {
+	lines := []string{"a:1|g", "b:2|g", "c:3|g"}
+	packets := packStatsD(lines, 11)
+	if !slices.Equal(packets, []string{"a:1|g\nb:2|g", "c:3|g"}) {
+		t.Fatalf("unexpected packets %q", packets)
+	}
+}
+
+func TestParseStatsDTags(t *testing.T) {
+	got := parseStatsDTags(" env:prod, ,team:infra ")
+	if !slices.Equal(got, []string{"env:prod", "team:infra"}) {
+		t.Fatalf("unexpected tags %v", got)
+	}
+}
+
+func TestHostLabels(t *testing.T) {
+	prevLabels, prevHide := exportHostLabels, hideMachineID
+	t.Cleanup(func() { exportHostLabels, hideMachineID = prevLabels, prevHide })
+
+	exportHostLabels = true
+	m := MetricsSnapshot{
+		Host:      "build-01",
+		MachineID: machineID("4C4C4544-0042"),
+		Thermal:   ThermalStatus{CPUTemp: 54},
+		Sensors:   []SensorReading{{Label: "CPU Die", Value: 61}},
+	}
+	dog := formatStatsD(snapshotMetrics(m), StatsDConfig{DogStatsD: true})
+	if dog[1] != "sensor_temperature_celsius:61|g|#host:build-01,machine_id:4C4C4544-0042,sensor:CPU_Die" {
+		t.Fatalf("unexpected labelled line %q", dog[1])
+	}
+
+	hideMachineID = true
+	m.MachineID = machineID("4C4C4544-0042")
+	dog = formatStatsD(snapshotMetrics(m), StatsDConfig{DogStatsD: true})
+	if dog[0] != "cpu_temperature_celsius:54|g|#host:build-01" {
+		t.Fatalf("machine id not suppressed: %q", dog[0])
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statusPage serves the latest snapshot as a small self-refreshing HTML page, for
+// checking a headless box from a phone. Rendering is server-side with no scripts;
+// the panels are the --table sections, so both views read the same.
+type statusPage struct {
+	refresh time.Duration
+
+	mu   sync.Mutex
+	last *MetricsSnapshot
+}
+
+// run keeps the most recent snapshot until the channel closes.
+func (p *statusPage) run(snapshots <-chan MetricsSnapshot) {
+	for snap := range snapshots {
+		p.mu.Lock()
+		p.last = &snap
+		p.mu.Unlock()
+	}
+}
+
+func (p *statusPage) latest() (MetricsSnapshot, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == nil {
+		return MetricsSnapshot{}, false
+	}
+	return *p.last, true
+}
+
+// statusPanel is one titled block of the page.
+type statusPanel struct {
+	Title string
+	Body  string
+}
+
+// statusGauge is one battery's charge bar.
+type statusGauge struct {
+	Label   string
+	Percent float64
+}
+
+type statusPageData struct {
+	Refresh   int
+	Host      string
+	Collected string
+	Health    string
+	Gauges    []statusGauge
+	Panels    []statusPanel
+}
+
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{if .Host}}{{.Host}} · {{end}}Mole status</title>
+<style>
+body { font: 14px/1.4 -apple-system, system-ui, sans-serif; margin: 1rem; background: #111; color: #ddd; }
+h1 { font-size: 1.1rem; margin: 0 0 .25rem; }
+.meta { color: #888; margin-bottom: 1rem; }
+section { background: #1b1b1b; border-radius: 6px; padding: .5rem .75rem; margin-bottom: .75rem; overflow-x: auto; }
+h2 { font-size: .8rem; letter-spacing: .05em; color: #9ab; margin: 0 0 .25rem; }
+pre { margin: 0; font: 12px/1.4 ui-monospace, Menlo, monospace; }
+meter { width: 100%; height: 1rem; }
+</style>
+</head>
+<body>
+<h1>{{if .Host}}{{.Host}}{{else}}Mole status{{end}}</h1>
+<div class="meta">{{if .Collected}}Updated {{.Collected}}{{if .Health}} · {{.Health}}{{end}}{{else}}Waiting for the first sample…{{end}}</div>
+{{range .Gauges}}<section><h2>{{.Label}} · {{printf "%.0f" .Percent}}%</h2><meter min="0" max="100" low="20" high="80" optimum="100" value="{{.Percent}}"></meter></section>
+{{end}}{{range .Panels}}<section><h2>{{.Title}}</h2><pre>{{.Body}}</pre></section>
+{{end}}</body>
+</html>
+`))
+
+// pageData lays m out as gauges plus one panel per --table section.
+func (p *statusPage) pageData(m MetricsSnapshot, ok bool) statusPageData {
+	d := statusPageData{Refresh: max(int(p.refresh/time.Second), 1)}
+	if !ok {
+		return d
+	}
+	d.Host = m.Host
+	d.Collected = m.CollectedAt.Format("15:04:05")
+	d.Health = m.HealthScoreMsg
+	for _, b := range m.Batteries {
+		d.Gauges = append(d.Gauges, statusGauge{Label: b.Name + " · " + b.Status, Percent: b.Percent})
+	}
+	for section := range strings.SplitSeq(RenderTable(m, TableOptions{}), "\n\n") {
+		title, body, _ := strings.Cut(section, "\n")
+		if title != "" {
+			d.Panels = append(d.Panels, statusPanel{Title: title, Body: body})
+		}
+	}
+	return d
+}
+
+func (p *statusPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	m, ok := p.latest()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := statusPageTemplate.Execute(w, p.pageData(m, ok)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// serveSnapshotJSON returns the latest snapshot in the WebSocket encoding.
+func (p *statusPage) serveSnapshotJSON(w http.ResponseWriter, _ *http.Request) {
+	m, ok := p.latest()
+	if !ok {
+		http.Error(w, "no snapshot yet", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := encodeSnapshot(m)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(body)
+}
+
+// runStatusPage serves the HTML page on http://addr/ and the raw snapshot on
+// /snapshot.json until ctx is done. The page reloads itself every refresh.
+func runStatusPage(ctx context.Context, addr string, interval, refresh time.Duration) error {
+	page := &statusPage{refresh: refresh}
+	collectSensorReadings = true
+	go page.run(NewCollector().Stream(ctx, interval))
+
+	mux := http.NewServeMux()
+	mux.Handle("/", page)
+	mux.HandleFunc("/snapshot.json", page.serveSnapshotJSON)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusPageBeforeFirstSnapshot(t *testing.T) {
+	page := &statusPage{refresh: 5 * time.Second}
+	rec := httptest.NewRecorder()
+	page.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Waiting for the first sample") {
+		t.Fatalf("code %d body %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	page.serveSnapshotJSON(rec, httptest.NewRequest(http.MethodGet, "/snapshot.json", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("snapshot.json code = %d, want 503", rec.Code)
+	}
+}
+
+func TestStatusPageRendersPanels(t *testing.T) {
+	snapshots := make(chan MetricsSnapshot, 1)
+	snapshots <- MetricsSnapshot{
+		Host:        "nas<1>",
+		CollectedAt: time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC),
+		Batteries:   []BatteryStatus{{Name: "BAT0", Percent: 72, Status: "Discharging"}},
+		Thermal:     ThermalStatus{CPUTemp: 54.5},
+		Sensors:     []SensorReading{{Label: "Package id 0", Class: SensorClassCPU, Value: 54.5, Unit: "°C"}},
+	}
+	close(snapshots)
+	page := &statusPage{refresh: 10 * time.Second}
+	page.run(snapshots)
+
+	rec := httptest.NewRecorder()
+	page.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="10">`,
+		"nas&lt;1&gt;",
+		`value="72"`,
+		"<h2>BATTERIES</h2>",
+		"<h2>THERMAL</h2>",
+		"<h2>SENSORS</h2>",
+		"Package id 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("page lacks %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "<script") || strings.Contains(body, "\x1b[") {
+		t.Fatal("page should be plain server-rendered HTML")
+	}
+
+	rec = httptest.NewRecorder()
+	page.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown path code = %d", rec.Code)
+	}
+}
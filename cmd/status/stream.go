@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StreamDrop says what Stream does with a snapshot when the consumer hasn't
+// made room for it. Collection never waits on the consumer either way.
+type StreamDrop string
+
+const (
+	// StreamDropOldest discards the oldest unread snapshot to queue the new one,
+	// so a slow consumer always catches up to current readings.
+	StreamDropOldest StreamDrop = "oldest"
+	// StreamDropNewest skips the new snapshot and keeps the queue as it is, so a
+	// consumer sees every snapshot it had room for, in order, with gaps.
+	StreamDropNewest StreamDrop = "newest"
+)
+
+// Stream settings; set from --stream-buffer and --stream-drop.
+var (
+	streamBuffer = 1
+	streamDrop   = StreamDropOldest
+)
+
+// parseStreamDrop parses a --stream-drop value.
+func parseStreamDrop(v string) (StreamDrop, error) {
+	switch d := StreamDrop(v); d {
+	case StreamDropOldest, StreamDropNewest:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown drop policy %q (want oldest or newest)", v)
+}
+
+// Stream collects every interval and delivers snapshots until ctx is done, then
+// closes the channel. One Stream serves any number of consumers via fan-out, so
+// subprocesses run once per tick however many clients are attached. It uses the
+// --stream-buffer and --stream-drop settings; see StreamWith.
+func (c *Collector) Stream(ctx context.Context, interval time.Duration) <-chan MetricsSnapshot {
+	return c.StreamWith(ctx, interval, streamBuffer, streamDrop)
+}
+
+// StreamWith is Stream with an explicit buffer size (at least 1) and drop policy.
+// A consumer that falls behind loses snapshots per drop rather than stalling
+// collection, so one goroutine serves the stream however slow the reader is.
+// Cancelling ctx aborts the in-flight collection, and the channel is closed on
+// every exit path.
+func (c *Collector) StreamWith(ctx context.Context, interval time.Duration, buffer int, drop StreamDrop) <-chan MetricsSnapshot {
+	out := make(chan MetricsSnapshot, max(buffer, 1))
+	go func() {
+		defer close(out)
+		c.Prime(ctx, primeInterval)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			snap, _ := c.Collect(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			offerSnapshot(out, snap, drop)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return out
+}
+
+// offerSnapshot queues snap on out without blocking, applying drop when out is
+// full. Only the stream goroutine sends, so after discarding the oldest entry
+// there is always room.
+func offerSnapshot(out chan MetricsSnapshot, snap MetricsSnapshot, drop StreamDrop) {
+	select {
+	case out <- snap:
+		return
+	default:
+	}
+	if drop == StreamDropNewest {
+		return
+	}
+	select {
+	case <-out:
+	default: // The consumer just made room.
+	}
+	select {
+	case out <- snap:
+	default:
+	}
+}
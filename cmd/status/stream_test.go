@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func drainHosts(out chan MetricsSnapshot) []string {
+	var hosts []string
+	for {
+		select {
+		case s := <-out:
+			hosts = append(hosts, s.Host)
+		default:
+			return hosts
+		}
+	}
+}
+
+func TestOfferSnapshotDropPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		drop StreamDrop
+		want []string
+	}{
+		{StreamDropOldest, []string{"c", "d"}},
+		{StreamDropNewest, []string{"a", "b"}},
+	} {
+		out := make(chan MetricsSnapshot, 2)
+		for _, host := range []string{"a", "b", "c", "d"} {
+			offerSnapshot(out, MetricsSnapshot{Host: host}, tc.drop)
+		}
+		if got := drainHosts(out); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.drop, got, tc.want)
+		}
+	}
+}
+
+func TestStreamClosesOnCancelWithSlowConsumer(t *testing.T) {
+	snap := MetricsSnapshot{Host: "replayed"}
+	replaySnapshot = &snap
+	t.Cleanup(func() { replaySnapshot = nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := NewCollector().StreamWith(ctx, time.Millisecond, 3, StreamDropOldest)
+	// Never read while many ticks pass: the stream must keep collecting without blocking.
+	time.Sleep(30 * time.Millisecond)
+	if n := len(out); n != 3 {
+		t.Fatalf("queue holds %d snapshots, want the full buffer of 3", n)
+	}
+	cancel()
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("stream wasn't closed after cancel")
+		}
+	}
+}
+
+func TestParseStreamDrop(t *testing.T) {
+	if d, err := parseStreamDrop("newest"); err != nil || d != StreamDropNewest {
+		t.Fatalf("got %q, %v", d, err)
+	}
+	if _, err := parseStreamDrop("latest"); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
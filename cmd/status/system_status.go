@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemStatus is the --json document: batteries, thermal state and sensors for
+// scripts. Unlike the WebSocket snapshot, which mirrors the Go structs, its keys
+// are fixed snake_case and unavailable values are omitted rather than written as
+// 0, so a consumer can tell "no data" from a real zero.
+type SystemStatus struct {
+	CollectedAt  time.Time       `json:"collected_at"`
+	Host         string          `json:"host,omitempty"`
+	Batteries    []StatusBattery `json:"batteries,omitempty"`
+	BatteryError string          `json:"battery_error,omitempty"`
+	PowerSource  *StatusPower    `json:"power_source,omitempty"`
+	Thermal      *StatusThermal  `json:"thermal,omitempty"`
+	Sensors      []StatusSensor  `json:"sensors,omitempty"`
+	// Sections says whether each section came up with data, so a consumer can
+	// tell "sensors failed" from "no sensors" while still using the batteries.
+	Sections *StatusSections `json:"sections,omitempty"`
+}
+
+// StatusSections is the per-section availability in SystemStatus. Each section
+// succeeds or fails on its own: a failing sensor probe leaves Batteries ok.
+type StatusSections struct {
+	Batteries SectionStatus `json:"batteries"`
+	Thermal   SectionStatus `json:"thermal"`
+	Sensors   SectionStatus `json:"sensors"`
+}
+
+// StatusBattery is one battery or UPS in SystemStatus. Percent is always set;
+// everything else is omitted when the source doesn't report it.
+type StatusBattery struct {
+	Name               string       `json:"name"`
+	Kind               BatteryKind  `json:"kind,omitempty"` // "ups", or omitted for system batteries
+	Source             string       `json:"source,omitempty"`
+	Percent            float64      `json:"percent"`
+	Status             string       `json:"status,omitempty"`
+	State              BatteryState `json:"state,omitempty"`
+	TimeLeftMinutes    int          `json:"time_left_minutes,omitempty"` // OS estimate; omitted when unreliable
+	HealthPercent      float64      `json:"health_percent,omitempty"`
+	CycleCount         int          `json:"cycle_count,omitempty"`
+	CyclesRemaining    int          `json:"cycles_remaining,omitempty"`
+	VoltageV           float64      `json:"voltage_v,omitempty"`
+	CurrentA           float64      `json:"current_a,omitempty"` // Negative while discharging
+	Watts              float64      `json:"watts,omitempty"`
+	DesignCapacity     float64      `json:"design_capacity,omitempty"`
+	FullChargeCapacity float64      `json:"full_charge_capacity,omitempty"`
+	CurrentCharge      float64      `json:"current_charge,omitempty"`
+	CapacityUnit       CapacityUnit `json:"capacity_unit,omitempty"`
+	ChargeLimitPercent float64      `json:"charge_limit_percent,omitempty"`
+	DrainRate          float64      `json:"drain_rate_percent_per_hour,omitempty"`
+	Worn               bool         `json:"worn,omitempty"`
+}
+
+// StatusPower is the power source in SystemStatus, omitted when unknown.
+type StatusPower struct {
+	OnAC         bool    `json:"on_ac"`
+	AdapterWatts float64 `json:"adapter_watts,omitempty"`
+}
+
+// StatusThermal is the thermal state in SystemStatus; temperatures are °C.
+type StatusThermal struct {
+	Level             string        `json:"level,omitempty"` // nominal, fair, serious, critical
+	CPUTemp           float64       `json:"cpu_temp_c,omitempty"`
+	CPUTempSource     CPUTempSource `json:"cpu_temp_source,omitempty"`
+	CPUTempEstimated  bool          `json:"cpu_temp_estimated,omitempty"`
+	GPUTemp           float64       `json:"gpu_temp_c,omitempty"`
+	EnclosureTemp     float64       `json:"enclosure_temp_c,omitempty"`
+	FanRPM            int           `json:"fan_rpm,omitempty"`
+	FanMaxRPM         int           `json:"fan_max_rpm,omitempty"`
+	FanCount          int           `json:"fan_count,omitempty"`
+	SystemPowerW      float64       `json:"system_power_w,omitempty"`
+	AdapterPowerW     float64       `json:"adapter_power_w,omitempty"`
+	BatteryPowerW     float64       `json:"battery_power_w,omitempty"`
+	CPUPowerW         float64       `json:"cpu_power_w,omitempty"`
+	Throttling        bool          `json:"throttling,omitempty"`
+	ThrottlePercent   int           `json:"cpu_speed_limit_percent,omitempty"`
+	CriticalSustained bool          `json:"critical_sustained,omitempty"`
+	PermissionDenied  bool          `json:"permission_denied,omitempty"`
+}
+
+// StatusSensor is one sensor reading in SystemStatus. Value is always set, since
+// a fan at 0 RPM is a reading.
+type StatusSensor struct {
+	Key      string         `json:"key,omitempty"`
+	Label    string         `json:"label"`
+	Value    float64        `json:"value"`
+	Unit     string         `json:"unit,omitempty"`
+	Class    SensorClass    `json:"class,omitempty"`
+	Location SensorLocation `json:"location,omitempty"`
+}
+
+// jsonCollectors are the collectors SystemStatus draws on; CollectAll skips the rest.
+var jsonCollectors = []CollectorKind{CollectBattery, CollectUPS, CollectThermal, CollectPower, CollectSensors, CollectHost}
+
+// CollectAll collects batteries, thermal state and sensors once. Collectors
+// turned off with --disable or --only stay off. Even with an error,
+// SystemStatus.Sections says which sections are still usable.
+func CollectAll(ctx context.Context) (SystemStatus, error) {
+	m, err := collectStatusSnapshot(ctx)
+	if ctx.Err() != nil {
+		return SystemStatus{}, ctx.Err()
+	}
+	return newSystemStatus(m), err
+}
+
+// collectStatusSnapshot is one collection with only jsonCollectors enabled, for
+// --json and --csv.
+func collectStatusSnapshot(ctx context.Context) (MetricsSnapshot, error) {
+	collectSensorReadings = true
+	collector := NewCollector()
+	for _, kind := range collectorKinds {
+		if !slices.Contains(jsonCollectors, kind) {
+			collector.SetEnabled(kind, false)
+		}
+	}
+	collector.Prime(ctx, primeInterval)
+	return collector.Collect(ctx)
+}
+
+// newSystemStatus picks the --json fields out of m.
+func newSystemStatus(m MetricsSnapshot) SystemStatus {
+	s := SystemStatus{CollectedAt: m.CollectedAt, Host: m.Host}
+	if m.BatteryErr != nil {
+		s.BatteryError = m.BatteryErr.Error()
+	}
+	for _, b := range m.Batteries {
+		s.Batteries = append(s.Batteries, newStatusBattery(b))
+	}
+	if p := m.Charger; p.Known {
+		s.PowerSource = &StatusPower{OnAC: p.Connected, AdapterWatts: p.Watts}
+	}
+	if t := newStatusThermal(m.Thermal); t != (StatusThermal{}) {
+		s.Thermal = &t
+	}
+	if m.Sections != nil {
+		s.Sections = &StatusSections{
+			Batteries: m.Sections[CollectBattery],
+			Thermal:   m.Sections[CollectThermal],
+			Sensors:   m.Sections[CollectSensors],
+		}
+	}
+	for _, r := range m.Sensors {
+		s.Sensors = append(s.Sensors, StatusSensor{
+			Key:      r.Key,
+			Label:    r.Label,
+			Value:    r.Value,
+			Unit:     r.Unit,
+			Class:    r.Class,
+			Location: r.Location,
+		})
+	}
+	return s
+}
+
+func newStatusBattery(b BatteryStatus) StatusBattery {
+	out := StatusBattery{
+		Name:               b.Name,
+		Kind:               b.Kind,
+		Source:             b.Source,
+		Percent:            b.Percent,
+		Status:             b.Status,
+		State:              b.State,
+		CycleCount:         b.CycleCount,
+		VoltageV:           b.VoltageV,
+		CurrentA:           b.CurrentA,
+		Watts:              b.Watts,
+		DesignCapacity:     b.DesignCapacity,
+		FullChargeCapacity: b.FullChargeCapacity,
+		CurrentCharge:      b.CurrentCharge,
+		CapacityUnit:       b.CapacityUnit,
+		DrainRate:          b.DrainRate,
+		Worn:               b.Worn,
+	}
+	if !b.TimeLeftUnreliable {
+		out.TimeLeftMinutes = timeLeftMinutes(b.TimeLeft)
+	}
+	if health, ok := b.HealthPercent(); ok {
+		out.HealthPercent = health
+	}
+	if b.Longevity != nil {
+		out.CyclesRemaining = b.Longevity.CyclesRemaining
+	}
+	if b.ChargeLimited {
+		out.ChargeLimitPercent = b.EffectiveFullPercent
+	}
+	return out
+}
+
+func newStatusThermal(t ThermalStatus) StatusThermal {
+	out := StatusThermal{
+		CPUTemp:           t.CPUTemp,
+		CPUTempSource:     t.CPUTempSource,
+		CPUTempEstimated:  t.Estimated,
+		GPUTemp:           t.GPUTemp,
+		EnclosureTemp:     t.EnclosureTemp,
+		FanRPM:            t.FanSpeed,
+		FanMaxRPM:         t.FanMax,
+		FanCount:          t.FanCount,
+		SystemPowerW:      t.SystemPower,
+		AdapterPowerW:     t.AdapterPower,
+		BatteryPowerW:     t.BatteryPower,
+		CPUPowerW:         t.CPUPower,
+		Throttling:        t.Throttling,
+		ThrottlePercent:   t.ThrottlePercent,
+		CriticalSustained: t.CriticalSustained,
+		PermissionDenied:  t.PermissionDenied,
+	}
+	if t.Level.Severity() >= 0 {
+		out.Level = t.Level.String()
+	}
+	return out
+}
+
+// timeLeftMinutes reads an OS time-left estimate such as "2:30"; anything else,
+// including "0:00", is 0.
+func timeLeftMinutes(s string) int {
+	h, m, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0
+	}
+	hours, err1 := strconv.Atoi(h)
+	minutes, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hours < 0 || minutes < 0 || minutes > 59 {
+		return 0
+	}
+	return hours*60 + minutes
+}
+
+// writeSystemStatus writes s as indented JSON.
+func writeSystemStatus(w io.Writer, s SystemStatus) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// runJSON collects once and writes the --json document to w.
+func runJSON(ctx context.Context, w io.Writer) error {
+	s, err := CollectAll(ctx)
+	if ctx.Err() != nil {
+		return err
+	}
+	// Section failures are already reflected by omitted fields and battery_error.
+	return writeSystemStatus(w, s)
+}
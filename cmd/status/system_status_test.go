@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestSystemStatusGolden(t *testing.T) {
+	m := MetricsSnapshot{
+		CollectedAt: time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC),
+		Host:        "studio",
+		Batteries: []BatteryStatus{
+			{
+				Name: "InternalBattery-0", Percent: 72, Status: "Discharging", State: BatteryStateDischarging,
+				TimeLeft: "3:10", CycleCount: 412, Capacity: 91, VoltageV: 12.41, CurrentA: -1.25,
+				DesignCapacity: 4382, FullChargeCapacity: 3988, CurrentCharge: 2871, CapacityUnit: CapacityMAh,
+				Longevity: &BatteryLongevity{RatedCycles: 1000, CyclesRemaining: 588}, Source: "pmset",
+			},
+			// A UPS reporting nothing but its charge: every other field is omitted.
+			{Name: "ups@localhost", Kind: BatteryKindUPS, Percent: 0, TimeLeft: "0:00", TimeLeftUnreliable: true, Source: "nut"},
+		},
+		Thermal: ThermalStatus{
+			Level: ThermalLevelNominal, CPUTemp: 54.5, CPUTempSource: CPUTempSourceSMC,
+			FanSpeed: 2100, FanMax: 5900, FanCount: 1, SystemPower: 18.2,
+		},
+		Sensors: []SensorReading{
+			{Key: "TC0P", Label: "CPU Proximity", Value: 54.5, Unit: "°C", Class: SensorClassCPU, Trend: TrendRising},
+			{Key: "F0Ac", Label: "Fan 0", Value: 0, Unit: "RPM", Class: SensorClassFan},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSystemStatus(&buf, newSystemStatus(m)); err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "system_status.golden.json")
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Fatalf("--json output changed; rerun with -update if intended:\n%s", got)
+	}
+}
+
+func TestSystemStatusOmitsEmptySections(t *testing.T) {
+	var buf bytes.Buffer
+	s := newSystemStatus(MetricsSnapshot{BatteryErr: ErrNoBattery})
+	if err := writeSystemStatus(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, key := range []string{`"batteries"`, `"thermal"`, `"sensors"`} {
+		if strings.Contains(got, key) {
+			t.Errorf("expected %s to be omitted without data:\n%s", key, got)
+		}
+	}
+	if !strings.Contains(got, `"battery_error": "no battery present"`) {
+		t.Errorf("expected the battery error:\n%s", got)
+	}
+}
+
+func TestSystemStatusSectionsFailIndependently(t *testing.T) {
+	fakeBatterySources(t, batterySource{name: "fake", read: func(_ context.Context, add func(BatteryStatus)) bool {
+		add(BatteryStatus{Name: "BAT0", Percent: 64, Status: "Discharging"})
+		return true
+	}})
+	prevProbe, prevReadings := sensorProbe, collectSensorReadings
+	t.Cleanup(func() { sensorProbe, collectSensorReadings = prevProbe, prevReadings })
+	collectSensorReadings = true
+
+	for name, probe := range map[string]func(context.Context) ([]SensorReading, error){
+		"error": func(context.Context) ([]SensorReading, error) { return nil, errors.New("smc unreachable") },
+		"panic": func(context.Context) ([]SensorReading, error) { panic("bad sensor table") },
+	} {
+		sensorProbe = probe
+		c := NewCollector()
+		for _, kind := range collectorKinds {
+			c.SetEnabled(kind, kind == CollectBattery || kind == CollectSensors)
+		}
+		m, _ := c.Collect(context.Background())
+		s := newSystemStatus(m)
+
+		if len(s.Batteries) != 1 || s.Batteries[0].Percent != 64 {
+			t.Fatalf("%s: a failing sensor probe lost the battery: %+v", name, s.Batteries)
+		}
+		if s.Sections == nil || s.Sections.Batteries.State != SectionOK {
+			t.Fatalf("%s: batteries section = %+v", name, s.Sections)
+		}
+		if s.Sections.Sensors.State != SectionFailed || s.Sections.Sensors.Detail == "" {
+			t.Fatalf("%s: sensors section = %+v, want failed with a detail", name, s.Sections.Sensors)
+		}
+		if s.Sections.Thermal.State != SectionSkipped {
+			t.Fatalf("%s: thermal section = %+v, want skipped", name, s.Sections.Thermal)
+		}
+		if table := RenderTable(m, TableOptions{}); !strings.Contains(table, "SENSORS\nfailed (") {
+			t.Fatalf("%s: table should report the failed sensors:\n%s", name, table)
+		}
+	}
+}
+
+func TestTimeLeftMinutes(t *testing.T) {
+	for in, want := range map[string]int{"2:30": 150, "0:07": 7, "10:00": 600, "": 0, "(no estimate)": 0, "1:75": 0} {
+		if got := timeLeftMinutes(in); got != want {
+			t.Errorf("timeLeftMinutes(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
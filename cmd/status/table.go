@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+)
+
+// TempUnit is the unit temperatures are printed in.
+type TempUnit string
+
+const (
+	TempCelsius    TempUnit = "C"
+	TempFahrenheit TempUnit = "F"
+)
+
+// parseTempUnit accepts c/celsius and f/fahrenheit in any case.
+func parseTempUnit(v string) (TempUnit, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "c", "celsius":
+		return TempCelsius, nil
+	case "f", "fahrenheit":
+		return TempFahrenheit, nil
+	}
+	return "", fmt.Errorf("unknown temperature unit %q (want C or F)", v)
+}
+
+// Convert returns a Celsius reading in u. Readings are collected, compared
+// against thresholds and exported in Celsius; only display converts.
+func (u TempUnit) Convert(celsius float64) float64 {
+	if u == TempFahrenheit {
+		return CelsiusToFahrenheit(celsius)
+	}
+	return celsius
+}
+
+// Symbol is u's suffix, "°C" or "°F".
+func (u TempUnit) Symbol() string {
+	if u == TempFahrenheit {
+		return "°F"
+	}
+	return "°C"
+}
+
+// letter is u's bare unit letter for ASCII-only output.
+func (u TempUnit) letter() string {
+	if u == TempFahrenheit {
+		return "F"
+	}
+	return "C"
+}
+
+// format renders a Celsius reading in u, e.g. "54.5°C" or "130.1°F".
+func (u TempUnit) format(celsius float64) string {
+	return u.formatPrec(celsius, 1)
+}
+
+// formatPrec is format with prec decimals.
+func (u TempUnit) formatPrec(celsius float64, prec int) string {
+	return strconv.FormatFloat(u.Convert(celsius), 'f', prec, 64) + u.Symbol()
+}
+
+// displayTempUnit is the unit every human-facing output prints temperatures in:
+// the UI, --table and --prompt. Set from --temp-unit.
+var displayTempUnit = TempCelsius
+
+// TableOptions configures RenderTable.
+type TableOptions struct {
+	Width    int      // Maximum line width; 0 means unlimited
+	Color    bool     // Colorize values; only sensible when writing to a terminal
+	TempUnit TempUnit // Defaults to Celsius
+	// SensorSummary lists one row per sensor class (count, min, max, avg)
+	// instead of every sensor.
+	SensorSummary bool
+}
+
+// tableMinColumn is the narrowest a column is squeezed to before lines overflow.
+const tableMinColumn = 4
+
+// tableCell is one value; color, when set, styles the padded text.
+type tableCell struct {
+	text  string
+	color func(string) string
+}
+
+// RenderTable lays out batteries, thermal state and sensors as aligned, headed
+// columns for non-interactive output such as a pager. Empty sections are omitted.
+func RenderTable(m MetricsSnapshot, opts TableOptions) string {
+	unit := opts.TempUnit
+	if unit == "" {
+		unit = TempCelsius
+	}
+	tempCell := func(c float64) tableCell {
+		return tableCell{text: unit.format(c), color: func(s string) string { return tempStyle(c).Render(s) }}
+	}
+
+	var sections []string
+	add := func(title string, headers []string, rows [][]tableCell) {
+		if len(rows) == 0 {
+			return
+		}
+		lines := append([]string{title}, layoutTable(headers, rows, opts)...)
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	// A section that failed says so instead of vanishing, so a sensor error
+	// doesn't read as "no sensors" next to good battery rows.
+	addFailed := func(title string, kind CollectorKind, rows [][]tableCell) {
+		if st := m.Sections[kind]; len(rows) == 0 && st.State == SectionFailed {
+			sections = append(sections, title+"\n"+st.String())
+		}
+	}
+
+	var batteries [][]tableCell
+	for _, b := range m.Batteries {
+		level := tableCell{text: fmt.Sprintf("%.0f%%", b.Percent)}
+		pct := b.Percent
+		level.color = func(s string) string { return colorizeBattery(pct, s) }
+		health := ""
+		if h, ok := b.HealthPercent(); ok {
+			health = fmt.Sprintf("%.0f%%", h)
+		}
+		cycles := ""
+		if b.CycleCount > 0 {
+			cycles = strconv.Itoa(b.CycleCount)
+		}
+		timeLeft := b.TimeLeft
+		if b.TimeLeftUnreliable {
+			timeLeft = "?"
+		}
+		batteries = append(batteries, []tableCell{
+			{text: b.Name}, level, {text: b.Status}, {text: timeLeft}, {text: health}, {text: cycles}, {text: b.Source},
+		})
+	}
+	add("BATTERIES", []string{"NAME", "LEVEL", "STATUS", "TIME", "HEALTH", "CYCLES", "SOURCE"}, batteries)
+	addFailed("BATTERIES", CollectBattery, batteries)
+
+	var longevity [][]tableCell
+	for _, b := range m.Batteries {
+		if l := b.Longevity; l != nil {
+			months := longevityUnknown
+			if n, ok := l.MonthsRemaining(); ok {
+				months = fmt.Sprintf("~%.0f", n)
+			}
+			longevity = append(longevity, []tableCell{
+				{text: b.Name}, {text: strconv.Itoa(l.RatedCycles)}, {text: strconv.Itoa(l.CyclesRemaining)}, {text: months},
+			})
+		}
+	}
+	add("BATTERY LONGEVITY", []string{"NAME", "RATED", "CYCLES LEFT", "MONTHS LEFT"}, longevity)
+
+	var controllers [][]tableCell
+	for _, b := range m.Batteries {
+		if c := b.Controller; c != nil {
+			controllers = append(controllers, []tableCell{
+				{text: b.Name}, {text: c.Manufacturer}, {text: c.Model}, {text: c.Firmware}, {text: c.Chemistry}, {text: c.Serial},
+			})
+		}
+	}
+	add("BATTERY CONTROLLERS", []string{"NAME", "VENDOR", "MODEL", "FIRMWARE", "CHEMISTRY", "SERIAL"}, controllers)
+
+	var thermal [][]tableCell
+	t := m.Thermal
+	if t.CPUTemp > 0 {
+		cpu := tempCell(t.CPUTemp)
+		if t.Estimated {
+			cpu.text = "~" + cpu.text
+		}
+		thermal = append(thermal, []tableCell{{text: "CPU"}, cpu, {text: t.CPUTempTrend.Arrow()}})
+	}
+	if t.GPUTemp > 0 {
+		thermal = append(thermal, []tableCell{{text: "GPU"}, tempCell(t.GPUTemp), {}})
+	}
+	if t.EnclosureTemp > 0 {
+		thermal = append(thermal, []tableCell{{text: "Enclosure"}, tempCell(t.EnclosureTemp), {}})
+	}
+	if t.FanSpeed > 0 {
+		thermal = append(thermal, []tableCell{{text: "Fan"}, {text: fmt.Sprintf("%d RPM", t.FanSpeed)}, {text: string(t.FanNoise)}})
+	}
+	if t.CPUPower > 0 {
+		thermal = append(thermal, []tableCell{{text: "CPU power"}, {text: fmt.Sprintf("%.1f W", t.CPUPower)}, {}})
+	}
+	if t.Level != ThermalLevelUnknown {
+		thermal = append(thermal, []tableCell{{text: "Pressure"}, {text: t.Level.String()}, {}})
+	}
+	add("THERMAL", []string{"SOURCE", "VALUE", "TREND"}, thermal)
+	addFailed("THERMAL", CollectThermal, thermal)
+
+	sensorCell := func(v float64, unit string) tableCell {
+		if unit == "°C" {
+			return tempCell(v)
+		}
+		return tableCell{text: SensorReading{Value: v, Unit: unit}.DisplayValue()}
+	}
+	var sensorRows [][]tableCell
+	if opts.SensorSummary {
+		for _, s := range SummarizeSensors(m.Sensors) {
+			sensorRows = append(sensorRows, []tableCell{
+				{text: string(s.Class)}, {text: strconv.Itoa(s.Count)},
+				sensorCell(s.Min, s.Unit), sensorCell(s.Max, s.Unit), sensorCell(s.Avg, s.Unit),
+			})
+		}
+		add("SENSORS", []string{"CLASS", "SENSORS", "MIN", "MAX", "AVG"}, sensorRows)
+	} else {
+		for _, s := range m.Sensors {
+			sensorRows = append(sensorRows, []tableCell{{text: s.Label}, {text: string(s.Class)}, sensorCell(s.Value, s.Unit), {text: s.Trend.Arrow()}})
+		}
+		add("SENSORS", []string{"LABEL", "CLASS", "VALUE", "TREND"}, sensorRows)
+	}
+	addFailed("SENSORS", CollectSensors, sensorRows)
+
+	// Only --sensor-locations sets Location, so this section is absent by default.
+	var locationRows [][]tableCell
+	for _, s := range SummarizeSensorsByLocation(m.Sensors) {
+		locationRows = append(locationRows, []tableCell{
+			{text: string(s.Location)}, {text: strconv.Itoa(s.Count)}, tempCell(s.Min), tempCell(s.Max), tempCell(s.Avg),
+		})
+	}
+	add("SENSOR LOCATIONS", []string{"LOCATION", "SENSORS", "MIN", "MAX", "AVG"}, locationRows)
+
+	return strings.Join(sections, "\n\n")
+}
+
+// layoutTable pads every column to its widest cell and, when the table is wider
+// than opts.Width, narrows the widest columns first, truncating with "…".
+func layoutTable(headers []string, rows [][]tableCell, opts TableOptions) []string {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = lipgloss.Width(h)
+	}
+	for _, row := range rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], lipgloss.Width(c.text))
+		}
+	}
+	if opts.Width > 0 {
+		for total := tableWidth(widths); total > opts.Width; total-- {
+			widest := 0
+			for i, w := range widths {
+				if w > widths[widest] {
+					widest = i
+				}
+			}
+			if widths[widest] <= tableMinColumn {
+				break
+			}
+			widths[widest]--
+		}
+	}
+
+	line := func(cells []tableCell) string {
+		parts := make([]string, len(cells))
+		for i, c := range cells {
+			text := truncateCell(c.text, widths[i])
+			padded := text + strings.Repeat(" ", widths[i]-lipgloss.Width(text))
+			if opts.Color && c.color != nil && c.text != "" {
+				padded = c.color(padded)
+			}
+			parts[i] = padded
+		}
+		return strings.TrimRight(strings.Join(parts, "  "), " ")
+	}
+	head := make([]tableCell, len(headers))
+	for i, h := range headers {
+		head[i] = tableCell{text: h}
+	}
+	out := []string{line(head)}
+	for _, row := range rows {
+		out = append(out, line(row))
+	}
+	return out
+}
+
+// tableWidth is the line width for the given columns with two-space gutters.
+func tableWidth(widths []int) int {
+	total := 2 * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+// truncateCell shortens s to width display cells, ending in "…" when cut.
+func truncateCell(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// runTable collects one snapshot and writes it as a table. Color and width
+// follow the terminal; piped output is plain and as wide as it needs to be.
+func runTable(ctx context.Context, w io.Writer) {
+	collectSensorReadings = true
+	collector := NewCollector()
+	collector.Prime(ctx, primeInterval)
+	data, _ := collector.Collect(ctx)
+	opts := TableOptions{TempUnit: displayTempUnit, SensorSummary: sensorSummaryOnly}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(f.Fd()) {
+		opts.Color = true
+		if width, _, err := term.GetSize(f.Fd()); err == nil {
+			opts.Width = width
+		}
+	}
+	fmt.Fprintln(w, RenderTable(data, opts))
+}
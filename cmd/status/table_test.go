@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTable(t *testing.T) {
+	m := MetricsSnapshot{
+		Batteries: []BatteryStatus{{Name: "InternalBattery-0", Percent: 72, Status: "discharging", TimeLeft: "3:10", CycleCount: 120, Capacity: 91, Source: "pmset"}},
+		Thermal:   ThermalStatus{CPUTemp: 54.5, FanSpeed: 2100, FanNoise: FanNoise("Quiet"), Level: ThermalLevelNominal},
+		Sensors:   []SensorReading{{Label: "CPU Die", Value: 61, Unit: "°C", Class: SensorClassCPU, Trend: TrendRising}},
+	}
+	got := RenderTable(m, TableOptions{TempUnit: TempFahrenheit})
+	want := `BATTERIES
+NAME               LEVEL  STATUS       TIME  HEALTH  CYCLES  SOURCE
+InternalBattery-0  72%    discharging  3:10  91%     120     pmset
+
+THERMAL
+SOURCE    VALUE     TREND
+CPU       130.1°F
+Fan       2100 RPM  Quiet
+Pressure  nominal
+
+SENSORS
+LABEL    CLASS  VALUE    TREND
+CPU Die  CPU    141.8°F  ↑`
+	if got != want {
+		t.Fatalf("unexpected table:\n%s\nwant:\n%s", got, want)
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Fatal("uncolored table must not contain escape codes")
+	}
+}
+
+func TestRenderTableSensorSummary(t *testing.T) {
+	m := MetricsSnapshot{Sensors: []SensorReading{
+		{Label: "Core 0", Value: 52, Unit: "°C", Class: SensorClassCPU},
+		{Label: "Core 1", Value: 61, Unit: "°C", Class: SensorClassCPU},
+		{Label: "Fan 1", Value: 1200, Unit: "RPM", Class: SensorClassFan},
+	}}
+	got := RenderTable(m, TableOptions{SensorSummary: true})
+	want := `SENSORS
+CLASS  SENSORS  MIN         MAX         AVG
+CPU    2        52.0°C      61.0°C      56.5°C
+Fan    1        1200.0 RPM  1200.0 RPM  1200.0 RPM`
+	if got != want {
+		t.Fatalf("unexpected summary:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderTableWidth(t *testing.T) {
+	m := MetricsSnapshot{Sensors: []SensorReading{{Label: "A very long sensor label indeed", Value: 40, Unit: "°C", Class: SensorClassOther}}}
+	for line := range strings.Lines(RenderTable(m, TableOptions{Width: 30})) {
+		if w := len([]rune(strings.TrimSuffix(line, "\n"))); w > 30 {
+			t.Fatalf("line wider than 30: %q (%d)", line, w)
+		}
+	}
+	if !strings.Contains(RenderTable(m, TableOptions{Width: 30}), "…") {
+		t.Fatal("expected the long label to be truncated")
+	}
+}
+
+func TestRenderTableRoundsSensorValues(t *testing.T) {
+	m := MetricsSnapshot{Sensors: []SensorReading{{Label: "Fan 1", Value: 1234.5678, Unit: "RPM", Class: SensorClassFan}}}
+	if got := RenderTable(m, TableOptions{}); !strings.Contains(got, "Fan 1  Fan    1234.6 RPM") {
+		t.Fatalf("fan reading not rounded through DisplayValue:\n%s", got)
+	}
+}
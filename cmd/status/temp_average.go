@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// tempSamples is how many reads collectThermal and collectSensors average over
+// tempSampleWindow. 1 keeps the default single instantaneous read.
+var (
+	tempSamples      = 1
+	tempSampleWindow = 300 * time.Millisecond
+)
+
+// sampleSpacing is the pause between reads that spreads n samples across window.
+func sampleSpacing(n int, window time.Duration) time.Duration {
+	if n <= 1 {
+		return 0
+	}
+	return window / time.Duration(n-1)
+}
+
+// sampleN calls read up to n times, spaced across window, stopping early if ctx
+// ends. It always returns at least one result.
+func sampleN[T any](ctx context.Context, n int, window time.Duration, read func(context.Context) T) []T {
+	results := []T{read(ctx)}
+	spacing := sampleSpacing(n, window)
+	for len(results) < n {
+		select {
+		case <-ctx.Done():
+			return results
+		case <-time.After(spacing):
+		}
+		results = append(results, read(ctx))
+	}
+	return results
+}
+
+// averageThermal reads thermal state tempSamples times and averages the
+// temperatures; everything else comes from the last read.
+func averageThermal(ctx context.Context, read func(context.Context) ThermalStatus) ThermalStatus {
+	reads := sampleN(ctx, tempSamples, tempSampleWindow, read)
+	out := reads[len(reads)-1]
+	if len(reads) == 1 {
+		return out
+	}
+	out.CPUTemp = averageNonZero(reads, func(t ThermalStatus) float64 { return t.CPUTemp })
+	out.GPUTemp = averageNonZero(reads, func(t ThermalStatus) float64 { return t.GPUTemp })
+	zones := make([][]SensorReading, len(reads))
+	for i, t := range reads {
+		zones[i] = t.Zones
+	}
+	out.Zones = averageReadings(zones)
+	return out
+}
+
+// averageSensors reads sensors tempSamples times and averages each label.
+func averageSensors(ctx context.Context, read func(context.Context) ([]SensorReading, error)) ([]SensorReading, error) {
+	type result struct {
+		readings []SensorReading
+		err      error
+	}
+	reads := sampleN(ctx, tempSamples, tempSampleWindow, func(ctx context.Context) result {
+		r, err := read(ctx)
+		return result{r, err}
+	})
+	last := reads[len(reads)-1]
+	if len(reads) == 1 {
+		return last.readings, last.err
+	}
+	all := make([][]SensorReading, len(reads))
+	for i, r := range reads {
+		all[i] = r.readings
+	}
+	return averageReadings(all), last.err
+}
+
+// averageNonZero averages field over reads, skipping reads where it was unavailable.
+func averageNonZero[T any](reads []T, field func(T) float64) float64 {
+	var sum float64
+	var n int
+	for _, r := range reads {
+		if v := field(r); v > 0 {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// averageReadings averages readings by Label. The layout is the last read's,
+// so a sensor that dropped out mid-window is not resurrected.
+func averageReadings(reads [][]SensorReading) []SensorReading {
+	if len(reads) == 0 {
+		return nil
+	}
+	type acc struct {
+		sum float64
+		n   int
+	}
+	sums := map[string]*acc{}
+	for _, readings := range reads {
+		for _, r := range readings {
+			a, ok := sums[r.Label]
+			if !ok {
+				a = &acc{}
+				sums[r.Label] = a
+			}
+			a.sum += r.Value
+			a.n++
+		}
+	}
+	last := reads[len(reads)-1]
+	out := make([]SensorReading, len(last))
+	for i, r := range last {
+		a := sums[r.Label]
+		r.Value = a.sum / float64(a.n)
+		out[i] = r
+	}
+	return out
+}
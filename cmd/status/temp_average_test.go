@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAverageThermal(t *testing.T) {
+	prevN, prevWindow := tempSamples, tempSampleWindow
+	t.Cleanup(func() { tempSamples, tempSampleWindow = prevN, prevWindow })
+	tempSamples, tempSampleWindow = 3, time.Millisecond
+
+	temps := []float64{50, 0, 56}
+	var calls int
+	read := func(context.Context) ThermalStatus {
+		v := temps[calls]
+		calls++
+		return ThermalStatus{CPUTemp: v, FanSpeed: calls, Zones: []SensorReading{{Label: "acpitz", Value: v + 10}}}
+	}
+	got := averageThermal(context.Background(), read)
+	if calls != 3 {
+		t.Fatalf("want 3 reads, got %d", calls)
+	}
+	// The missed read is skipped for CPUTemp but zone readings average every sample.
+	if got.CPUTemp != 53 || got.FanSpeed != 3 || got.Zones[0].Value != 136.0/3 {
+		t.Fatalf("unexpected average %+v", got)
+	}
+}
+
+func TestAverageSensorsSingleShot(t *testing.T) {
+	var calls int
+	readings, err := averageSensors(context.Background(), func(context.Context) ([]SensorReading, error) {
+		calls++
+		return []SensorReading{{Label: "CPU Die", Value: 61}}, nil
+	})
+	if err != nil || calls != 1 || readings[0].Value != 61 {
+		t.Fatalf("default must be a single read: calls=%d readings=%v err=%v", calls, readings, err)
+	}
+}
+
+func TestAverageReadingsKeepsLastLayout(t *testing.T) {
+	got := averageReadings([][]SensorReading{
+		{{Label: "a", Value: 40}, {Label: "gone", Value: 90}},
+		{{Label: "a", Value: 44}},
+	})
+	if len(got) != 1 || got[0].Label != "a" || got[0].Value != 42 {
+		t.Fatalf("unexpected readings %+v", got)
+	}
+}
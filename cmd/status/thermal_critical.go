@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sustained critical temperature settings; set from --critical-temp and
+// --critical-samples. A single spike past the limit is normal under burst load,
+// so the warning needs several consecutive samples above it.
+var (
+	criticalTempCelsius = 95.0
+	criticalTempSamples = 3
+)
+
+// criticalTempTracker counts consecutive samples whose hottest temperature is at or
+// above criticalTempCelsius.
+type criticalTempTracker struct {
+	streak int
+}
+
+// observe sets CriticalSustained and CriticalPeak on t from the hottest of CPUTemp
+// and the temperature sensors. A wake restarts the count, since the gap says nothing
+// about what the temperature did in between.
+func (c *criticalTempTracker) observe(t *ThermalStatus, hottest SensorReading, afterWake bool) {
+	peak := t.CPUTemp
+	if hottest.Unit == "°C" && hottest.Value > peak {
+		peak = hottest.Value
+	}
+	if afterWake {
+		c.streak = 0
+	}
+	if peak > 0 && peak >= criticalTempCelsius {
+		c.streak++
+	} else {
+		c.streak = 0
+	}
+	t.CriticalSustained = c.streak >= max(criticalTempSamples, 1)
+	if t.CriticalSustained {
+		t.CriticalPeak = peak
+	}
+}
+
+// renderCriticalWarning is the banner shown above everything else while the
+// temperature stays critical; "" otherwise.
+func renderCriticalWarning(t ThermalStatus, width int) string {
+	if !t.CriticalSustained {
+		return ""
+	}
+	lines := []string{
+		fmt.Sprintf("CRITICAL TEMPERATURE: %s for %d+ samples", displayTempUnit.formatPrec(t.CriticalPeak, 0), criticalTempSamples),
+		"Cooling may be failing. Save your work and shut down.",
+	}
+	w := max(width, 0)
+	for i, l := range lines {
+		lines[i] = dangerStyle.Width(w).Render(l)
+	}
+	return strings.Join(lines, "\n")
+}
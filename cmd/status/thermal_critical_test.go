@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestCriticalTempNeedsConsecutiveSamples(t *testing.T) {
+	oldTemp, oldSamples := criticalTempCelsius, criticalTempSamples
+	criticalTempCelsius, criticalTempSamples = 95, 3
+	t.Cleanup(func() { criticalTempCelsius, criticalTempSamples = oldTemp, oldSamples })
+
+	var c criticalTempTracker
+	observe := func(cpu, sensor float64, afterWake bool) ThermalStatus {
+		th := ThermalStatus{CPUTemp: cpu}
+		c.observe(&th, SensorReading{Label: "Heatsink", Value: sensor, Unit: "°C"}, afterWake)
+		return th
+	}
+	if th := observe(99, 0, false); th.CriticalSustained {
+		t.Fatal("a single spike should not raise the warning")
+	}
+	observe(70, 0, false) // Spike over; the count restarts
+	observe(96, 0, false)
+	observe(80, 97, false) // A hot sensor counts as much as the CPU
+	th := observe(96, 0, false)
+	if !th.CriticalSustained || th.CriticalPeak != 96 {
+		t.Fatalf("want sustained warning at 96°C, got %+v", th)
+	}
+	if renderCriticalWarning(th, 80) == "" {
+		t.Fatal("want a banner while the warning is active")
+	}
+	if th := observe(96, 0, true); th.CriticalSustained {
+		t.Fatal("a wake should restart the count")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cpuSysfsRoot holds cpuN/thermal_throttle on Linux x86.
+const cpuSysfsRoot = "/sys/devices/system/cpu"
+
+// readThrottleCount sums every CPU's core and package thermal throttle counters,
+// which the kernel bumps each time the CPU is throttled. ok is false when the
+// platform has no such counters.
+func readThrottleCount(root string) (total int64, ok bool) {
+	dirs, _ := filepath.Glob(filepath.Join(root, "cpu[0-9]*", "thermal_throttle"))
+	for _, dir := range dirs {
+		for _, name := range []string{"core_throttle_count", "package_throttle_count"} {
+			if n, found := readSysfsInt(filepath.Join(dir, name)); found {
+				total += n
+				ok = true
+			}
+		}
+	}
+	return total, ok
+}
+
+// pmsetThermTimeout bounds `pmset -g therm`.
+const pmsetThermTimeout = time.Second
+
+// readCPUSpeedLimit returns the percent of full clock speed macOS currently allows,
+// from `pmset -g therm`. Only Intel Macs report it; ok is false elsewhere.
+func readCPUSpeedLimit(ctx context.Context) (int, bool) {
+	ctx, cancel := context.WithTimeout(ctx, pmsetThermTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "pmset", "-g", "therm")
+	if err != nil {
+		return 0, false
+	}
+	return parseCPUSpeedLimit(out)
+}
+
+// parseCPUSpeedLimit reads the "CPU_Speed_Limit = 100" line of `pmset -g therm`.
+func parseCPUSpeedLimit(out string) (int, bool) {
+	for line := range strings.Lines(out) {
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) != "CPU_Speed_Limit" {
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit <= 0 || limit > 100 {
+			return 0, false
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+// throttleMeter accumulates throttled time over a session from the per-tick signal.
+type throttleMeter struct {
+	occurred  bool
+	total     time.Duration
+	lastAt    time.Time
+	lastCount int64
+	hasCount  bool
+}
+
+// observe sets t.Throttling for this tick and adds the interval since the previous
+// tick to the throttled total when it is set. A rising Linux counter means the CPU
+// throttled since the last tick; elsewhere the OS thermal state is taken as is.
+// An interval spanning a sleep, or longer than energyMaxGap, isn't counted.
+func (m *throttleMeter) observe(t *ThermalStatus, now time.Time, afterWake bool) {
+	if t.ThrottleCount > 0 || m.hasCount {
+		if m.hasCount && t.ThrottleCount > m.lastCount {
+			t.Throttling = true
+		}
+		m.lastCount, m.hasCount = t.ThrottleCount, true
+	}
+	if t.Throttling {
+		m.occurred = true
+		if !m.lastAt.IsZero() && !afterWake {
+			if gap := now.Sub(m.lastAt); gap > 0 && gap <= energyMaxGap {
+				m.total += gap
+			}
+		}
+	}
+	m.lastAt = now
+}
+
+// reset forgets the session's throttling; the counter baseline is kept so the
+// next tick doesn't read every past event as new.
+func (m *throttleMeter) reset() {
+	m.occurred, m.total = false, 0
+}
+
+// ResetThrottle clears Throttled and ThrottledFor, e.g. before a benchmark run.
+func (c *Collector) ResetThrottle() {
+	c.throttle.reset()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadThrottleCount(t *testing.T) {
+	root := t.TempDir()
+	if _, ok := readThrottleCount(root); ok {
+		t.Fatal("no counters should read as unavailable")
+	}
+	writeSysfs(t, root, "cpu0/thermal_throttle/core_throttle_count", "3\n")
+	writeSysfs(t, root, "cpu0/thermal_throttle/package_throttle_count", "5\n")
+	writeSysfs(t, root, "cpu1/thermal_throttle/core_throttle_count", "2\n")
+	writeSysfs(t, root, "cpufreq/policy0/scaling_cur_freq", "2400000\n")
+	if got, ok := readThrottleCount(root); !ok || got != 10 {
+		t.Fatalf("readThrottleCount = %d, %v; want 10", got, ok)
+	}
+}
+
+func TestThrottleMeterAccumulates(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var m throttleMeter
+	tick := func(at time.Duration, count int64, afterWake bool) ThermalStatus {
+		th := ThermalStatus{ThrottleCount: count}
+		m.observe(&th, start.Add(at), afterWake)
+		return th
+	}
+
+	// The first reading is only a baseline, however high the counter already is.
+	if th := tick(0, 40, false); th.Throttling || m.occurred {
+		t.Fatal("baseline tick must not count as throttling")
+	}
+	tick(2*time.Second, 40, false)
+	if th := tick(4*time.Second, 42, false); !th.Throttling {
+		t.Fatal("rising counter should mark the tick as throttling")
+	}
+	tick(6*time.Second, 45, false)
+	if !m.occurred || m.total != 4*time.Second {
+		t.Fatalf("throttled %v, occurred %v; want 4s", m.total, m.occurred)
+	}
+
+	// A sleep gap isn't throttled time even if the counter rose across it.
+	tick(2*time.Hour, 50, true)
+	if m.total != 4*time.Second {
+		t.Fatalf("sleep gap counted: %v", m.total)
+	}
+
+	m.reset()
+	if th := tick(2*time.Hour+2*time.Second, 50, false); th.Throttling || m.occurred || m.total != 0 {
+		t.Fatalf("after reset: %+v", m)
+	}
+
+	// The OS thermal state (macOS) is used as reported.
+	var mac throttleMeter
+	mac.observe(&ThermalStatus{Throttling: true}, start, false)
+	mac.observe(&ThermalStatus{Throttling: true}, start.Add(3*time.Second), false)
+	if mac.total != 3*time.Second {
+		t.Fatalf("macOS throttled %v, want 3s", mac.total)
+	}
+}
+
+func TestParseCPUSpeedLimit(t *testing.T) {
+	throttled := "Note: No thermal warning level has been recorded\n" +
+		"2026-10-14 09:12:44 +0200 CPU Power notify\n" +
+		"\tCPU_Scheduler_Limit \t= 100\n" +
+		"\tCPU_Available_CPUs \t= 8\n" +
+		"\tCPU_Speed_Limit \t= 63\n"
+	if got, ok := parseCPUSpeedLimit(throttled); !ok || got != 63 {
+		t.Fatalf("parseCPUSpeedLimit = %d, %v; want 63", got, ok)
+	}
+	// Apple Silicon prints only the notes.
+	if _, ok := parseCPUSpeedLimit("Note: No thermal warning level has been recorded\n"); ok {
+		t.Fatal("no CPU_Speed_Limit line should read as unknown")
+	}
+	if _, ok := parseCPUSpeedLimit("\tCPU_Speed_Limit \t= 0\n"); ok {
+		t.Fatal("a 0% limit is garbage, not a stopped CPU")
+	}
+}
+
+func TestReadCPUSpeedLimitRunsPmset(t *testing.T) {
+	prev := cmdRunner
+	t.Cleanup(func() { cmdRunner = prev })
+	var ran string
+	cmdRunner = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		ran = strings.Join(append([]string{name}, args...), " ")
+		return "\tCPU_Speed_Limit \t= 100\n", nil
+	}
+	if got, ok := readCPUSpeedLimit(context.Background()); !ok || got != 100 {
+		t.Fatalf("readCPUSpeedLimit = %d, %v; want 100", got, ok)
+	}
+	if ran != "pmset -g therm" {
+		t.Fatalf("ran %q", ran)
+	}
+}
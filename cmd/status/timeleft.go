@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// timeLeftSettle is how long after a wake the OS time estimate is treated as
+// stale; pmset needs a minute or two of discharge to re-estimate.
+const timeLeftSettle = 2 * time.Minute
+
+// timeLeftZeroFloor is the charge above which a "0:00" estimate can't be right.
+const timeLeftZeroFloor = 5.0
+
+// timeLeftGuard flags OS time estimates that are known to mislead: for a while
+// after the machine wakes, and "0:00" on a discharging battery that clearly isn't
+// empty, which macOS reports while the display sleeps or before it has an
+// estimate. On AC, "0:00" just means nothing is left to charge.
+type timeLeftGuard struct {
+	settleUntil time.Time
+}
+
+// observe sets TimeLeftUnreliable on every battery with an estimate it doesn't
+// trust. Batteries must already have their State.
+func (g *timeLeftGuard) observe(batts []BatteryStatus, now time.Time, afterWake bool) {
+	if afterWake {
+		g.settleUntil = now.Add(timeLeftSettle)
+	}
+	settling := now.Before(g.settleUntil)
+	for i := range batts {
+		b := &batts[i]
+		if b.TimeLeft == "" || b.Kind == BatteryKindUPS {
+			continue
+		}
+		discharging := b.State == BatteryStateDischarging
+		zero := discharging && b.TimeLeft == "0:00" && b.Percent > timeLeftZeroFloor
+		b.TimeLeftUnreliable = zero || (settling && discharging)
+	}
+}
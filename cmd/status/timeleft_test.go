@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeLeftGuardFlagsZeroOnHealthyBattery(t *testing.T) {
+	var g timeLeftGuard
+	batts := []BatteryStatus{
+		{Name: "InternalBattery-0", Percent: 80, Status: "discharging", State: BatteryStateDischarging, TimeLeft: "0:00"},
+		{Name: "BAT1", Percent: 3, Status: "discharging", State: BatteryStateDischarging, TimeLeft: "0:00"},
+	}
+	g.observe(batts, time.Now(), false)
+	if !batts[0].TimeLeftUnreliable {
+		t.Fatal("0:00 at 80% should be flagged")
+	}
+	if batts[1].TimeLeftUnreliable {
+		t.Fatal("0:00 on a nearly empty battery is plausible")
+	}
+}
+
+func TestTimeLeftGuardTrustsZeroWhenCharged(t *testing.T) {
+	var g timeLeftGuard
+	// pmset on a full Mac on AC: "100%; charged; 0:00 remaining".
+	batts := []BatteryStatus{{Name: "InternalBattery-0", Percent: 100, Status: "charged", State: BatteryStateFull, TimeLeft: "0:00"}}
+	g.observe(batts, time.Now(), false)
+	if batts[0].TimeLeftUnreliable {
+		t.Fatal("0:00 on a charged battery should not read as an estimate in progress")
+	}
+}
+
+func TestTimeLeftGuardSettlesAfterWake(t *testing.T) {
+	var g timeLeftGuard
+	now := time.Now()
+	batt := func() []BatteryStatus {
+		return []BatteryStatus{{Percent: 60, Status: "discharging", State: BatteryStateDischarging, TimeLeft: "4:12"}}
+	}
+
+	b := batt()
+	g.observe(b, now, false)
+	if b[0].TimeLeftUnreliable {
+		t.Fatal("estimate flagged without a wake")
+	}
+	b = batt()
+	g.observe(b, now.Add(time.Second), true)
+	if !b[0].TimeLeftUnreliable {
+		t.Fatal("estimate right after wake should be flagged")
+	}
+	b = batt()
+	g.observe(b, now.Add(time.Minute), false)
+	if !b[0].TimeLeftUnreliable {
+		t.Fatal("estimate should stay flagged while pmset re-estimates")
+	}
+	b = batt()
+	g.observe(b, now.Add(time.Second+timeLeftSettle), false)
+	if b[0].TimeLeftUnreliable {
+		t.Fatal("estimate should be trusted again once settled")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+// Trend is the short-term direction of a temperature reading.
+type Trend int
+
+const (
+	TrendUnknown Trend = iota // Fewer than trendMinSamples readings so far
+	TrendSteady
+	TrendRising
+	TrendFalling
+)
+
+const (
+	// trendWindow is how many recent samples a trend is judged over.
+	trendWindow = 5
+	// trendMinSamples is the fewest samples that can show a direction.
+	trendMinSamples = 3
+	// trendTolerance is the change in degrees across the window that still reads as steady.
+	trendTolerance = 1.0
+)
+
+// cpuTempTrendKey keys CPUTemp in the trend tracker; sensor keys are prettified labels.
+const cpuTempTrendKey = "\x00cpu"
+
+func (t Trend) String() string {
+	switch t {
+	case TrendSteady:
+		return "steady"
+	case TrendRising:
+		return "rising"
+	case TrendFalling:
+		return "falling"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the trend by name.
+func (t Trend) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes a name from MarshalText; anything else is unknown.
+func (t *Trend) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "steady":
+		*t = TrendSteady
+	case "rising":
+		*t = TrendRising
+	case "falling":
+		*t = TrendFalling
+	default:
+		*t = TrendUnknown
+	}
+	return nil
+}
+
+// Arrow returns ↑, ↓ or →, or "" while the trend is unknown.
+func (t Trend) Arrow() string {
+	switch t {
+	case TrendSteady:
+		return "→"
+	case TrendRising:
+		return "↑"
+	case TrendFalling:
+		return "↓"
+	default:
+		return ""
+	}
+}
+
+// trendFromSamples compares the newest sample with the mean of the older ones,
+// so a single noisy reading at the start of the window doesn't set the direction.
+func trendFromSamples(samples []float64) Trend {
+	if len(samples) < trendMinSamples {
+		return TrendUnknown
+	}
+	older := samples[:len(samples)-1]
+	var sum float64
+	for _, v := range older {
+		sum += v
+	}
+	delta := samples[len(samples)-1] - sum/float64(len(older))
+	switch {
+	case delta > trendTolerance:
+		return TrendRising
+	case delta < -trendTolerance:
+		return TrendFalling
+	default:
+		return TrendSteady
+	}
+}
+
+// trendTracker keeps a short history per sensor label.
+type trendTracker struct {
+	history map[string]*RingBuffer
+}
+
+// observe records v under key and returns the trend including it.
+func (t *trendTracker) observe(key string, v float64) Trend {
+	if t.history == nil {
+		t.history = make(map[string]*RingBuffer)
+	}
+	buf, ok := t.history[key]
+	if !ok {
+		buf = NewRingBuffer(min(trendWindow, historyRetention.capacity()))
+		t.history[key] = buf
+	}
+	buf.Add(v)
+	return trendFromSamples(buf.Slice())
+}
+
+// apply sets Trend on each reading and on thermal.CPUTempTrend. Sensors that
+// disappear are forgotten, so an unplugged sensor starts fresh if it returns.
+func (t *trendTracker) apply(readings []SensorReading, thermal *ThermalStatus) {
+	seen := make(map[string]bool, len(readings)+1)
+	for i := range readings {
+		key := readings[i].StableKey()
+		readings[i].Trend = t.observe(key, readings[i].Value)
+		seen[key] = true
+	}
+	if thermal.CPUTemp > 0 {
+		thermal.CPUTempTrend = t.observe(cpuTempTrendKey, thermal.CPUTemp)
+		seen[cpuTempTrendKey] = true
+	}
+	for key := range t.history {
+		if !seen[key] {
+			delete(t.history, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestTrendFromSamples(t *testing.T) {
+	cases := []struct {
+		samples []float64
+		want    Trend
+	}{
+		{[]float64{50, 51}, TrendUnknown},
+		{[]float64{50, 50.4, 49.8, 50.3}, TrendSteady},
+		{[]float64{50, 51, 52, 54}, TrendRising},
+		{[]float64{60, 58, 57, 55}, TrendFalling},
+	}
+	for _, tc := range cases {
+		if got := trendFromSamples(tc.samples); got != tc.want {
+			t.Errorf("trendFromSamples(%v) = %v, want %v", tc.samples, got, tc.want)
+		}
+	}
+}
+
+func TestTrendTrackerApply(t *testing.T) {
+	var tr trendTracker
+	for _, v := range []float64{60, 62, 65} {
+		readings := []SensorReading{{Label: "CPU Die", Value: v}, {Label: "GPU", Value: 40}}
+		thermal := ThermalStatus{CPUTemp: v}
+		tr.apply(readings, &thermal)
+		if v == 65 {
+			if readings[0].Trend != TrendRising || readings[1].Trend != TrendSteady {
+				t.Fatalf("unexpected sensor trends %v, %v", readings[0].Trend, readings[1].Trend)
+			}
+			if thermal.CPUTempTrend != TrendRising || thermal.CPUTempTrend.Arrow() != "↑" {
+				t.Fatalf("unexpected CPU trend %v", thermal.CPUTempTrend)
+			}
+		}
+	}
+
+	// A sensor that vanishes starts over when it comes back.
+	tr.apply([]SensorReading{{Label: "CPU Die", Value: 65}}, &ThermalStatus{})
+	readings := []SensorReading{{Label: "GPU", Value: 40}}
+	tr.apply(readings, &ThermalStatus{})
+	if readings[0].Trend != TrendUnknown {
+		t.Fatalf("expected a fresh history for GPU, got %v", readings[0].Trend)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+// Temperatures are stored in Celsius everywhere inside the collector; raw source
+// units are converted here, at the edge, so Kelvin or centi-degree values never leak.
+
+const kelvinOffset = 273.15
+
+// FromDeciKelvin converts tenths of a Kelvin (WMI MSAcpi_ThermalZoneTemperature) to Celsius.
+func FromDeciKelvin(v int) float64 {
+	return float64(v)/10.0 - kelvinOffset
+}
+
+// FromCentiCelsius converts hundredths of a degree (ioreg AppleSmartBattery) to Celsius.
+func FromCentiCelsius(v int) float64 {
+	return float64(v) / 100.0
+}
+
+// FahrenheitToCelsius converts a source that reports Fahrenheit; see --sensor-unit.
+func FahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// CelsiusToFahrenheit converts for display; nothing is stored in Fahrenheit.
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// FromMicro converts sysfs power_supply micro-units (µV, µA, µW) to volts, amps or watts.
+func FromMicro(v int64) float64 {
+	return float64(v) / 1e6
+}
+
+// FromMilli converts ioreg milli-units (mV, mA, mW) to volts, amps or watts.
+func FromMilli(v int64) float64 {
+	return float64(v) / 1e3
+}
+
+// Battery capacities are kept in the unit the OS reported (BatteryStatus.CapacityUnit)
+// and converted only for display and export. Charge and energy differ by the pack
+// voltage, Wh = mAh × V / 1000, so conversion needs a voltage reading.
+
+// MAhToWh converts charge in milliamp-hours to energy in watt-hours at volts.
+func MAhToWh(mah, volts float64) float64 {
+	return mah / 1000 * volts
+}
+
+// WhToMAh converts energy in watt-hours to charge in milliamp-hours at volts.
+func WhToMAh(wh, volts float64) float64 {
+	if volts <= 0 {
+		return 0
+	}
+	return wh * 1000 / volts
+}
+
+// ConvertCapacity converts v from one capacity unit to another at volts. ok is
+// false when the units differ and the voltage is unknown.
+func ConvertCapacity(v float64, from, to CapacityUnit, volts float64) (float64, bool) {
+	switch {
+	case from == to:
+		return v, true
+	case volts <= 0:
+		return 0, false
+	case from == CapacityMAh && to == CapacityWh:
+		return MAhToWh(v, volts), true
+	case from == CapacityWh && to == CapacityMAh:
+		return WhToMAh(v, volts), true
+	}
+	return 0, false
+}
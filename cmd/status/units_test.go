@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFromDeciKelvin(t *testing.T) {
+	tests := []struct {
+		raw  int
+		want float64
+	}{
+		{2732, 0.05},
+		{3232, 50.05},
+		{2731, -0.05},
+	}
+	for _, tt := range tests {
+		if got := FromDeciKelvin(tt.raw); math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("FromDeciKelvin(%d) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFromCentiCelsius(t *testing.T) {
+	if got := FromCentiCelsius(3055); got != 30.55 {
+		t.Fatalf("expected 30.55, got %v", got)
+	}
+}
+
+func TestConvertCapacity(t *testing.T) {
+	tests := []struct {
+		v        float64
+		from, to CapacityUnit
+		volts    float64
+		want     float64
+		ok       bool
+	}{
+		{5000, CapacityMAh, CapacityWh, 11.4, 57, true},
+		{57, CapacityWh, CapacityMAh, 11.4, 5000, true},
+		{57, CapacityWh, CapacityWh, 0, 57, true},
+		{5000, CapacityMAh, CapacityWh, 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ConvertCapacity(tt.v, tt.from, tt.to, tt.volts)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ConvertCapacity(%v, %s, %s, %v) = %v, %v; want %v, %v", tt.v, tt.from, tt.to, tt.volts, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTempUnitConvert(t *testing.T) {
+	for _, tc := range []struct {
+		celsius float64
+		unit    TempUnit
+		want    float64
+		text    string
+	}{
+		{0, TempFahrenheit, 32, "32.0°F"},
+		{100, TempFahrenheit, 212, "212.0°F"},
+		{-40, TempFahrenheit, -40, "-40.0°F"},
+		{54.5, TempCelsius, 54.5, "54.5°C"},
+		{54.5, "", 54.5, "54.5°C"},
+	} {
+		if got := tc.unit.Convert(tc.celsius); got != tc.want {
+			t.Errorf("%q.Convert(%v) = %v, want %v", tc.unit, tc.celsius, got, tc.want)
+		}
+		if got := tc.unit.format(tc.celsius); got != tc.text {
+			t.Errorf("%q.format(%v) = %q, want %q", tc.unit, tc.celsius, got, tc.text)
+		}
+	}
+}
+
+func TestFahrenheitDisplayKeepsCelsiusBounds(t *testing.T) {
+	prev := displayTempUnit
+	t.Cleanup(func() { displayTempUnit = prev })
+	displayTempUnit = TempFahrenheit
+
+	// The plausibility bound applies to the collected Celsius value: 120°C is
+	// kept although its 248°F display value is past the bound.
+	if !plausibleCelsius(120) || plausibleCelsius(maxPlausibleCelsius+1) {
+		t.Fatal("plausibility must be judged in Celsius")
+	}
+	r := SensorReading{Label: "CPU Die", Value: 120, Unit: "°C"}
+	if got := r.DisplayValue(); got != "248.0°F" {
+		t.Fatalf("DisplayValue = %q, want 248.0°F", got)
+	}
+	fan := SensorReading{Label: "Fan", Value: 2100, Unit: "RPM"}
+	if got := fan.DisplayValue(); got != "2100.0 RPM" {
+		t.Fatalf("non-temperature readings must not convert, got %q", got)
+	}
+}
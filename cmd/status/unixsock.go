@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// sockHub fans one snapshot stream out to every client on a Unix socket, one JSON
+// document per line. Like wsHub, a slow client only ever has the newest snapshot queued.
+type sockHub struct {
+	mu      sync.Mutex
+	clients map[*sockClient]bool
+	last    []byte
+}
+
+func newSockHub() *sockHub {
+	return &sockHub{clients: make(map[*sockClient]bool)}
+}
+
+// run broadcasts each snapshot until the channel closes, then disconnects everyone.
+func (h *sockHub) run(snapshots <-chan MetricsSnapshot) {
+	for snap := range snapshots {
+		msg, err := encodeSnapshot(snap)
+		if err != nil {
+			continue
+		}
+		msg = append(msg, '\n')
+		h.mu.Lock()
+		h.last = msg
+		for c := range h.clients {
+			c.offer(msg)
+		}
+		h.mu.Unlock()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		c.shutdown()
+	}
+}
+
+// serve pushes snapshots to conn until the client hangs up or the hub stops.
+func (h *sockHub) serve(conn net.Conn) {
+	c := &sockClient{conn: conn, send: make(chan []byte, 1), done: make(chan struct{})}
+	h.mu.Lock()
+	h.clients[c] = true
+	if h.last != nil {
+		c.offer(h.last)
+	}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+	}()
+	// Clients never send anything; a read returning means they disconnected.
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		c.shutdown()
+	}()
+	c.writeLoop()
+}
+
+// sockClient is one connection; send holds at most the newest unsent snapshot.
+type sockClient struct {
+	conn net.Conn
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+}
+
+func (c *sockClient) offer(msg []byte) {
+	select {
+	case <-c.send:
+	default:
+	}
+	select {
+	case c.send <- msg:
+	default:
+	}
+}
+
+func (c *sockClient) shutdown() {
+	c.once.Do(func() { close(c.done) })
+}
+
+func (c *sockClient) writeLoop() {
+	defer c.conn.Close()
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if _, err := c.conn.Write(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// listenUnix listens on path, replacing a socket file left behind by a crashed
+// run. A live listener at path is an error rather than something to steal, and
+// so is anything at path that isn't a socket: a typo must not delete a file.
+func listenUnix(path string) (*net.UnixListener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	// Snapshots include host details; keep them to the current user.
+	var ln *net.UnixListener
+	err := withPrivateUmask(func() (err error) {
+		ln, err = net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// runUnixSocket streams snapshots as newline-delimited JSON to every client that
+// connects to path, until ctx ends. The socket file is removed on the way out.
+func runUnixSocket(ctx context.Context, path string, interval time.Duration) error {
+	ln, err := listenUnix(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	hub := newSockHub()
+	go hub.run(NewCollector().Stream(ctx, interval))
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hub.serve(conn)
+		}()
+	}
+}
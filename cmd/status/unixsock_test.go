@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSockHubFansOutSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mole.sock")
+	ln, err := listenUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if _, err := listenUnix(path); err == nil {
+		t.Fatal("want an error for a socket that is already in use")
+	}
+
+	hub := newSockHub()
+	snapshots := make(chan MetricsSnapshot)
+	go hub.run(snapshots)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go hub.serve(conn)
+		}
+	}()
+
+	dial := func() (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		return conn, bufio.NewReader(conn)
+	}
+	gone, _ := dial()
+	_, r := dial()
+	waitForClients(t, hub, 2)
+	gone.Close()
+	waitForClients(t, hub, 1)
+
+	snapshots <- MetricsSnapshot{Host: "build-01"}
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(line, &got); err != nil || got["Host"] != "build-01" {
+		t.Fatalf("unexpected line %q: %v", line, err)
+	}
+	close(snapshots)
+	if _, err := r.ReadBytes('\n'); err == nil {
+		t.Fatal("want the connection closed when the stream ends")
+	}
+}
+
+func TestListenUnixReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mole.sock")
+	// A crashed run leaves its socket file behind with nobody listening.
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	if _, err := os.Lstat(path); err != nil {
+		t.Fatalf("stale socket file missing: %v", err)
+	}
+
+	ln, err := listenUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("socket mode = %v, %v; want 0600", fi.Mode().Perm(), err)
+	}
+}
+
+func TestListenUnixKeepsRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("keep me"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if ln, err := listenUnix(path); err == nil {
+		ln.Close()
+		t.Fatal("expected an error for a path that isn't a socket")
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "keep me" {
+		t.Fatalf("regular file was touched: %q, %v", got, err)
+	}
+}
+
+func waitForClients(t *testing.T, h *sockHub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		h.mu.Lock()
+		got := len(h.clients)
+		h.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("hub never reached %d clients", n)
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// withPrivateUmask runs fn with a 0177 umask, so a socket it creates is 0600 from
+// the start rather than after a chmod. The umask is process-wide, so this is
+// only for listenUnix, which runs before the collector starts.
+func withPrivateUmask(fn func() error) error {
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+	return fn()
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package main
+
+// withPrivateUmask just runs fn: there is no umask, and the chmod after listening
+// is all there is.
+func withPrivateUmask(fn func() error) error { return fn() }
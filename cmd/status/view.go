@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -165,6 +168,17 @@ func renderHeader(m MetricsSnapshot, errMsg string, animFrame int, termWidth int
 	if m.Hardware.OSVersion != "" {
 		infoParts = append(infoParts, m.Hardware.OSVersion)
 	}
+	if m.PowerProfile == PowerProfileLowPower {
+		infoParts = append(infoParts, warnStyle.Render(string(m.PowerProfile)))
+	} else if m.PowerProfile != PowerProfileUnknown {
+		infoParts = append(infoParts, string(m.PowerProfile))
+	}
+	if w := m.SystemWatts.String(); w != "" {
+		infoParts = append(infoParts, w)
+	}
+	if split := m.SystemWatts.Breakdown(); split != "" {
+		infoParts = append(infoParts, subtleStyle.Render(split))
+	}
 	if m.Uptime != "" {
 		infoParts = append(infoParts, subtleStyle.Render("up "+m.Uptime))
 	}
@@ -204,7 +218,7 @@ func getScoreStyle(score int) lipgloss.Style {
 	}
 }
 
-func renderCPUCard(cpu CPUStatus, thermal ThermalStatus) cardData {
+func renderCPUCard(cpu CPUStatus, thermal ThermalStatus, throttled bool, throttledFor time.Duration) cardData {
 	var lines []string
 
 	// Line 1: Usage + Temp (Format: 15% @ 30.4°C)
@@ -212,10 +226,34 @@ func renderCPUCard(cpu CPUStatus, thermal ThermalStatus) cardData {
 
 	headerText := fmt.Sprintf("%5.1f%%", cpu.Usage)
 	if thermal.CPUTemp > 0 {
-		headerText += fmt.Sprintf(" @ %s°C", colorizeTemp(thermal.CPUTemp))
+		headerText += " @ " + estimateMark(thermal) + colorizeTemp(thermal.CPUTemp)
+		if arrow := thermal.CPUTempTrend.Arrow(); arrow != "" {
+			headerText += " " + subtleStyle.Render(arrow)
+		}
+		// Only worth the space when cores disagree, e.g. one hot cluster.
+		if thermal.CPUTempAvg > 0 && thermal.CPUTemp-thermal.CPUTempAvg >= 5 {
+			headerText += subtleStyle.Render(" avg " + displayTempUnit.formatPrec(thermal.CPUTempAvg, 0))
+		}
+	}
+	if thermal.CPUPower > 0 {
+		headerText += fmt.Sprintf(" · %.1fW", thermal.CPUPower)
+	}
+	if thermal.GPUTemp > 0 {
+		headerText += " · GPU " + colorizeTemp(thermal.GPUTemp)
 	}
 
 	lines = append(lines, fmt.Sprintf("Total  %s  %s", usageBar, headerText))
+	if thermal.CPUTemp == 0 && thermal.PermissionDenied {
+		lines = append(lines, subtleStyle.Render("Temperature: permission denied"))
+	}
+	switch {
+	case thermal.Throttling && thermal.ThrottlePercent > 0 && thermal.ThrottlePercent < 100:
+		lines = append(lines, warnStyle.Render(fmt.Sprintf("Throttling to %d%% speed · %s this session", thermal.ThrottlePercent, throttledFor.Round(time.Second))))
+	case thermal.Throttling:
+		lines = append(lines, warnStyle.Render(fmt.Sprintf("Throttling · %s this session", throttledFor.Round(time.Second))))
+	case throttled:
+		lines = append(lines, subtleStyle.Render(fmt.Sprintf("Throttled %s this session", throttledFor.Round(time.Second))))
+	}
 
 	if cpu.PerCoreEstimated {
 		lines = append(lines, subtleStyle.Render("Per-core data unavailable, using averaged load"))
@@ -397,13 +435,9 @@ func renderProcessCard(procs []ProcessInfo) cardData {
 }
 
 func buildCards(m MetricsSnapshot, width int) []cardData {
-	cards := []cardData{
-		renderCPUCard(m.CPU, m.Thermal),
-		renderMemoryCard(m.Memory),
-		renderDiskCard(m.Disks, m.DiskIO),
-		renderBatteryCard(m.Batteries, m.Thermal),
-		renderProcessCard(m.TopProcesses),
-		renderNetworkCard(m.Network, m.NetworkHistory, m.Proxy, width),
+	cards := make([]cardData, len(viewSections))
+	for i, s := range viewSections {
+		cards[i] = renderViewSection(s, m, width)
 	}
 	// Sensors card disabled - redundant with CPU temp
 	// if hasSensorData(m.Sensors) {
@@ -423,13 +457,16 @@ func miniBar(percent float64) string {
 func renderNetworkCard(netStats []NetworkStatus, history NetworkHistory, proxy ProxyStatus, cardWidth int) cardData {
 	var lines []string
 	var totalRx, totalTx float64
-	var primaryIP string
+	var primaryIP, primaryLabel string
 
 	for _, n := range netStats {
 		totalRx += n.RxRateMBs
 		totalTx += n.TxRateMBs
 		if primaryIP == "" && n.IP != "" && n.Name == "en0" {
 			primaryIP = n.IP
+			if n.Label != n.Name {
+				primaryLabel = n.Label
+			}
 		}
 	}
 
@@ -459,7 +496,7 @@ func renderNetworkCard(netStats []NetworkStatus, history NetworkHistory, proxy P
 			infoParts = append(infoParts, "Proxy "+proxy.Type)
 		}
 		if primaryIP != "" {
-			infoParts = append(infoParts, primaryIP)
+			infoParts = append(infoParts, strings.TrimSpace(primaryLabel+" "+primaryIP))
 		}
 		if len(infoParts) > 0 {
 			lines = append(lines, strings.Join(infoParts, " · "))
@@ -518,18 +555,46 @@ func sparkline(history []float64, current float64, width int) string {
 	return okStyle.Render(result)
 }
 
-func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
+func renderBatteryCard(batts []BatteryStatus, battErr error, thermal ThermalStatus, charger ChargerInfo, display *DisplayStatus, assertions []PowerAssertion, energy []ProcessEnergy) cardData {
 	var lines []string
-	if len(batts) == 0 {
-		lines = append(lines, subtleStyle.Render("No battery"))
+	if b, ok := primaryBattery(batts); !ok {
+		if errors.Is(battErr, ErrBatteryPermission) {
+			lines = append(lines, warnStyle.Render("Battery permission denied"))
+			lines = append(lines, subtleStyle.Render("Grant read access to "+powerSupplyRoot))
+		} else if errors.Is(battErr, ErrBatteryUnreadable) {
+			lines = append(lines, warnStyle.Render("Battery unreadable"))
+			lines = append(lines, subtleStyle.Render("Check permissions or hardware"))
+		} else {
+			lines = append(lines, subtleStyle.Render("No battery"))
+		}
 	} else {
-		b := batts[0]
-		statusLower := strings.ToLower(b.Status)
-		percentText := fmt.Sprintf("%5.1f%%", b.Percent)
-		if b.Percent < 20 && statusLower != "charging" && statusLower != "charged" {
+		// The decimal is only meaningful with the raw charge behind it.
+		percentText := fmt.Sprintf("%5.1f%%", b.DisplayPercent())
+		charging := batteryCharging(b.Status)
+		if b.Percent < 20 && !charging {
 			percentText = dangerStyle.Render(percentText)
 		}
-		lines = append(lines, fmt.Sprintf("Level  %s  %s", batteryProgressBar(b.Percent), percentText))
+		if b.ChargeLimited && b.EffectiveFullPercent > 0 {
+			// Scale the gauge to the limit so a capped battery doesn't look stuck.
+			gauge := min(b.DisplayPercent()/b.EffectiveFullPercent*100, 100)
+			lines = append(lines, fmt.Sprintf("Level  %s  %s of %.0f%% limit", batteryProgressBar(gauge), percentText, b.EffectiveFullPercent))
+		} else {
+			lines = append(lines, fmt.Sprintf("Level  %s  %s", batteryProgressBar(b.DisplayPercent()), percentText))
+		}
+		if b.Kind == BatteryKindUPS && b.LoadPercent > 0 {
+			lines = append(lines, fmt.Sprintf("Load   %s  %5.1f%%", progressBar(b.LoadPercent), b.LoadPercent))
+		}
+		if in := b.Input; in != nil {
+			inputText := fmt.Sprintf("Input %.0fV", in.VoltageV)
+			if in.FrequencyHz > 0 {
+				inputText += fmt.Sprintf(" · %.1fHz", in.FrequencyHz)
+			}
+			if in.Low() {
+				lines = append(lines, warnStyle.Render(inputText+fmt.Sprintf(" · Low line (%.0fV nominal)", in.NominalV)))
+			} else {
+				lines = append(lines, subtleStyle.Render(inputText))
+			}
+		}
 
 		// Add capacity line if available.
 		if b.Capacity > 0 {
@@ -542,9 +607,13 @@ func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
 			lines = append(lines, fmt.Sprintf("Health %s  %s", batteryProgressBar(float64(b.Capacity)), capacityText))
 		}
 
+		if c, ok := b.DisplayCapacity(); ok && c.Design > 0 {
+			lines = append(lines, subtleStyle.Render(fmt.Sprintf("Capacity %s / %s %s", formatCapacity(c.Full, c.Unit), formatCapacity(c.Design, c.Unit), c.Unit)))
+		}
+
 		statusIcon := ""
 		statusStyle := subtleStyle
-		if statusLower == "charging" || statusLower == "charged" {
+		if charging {
 			statusIcon = " ⚡"
 			statusStyle = okStyle
 		} else if b.Percent < 20 {
@@ -554,11 +623,24 @@ func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
 		if len(statusText) > 0 {
 			statusText = strings.ToUpper(statusText[:1]) + strings.ToLower(statusText[1:])
 		}
-		if b.TimeLeft != "" {
+		if b.State == BatteryStateBypass {
+			// Say it's intentional, so a held battery doesn't read as a fault.
+			statusText = "Bypass · running on AC, battery held"
+		}
+		if b.TimeLeftUnreliable {
+			statusText += " · estimating…"
+		} else if b.TimeLeft != "" && (b.TimeLeft != "0:00" || b.State == BatteryStateDischarging) {
+			// On AC, "0:00" only says charging is done; the status already does.
 			statusText += " · " + b.TimeLeft
 		}
+		if d := b.OnBatteryFor; d > 0 {
+			statusText += " · on battery " + d.Round(time.Second).String()
+		}
+		if d := b.ComputedTimeLeft; d > 0 {
+			statusText += fmt.Sprintf(" (calc %d:%02d)", int(d.Hours()), int(d.Minutes())%60)
+		}
 		// Add power info.
-		if statusLower == "charging" || statusLower == "charged" {
+		if charging {
 			if thermal.SystemPower > 0 {
 				statusText += fmt.Sprintf(" · %.0fW", thermal.SystemPower)
 			} else if thermal.AdapterPower > 0 {
@@ -574,27 +656,158 @@ func renderBatteryCard(batts []BatteryStatus, thermal ThermalStatus) cardData {
 		if b.Health != "" {
 			healthParts = append(healthParts, b.Health)
 		}
+		if b.Worn {
+			healthParts = append(healthParts, warnStyle.Render("Worn"))
+		}
 		if b.CycleCount > 0 {
-			healthParts = append(healthParts, fmt.Sprintf("%d cycles", b.CycleCount))
+			cycleText := fmt.Sprintf("%d cycles", b.CycleCount)
+			if l := b.Longevity; l != nil {
+				cycleText = fmt.Sprintf("%d/%d cycles", b.CycleCount, l.RatedCycles)
+				if months, ok := l.MonthsRemaining(); ok {
+					cycleText += fmt.Sprintf(" (~%.0f mo left)", months)
+				}
+			}
+			healthParts = append(healthParts, cycleText)
 		}
 
 		if thermal.CPUTemp > 0 {
-			tempText := colorizeTemp(thermal.CPUTemp) + "°C" // Reuse common color logic
+			tempText := estimateMark(thermal) + colorizeTemp(thermal.CPUTemp)
 			healthParts = append(healthParts, tempText)
 		}
 
 		if thermal.FanSpeed > 0 {
-			healthParts = append(healthParts, fmt.Sprintf("%d RPM", thermal.FanSpeed))
+			fanText := fmt.Sprintf("%d RPM", thermal.FanSpeed)
+			if thermal.FanNoise != "" {
+				fanText += fmt.Sprintf(" (%s)", thermal.FanNoise)
+			}
+			if thermal.FanControlMode == FanControlManual {
+				fanText += " · Manual control"
+			}
+			healthParts = append(healthParts, fanText)
 		}
 
 		if len(healthParts) > 0 {
 			lines = append(lines, strings.Join(healthParts, " · "))
 		}
+
+		if b.Cells != nil {
+			cells := make([]string, len(b.Cells.Voltages))
+			for i, v := range b.Cells.Voltages {
+				cells[i] = strconv.FormatFloat(v, 'f', 3, 64)
+			}
+			cellText := fmt.Sprintf("Cells %sV · Δ%.0fmV", strings.Join(cells, "/"), b.Cells.ImbalanceMV)
+			if b.Cells.Imbalanced() {
+				lines = append(lines, warnStyle.Render(cellText+" · Imbalanced"))
+			} else {
+				lines = append(lines, subtleStyle.Render(cellText))
+			}
+		}
+
+		if share, ok := b.ChargeCurrentUtilization(); ok {
+			lines = append(lines, subtleStyle.Render(fmt.Sprintf("Charging at %.1fA of %.1fA max (%.0f%%)", b.CurrentA, b.MaxChargeCurrentA, share*100)))
+		}
+
+		if b.InstantCurrentA != 0 && b.AverageCurrentA != 0 {
+			lines = append(lines, subtleStyle.Render(fmt.Sprintf("Current %.2fA avg · %.2fA now", math.Abs(b.AverageCurrentA), math.Abs(b.InstantCurrentA))))
+		}
+
+		if b.Trend != nil {
+			if trend := b.Trend.String(time.Now()); trend != "" {
+				lines = append(lines, subtleStyle.Render(trend))
+			}
+		}
+
+		if b.LastFullCharge != nil && !b.atFull() {
+			lines = append(lines, subtleStyle.Render("Last full charge "+lastFullLabel(time.Since(*b.LastFullCharge))))
+		}
+
+		if charger.Connected && charger.Profile() != "" {
+			chargerText := fmt.Sprintf("Charger %.0fW · %s", charger.Watts, charger.Profile())
+			if charger.FastCharging {
+				lines = append(lines, okStyle.Render(chargerText+" · Fast"))
+			} else {
+				lines = append(lines, warnStyle.Render(chargerText+" · Slow"))
+			}
+		}
+		if charger.UnderpoweredCharger {
+			lines = append(lines, warnStyle.Render(fmt.Sprintf("Underpowered charger: %.0fW of %.0fW", charger.Watts, charger.ExpectedWatts)))
+		}
+		if display != nil {
+			lines = append(lines, subtleStyle.Render(displayText(*display)))
+		}
+		if awake := awakeText(assertions); awake != "" {
+			lines = append(lines, subtleStyle.Render(awake))
+		}
+		if drain := energyImpactText(energy); drain != "" {
+			lines = append(lines, subtleStyle.Render(drain))
+		}
 	}
 
 	return cardData{icon: iconBattery, title: "Power", lines: lines}
 }
 
+// awakeAssertionsShown caps how many owners the Power card names.
+const awakeAssertionsShown = 2
+
+// awakeText names what keeps the Mac awake, e.g. "Kept awake by coreaudiod
+// (PreventUserIdleSystemSleep) +1 more"; "" when nothing prevents sleep.
+func awakeText(assertions []PowerAssertion) string {
+	var holders []string
+	for _, a := range assertions {
+		if a.PreventsSleep() {
+			holders = append(holders, fmt.Sprintf("%s (%s)", a.Process, a.Type))
+		}
+	}
+	if len(holders) == 0 {
+		return ""
+	}
+	text := "Kept awake by " + strings.Join(holders[:min(len(holders), awakeAssertionsShown)], ", ")
+	if extra := len(holders) - awakeAssertionsShown; extra > 0 {
+		text += fmt.Sprintf(" +%d more", extra)
+	}
+	return text
+}
+
+// energyImpactShown caps how many processes the Power card names.
+const energyImpactShown = 3
+
+// energyImpactText names the biggest energy users, e.g. "Energy: Safari 42.1 ·
+// WindowServer 12.3"; "" when none were collected.
+func energyImpactText(procs []ProcessEnergy) string {
+	if len(procs) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, energyImpactShown)
+	for _, p := range procs[:min(len(procs), energyImpactShown)] {
+		parts = append(parts, fmt.Sprintf("%s %.1f", p.Name, p.Impact))
+	}
+	return "Energy: " + strings.Join(parts, " · ")
+}
+
+// displayText summarizes backlight levels, e.g. "Display 80% · Keyboard 40%".
+func displayText(d DisplayStatus) string {
+	var parts []string
+	if d.HasBrightness {
+		if d.BacklightOn {
+			parts = append(parts, fmt.Sprintf("Display %.0f%%", d.BrightnessPercent))
+		} else {
+			parts = append(parts, "Display off")
+		}
+	}
+	if d.HasKeyboard {
+		parts = append(parts, fmt.Sprintf("Keyboard %.0f%%", d.KeyboardPercent))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatCapacity prints mAh as a whole number and Wh to one decimal.
+func formatCapacity(v float64, unit CapacityUnit) string {
+	if unit == CapacityWh {
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 0, 64)
+}
+
 func renderCard(data cardData, width int, height int) string {
 	titleText := data.icon + " " + data.title
 	lineLen := max(width-lipgloss.Width(titleText)-2, 4)
@@ -672,14 +885,30 @@ func colorizeBattery(percent float64, s string) string {
 	}
 }
 
+// colorizeTemp renders a Celsius temperature in displayTempUnit, colored by the
+// Celsius value so the bands don't move with the unit.
 func colorizeTemp(t float64) string {
+	return tempStyle(t).Render(displayTempUnit.format(t))
+}
+
+// estimateMark is a leading "~" for an estimated CPU temperature, as SystemWatts
+// marks estimated power.
+func estimateMark(t ThermalStatus) string {
+	if !t.Estimated {
+		return ""
+	}
+	return subtleStyle.Render("~")
+}
+
+// tempStyle is the color for a Celsius temperature.
+func tempStyle(t float64) lipgloss.Style {
 	switch {
 	case t >= 76:
-		return dangerStyle.Render(fmt.Sprintf("%.1f", t))
+		return dangerStyle
 	case t >= 56:
-		return warnStyle.Render(fmt.Sprintf("%.1f", t))
+		return warnStyle
 	default:
-		return okStyle.Render(fmt.Sprintf("%.1f", t))
+		return okStyle
 	}
 }
 
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"reflect"
+	"time"
+)
+
+// ViewSection is one card of the TUI. The header isn't one: the cat animates on
+// every frame, so it is always drawn fresh.
+type ViewSection string
+
+const (
+	ViewCPU       ViewSection = "cpu"
+	ViewMemory    ViewSection = "memory"
+	ViewDisks     ViewSection = "disks"
+	ViewBattery   ViewSection = "battery"
+	ViewProcesses ViewSection = "processes"
+	ViewNetwork   ViewSection = "network"
+)
+
+// viewSections lists the cards in display order.
+var viewSections = []ViewSection{ViewCPU, ViewMemory, ViewDisks, ViewBattery, ViewProcesses, ViewNetwork}
+
+// renderViewSection draws one card from m.
+func renderViewSection(s ViewSection, m MetricsSnapshot, width int) cardData {
+	switch s {
+	case ViewCPU:
+		return renderCPUCard(m.CPU, m.Thermal, m.Throttled, m.ThrottledFor)
+	case ViewMemory:
+		return renderMemoryCard(m.Memory)
+	case ViewDisks:
+		return renderDiskCard(m.Disks, m.DiskIO)
+	case ViewBattery:
+		return renderBatteryCard(m.Batteries, m.BatteryErr, m.Thermal, m.Charger, m.Display, m.Assertions, m.ProcessEnergy)
+	case ViewProcesses:
+		return renderProcessCard(m.TopProcesses)
+	case ViewNetwork:
+		return renderNetworkCard(m.Network, m.NetworkHistory, m.Proxy, width)
+	}
+	return cardData{}
+}
+
+// viewSectionInputs is everything a card is drawn from, so two snapshots with equal
+// inputs render the same card. The battery card prints relative times ("5m ago"),
+// so it also depends on the minute.
+func viewSectionInputs(s ViewSection, m MetricsSnapshot, now time.Time) any {
+	switch s {
+	case ViewCPU:
+		return []any{m.CPU, m.Thermal, m.Throttled, m.ThrottledFor}
+	case ViewMemory:
+		return m.Memory
+	case ViewDisks:
+		return []any{m.Disks, m.DiskIO}
+	case ViewBattery:
+		errText := ""
+		if m.BatteryErr != nil {
+			errText = m.BatteryErr.Error()
+		}
+		return []any{m.Batteries, errText, m.Thermal, m.Charger, m.Display, m.Assertions, m.ProcessEnergy, now.Truncate(time.Minute)}
+	case ViewProcesses:
+		return m.TopProcesses
+	case ViewNetwork:
+		return []any{m.Network, m.NetworkHistory, m.Proxy}
+	}
+	return nil
+}
+
+// DirtySections lists, in display order, the cards whose inputs differ between prev
+// and cur. A nil prev marks every card dirty. A section that loses its data (the
+// last battery pack unplugged) differs from one that had it, so it is redrawn too.
+func DirtySections(prev *MetricsSnapshot, cur MetricsSnapshot) []ViewSection {
+	return dirtySectionsAt(prev, cur, time.Now())
+}
+
+func dirtySectionsAt(prev *MetricsSnapshot, cur MetricsSnapshot, now time.Time) []ViewSection {
+	if prev == nil {
+		return append([]ViewSection(nil), viewSections...)
+	}
+	var dirty []ViewSection
+	for _, s := range viewSections {
+		if !reflect.DeepEqual(viewSectionInputs(s, *prev, now), viewSectionInputs(s, cur, now)) {
+			dirty = append(dirty, s)
+		}
+	}
+	return dirty
+}
+
+// cardCache keeps the last drawn cards and only redraws the dirty ones, which keeps
+// each frame cheap on slow terminals. A width change redraws everything.
+type cardCache struct {
+	width int
+	last  *MetricsSnapshot
+	cards map[ViewSection]cardData
+	dirty []ViewSection // Sections redrawn by the last build
+}
+
+// build returns the cards for m in display order.
+func (c *cardCache) build(m MetricsSnapshot, width int) []cardData {
+	return c.buildAt(m, width, time.Now())
+}
+
+func (c *cardCache) buildAt(m MetricsSnapshot, width int, now time.Time) []cardData {
+	prev := c.last
+	if c.cards == nil || width != c.width {
+		prev = nil
+		c.cards = make(map[ViewSection]cardData, len(viewSections))
+	}
+	c.dirty = dirtySectionsAt(prev, m, now)
+	for _, s := range c.dirty {
+		c.cards[s] = renderViewSection(s, m, width)
+	}
+	c.width, c.last = width, &m
+	cards := make([]cardData, len(viewSections))
+	for i, s := range viewSections {
+		cards[i] = c.cards[s]
+	}
+	return cards
+}
+
+// Dirty reports the sections the last build redrew.
+func (c *cardCache) Dirty() []ViewSection {
+	return c.dirty
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCardCacheRedrawsDirtySections(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	m := MetricsSnapshot{
+		Memory: MemoryStatus{Total: 16 << 30, Used: 8 << 30, UsedPercent: 50},
+		Batteries: []BatteryStatus{
+			{Name: "InternalBattery-0", Percent: 80, Status: "discharging"},
+			{Name: "UPS", Kind: BatteryKindUPS, Percent: 100, Status: "Full"},
+		},
+	}
+	var c cardCache
+	c.buildAt(m, 60, now)
+	if !slices.Equal(c.Dirty(), viewSections) {
+		t.Fatalf("first build dirty = %v, want every section", c.Dirty())
+	}
+
+	c.buildAt(m, 60, now.Add(10*time.Second))
+	if len(c.Dirty()) != 0 {
+		t.Fatalf("unchanged snapshot dirty = %v", c.Dirty())
+	}
+
+	next := m
+	next.Memory.UsedPercent = 75
+	c.buildAt(next, 60, now.Add(20*time.Second))
+	if !slices.Equal(c.Dirty(), []ViewSection{ViewMemory}) {
+		t.Fatalf("memory change dirty = %v", c.Dirty())
+	}
+
+	// The UPS pack going away must redraw the battery card, not keep the stale one.
+	unplugged := next
+	unplugged.Batteries = next.Batteries[:1]
+	cards := c.buildAt(unplugged, 60, now.Add(30*time.Second))
+	if !slices.Equal(c.Dirty(), []ViewSection{ViewBattery}) {
+		t.Fatalf("unplugged pack dirty = %v", c.Dirty())
+	}
+	want := renderViewSection(ViewBattery, unplugged, 60)
+	if got := cards[slices.Index(viewSections, ViewBattery)]; !slices.Equal(got.lines, want.lines) {
+		t.Fatalf("battery card not redrawn:\n%v\nwant\n%v", got.lines, want.lines)
+	}
+
+	c.buildAt(unplugged, 80, now.Add(40*time.Second))
+	if !slices.Equal(c.Dirty(), viewSections) {
+		t.Fatalf("width change dirty = %v, want every section", c.Dirty())
+	}
+}
+
+func TestDirtySectionsNilPrev(t *testing.T) {
+	if got := DirtySections(nil, MetricsSnapshot{}); !slices.Equal(got, viewSections) {
+		t.Fatalf("DirtySections(nil) = %v", got)
+	}
+}
@@ -542,6 +542,42 @@ func TestParsePMSet(t *testing.T) {
 			wantStat: "charged",
 			wantTime: "",
 		},
+		{
+			name: "finishing charge",
+			raw: `Now drawing from 'AC Power'
+ -InternalBattery-0 (id=1234)	99%; finishing charge; 0:05 remaining present: true`,
+			health:   "Normal",
+			cycles:   10,
+			capacity: 100,
+			wantLen:  1,
+			wantPct:  99,
+			wantStat: "finishing charge",
+			wantTime: "0:05",
+		},
+		{
+			name: "ac attached not charging",
+			raw: `Now drawing from 'AC Power'
+ -InternalBattery-0 (id=1234)	80%; AC attached; not charging present: true`,
+			health:   "Normal",
+			cycles:   10,
+			capacity: 100,
+			wantLen:  1,
+			wantPct:  80,
+			wantStat: "not charging",
+			wantTime: "",
+		},
+		{
+			name: "ac attached not charging at full",
+			raw: `Now drawing from 'AC Power'
+ -InternalBattery-0 (id=1234)	100%; AC attached; not charging present: true`,
+			health:   "Normal",
+			cycles:   10,
+			capacity: 100,
+			wantLen:  1,
+			wantPct:  100,
+			wantStat: "charged",
+			wantTime: "",
+		},
 		{
 			name:     "empty output",
 			raw:      "",
@@ -562,7 +598,7 @@ func TestParsePMSet(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := parsePMSet(tt.raw, tt.health, tt.cycles, tt.capacity)
+			got := parsePMSet(tt.raw, []powerData{{Health: tt.health, Cycles: tt.cycles, Capacity: tt.capacity}})
 			if len(got) != tt.wantLen {
 				t.Errorf("parsePMSet() returned %d batteries, want %d", len(got), tt.wantLen)
 				return
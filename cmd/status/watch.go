@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// watchSparkWidth is how many recent samples each --watch sparkline shows.
+const watchSparkWidth = 20
+
+// runWatch collects every interval and prints one line per sample with battery
+// and CPU temperature sparklines, until ctx is cancelled. The history is bounded
+// by --history-samples.
+func runWatch(ctx context.Context, w io.Writer, interval time.Duration) error {
+	collector := NewCollector()
+	collector.Prime(ctx, primeInterval)
+	sampler := NewSampler(historyRetention.Samples)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Partial failures still leave the fields that did collect.
+		data, _ := collector.Collect(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		sampler.Add(sampleOf(data))
+		if _, err := fmt.Fprintln(w, formatWatchLine(sampler.Recent(watchSparkWidth), displayTempUnit)); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatWatchLine renders the newest sample with sparklines over samples, e.g.
+// "09:26:53  battery 72% ▅▄▃ -3.2%/h  cpu 54.5°C ▂▅█". Missing values are omitted.
+func formatWatchLine(samples []Sample, unit TempUnit) string {
+	if len(samples) == 0 {
+		return ""
+	}
+	latest := samples[len(samples)-1]
+	parts := []string{latest.At.Format("15:04:05")}
+
+	if latest.HasBattery {
+		var percents []float64
+		for _, s := range samples {
+			if s.HasBattery {
+				percents = append(percents, s.BatteryPercent)
+			}
+		}
+		text := fmt.Sprintf("battery %.0f%% %s", latest.BatteryPercent, plainSparkline(percents))
+		if rate, ok := batteryRate(samples); ok {
+			text += fmt.Sprintf(" %+.1f%%/h", rate)
+		}
+		parts = append(parts, text)
+	}
+	if latest.CPUTemp > 0 {
+		var temps []float64
+		for _, s := range samples {
+			if s.CPUTemp > 0 {
+				temps = append(temps, s.CPUTemp)
+			}
+		}
+		parts = append(parts, "cpu "+unit.format(latest.CPUTemp)+" "+plainSparkline(temps))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// plainSparkline draws values scaled between their own minimum and maximum, so
+// small movements such as a battery losing a few percent stay visible. A flat
+// series draws at mid height. No color, for logs and pipes.
+func plainSparkline(values []float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := len(blocks) / 2
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(blocks)-1))
+		}
+		b.WriteRune(blocks[level])
+	}
+	return b.String()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatWatchLine(t *testing.T) {
+	base := time.Date(2026, 10, 14, 9, 26, 0, 0, time.UTC)
+	samples := []Sample{
+		{At: base, BatteryPercent: 74, HasBattery: true, CPUTemp: 50},
+		{At: base.Add(30 * time.Minute), BatteryPercent: 73, HasBattery: true, CPUTemp: 60},
+		{At: base.Add(time.Hour), BatteryPercent: 72, HasBattery: true, CPUTemp: 55},
+	}
+	want := "10:26:00  battery 72% █▄▁ -2.0%/h  cpu 55.0°C ▁█▄"
+	if got := formatWatchLine(samples, TempCelsius); got != want {
+		t.Fatalf("formatWatchLine = %q, want %q", got, want)
+	}
+
+	// No battery and no temperature leaves only the time.
+	if got := formatWatchLine([]Sample{{At: base}}, TempCelsius); got != "09:26:00" {
+		t.Fatalf("empty sample: %q", got)
+	}
+}
+
+func TestPlainSparklineFlat(t *testing.T) {
+	if got := plainSparkline([]float64{80, 80, 80}); got != "▅▅▅" {
+		t.Fatalf("flat series = %q", got)
+	}
+}
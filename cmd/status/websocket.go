@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// wsGUID is the fixed RFC 6455 handshake suffix.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used by the push-only server.
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// wsMaxClientFrame bounds client frames; clients only send control frames.
+const wsMaxClientFrame = 4096
+
+// snapshotJSON is the wire form of a snapshot; errors don't marshal, so they travel as text.
+type snapshotJSON struct {
+	MetricsSnapshot
+	BatteryErr string `json:",omitempty"`
+}
+
+func encodeSnapshot(m MetricsSnapshot) ([]byte, error) {
+	out := snapshotJSON{MetricsSnapshot: m}
+	if m.BatteryErr != nil {
+		out.BatteryErr = m.BatteryErr.Error()
+	}
+	return json.Marshal(out)
+}
+
+// wsHub fans one snapshot stream out to every connected WebSocket client.
+type wsHub struct {
+	mu      sync.Mutex
+	clients map[*wsClient]bool
+	last    []byte // Sent to new clients so they don't wait a full tick
+}
+
+func newWSHub() *wsHub {
+	return &wsHub{clients: make(map[*wsClient]bool)}
+}
+
+// run broadcasts each snapshot until the channel closes, then disconnects everyone.
+func (h *wsHub) run(snapshots <-chan MetricsSnapshot) {
+	for snap := range snapshots {
+		msg, err := encodeSnapshot(snap)
+		if err != nil {
+			continue
+		}
+		h.mu.Lock()
+		h.last = msg
+		for c := range h.clients {
+			c.offer(msg)
+		}
+		h.mu.Unlock()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		c.shutdown()
+	}
+}
+
+func (h *wsHub) add(c *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[c] = true
+	if h.last != nil {
+		c.offer(h.last)
+	}
+}
+
+func (h *wsHub) remove(c *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, c)
+}
+
+// ServeHTTP upgrades the request and pushes snapshots as JSON text frames.
+func (h *wsHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+		return
+	}
+	// Browsers let any page open a WebSocket to localhost; only same-origin
+	// pages, and clients that send no Origin at all, may read snapshots.
+	if !wsOriginAllowed(r) {
+		http.Error(w, "cross-origin WebSocket refused", http.StatusForbidden)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", wsAccept(key))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+
+	c := &wsClient{conn: conn, send: make(chan []byte, 1), done: make(chan struct{})}
+	h.add(c)
+	defer h.remove(c)
+	go c.readLoop(rw.Reader)
+	c.writeLoop()
+}
+
+// wsOriginAllowed reports whether the request has no Origin header, as from a
+// script or CLI client, or one whose host matches the Host it was sent to.
+func wsOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// loopbackAddr binds a host-less listen address such as ":8080" to 127.0.0.1,
+// so the server isn't reachable from the network unless a host is named.
+func loopbackAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// wsAccept computes Sec-WebSocket-Accept for a client key.
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for part := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wsClient is one connection; send holds at most the newest unsent snapshot.
+type wsClient struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	send    chan []byte
+	done    chan struct{}
+	once    sync.Once
+}
+
+// offer queues msg, replacing a snapshot the client hasn't picked up yet.
+func (c *wsClient) offer(msg []byte) {
+	select {
+	case <-c.send:
+	default:
+	}
+	select {
+	case c.send <- msg:
+	default:
+	}
+}
+
+func (c *wsClient) shutdown() {
+	c.once.Do(func() { close(c.done) })
+}
+
+func (c *wsClient) writeLoop() {
+	defer c.conn.Close()
+	for {
+		select {
+		case <-c.done:
+			_ = c.writeFrame(wsOpClose, nil)
+			return
+		case msg := <-c.send:
+			if err := c.writeFrame(wsOpText, msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop answers pings and stops the client on close or any read error.
+func (c *wsClient) readLoop(r *bufio.Reader) {
+	defer c.shutdown()
+	for {
+		op, payload, err := readWSFrame(r)
+		if err != nil {
+			return
+		}
+		switch op {
+		case wsOpClose:
+			return
+		case wsOpPing:
+			if c.writeFrame(wsOpPong, payload) != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeFrame sends one unmasked, unfragmented frame; servers never mask.
+func (c *wsClient) writeFrame(op byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	header := []byte{0x80 | op}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// readWSFrame reads one masked client frame and returns its unmasked payload.
+func readWSFrame(r *bufio.Reader) (op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(r, head[:]); err != nil {
+		return 0, nil, err
+	}
+	op = head[0] & 0x0F
+	if head[1]&0x80 == 0 {
+		return 0, nil, errors.New("websocket: unmasked client frame")
+	}
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > wsMaxClientFrame {
+		return 0, nil, fmt.Errorf("websocket: client frame of %d bytes", n)
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+// runWebSocket serves live snapshots on ws://addr/ws until ctx is done.
+func runWebSocket(ctx context.Context, addr string, interval time.Duration) error {
+	hub := newWSHub()
+	go hub.run(NewCollector().Stream(ctx, interval))
+
+	mux := http.NewServeMux()
+	mux.Handle("/ws", hub)
+	srv := &http.Server{Addr: loopbackAddr(addr), Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
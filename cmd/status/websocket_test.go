@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWSAccept(t *testing.T) {
+	// Example from RFC 6455 section 1.3.
+	if got := wsAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// dialWS performs a client handshake against srv and returns the open connection.
+func dialWS(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req := "GET /ws HTTP/1.1\r\nHost: x\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("bad handshake: %s %v", resp.Status, resp.Header)
+	}
+	return conn, r
+}
+
+// readServerFrame reads one unmasked server frame.
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := uint64(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		_, _ = io.ReadFull(r, ext[:])
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, _ = io.ReadFull(r, ext[:])
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func TestWSHubFansOutSnapshots(t *testing.T) {
+	hub := newWSHub()
+	snapshots := make(chan MetricsSnapshot)
+	go hub.run(snapshots)
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	_, r1 := dialWS(t, srv)
+	_, r2 := dialWS(t, srv)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		hub.mu.Lock()
+		n := len(hub.clients)
+		hub.mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	snapshots <- MetricsSnapshot{Host: "mole-test", BatteryErr: ErrNoBattery}
+	for _, r := range []*bufio.Reader{r1, r2} {
+		op, payload := readServerFrame(t, r)
+		if op != wsOpText {
+			t.Fatalf("expected a text frame, got opcode %d", op)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(payload, &got); err != nil {
+			t.Fatalf("bad JSON %q: %v", payload, err)
+		}
+		if got["Host"] != "mole-test" || got["BatteryErr"] != ErrNoBattery.Error() {
+			t.Fatalf("unexpected snapshot %v", got)
+		}
+	}
+
+	close(snapshots)
+	if op, _ := readServerFrame(t, r1); op != wsOpClose {
+		t.Fatalf("expected a close frame when the stream ends, got opcode %d", op)
+	}
+}
+
+func TestWSRejectsPlainHTTP(t *testing.T) {
+	srv := httptest.NewServer(newWSHub())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("got %s, want 400", resp.Status)
+	}
+}
+
+func TestWSRejectsForeignOrigin(t *testing.T) {
+	srv := httptest.NewServer(newWSHub())
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	for origin, want := range map[string]int{
+		"https://evil.example": http.StatusForbidden,
+		"null":                 http.StatusForbidden,
+		"http://" + host:       http.StatusSwitchingProtocols,
+	} {
+		conn, err := net.Dial("tcp", host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		req := "GET /ws HTTP/1.1\r\nHost: " + host + "\r\nOrigin: " + origin + "\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"
+		if _, err := io.WriteString(conn, req); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		conn.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("Origin %q: got %s, want %d", origin, resp.Status, want)
+		}
+	}
+}
+
+func TestLoopbackAddr(t *testing.T) {
+	for in, want := range map[string]string{":8080": "127.0.0.1:8080", "0.0.0.0:8080": "0.0.0.0:8080", "[::1]:9": "[::1]:9"} {
+		if got := loopbackAddr(in); got != want {
+			t.Errorf("loopbackAddr(%q) = %q, want %q", in, got, want)
+		}
+	}
+}